@@ -0,0 +1,49 @@
+// Command openapi writes the API's OpenAPI document and the TypeScript types the
+// web UI is built against. Run it through go generate ./internal/api.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gocommender/internal/api"
+	"gocommender/internal/openapi"
+)
+
+func main() {
+	var (
+		output     = flag.String("o", "", "Write the OpenAPI document to this file (- for stdout)")
+		typescript = flag.String("ts", "", "Write TypeScript types and the endpoint table to this file")
+		version    = flag.String("version", "dev", "API version in the document")
+	)
+	flag.Parse()
+
+	if *output == "" && *typescript == "" {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-o openapi.json] [-ts schema.gen.ts]\n", os.Args[0])
+		os.Exit(2)
+	}
+
+	doc := api.OpenAPIDocument(*version)
+
+	if *output != "" {
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode OpenAPI document: %v", err)
+		}
+		data = append(data, '\n')
+		if *output == "-" {
+			os.Stdout.Write(data)
+		} else if err := os.WriteFile(*output, data, 0644); err != nil {
+			log.Fatalf("Failed to write OpenAPI document: %v", err)
+		}
+	}
+
+	if *typescript != "" {
+		if err := os.WriteFile(*typescript, []byte(openapi.TypeScript(doc)), 0644); err != nil {
+			log.Fatalf("Failed to write TypeScript types: %v", err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"gocommender/internal/db"
+	"gocommender/internal/models"
+)
+
+// runAPIKey handles "apikey create", "apikey list" and "apikey revoke"
+func runAPIKey(store *db.Store, args []string) error {
+	action := "list"
+	if len(args) > 0 {
+		action = args[0]
+		args = args[1:]
+	}
+
+	switch action {
+	case "create":
+		fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		name := fs.String("name", "", "Name describing who uses the key")
+		roleName := fs.String("role", string(models.RoleReadOnly), "Role: read-only, recommend or admin")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *name == "" {
+			return fmt.Errorf("-name is required")
+		}
+		role, err := models.ParseRole(*roleName)
+		if err != nil {
+			return err
+		}
+
+		key, record, err := db.NewAPIKey(*name, role)
+		if err != nil {
+			return err
+		}
+		if err := store.APIKeys.SaveAPIKey(record); err != nil {
+			return err
+		}
+
+		fmt.Printf("✅ Created %s key %d for %s\n", record.Role, record.ID, record.Name)
+		fmt.Println(key)
+		fmt.Println("Store the key now, it cannot be shown again.")
+		return nil
+
+	case "list":
+		keys, err := store.APIKeys.ListAPIKeys()
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			lastUsed := "never used"
+			if key.LastUsedAt != nil {
+				lastUsed = "used " + key.LastUsedAt.Format("2006-01-02 15:04")
+			}
+			state := "active"
+			if !key.IsActive() {
+				state = "revoked " + key.RevokedAt.Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%4d  %-10s… %-10s %-24s %-22s %s\n",
+				key.ID, key.Prefix, key.Role, key.Name, lastUsed, state)
+		}
+		fmt.Printf("%d keys\n", len(keys))
+		return nil
+
+	case "revoke":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: apikey revoke ID")
+		}
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid key ID %q", args[0])
+		}
+
+		revoked, err := store.APIKeys.RevokeAPIKey(id)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return fmt.Errorf("no active key with ID %d", id)
+		}
+		fmt.Printf("✅ Revoked key %d\n", id)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown apikey action %q (available: create, list, revoke)", action)
+	}
+}
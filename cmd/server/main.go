@@ -1,16 +1,31 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"runtime"
+	"sync"
+	"syscall"
+	"time"
 
 	"gocommender/internal/api"
 	"gocommender/internal/config"
 	"gocommender/internal/db"
+	"gocommender/internal/dialect"
+	"gocommender/internal/images"
+	"gocommender/internal/logging"
+	"gocommender/internal/metrics"
+	"gocommender/internal/models"
+	"gocommender/internal/notify"
 	"gocommender/internal/services"
+	"gocommender/internal/tracing"
+	"gocommender/web"
 )
 
 // Build information (set by ldflags during build)
@@ -20,6 +35,8 @@ var (
 	BuildDate = "unknown"
 )
 
+var logger = logging.For("server")
+
 // BuildInfo contains application build information
 type BuildInfo struct {
 	Version   string `json:"version"`
@@ -47,18 +64,28 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	logging.Setup(os.Stderr, cfg.Logging.Options())
 
 	if *configTest {
 		fmt.Println("✅ Configuration loaded successfully")
 		fmt.Printf("Plex URL: %s\n", cfg.Plex.URL)
 		fmt.Printf("Server: %s:%s\n", cfg.Server.Host, cfg.Server.Port)
 		fmt.Printf("Database: %s\n", cfg.Database.Path)
+		fmt.Printf("Database driver: %s\n", cfg.Database.Dialect())
 		showVersion()
 		return
 	}
 
+	// migrate runs before ConnectDatabase so status reflects the database as found
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(cfg, flag.Args()[1:]); err != nil {
+			log.Fatalf("Migrate failed: %v", err)
+		}
+		return
+	}
+
 	// Initialize database
-	database, err := config.InitDatabase(cfg.Database.Path)
+	database, err := config.ConnectDatabase(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -69,29 +96,151 @@ func main() {
 		return
 	}
 
+	store := db.NewStore(database, cfg.Database.Dialect())
+
+	// Subcommands
+	switch flag.Arg(0) {
+	case "":
+	case "reprocess":
+		if err := runReprocess(cfg, store, flag.Args()[1:]); err != nil {
+			log.Fatalf("Reprocess failed: %v", err)
+		}
+		return
+	case "apikey":
+		if err := runAPIKey(store, flag.Args()[1:]); err != nil {
+			log.Fatalf("API key command failed: %v", err)
+		}
+		return
+	case "export":
+		if err := runExport(store, flag.Args()[1:]); err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		return
+	case "import":
+		if err := runImport(cfg, store, flag.Args()[1:]); err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+		return
+	default:
+		log.Fatalf("Unknown command %q (available: apikey, export, import, migrate, reprocess)", flag.Arg(0))
+	}
+
+	// Export spans of recommendation requests
+	var traceExporter tracing.Exporter
+	switch cfg.Tracing.Exporter {
+	case "stdout":
+		traceExporter = tracing.NewJSONExporter(os.Stdout)
+		logger.Info("Tracing: writing spans to stdout")
+	case "otlp":
+		traceExporter = tracing.NewOTLPExporter(cfg.Tracing.OTLPEndpoint, cfg.Tracing.ServiceName)
+		logger.Info("Tracing: exporting spans", "endpoint", cfg.Tracing.OTLPEndpoint)
+	}
+	if traceExporter != nil {
+		tracing.SetExporter(traceExporter)
+	}
+
+	// Background services run until shutdown; cancelling backgroundCtx aborts
+	// work that does not finish within the shutdown timeout
+	backgroundCtx, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
+	var background sync.WaitGroup
+
 	// Initialize services
-	cacheManager := db.NewCacheManager(database)
+	cacheManager := db.NewCacheManagerWithStore(store)
+	cacheManager.SetTTLPolicy(cfg.Cache.Policy())
 	enrichmentService := services.NewEnrichmentService(
 		cfg.External.DiscogsToken,
 		cfg.External.LastFMAPIKey,
 		"", // Last.fm secret not used
+		cfg.External.Languages...,
 	)
+	enrichmentService.UseTTLPolicy(cfg.Cache.Policy())
+	if cfg.ResponseCache.Enabled {
+		enrichmentService.UseResponseCache(store.Responses, cfg.ResponseCache.TTLs(), false)
+	}
 	plexClient := services.NewPlexClient(cfg.Plex.URL, cfg.Plex.Token)
 	openaiClient, err := services.NewOpenAIClient(
 		cfg.OpenAI.APIKey,
 		cfg.OpenAI.Model,
 		"prompts/openai_recommendation.tmpl",
-		false, // debug
 	)
 	if err != nil {
 		log.Fatalf("Failed to initialize OpenAI client: %v", err)
 	}
+	// LLM usage is always recorded so /api/usage reports it, quotas only apply when set
+	prices := services.DefaultModelPrices()
+	if cfg.OpenAI.InputPrice > 0 || cfg.OpenAI.OutputPrice > 0 {
+		prices[cfg.OpenAI.Model] = services.ModelPrice{
+			InputPerMillion:  cfg.OpenAI.InputPrice,
+			OutputPerMillion: cfg.OpenAI.OutputPrice,
+		}
+	}
+	llmQuota := services.NewLLMQuota(store.Usage, services.QuotaConfig{
+		DailyTokens:   cfg.LLMQuota.DailyTokens,
+		MonthlyTokens: cfg.LLMQuota.MonthlyTokens,
+		DailyCost:     cfg.LLMQuota.DailyCost,
+		MonthlyCost:   cfg.LLMQuota.MonthlyCost,
+	}, prices)
+	openaiClient.UseQuota(llmQuota)
+	if !openaiClient.IsConfigured() {
+		logger.Warn("OPENAI_API_KEY not set, recommendations will use the similar-artist graph")
+	}
 	recommendationService := services.NewRecommendationService(
 		plexClient,
 		openaiClient,
 		enrichmentService,
+		cacheManager,
 	)
 
+	// Results of recommendation runs are sent to the targets they name
+	notifier, err := notify.New(cfg.Notifications.Options())
+	if err != nil {
+		log.Fatalf("Failed to configure notifications: %v", err)
+	}
+	recommendationService.UseNotifier(notifier)
+
+	// Artist artwork: prefer Discogs photos, then album covers, then Last.fm
+	imageConfig := images.DefaultConfig(cfg.Images.CacheDir)
+	imageConfig.MaxSize = cfg.Images.MaxSize
+	imageConfig.TTL = cfg.Images.TTL
+	imageCache, err := images.NewCache(imageConfig,
+		images.NewDiscogsSource(cfg.External.DiscogsToken),
+		images.NewCoverArtArchiveSource(""),
+		images.NewLastFMSource(),
+	)
+	if err != nil {
+		log.Fatalf("Failed to initialize image cache: %v", err)
+	}
+
+	// Background refresh of expired artists, also drains force-refresh requests
+	refreshService := db.NewRefreshService(cacheManager, db.DefaultRefreshConfig())
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		err := refreshService.Start(backgroundCtx, func(ctx context.Context, artist models.Artist) (*models.Artist, error) {
+			return enrichmentService.EnrichArtistByMBID(ctx, artist.MBID, nil)
+		})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Refresh service stopped", "error", err)
+		}
+	}()
+
+	// Scheduled SQLite snapshots; PostgreSQL is backed up with its own tooling
+	backupService := db.NewBackupService(database, cfg.Database.Dialect(), db.BackupConfig{
+		Dir:      cfg.Backup.Dir,
+		Interval: cfg.Backup.Interval,
+		Retain:   cfg.Backup.Retain,
+	})
+	if cfg.Backup.Interval > 0 && cfg.Database.Dialect() == dialect.SQLite {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			if err := backupService.Start(backgroundCtx); err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("Backup service stopped", "error", err)
+			}
+		}()
+	}
+
 	// Create build info
 	buildInfo := &api.BuildInfo{
 		Version:   Version,
@@ -101,22 +250,143 @@ func main() {
 		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 	}
 
+	// Process metrics alongside the application's own
+	metrics.NewGauge("gocommender_build_info", "Build information, always 1.",
+		"version", "commit", "go_version").Set(1, Version, Commit, buildInfo.GoVersion)
+	metrics.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+
 	// Create API server
 	apiServer := api.NewServer(
 		recommendationService,
 		enrichmentService,
 		plexClient,
 		cacheManager,
+		imageCache,
+		refreshService,
+		backupService,
 		buildInfo,
 	)
 
+	apiServer.UseQuota(llmQuota)
+
+	if ui := web.Dist(); ui != nil {
+		if err := apiServer.UseUI(ui); err != nil {
+			log.Fatalf("Failed to serve web UI: %v", err)
+		}
+	} else {
+		logger.Info("Built without the web UI, build with -tags embedui to serve it")
+	}
+
+	trusted, err := cfg.Auth.TrustedProxyPrefixes()
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+	if cfg.Auth.Enabled {
+		apiServer.UseAuth(api.NewAuthenticator(store.APIKeys, api.ProxyAuthConfig{
+			UserHeader:  cfg.Auth.ProxyUserHeader,
+			RoleHeader:  cfg.Auth.ProxyRoleHeader,
+			DefaultRole: models.Role(cfg.Auth.ProxyDefaultRole),
+			Trusted:     trusted,
+		}))
+		if cfg.Auth.ProxyUserHeader == "" && !hasActiveAPIKey(store.APIKeys) {
+			logger.Warn("Authentication is enabled but there is no API key, create one with `gocommender apikey create -name NAME -role ROLE`")
+		}
+	} else {
+		logger.Warn("AUTH_ENABLED=false, the API is open to anyone who can reach it")
+	}
+	if cfg.RateLimit.Enabled {
+		apiServer.UseRateLimits(api.RateLimits{
+			Address:   api.NewRateLimiter(cfg.RateLimit.AddressPerSecond, cfg.RateLimit.AddressBurst),
+			General:   api.NewRateLimiter(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst),
+			Recommend: api.NewRateLimiter(cfg.RateLimit.RecommendPerMinute/60, cfg.RateLimit.RecommendBurst),
+			Trusted:   trusted,
+		})
+	}
+
 	// Start HTTP server
 	addr := fmt.Sprintf("%s:%s", cfg.Server.Host, cfg.Server.Port)
-	log.Printf("🚀 GoCommender v%s starting on %s", Version, addr)
-	log.Printf("📝 Build: %s (%s)", getShortCommit(), BuildDate)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           apiServer,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	logger.Info("GoCommender starting", "version", Version, "addr", addr,
+		"commit", getShortCommit(), "build_date", BuildDate)
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		logger.Error("Server failed", "error", err)
+		exitCode = 1
+	case <-signals.Done():
+		stopSignals() // A second signal terminates immediately
+		logger.Info("Shutting down", "delay", cfg.Server.ShutdownDelay, "timeout", cfg.Server.ShutdownTimeout)
+		apiServer.Drain()
+		time.Sleep(cfg.Server.ShutdownDelay)
+	}
 
-	if err := http.ListenAndServe(addr, apiServer); err != nil {
-		log.Fatal("Server failed to start:", err)
+	// In-flight requests and background work share the shutdown timeout
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Warn("Requests still running at shutdown timeout, closing connections", "error", err)
+		server.Close()
+	}
+
+	refreshService.Stop()
+	backupService.Stop()
+	if !waitFor(ctx, &background) {
+		logger.Warn("Background services still running at shutdown timeout, cancelling them")
+		cancelBackground()
+		background.Wait()
+	}
+	if err := notifier.Close(ctx); err != nil {
+		logger.Warn("Notifications still being sent at shutdown timeout, cancelled them")
+	}
+
+	enrichmentService.Close()
+	if traceExporter != nil {
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancelFlush()
+		if err := traceExporter.Shutdown(flushCtx); err != nil {
+			logger.Warn("Failed to flush spans", "error", err)
+		}
+	}
+	if err := database.Close(); err != nil {
+		logger.Warn("Failed to close database", "error", err)
+	}
+
+	logger.Info("Shutdown complete")
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// waitFor waits for the group to finish, reporting false if ctx ends first
+func waitFor(ctx context.Context, group *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		group.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
 
@@ -142,3 +412,17 @@ func getShortCommit() string {
 	}
 	return Commit
 }
+
+// hasActiveAPIKey reports whether any API key can authenticate requests
+func hasActiveAPIKey(keys db.APIKeyRepository) bool {
+	list, err := keys.ListAPIKeys()
+	if err != nil {
+		return true // Reported when a request authenticates
+	}
+	for _, key := range list {
+		if key.IsActive() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"gocommender/internal/config"
+	"gocommender/internal/migrations"
+)
+
+// runMigrate handles "migrate status" and "migrate up"
+func runMigrate(cfg *config.Config, args []string) error {
+	action := "status"
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	database, err := config.OpenDatabase(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	switch action {
+	case "status":
+		statuses, err := migrations.GetStatus(database, cfg.Database.Dialect())
+		if err != nil {
+			return err
+		}
+
+		pending := 0
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			} else {
+				pending++
+			}
+			fmt.Printf("%04d_%-30s %s\n", status.Version, status.Name, state)
+		}
+		fmt.Printf("%d migrations, %d pending\n", len(statuses), pending)
+		return nil
+
+	case "up":
+		applied, err := migrations.Up(database, cfg.Database.Dialect())
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("✅ Database is up to date")
+			return nil
+		}
+		fmt.Printf("✅ Applied %d migrations\n", len(applied))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown migrate action %q (available: status, up)", action)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"gocommender/internal/config"
+	"gocommender/internal/db"
+	"gocommender/internal/logging"
+	"gocommender/internal/services"
+)
+
+var reprocessLog = logging.For("reprocess")
+
+// runReprocess re-derives cached artists from stored upstream responses without network calls.
+// Artists whose responses are incomplete are left untouched unless -allow-partial is given.
+func runReprocess(cfg *config.Config, store *db.Store, args []string) error {
+	fs := flag.NewFlagSet("reprocess", flag.ContinueOnError)
+	mbid := fs.String("mbid", "", "Reprocess a single artist")
+	limit := fs.Int("limit", 0, "Maximum number of artists to reprocess (0 = all)")
+	allowPartial := fs.Bool("allow-partial", false, "Save artists even when some responses are not cached")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cacheManager := db.NewCacheManagerWithStore(store)
+	cacheManager.SetTTLPolicy(cfg.Cache.Policy())
+
+	mbids := []string{*mbid}
+	if *mbid == "" {
+		var err error
+		mbids, err = cachedArtistMBIDs(cacheManager, *limit)
+		if err != nil {
+			return err
+		}
+	}
+
+	enrichmentService := services.NewEnrichmentService(
+		cfg.External.DiscogsToken,
+		cfg.External.LastFMAPIKey,
+		"", // Last.fm secret not used
+		cfg.External.Languages...,
+	)
+	defer enrichmentService.Close()
+	enrichmentService.UseTTLPolicy(cfg.Cache.Policy())
+	enrichmentService.UseResponseCache(store.Responses, cfg.ResponseCache.TTLs(), true)
+
+	var updated, partial, skipped int
+	for _, id := range mbids {
+		missesBefore := totalMisses(enrichmentService)
+
+		artist, err := enrichmentService.EnrichArtistByMBID(context.Background(), id, nil)
+		if err != nil {
+			reprocessLog.Warn("Skipping artist", "mbid", id, "error", err)
+			skipped++
+			continue
+		}
+
+		if totalMisses(enrichmentService) > missesBefore {
+			partial++
+			if !*allowPartial {
+				reprocessLog.Warn("Skipping artist, some responses are not cached", "mbid", id, "artist", artist.Name)
+				continue
+			}
+		}
+
+		if err := cacheManager.CacheArtist(artist, cacheManager.TTLPolicy()); err != nil {
+			return fmt.Errorf("failed to save %s: %w", id, err)
+		}
+		updated++
+	}
+
+	fmt.Printf("✅ Reprocessed %d of %d artists (%d incomplete, %d without MusicBrainz data)\n",
+		updated, len(mbids), partial, skipped)
+	return nil
+}
+
+// cachedArtistMBIDs lists cached artists, most popular first
+func cachedArtistMBIDs(cacheManager *db.CacheManager, limit int) ([]string, error) {
+	const pageSize = 200
+
+	var mbids []string
+	for offset := 0; ; offset += pageSize {
+		artists, err := cacheManager.GetArtistsByPopularity(db.PopularityFilter{Limit: pageSize, Offset: offset})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list cached artists: %w", err)
+		}
+
+		for _, artist := range artists {
+			mbids = append(mbids, artist.MBID)
+			if limit > 0 && len(mbids) >= limit {
+				return mbids, nil
+			}
+		}
+
+		if len(artists) < pageSize {
+			return mbids, nil
+		}
+	}
+}
+
+// totalMisses sums offline cache misses over all sources
+func totalMisses(enrichmentService *services.EnrichmentService) int64 {
+	var misses int64
+	for _, stats := range enrichmentService.GetResponseCacheStats() {
+		misses += stats.Misses
+	}
+	return misses
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gocommender/internal/config"
+	"gocommender/internal/db"
+)
+
+// runExport writes the artist cache as NDJSON to a file or stdout.
+// The summary goes to stderr so stdout can be piped into another instance.
+func runExport(store *db.Store, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "-", "Output file (- for stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	buffered := bufio.NewWriter(w)
+	stats, err := db.NewCacheManagerWithStore(store).Export(buffered)
+	if err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ Exported %d artists (%d aliases, %d releases, %d similar edges)\n",
+		stats.Artists, stats.Aliases, stats.Releases, stats.Similar)
+	return nil
+}
+
+// runImport reads an NDJSON export from a file or stdin into the artist cache
+func runImport(cfg *config.Config, store *db.Store, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	input := fs.String("i", "-", "Input file (- for stdin)")
+	policy := fs.String("on-conflict", db.ConflictNewestWins, "What to do with artists that are already cached: skip, overwrite or newest-wins")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := db.ValidateConflictPolicy(*policy); err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", *input, err)
+		}
+		defer file.Close()
+		r = file
+	}
+
+	// Records without an expiry get one from the configured policy
+	stats, err := db.NewCacheManagerWithStore(store).Import(r, *policy, cfg.Cache.Policy())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Imported %d artists (%d cached artists kept, %d unsupported records ignored)\n",
+		stats.Imported, stats.Skipped, stats.Ignored)
+	return nil
+}
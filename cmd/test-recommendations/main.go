@@ -8,9 +8,13 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
 
 	"gocommender/internal/config"
+	"gocommender/internal/logging"
 	"gocommender/internal/models"
+	"gocommender/internal/notify"
 	"gocommender/internal/services"
 )
 
@@ -20,10 +24,11 @@ func main() {
 	var count = flag.Int("count", 5, "Number of recommendations")
 	var verbose = flag.Bool("verbose", false, "Enable verbose logging")
 	var debug = flag.Bool("debug", false, "Enable debug logging")
+	var notifyTargets = flag.String("notify", "", "Comma-separated notification targets to send the result to (optional)")
 	flag.Parse()
 
 	if *playlist == "" {
-		fmt.Printf("Usage: %s -playlist=\"My Playlist\" [-genre=\"rock\"] [-count=5] [-verbose] [-debug]\n", os.Args[0])
+		fmt.Printf("Usage: %s -playlist=\"My Playlist\" [-genre=\"rock\"] [-count=5] [-notify=discord,email] [-verbose] [-debug]\n", os.Args[0])
 		fmt.Println("\nRequired environment variables:")
 		fmt.Println("  PLEX_URL=http://localhost:32400")
 		fmt.Println("  PLEX_TOKEN=your-plex-token")
@@ -39,11 +44,11 @@ func main() {
 	}
 
 	// Configure debug logging
+	logConfig := logging.Config{Format: "text", Level: slog.LevelInfo}
 	if *debug {
-		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-			Level: slog.LevelDebug,
-		})))
+		logConfig.Level = slog.LevelDebug
 	}
+	logging.Setup(os.Stderr, logConfig)
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -58,7 +63,7 @@ func main() {
 	plexClient := services.NewPlexClient(cfg.Plex.URL, cfg.Plex.Token)
 
 	// Create OpenAI client
-	openaiClient, err := services.NewOpenAIClient(cfg.OpenAI.APIKey, cfg.OpenAI.Model, cfg.OpenAI.PromptTemplatePath, *debug)
+	openaiClient, err := services.NewOpenAIClient(cfg.OpenAI.APIKey, cfg.OpenAI.Model, cfg.OpenAI.PromptTemplatePath)
 	if err != nil {
 		log.Fatalf("Failed to create OpenAI client: %v", err)
 	}
@@ -68,6 +73,7 @@ func main() {
 		cfg.External.DiscogsToken,
 		cfg.External.LastFMAPIKey,
 		"", // LastFM secret not used in current implementation
+		cfg.External.Languages...,
 	)
 
 	// Create recommendation service
@@ -75,11 +81,22 @@ func main() {
 		plexClient,
 		openaiClient,
 		enrichmentService,
+		nil, // No artist cache for one-off test runs
 	)
 
+	// Notifications let cron jobs push the results of scheduled runs
+	var notifier *notify.Dispatcher
+	if *notifyTargets != "" {
+		notifier, err = notify.New(cfg.Notifications.Options())
+		if err != nil {
+			log.Fatalf("Failed to configure notifications: %v", err)
+		}
+		recommendationService.UseNotifier(notifier)
+	}
+
 	// Test Plex connection
 	log.Println("Testing Plex connection...")
-	if err := plexClient.TestConnection(); err != nil {
+	if err := plexClient.TestConnection(context.Background()); err != nil {
 		log.Fatalf("Failed to connect to Plex: %v", err)
 	}
 
@@ -91,6 +108,9 @@ func main() {
 	if *genre != "" {
 		request.Genre = genre
 	}
+	if *notifyTargets != "" {
+		request.Notify = strings.Split(*notifyTargets, ",")
+	}
 
 	// Generate recommendations
 	log.Printf("Generating %d recommendations from playlist '%s'", *count, *playlist)
@@ -112,6 +132,15 @@ func main() {
 
 	fmt.Println(string(output))
 
+	// Notifications are sent in the background, including their retries
+	if notifier != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		if err := notifier.Close(ctx); err != nil {
+			log.Printf("Notifications still being sent after 5 minutes, cancelled them")
+		}
+	}
+
 	// Print stats to stderr if verbose
 	if *verbose {
 		fmt.Fprintf(os.Stderr, "\n=== Performance Stats ===\n")
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"gocommender/internal/db"
+	"gocommender/internal/models"
+)
+
+// Authentication errors, both answered with 401
+var (
+	ErrMissingCredentials = errors.New("API key required")
+	ErrInvalidAPIKey      = errors.New("invalid or revoked API key")
+)
+
+// touchInterval limits how often a key's last_used_at is written
+const touchInterval = time.Minute
+
+// ProxyAuthConfig trusts identity headers set by an authenticating reverse proxy
+type ProxyAuthConfig struct {
+	UserHeader  string         // Header carrying the authenticated user, e.g. X-Forwarded-User; empty disables
+	RoleHeader  string         // Optional header carrying a role or comma-separated groups
+	DefaultRole models.Role    // Role for users whose role header names no known role
+	Trusted     []netip.Prefix // Proxy addresses allowed to set the headers
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Name   string      `json:"name"`
+	Role   models.Role `json:"role"`
+	Method string      `json:"method"` // api_key or proxy
+}
+
+// principalKey stores the Principal in the request context
+type principalKey struct{}
+
+// PrincipalFromContext returns the caller authenticated by the auth middleware, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
+
+// Authenticator resolves API keys and trusted proxy headers to principals
+type Authenticator struct {
+	keys  db.APIKeyRepository
+	proxy ProxyAuthConfig
+}
+
+// NewAuthenticator creates an authenticator for the stored API keys and optional proxy headers
+func NewAuthenticator(keys db.APIKeyRepository, proxy ProxyAuthConfig) *Authenticator {
+	return &Authenticator{keys: keys, proxy: proxy}
+}
+
+// Authenticate identifies the caller. A presented API key always takes precedence
+// over proxy headers, and proxy headers are ignored unless the peer is a trusted proxy.
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if key := apiKeyFromRequest(r); key != "" {
+		return a.authenticateKey(key)
+	}
+
+	if principal := a.authenticateProxy(r); principal != nil {
+		return principal, nil
+	}
+
+	return nil, ErrMissingCredentials
+}
+
+// authenticateKey looks up an API key by its hash
+func (a *Authenticator) authenticateKey(key string) (*Principal, error) {
+	stored, err := a.keys.GetAPIKeyByHash(db.HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || !stored.IsActive() {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := a.keys.TouchAPIKey(stored.ID, time.Now(), touchInterval); err != nil {
+		logger.Warn("Failed to record API key use", "error", err)
+	}
+
+	return &Principal{Name: stored.Name, Role: stored.Role, Method: "api_key"}, nil
+}
+
+// authenticateProxy reads the identity headers of a trusted proxy, nil if there are none
+func (a *Authenticator) authenticateProxy(r *http.Request) *Principal {
+	if a.proxy.UserHeader == "" || !isTrustedAddr(peerAddr(r), a.proxy.Trusted) {
+		return nil
+	}
+
+	user := strings.TrimSpace(r.Header.Get(a.proxy.UserHeader))
+	if user == "" {
+		return nil
+	}
+
+	role := a.proxy.DefaultRole
+	if a.proxy.RoleHeader != "" {
+		// SSO proxies usually pass groups; the most privileged known role wins
+		for _, group := range strings.Split(r.Header.Get(a.proxy.RoleHeader), ",") {
+			if parsed, err := models.ParseRole(strings.TrimSpace(group)); err == nil && parsed.Allows(role) {
+				role = parsed
+			}
+		}
+	}
+
+	return &Principal{Name: user, Role: role, Method: "proxy"}
+}
+
+// peerAddr returns the address of the connection's peer without its port
+func peerAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// apiKeyFromRequest reads a key from "Authorization: Bearer" or X-API-Key
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		if scheme, token, ok := strings.Cut(auth, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+// requireRole wraps a handler so only callers with at least the given role reach it.
+// Without an authenticator (see UseAuth) every request is let through.
+func (s *Server) requireRole(role models.Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.auth == nil {
+			next(w, r)
+			return
+		}
+
+		principal, err := s.auth.Authenticate(r)
+		if err != nil {
+			if errors.Is(err, ErrMissingCredentials) || errors.Is(err, ErrInvalidAPIKey) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="gocommender"`)
+				writeErrorResponse(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			logger.ErrorContext(r.Context(), "Authentication failed", "error", err)
+			writeErrorResponse(w, "Authentication failed", http.StatusInternalServerError)
+			return
+		}
+
+		if !principal.Role.Allows(role) {
+			writeErrorResponse(w, "This endpoint requires the "+string(role)+" role", http.StatusForbidden)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	}
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"gocommender/internal/db"
+	"gocommender/internal/dialect"
+	"gocommender/internal/models"
+	"gocommender/internal/testutil"
+)
+
+// createAuthTestServer returns a server with authentication and one key per role
+func createAuthTestServer(t *testing.T) (*Server, map[models.Role]string) {
+	t.Helper()
+
+	database, cleanup := testutil.CreateTestDB(t)
+	t.Cleanup(cleanup)
+
+	store := db.NewStore(database, dialect.SQLite)
+	keys := make(map[models.Role]string)
+	for _, role := range []models.Role{models.RoleReadOnly, models.RoleRecommend, models.RoleAdmin} {
+		key, record, err := db.NewAPIKey(string(role)+"-key", role)
+		testutil.AssertNoError(t, err)
+		testutil.AssertNoError(t, store.APIKeys.SaveAPIKey(record))
+		keys[role] = key
+	}
+
+	server := createTestServer()
+	server.UseAuth(NewAuthenticator(store.APIKeys, ProxyAuthConfig{
+		UserHeader:  "X-Forwarded-User",
+		RoleHeader:  "X-Forwarded-Groups",
+		DefaultRole: models.RoleReadOnly,
+		Trusted:     []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}))
+	return server, keys
+}
+
+func TestRequireRole(t *testing.T) {
+	server, keys := createAuthTestServer(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		key      string
+		expected int
+	}{
+		{"info is open", "GET", "/api/info", "", http.StatusOK},
+		{"missing key", "GET", "/api/cache/stats", "", http.StatusUnauthorized},
+		{"unknown key", "GET", "/api/cache/stats", "gck_unknown", http.StatusUnauthorized},
+		{"read-only cannot recommend", "POST", "/api/recommend", keys[models.RoleReadOnly], http.StatusForbidden},
+		{"recommend cannot clear cache", "POST", "/api/cache/clear", keys[models.RoleRecommend], http.StatusForbidden},
+		{"recommend cannot back up", "POST", "/api/admin/backup", keys[models.RoleRecommend], http.StatusForbidden},
+		// Requests that pass authentication reach the handler, which rejects the bad request
+		{"recommend reaches handler", "GET", "/api/recommend", keys[models.RoleRecommend], http.StatusMethodNotAllowed},
+		{"admin reaches handler", "GET", "/api/cache/clear", keys[models.RoleAdmin], http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.key)
+			}
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// X-API-Key works like a bearer token
+	req := httptest.NewRequest("GET", "/api/cache/clear", nil)
+	req.Header.Set("X-API-Key", keys[models.RoleAdmin])
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+	testutil.AssertEqual(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestAuthenticatorRevokedKey(t *testing.T) {
+	database, cleanup := testutil.CreateTestDB(t)
+	defer cleanup()
+
+	keys := db.NewAPIKeyDB(database)
+	key, record, err := db.NewAPIKey("old", models.RoleAdmin)
+	testutil.AssertNoError(t, err)
+	testutil.AssertNoError(t, keys.SaveAPIKey(record))
+
+	auth := NewAuthenticator(keys, ProxyAuthConfig{})
+	req := httptest.NewRequest("GET", "/api/cache/stats", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	principal, err := auth.Authenticate(req)
+	testutil.AssertNoError(t, err)
+	testutil.AssertEqual(t, models.RoleAdmin, principal.Role)
+
+	_, err = keys.RevokeAPIKey(record.ID)
+	testutil.AssertNoError(t, err)
+	_, err = auth.Authenticate(req)
+	testutil.AssertEqual(t, ErrInvalidAPIKey, err)
+}
+
+func TestAuthenticatorProxyHeaders(t *testing.T) {
+	server, _ := createAuthTestServer(t)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		groups       string
+		expectedRole models.Role
+		expectedErr  error
+	}{
+		{"trusted proxy with default role", "10.1.2.3:4567", "", models.RoleReadOnly, nil},
+		{"highest known group wins", "10.1.2.3:4567", "staff, admin,recommend", models.RoleAdmin, nil},
+		{"unknown groups use default role", "10.1.2.3:4567", "staff", models.RoleReadOnly, nil},
+		{"untrusted peer is ignored", "192.0.2.1:4567", "admin", "", ErrMissingCredentials},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/cache/stats", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-User", "alex")
+			if tt.groups != "" {
+				req.Header.Set("X-Forwarded-Groups", tt.groups)
+			}
+
+			principal, err := server.auth.Authenticate(req)
+			if err != tt.expectedErr {
+				t.Fatalf("Expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err == nil && (principal.Role != tt.expectedRole || principal.Name != "alex" || principal.Method != "proxy") {
+				t.Errorf("Expected alex with role %s, got %+v", tt.expectedRole, principal)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"gocommender/internal/services"
+)
+
+// errorResponse is the body of every failed request
+type errorResponse struct {
+	Code      services.ErrorCode     `json:"code"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestID string                 `json:"request_id"`
+}
+
+// codeStatus maps service error codes to HTTP statuses
+var codeStatus = map[services.ErrorCode]int{
+	services.CodeInvalidRequest:      http.StatusBadRequest,
+	services.CodeUnauthorized:        http.StatusUnauthorized,
+	services.CodeForbidden:           http.StatusForbidden,
+	services.CodeNotFound:            http.StatusNotFound,
+	services.CodeMethodNotAllowed:    http.StatusMethodNotAllowed,
+	services.CodeRateLimited:         http.StatusTooManyRequests,
+	services.CodeInternal:            http.StatusInternalServerError,
+	services.CodeNotImplemented:      http.StatusNotImplemented,
+	services.CodeUnavailable:         http.StatusServiceUnavailable,
+	services.CodePlaylistNotFound:    http.StatusNotFound,
+	services.CodeNoSeedTracks:        http.StatusUnprocessableEntity,
+	services.CodeNoRecommendations:   http.StatusUnprocessableEntity,
+	services.CodeEngineUnavailable:   http.StatusServiceUnavailable,
+	services.CodeQuotaExceeded:       http.StatusTooManyRequests,
+	services.CodePlexUnauthorized:    http.StatusBadGateway,
+	services.CodePlexError:           http.StatusBadGateway,
+	services.CodeUpstreamUnavailable: http.StatusBadGateway,
+	services.CodeUpstreamTimeout:     http.StatusGatewayTimeout,
+}
+
+// statusCode is the default code of errors written with only a status
+func statusCode(status int) services.ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return services.CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return services.CodeUnauthorized
+	case http.StatusForbidden:
+		return services.CodeForbidden
+	case http.StatusNotFound:
+		return services.CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return services.CodeMethodNotAllowed
+	case http.StatusTooManyRequests:
+		return services.CodeRateLimited
+	case http.StatusNotImplemented:
+		return services.CodeNotImplemented
+	case http.StatusBadGateway:
+		return services.CodeUpstreamUnavailable
+	case http.StatusServiceUnavailable:
+		return services.CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return services.CodeUpstreamTimeout
+	}
+	return services.CodeInternal
+}
+
+// writeErrorResponse writes a standardized error response with the status's default code
+func writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	writeErrorCode(w, statusCode, "", message, nil)
+}
+
+// writeErrorCode writes an error response; an empty code is derived from the status
+func writeErrorCode(w http.ResponseWriter, status int, code services.ErrorCode, message string, details map[string]interface{}) {
+	if code == "" {
+		code = statusCode(status)
+	}
+	writeJSONResponse(w, errorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: w.Header().Get("X-Request-ID"), // Set by requestIDMiddleware
+	}, status)
+}
+
+// errorCode returns the code writeError would answer an error with
+func errorCode(err error) services.ErrorCode {
+	var netErr net.Error
+	switch code := services.Code(err); {
+	case code != "":
+		return code
+	case errors.Is(err, context.DeadlineExceeded):
+		return services.CodeUpstreamTimeout
+	case errors.As(err, &netErr):
+		return services.CodeUpstreamUnavailable
+	}
+	return services.CodeInternal
+}
+
+// writeError maps a service error to its status and code. Errors without a code
+// are logged and answered with fallback, so internal details are not leaked.
+func writeError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var serviceErr *services.Error
+	var plexErr *services.PlexError
+	var quotaErr *services.QuotaExceededError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &serviceErr):
+		writeErrorCode(w, codeStatus[serviceErr.Code], serviceErr.Code, serviceErr.Message, serviceErr.Details)
+	case errors.As(err, &quotaErr):
+		retryAfter := int(math.Ceil(time.Until(quotaErr.ResetAt).Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		writeErrorCode(w, http.StatusTooManyRequests, services.CodeQuotaExceeded, quotaErr.Error(), map[string]interface{}{
+			"period":   quotaErr.Period,
+			"limit":    quotaErr.Limit,
+			"used":     quotaErr.Used,
+			"max":      quotaErr.Max,
+			"reset_at": quotaErr.ResetAt,
+		})
+	case errors.As(err, &plexErr):
+		// Error() includes the request URL, which carries the Plex token
+		logger.WarnContext(r.Context(), "Plex error", "status", plexErr.StatusCode, "message", plexErr.Message)
+		code := services.Code(plexErr)
+		writeErrorCode(w, codeStatus[code], code, "Plex: "+plexErr.Message,
+			map[string]interface{}{"upstream_status": plexErr.StatusCode})
+	case errors.Is(err, context.DeadlineExceeded):
+		logger.WarnContext(r.Context(), fallback, "error", err)
+		writeErrorCode(w, http.StatusGatewayTimeout, services.CodeUpstreamTimeout, fallback+": upstream service timed out", nil)
+	case errors.As(err, &netErr):
+		logger.WarnContext(r.Context(), fallback, "error", err)
+		writeErrorCode(w, http.StatusBadGateway, services.CodeUpstreamUnavailable, fallback+": upstream service unreachable", nil)
+	default:
+		logger.ErrorContext(r.Context(), fallback, "error", err)
+		writeErrorCode(w, http.StatusInternalServerError, services.CodeInternal, fallback, nil)
+	}
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"gocommender/internal/services"
+	"gocommender/internal/testutil"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    services.ErrorCode
+		message string
+	}{
+		{
+			name:    "playlist not found",
+			err:     fmt.Errorf("failed to get seed tracks: %w", services.NewError(services.CodePlaylistNotFound, "playlist 'x' not found", nil)),
+			status:  http.StatusNotFound,
+			code:    services.CodePlaylistNotFound,
+			message: "playlist 'x' not found",
+		},
+		{
+			name:    "all filtered",
+			err:     services.NewError(services.CodeNoRecommendations, "all LLM suggestions were filtered out as known artists", nil),
+			status:  http.StatusUnprocessableEntity,
+			code:    services.CodeNoRecommendations,
+			message: "all LLM suggestions were filtered out as known artists",
+		},
+		{
+			name:    "plex unauthorized",
+			err:     fmt.Errorf("failed to find playlist: %w", &services.PlexError{StatusCode: http.StatusUnauthorized, Message: "Invalid or missing Plex token", URL: "http://plex/?X-Plex-Token=secret"}),
+			status:  http.StatusBadGateway,
+			code:    services.CodePlexUnauthorized,
+			message: "Plex: Invalid or missing Plex token",
+		},
+		{
+			name:    "upstream unreachable",
+			err:     fmt.Errorf("failed to get playlists: %w", &url.Error{Op: "Get", URL: "http://plex/?X-Plex-Token=secret", Err: syscall.ECONNREFUSED}),
+			status:  http.StatusBadGateway,
+			code:    services.CodeUpstreamUnavailable,
+			message: "Failed: upstream service unreachable",
+		},
+		{
+			name:    "timeout",
+			err:     fmt.Errorf("failed to get LLM suggestions: %w", context.DeadlineExceeded),
+			status:  http.StatusGatewayTimeout,
+			code:    services.CodeUpstreamTimeout,
+			message: "Failed: upstream service timed out",
+		},
+		{
+			name:    "unknown",
+			err:     errors.New("database is locked"),
+			status:  http.StatusInternalServerError,
+			code:    services.CodeInternal,
+			message: "Failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			w.Header().Set("X-Request-ID", "req_test")
+			writeError(w, httptest.NewRequest("GET", "/", nil), tt.err, "Failed")
+
+			testutil.AssertEqual(t, tt.status, w.Code)
+			testutil.AssertFalse(t, strings.Contains(w.Body.String(), "secret"))
+
+			var response errorResponse
+			testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			testutil.AssertEqual(t, tt.code, response.Code)
+			testutil.AssertEqual(t, tt.message, response.Message)
+			testutil.AssertEqual(t, "req_test", response.RequestID)
+		})
+	}
+}
+
+func TestWriteErrorQuota(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := &services.QuotaExceededError{Period: "daily", Limit: "tokens", Used: 1200, Max: 1000, ResetAt: time.Now().Add(time.Hour)}
+	writeError(w, httptest.NewRequest("POST", "/api/recommend", nil), fmt.Errorf("failed to get LLM suggestions: %w", err), "Failed")
+
+	testutil.AssertEqual(t, http.StatusTooManyRequests, w.Code)
+	testutil.AssertTrue(t, w.Header().Get("Retry-After") != "")
+
+	var response errorResponse
+	testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	testutil.AssertEqual(t, services.CodeQuotaExceeded, response.Code)
+	testutil.AssertEqual(t, "daily", response.Details["period"].(string))
+	testutil.AssertEqual(t, float64(1000), response.Details["max"].(float64))
+}
+
+func TestErrorCodesHaveStatus(t *testing.T) {
+	for _, code := range services.ErrorCodes {
+		if codeStatus[code] == 0 {
+			t.Errorf("Error code %s has no HTTP status", code)
+		}
+	}
+}
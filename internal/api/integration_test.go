@@ -1,11 +1,18 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"gocommender/internal/db"
+	"gocommender/internal/models"
 	"gocommender/internal/testutil"
 )
 
@@ -27,17 +34,17 @@ func TestMockServicesIntegration(t *testing.T) {
 		testutil.AssertNotNil(t, cacheManager)
 
 		// Test Plex client
-		playlists, err := plexClient.GetPlaylists()
+		playlists, err := plexClient.GetPlaylists(context.Background())
 		testutil.AssertNoError(t, err)
 		testutil.AssertTrue(t, len(playlists) > 0)
 
 		// Test error handling
 		plexClient.SetError(true)
-		_, err = plexClient.GetPlaylists()
+		_, err = plexClient.GetPlaylists(context.Background())
 		testutil.AssertError(t, err)
 
 		plexClient.SetError(false)
-		err = plexClient.TestConnection()
+		err = plexClient.TestConnection(context.Background())
 		testutil.AssertNoError(t, err)
 	})
 }
@@ -277,3 +284,251 @@ func TestConcurrentOperations(t *testing.T) {
 		testutil.AssertGreaterOrEqual(t, stats.Total, numGoroutines*artistsPerGoroutine)
 	})
 }
+
+func TestArtistGraphEndpoints(t *testing.T) {
+	database, cleanup := testutil.CreateTestDB(t)
+	defer cleanup()
+
+	cacheManager := db.NewCacheManager(database)
+	server := createTestServer()
+	server.cacheManager = cacheManager
+
+	seed := &models.Artist{
+		MBID:      "b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d",
+		Name:      "Seed Artist",
+		Verified:  models.VerificationMap{"lastfm": true},
+		Listeners: 250000,
+		Similar: []models.SimilarArtist{
+			{Name: "Neighbour", MBID: "a74b1b7f-71a5-4011-9441-d0b5e4122711", Match: 0.8, Source: models.SimilaritySourceLastFM},
+		},
+	}
+	testutil.AssertNoError(t, cacheManager.CacheArtist(seed, db.DefaultCacheConfig()))
+
+	t.Run("similar", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/artists/"+seed.MBID+"/similar", nil)
+		w := httptest.NewRecorder()
+		server.mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+
+		var response struct {
+			Similar []models.SimilarArtist `json:"similar"`
+		}
+		testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if len(response.Similar) != 1 || response.Similar[0].Name != "Neighbour" {
+			t.Errorf("Unexpected neighbours: %+v", response.Similar)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		neighbour := &models.Artist{
+			MBID:        "a74b1b7f-71a5-4011-9441-d0b5e4122711",
+			Name:        "Neighbour",
+			Genres:      models.Genres{"doom metal"},
+			Country:     "US",
+			Description: "Neighbouring band of the seed artist",
+		}
+		testutil.AssertNoError(t, cacheManager.CacheArtist(neighbour, db.DefaultCacheConfig()))
+
+		tests := map[string][]string{
+			"q=seed":                      {"Seed Artist", "Neighbour"},
+			"q=seed&sort=name":            {"Neighbour", "Seed Artist"},
+			"genre=metal":                 {"Neighbour"},
+			"country=us":                  {"Neighbour"},
+			"verified=true":               {"Seed Artist"},
+			"verified=lastfm&q=neighbour": {},
+		}
+		for query, expected := range tests {
+			req := httptest.NewRequest("GET", "/api/artists?"+query, nil)
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: expected status %d, got %d: %s", query, http.StatusOK, w.Code, w.Body.String())
+			}
+
+			var response struct {
+				Artists []models.Artist `json:"artists"`
+			}
+			testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+			var names []string
+			for _, artist := range response.Artists {
+				names = append(names, artist.Name)
+			}
+			if fmt.Sprint(names) != fmt.Sprint(expected) {
+				t.Errorf("%s: expected %v, got %v", query, expected, names)
+			}
+		}
+
+		// Follow next_page until the listing is exhausted
+		var names []string
+		next := "/api/artists?sort=name&limit=1"
+		for next != "" {
+			req := httptest.NewRequest("GET", next, nil)
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, req)
+
+			var response struct {
+				Artists  []models.Artist `json:"artists"`
+				NextPage string          `json:"next_page"`
+			}
+			testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			for _, artist := range response.Artists {
+				names = append(names, artist.Name)
+			}
+
+			next = ""
+			if response.NextPage != "" {
+				next = "/api/artists?sort=name&limit=1&page=" + response.NextPage
+			}
+		}
+		if fmt.Sprint(names) != "[Neighbour Seed Artist]" {
+			t.Errorf("Expected to page through all artists by name, got %v", names)
+		}
+	})
+
+	t.Run("popularity filter", func(t *testing.T) {
+		for query, expected := range map[string]int{"min_listeners=100000": 1, "min_listeners=500000": 0} {
+			req := httptest.NewRequest("GET", "/api/artists?"+query, nil)
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, req)
+
+			var response struct {
+				Artists []models.Artist `json:"artists"`
+			}
+			testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if len(response.Artists) != expected {
+				t.Errorf("%s: expected %d artists, got %d", query, expected, len(response.Artists))
+			}
+		}
+	})
+}
+
+func TestAdminExportImport(t *testing.T) {
+	sourceDB, cleanupSource := testutil.CreateTestDB(t)
+	defer cleanupSource()
+	targetDB, cleanupTarget := testutil.CreateTestDB(t)
+	defer cleanupTarget()
+
+	source := createTestServer()
+	source.cacheManager = db.NewCacheManager(sourceDB)
+	artist := &models.Artist{
+		MBID:     "b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d",
+		Name:     "Seed Artist",
+		Verified: models.VerificationMap{"musicbrainz": true},
+	}
+	testutil.AssertNoError(t, source.cacheManager.CacheArtist(artist, db.DefaultCacheConfig()))
+
+	req := httptest.NewRequest("GET", "/api/admin/export", nil)
+	w := httptest.NewRecorder()
+	source.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("Expected NDJSON export, got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	export := w.Body.String()
+
+	target := createTestServer()
+	target.cacheManager = db.NewCacheManager(targetDB)
+
+	for _, expected := range []int{1, 0} {
+		req := httptest.NewRequest("POST", "/api/admin/import?on_conflict=skip", strings.NewReader(export))
+		w := httptest.NewRecorder()
+		target.mux.ServeHTTP(w, req)
+
+		var response struct {
+			Imported int `json:"imported"`
+			Skipped  int `json:"skipped"`
+		}
+		testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if w.Code != http.StatusOK || response.Imported != expected || response.Skipped != 1-expected {
+			t.Errorf("Expected %d imported, got %d: %s", expected, w.Code, w.Body.String())
+		}
+	}
+
+	req = httptest.NewRequest("POST", "/api/admin/import", strings.NewReader("not json\n"))
+	w = httptest.NewRecorder()
+	target.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a malformed export, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestAdminImportOutlivesReadTimeout(t *testing.T) {
+	source, cleanupSource := testutil.CreateTestDB(t)
+	defer cleanupSource()
+	target, cleanupTarget := testutil.CreateTestDB(t)
+	defer cleanupTarget()
+
+	sourceManager := db.NewCacheManager(source)
+	testutil.AssertNoError(t, sourceManager.CacheArtist(&models.Artist{
+		MBID: "b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d",
+		Name: "Seed Artist",
+	}, db.DefaultCacheConfig()))
+	var export strings.Builder
+	_, err := sourceManager.Export(&export)
+	testutil.AssertNoError(t, err)
+
+	server := createTestServer()
+	server.cacheManager = db.NewCacheManager(target)
+	httpServer := httptest.NewUnstartedServer(server)
+	httpServer.Config.ReadTimeout = 100 * time.Millisecond
+	httpServer.Config.WriteTimeout = 100 * time.Millisecond
+	httpServer.Start()
+	defer httpServer.Close()
+
+	// The upload trickles in for longer than the server's read and write timeouts
+	body, upload := io.Pipe()
+	go func() {
+		half := export.Len() / 2
+		io.WriteString(upload, export.String()[:half])
+		time.Sleep(300 * time.Millisecond)
+		io.WriteString(upload, export.String()[half:])
+		upload.Close()
+	}()
+
+	resp, err := http.Post(httpServer.URL+"/api/admin/import", "application/x-ndjson", body)
+	testutil.AssertNoError(t, err)
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		response, _ := io.ReadAll(resp.Body)
+		t.Errorf("Expected a slow import to succeed, got %d: %s", resp.StatusCode, response)
+	}
+}
+
+func TestCacheClearEndpoint(t *testing.T) {
+	database, cleanup := testutil.CreateTestDB(t)
+	defer cleanup()
+
+	server := createTestServer()
+	server.cacheManager = db.NewCacheManager(database)
+	for _, artist := range testutil.TestArtistCollection() {
+		testutil.AssertNoError(t, server.cacheManager.CacheArtist(&artist, db.DefaultCacheConfig()))
+	}
+	mbid := testutil.TestArtistCollection()[0].MBID
+
+	clearCache := func(query string) map[string]interface{} {
+		req := httptest.NewRequest("POST", "/api/cache/clear?"+query, nil)
+		w := httptest.NewRecorder()
+		server.mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d: %s", query, http.StatusOK, w.Code, w.Body.String())
+		}
+		var response map[string]interface{}
+		testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	invalidated := clearCache("type=invalidate&mbid=" + mbid)["invalidated"].(map[string]interface{})
+	testutil.AssertEqual(t, 1.0, invalidated["artists"].(float64))
+	_, needsFetch, err := server.cacheManager.GetOrFetchArtist(mbid)
+	testutil.AssertNoError(t, err)
+	testutil.AssertTrue(t, needsFetch)
+
+	cleared := clearCache("type=all")["cleared"].(map[string]interface{})
+	testutil.AssertEqual(t, float64(len(testutil.TestArtistCollection())), cleared["artists"].(float64))
+}
@@ -0,0 +1,395 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"gocommender/internal/db"
+	"gocommender/internal/models"
+	"gocommender/internal/openapi"
+	"gocommender/internal/services"
+)
+
+//go:generate go run ../../cmd/openapi -ts ../../web/src/types/schema.gen.ts
+
+// OpenAPIDocument describes every route of the API. Model schemas are derived from
+// the Go types the handlers encode; the contract tests check handlers against it.
+func OpenAPIDocument(version string) *openapi.Document {
+	doc := openapi.New("GoCommender API", version)
+	doc.Info.Description = "Music discovery backend using Plex, LLMs, and external APIs"
+	doc.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		"bearerAuth": {Type: "http", Scheme: "bearer", Description: "API key as a bearer token"},
+		"apiKey":     {Type: "apiKey", In: "header", Name: "X-API-Key"},
+	}
+	doc.Security = []openapi.SecurityRequirement{{"bearerAuth": {}}, {"apiKey": {}}}
+	doc.Tags = []openapi.Tag{
+		{Name: "recommendations", Description: "Artist recommendations from Plex playlists"},
+		{Name: "artists", Description: "Cached artist data"},
+		{Name: "plex", Description: "Plex server access"},
+		{Name: "cache", Description: "Artist cache maintenance"},
+		{Name: "admin", Description: "Export, import and backups"},
+		{Name: "system", Description: "Health, build information and metrics"},
+	}
+
+	// Hand-written schemas of the responses handlers build as maps
+	codes := make([]string, len(services.ErrorCodes))
+	for i, code := range services.ErrorCodes {
+		codes[i] = string(code)
+	}
+	errorSchema := doc.Define("ErrorResponse", openapi.Object(map[string]*openapi.Schema{
+		"code":       openapi.Enum(codes...).Describe("Stable identifier of the failure for clients to match on"),
+		"message":    openapi.String().Describe("Human-readable description"),
+		"details":    openapi.Map(openapi.Any()).Describe("Context such as the playlist, quota or retry_after, depending on the code"),
+		"request_id": openapi.String().Describe("ID of the request, also in the X-Request-ID header"),
+	}, "code", "message", "request_id"))
+	connection := func(counts bool) *openapi.Schema {
+		properties := map[string]*openapi.Schema{
+			"status": openapi.Enum("connected", "error"),
+			"error":  openapi.Enum(codes...).Describe("Code of the failure, the details are logged"),
+		}
+		if counts {
+			properties["total_entries"] = openapi.Integer()
+			properties["valid_entries"] = openapi.Integer()
+			properties["expired_entries"] = openapi.Integer()
+		}
+		return openapi.Object(properties, "status")
+	}
+	health := doc.Define("HealthResponse", openapi.Object(map[string]*openapi.Schema{
+		"status":    openapi.String(),
+		"service":   openapi.String(),
+		"timestamp": openapi.DateTime(),
+		"version":   openapi.String(),
+		"database":  connection(true),
+		"plex":      connection(false),
+	}, "status", "service", "timestamp", "version", "database", "plex"))
+	liveness := doc.Define("LivenessResponse", openapi.Object(map[string]*openapi.Schema{
+		"status": openapi.Enum("alive"),
+	}, "status"))
+	readiness := doc.Define("ReadinessResponse", openapi.Object(map[string]*openapi.Schema{
+		"status": openapi.Enum("ready", "unavailable", "draining"),
+		"checks": openapi.Map(openapi.Enum("ok", "error")).Describe("Result of each dependency check"),
+	}, "status", "checks"))
+	info := doc.Define("InfoResponse", openapi.Object(map[string]*openapi.Schema{
+		"service":      openapi.String(),
+		"description":  openapi.String(),
+		"build":        doc.SchemaOf(BuildInfo{}),
+		"features":     openapi.Array(openapi.String()),
+		"data_sources": openapi.Array(openapi.String()),
+	}, "service", "description", "build", "features", "data_sources"))
+	root := doc.Define("RootResponse", openapi.Object(map[string]*openapi.Schema{
+		"service":     openapi.String(),
+		"version":     openapi.String(),
+		"description": openapi.String(),
+		"endpoints":   openapi.Map(openapi.String()).Describe("Summary of each route, by method and path"),
+	}, "service", "version", "description", "endpoints"))
+	artist := doc.Define("ArtistResponse", openapi.Object(map[string]*openapi.Schema{
+		"artist":      doc.SchemaOf(models.Artist{}),
+		"needs_fetch": openapi.Boolean().Describe("The artist is not cached or has expired, and is null until it is fetched"),
+	}, "artist", "needs_fetch"))
+	artistList := doc.Define("ArtistListResponse", openapi.Object(map[string]*openapi.Schema{
+		"artists":   openapi.Array(doc.SchemaOf(models.Artist{})),
+		"count":     openapi.Integer(),
+		"limit":     openapi.Integer(),
+		"offset":    openapi.Integer(),
+		"sort":      openapi.Enum("relevance", "name", "listeners"),
+		"next_page": openapi.String().Describe("Cursor for the following page, empty on the last page"),
+	}, "artists", "count", "limit", "offset", "sort", "next_page"))
+	similar := doc.Define("SimilarArtistsResponse", openapi.Object(map[string]*openapi.Schema{
+		"mbid":    openapi.String(),
+		"similar": openapi.Array(doc.SchemaOf(models.SimilarArtist{})),
+		"count":   openapi.Integer(),
+	}, "mbid", "similar", "count"))
+	playlists := doc.Define("PlaylistsResponse", openapi.Object(map[string]*openapi.Schema{
+		"playlists": openapi.Array(doc.SchemaOf(models.PlexPlaylist{})),
+		"count":     openapi.Integer(),
+	}, "playlists", "count"))
+	plexTest := doc.Define("PlexTestResponse", openapi.Object(map[string]*openapi.Schema{
+		"status": openapi.Enum("connected"),
+		"server": openapi.Map(openapi.String()),
+	}, "status", "server"))
+	usage := doc.Define("UsageResponse", openapi.Object(map[string]*openapi.Schema{
+		"llm":       doc.SchemaOf(services.QuotaStatus{}),
+		"timestamp": openapi.DateTime(),
+	}, "llm", "timestamp"))
+	cacheClear := doc.Define("CacheClearResponse", openapi.Object(map[string]*openapi.Schema{
+		"status":      openapi.Enum("success"),
+		"message":     openapi.String(),
+		"type":        openapi.Enum("expired", "all", "invalidate", "refresh"),
+		"cleared":     doc.SchemaOf(db.ClearResult{}),
+		"invalidated": doc.SchemaOf(db.InvalidationResult{}),
+	}, "status", "message", "type"))
+	imported := doc.Define("ImportResponse", openapi.Object(map[string]*openapi.Schema{
+		"status":      openapi.Enum("success"),
+		"on_conflict": openapi.Enum(db.ConflictSkip, db.ConflictOverwrite, db.ConflictNewestWins),
+		"imported":    openapi.Integer(),
+		"skipped":     openapi.Integer(),
+		"ignored":     openapi.Integer(),
+	}, "status", "on_conflict", "imported", "skipped", "ignored"))
+
+	withErrors := func(op *openapi.Operation, codes ...int) *openapi.Operation {
+		for _, code := range codes {
+			op.Responses[strconv.Itoa(code)] = openapi.JSON(http.StatusText(code), errorSchema)
+		}
+		return op
+	}
+	// protected documents the role and rate limit checks of s.protect and s.requireRole
+	protected := func(role models.Role, op *openapi.Operation) *openapi.Operation {
+		op.Description = joinSentences(op.Description, "Requires the `"+string(role)+"` role when authentication is enabled.")
+		op.Responses["429"] = rateLimitedResponse(errorSchema)
+		return withErrors(op, http.StatusUnauthorized, http.StatusForbidden)
+	}
+	mbid := openapi.Parameter{Name: "mbid", In: "path", Required: true, Description: "MusicBrainz artist ID",
+		Schema: &openapi.Schema{Type: "string", Format: "uuid"}}
+
+	// System
+	doc.Add("GET", "/api/health", &openapi.Operation{
+		OperationID: "getHealth",
+		Summary:     "Service health check",
+		Tags:        []string{"system"},
+		Security:    openapi.NoSecurity(),
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Database and Plex connectivity", health)},
+	})
+	doc.Add("GET", "/api/health/live", &openapi.Operation{
+		OperationID: "getLiveness",
+		Summary:     "Liveness probe",
+		Description: "Succeeds while the process serves requests.",
+		Tags:        []string{"system"},
+		Security:    openapi.NoSecurity(),
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Alive", liveness)},
+	})
+	doc.Add("GET", "/api/health/ready", &openapi.Operation{
+		OperationID: "getReadiness",
+		Summary:     "Readiness probe",
+		Description: "Fails when the database is unreachable or the server is shutting down, so load balancers stop routing requests here.",
+		Tags:        []string{"system"},
+		Security:    openapi.NoSecurity(),
+		Responses: map[string]*openapi.Response{
+			"200": openapi.JSON("Ready to serve requests", readiness),
+			"503": openapi.JSON("Not ready", readiness),
+		},
+	})
+	doc.Add("GET", "/api/info", &openapi.Operation{
+		OperationID: "getInfo",
+		Summary:     "Detailed API and build information",
+		Tags:        []string{"system"},
+		Security:    openapi.NoSecurity(),
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Build information", info)},
+	})
+	doc.Add("GET", "/api/openapi.json", &openapi.Operation{
+		OperationID: "getOpenAPI",
+		Summary:     "This OpenAPI document",
+		Tags:        []string{"system"},
+		Security:    openapi.NoSecurity(),
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("OpenAPI 3 document", &openapi.Schema{Type: "object"})},
+	})
+	doc.Add("GET", "/metrics", protected(models.RoleReadOnly, &openapi.Operation{
+		OperationID: "getMetrics",
+		Summary:     "Prometheus metrics",
+		Tags:        []string{"system"},
+		Responses:   map[string]*openapi.Response{"200": openapi.Body("Prometheus text format", "text/plain", openapi.String())},
+	}))
+	doc.Add("GET", "/api", &openapi.Operation{
+		OperationID: "getAPIIndex",
+		Summary:     "API overview",
+		Tags:        []string{"system"},
+		Security:    openapi.NoSecurity(),
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Service description and routes", root)},
+	})
+	doc.Add("GET", "/", &openapi.Operation{
+		OperationID: "getUI",
+		Summary:     "Web UI",
+		Description: "Serves the web UI when the server is built with it. Paths outside /api that are not files return index.html for client-side routing.",
+		Tags:        []string{"system"},
+		Security:    openapi.NoSecurity(),
+		Responses: map[string]*openapi.Response{
+			"200": openapi.Body("The web UI", "text/html", openapi.String()),
+			"302": openapi.Body("Redirect to /api, when the server is built without the web UI", "text/html", openapi.String()),
+		},
+	})
+
+	// Recommendations
+	recommend := protected(models.RoleRecommend, withErrors(&openapi.Operation{
+		OperationID: "recommend",
+		Summary:     "Generate artist recommendations",
+		Description: "Recommends artists similar to the highest rated tracks of a Plex playlist. A W3C traceparent header continues the caller's trace.",
+		Tags:        []string{"recommendations"},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]openapi.MediaType{"application/json": {Schema: doc.SchemaOf(models.RecommendRequest{})}},
+		},
+		Responses: map[string]*openapi.Response{"200": openapi.JSON("Recommended artists", doc.SchemaOf(models.RecommendResponse{}))},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout))
+	recommend.Description = joinSentences(recommend.Description, "Fails with `playlist_not_found` (404), `no_seed_tracks` or "+
+		"`no_recommendations` (422), `llm_quota_exceeded` (429), `engine_unavailable` (503), or a Plex or upstream error (502, 504).")
+	recommend.Responses["200"].Headers = map[string]openapi.Header{
+		"X-Trace-ID": {Description: "Trace of the request", Schema: openapi.String()},
+	}
+	recommend.Responses["429"].Description = "Rate limit or LLM quota exceeded"
+	doc.Add("POST", "/api/recommend", recommend)
+
+	doc.Add("GET", "/api/usage", protected(models.RoleReadOnly, withErrors(&openapi.Operation{
+		OperationID: "getUsage",
+		Summary:     "LLM token and cost usage against the quotas",
+		Tags:        []string{"recommendations"},
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Usage of the current day and month", usage)},
+	}, http.StatusInternalServerError, http.StatusServiceUnavailable)))
+
+	// Artists
+	doc.Add("GET", "/api/artists", protected(models.RoleReadOnly, withErrors(&openapi.Operation{
+		OperationID: "listArtists",
+		Summary:     "Search cached artists",
+		Description: "Searches names, aliases, descriptions and genres. Pass next_page of the response as page for the next page.",
+		Tags:        []string{"artists"},
+		Parameters: []openapi.Parameter{
+			query("q", openapi.String(), "Full-text search"),
+			query("genre", openapi.String(), "Genre, including its subgenres"),
+			query("country", openapi.String(), "ISO 3166 two-letter country code"),
+			query("verified", openapi.String(), "true, or a source name such as discogs"),
+			query("sort", openapi.Enum("relevance", "name", "listeners"), "Order of the results"),
+			query("min_listeners", openapi.Integer(), "Minimum Last.fm listeners"),
+			query("max_listeners", openapi.Integer(), "Maximum Last.fm listeners, 0 for no limit"),
+			query("limit", openapi.Integer(), "Page size, 1 to 200, default 50"),
+			query("offset", openapi.Integer(), "Results to skip"),
+			query("page", openapi.String(), "next_page of the previous response"),
+		},
+		Responses: map[string]*openapi.Response{"200": openapi.JSON("Matching artists", artistList)},
+	}, http.StatusBadRequest, http.StatusInternalServerError)))
+	doc.Add("GET", "/api/artists/{mbid}", protected(models.RoleReadOnly, withErrors(&openapi.Operation{
+		OperationID: "getArtist",
+		Summary:     "Get artist information by MusicBrainz ID",
+		Tags:        []string{"artists"},
+		Parameters:  []openapi.Parameter{mbid},
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Cached artist", artist)},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError)))
+	doc.Add("GET", "/api/artists/{mbid}/releases", protected(models.RoleReadOnly, withErrors(&openapi.Operation{
+		OperationID: "getArtistReleases",
+		Summary:     "Get artist discography (albums, EPs, singles)",
+		Tags:        []string{"artists"},
+		Parameters:  []openapi.Parameter{mbid},
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Release groups by type", doc.SchemaOf(models.Discography{}))},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError)))
+	doc.Add("GET", "/api/artists/{mbid}/similar", protected(models.RoleReadOnly, withErrors(&openapi.Operation{
+		OperationID: "getSimilarArtists",
+		Summary:     "Get similar artists from the similarity graph",
+		Tags:        []string{"artists"},
+		Parameters: []openapi.Parameter{
+			mbid,
+			query("min_match", openapi.Number(), "Minimum similarity, 0 to 1"),
+			query("limit", openapi.Integer(), "Maximum neighbours, 1 to 100, default 25"),
+		},
+		Responses: map[string]*openapi.Response{"200": openapi.JSON("Neighbours by similarity", similar)},
+	}, http.StatusBadRequest, http.StatusInternalServerError)))
+	image := withErrors(&openapi.Operation{
+		OperationID: "getArtistImage",
+		Summary:     "Get cached artist thumbnail",
+		Description: "Fetched from Discogs, the Cover Art Archive or Last.fm on first request. Open because <img> tags cannot send an API key; HEAD and conditional requests are supported.",
+		Tags:        []string{"artists"},
+		Security:    openapi.NoSecurity(),
+		Parameters:  []openapi.Parameter{mbid},
+		Responses: map[string]*openapi.Response{
+			"200": openapi.Body("JPEG thumbnail", "image/jpeg", &openapi.Schema{Type: "string", Format: "binary"}),
+			"304": {Description: "Not modified"},
+			"429": rateLimitedResponse(errorSchema),
+		},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable)
+	doc.Add("GET", "/api/images/{mbid}", image)
+
+	// Plex
+	doc.Add("GET", "/api/plex/playlists", protected(models.RoleReadOnly, withErrors(&openapi.Operation{
+		OperationID: "getPlaylists",
+		Summary:     "List Plex playlists",
+		Tags:        []string{"plex"},
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Audio playlists", playlists)},
+	}, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout)))
+	doc.Add("GET", "/api/plex/test", protected(models.RoleReadOnly, withErrors(&openapi.Operation{
+		OperationID: "testPlex",
+		Summary:     "Test Plex connection",
+		Tags:        []string{"plex"},
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Plex server information", plexTest)},
+	}, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout)))
+
+	// Cache
+	doc.Add("GET", "/api/cache/stats", protected(models.RoleAdmin, withErrors(&openapi.Operation{
+		OperationID: "getCacheStats",
+		Summary:     "Cache performance statistics",
+		Tags:        []string{"cache"},
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("Cached artists by state", doc.SchemaOf(db.CacheStats{}))},
+	}, http.StatusInternalServerError)))
+	doc.Add("POST", "/api/cache/clear", protected(models.RoleAdmin, withErrors(&openapi.Operation{
+		OperationID: "clearCache",
+		Summary:     "Clear cache entries",
+		Description: "expired purges long-expired artists and all drops the artist cache. invalidate expires the artists selected by mbid, source, genre and updated_after/updated_before; refresh also queues them for an immediate background refresh.",
+		Tags:        []string{"cache"},
+		Parameters: []openapi.Parameter{
+			query("type", openapi.Enum("expired", "all", "invalidate", "refresh"), "What to clear, default expired"),
+			query("mbid", openapi.String(), "Comma separated MusicBrainz IDs"),
+			query("source", openapi.String(), "Artists verified by a source such as discogs, also expiring its stored responses; not combinable with the other filters"),
+			query("genre", openapi.String(), "Genre, including its subgenres"),
+			query("updated_after", openapi.String(), "RFC 3339 timestamp or YYYY-MM-DD"),
+			query("updated_before", openapi.String(), "RFC 3339 timestamp or YYYY-MM-DD"),
+		},
+		Responses: map[string]*openapi.Response{"200": openapi.JSON("What was cleared", cacheClear)},
+	}, http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable)))
+
+	// Admin
+	doc.Add("GET", "/api/admin/export", protected(models.RoleAdmin, &openapi.Operation{
+		OperationID: "exportArtists",
+		Summary:     "Export the artist cache as NDJSON",
+		Tags:        []string{"admin"},
+		Responses:   map[string]*openapi.Response{"200": openapi.Body("One artist per line", "application/x-ndjson", openapi.String())},
+	}))
+	doc.Add("POST", "/api/admin/import", protected(models.RoleAdmin, withErrors(&openapi.Operation{
+		OperationID: "importArtists",
+		Summary:     "Import an NDJSON export into the artist cache",
+		Description: "Batches imported before a failure are kept and counted in the error response.",
+		Tags:        []string{"admin"},
+		Parameters: []openapi.Parameter{
+			query("on_conflict", openapi.Enum(db.ConflictSkip, db.ConflictOverwrite, db.ConflictNewestWins), "Artists already cached, default newest-wins"),
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content:  map[string]openapi.MediaType{"application/x-ndjson": {Schema: openapi.String()}},
+		},
+		Responses: map[string]*openapi.Response{"200": openapi.JSON("Import counts", imported)},
+	}, http.StatusBadRequest, http.StatusInternalServerError)))
+	doc.Add("POST", "/api/admin/backup", protected(models.RoleAdmin, withErrors(&openapi.Operation{
+		OperationID: "backupDatabase",
+		Summary:     "Write a database backup to the backup directory",
+		Tags:        []string{"admin"},
+		Responses:   map[string]*openapi.Response{"200": openapi.JSON("The backup written", doc.SchemaOf(db.BackupResult{}))},
+	}, http.StatusInternalServerError, http.StatusNotImplemented, http.StatusServiceUnavailable)))
+
+	return doc
+}
+
+// rateLimitedResponse documents a 429 with its Retry-After header
+func rateLimitedResponse(errorSchema *openapi.Schema) *openapi.Response {
+	response := openapi.JSON("Rate limit exceeded", errorSchema)
+	response.Headers = map[string]openapi.Header{
+		"Retry-After": {Description: "Seconds to wait before retrying", Schema: openapi.Integer()},
+	}
+	return response
+}
+
+// query documents an optional query parameter
+func query(name string, schema *openapi.Schema, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func joinSentences(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
+
+// handleOpenAPI serves the OpenAPI document
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSONResponse(w, OpenAPIDocument(s.buildInfo.Version), http.StatusOK)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gocommender/internal/db"
+	"gocommender/internal/dialect"
+	"gocommender/internal/models"
+	"gocommender/internal/openapi"
+	"gocommender/internal/services"
+	"gocommender/internal/testutil"
+)
+
+const contractMBID = "b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d"
+
+// samplePath fills the templated segments of a documented path
+func samplePath(path string) string {
+	return strings.ReplaceAll(path, "{mbid}", contractMBID)
+}
+
+// TestOpenAPIRoutes fails when a route is added without documenting it, or the
+// document describes a route or method the server does not serve
+func TestOpenAPIRoutes(t *testing.T) {
+	server := createTestServer()
+	doc := OpenAPIDocument("test")
+
+	documented := make(map[string]bool)
+	doc.Operations(func(method, path string, op *openapi.Operation) {
+		req := httptest.NewRequest(method, samplePath(path), nil)
+		_, pattern := server.mux.Handler(req)
+		if pattern == "/" && path != "/" {
+			t.Errorf("%s %s is documented but not served", method, path)
+		}
+		documented[pattern] = true
+
+		if op.OperationID == "" || op.Summary == "" || op.Responses["200"] == nil {
+			t.Errorf("%s %s needs an operation ID, a summary and a 200 response", method, path)
+		}
+	})
+
+	for _, pattern := range server.routes {
+		if !documented[pattern] {
+			t.Errorf("Route %s is not in the OpenAPI document", pattern)
+		}
+	}
+
+	// Methods that are not documented must be rejected
+	for path, item := range doc.Paths {
+		for _, method := range []string{"GET", "POST", "PUT", "DELETE", "PATCH"} {
+			if item[strings.ToLower(method)] != nil {
+				continue
+			}
+			req := httptest.NewRequest(method, samplePath(path), nil)
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, req)
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Errorf("%s %s is not documented but returned %d", method, path, w.Code)
+			}
+		}
+	}
+}
+
+// TestOpenAPIContract sends requests to the handlers and checks that each status
+// code is documented and each JSON response matches its schema
+func TestOpenAPIContract(t *testing.T) {
+	database, cleanup := testutil.CreateTestDB(t)
+	defer cleanup()
+
+	store := db.NewStore(database, dialect.SQLite)
+	server := createTestServer()
+	server.cacheManager = db.NewCacheManagerWithStore(store)
+	server.plexClient = services.NewPlexClient("http://127.0.0.1:1", "token") // Unreachable
+	server.quota = services.NewLLMQuota(store.Usage, services.QuotaConfig{DailyTokens: 1000}, nil)
+
+	artist := testutil.TestArtist()
+	artist.MBID = contractMBID
+	artist.Similar = []models.SimilarArtist{{Name: "Neighbour", Match: 0.5, Source: models.SimilaritySourceLastFM}}
+	testutil.AssertNoError(t, server.cacheManager.CacheArtist(artist, db.DefaultCacheConfig()))
+
+	doc := OpenAPIDocument("test")
+	exchanges := []struct {
+		method, target, body string
+		status               int
+	}{
+		{"GET", "/", "", http.StatusFound},
+		{"GET", "/api", "", http.StatusOK},
+		{"GET", "/api/health", "", http.StatusOK},
+		{"GET", "/api/health/live", "", http.StatusOK},
+		{"GET", "/api/health/ready", "", http.StatusOK},
+		{"GET", "/api/info", "", http.StatusOK},
+		{"GET", "/api/openapi.json", "", http.StatusOK},
+		{"POST", "/api/recommend", "not json", http.StatusBadRequest},
+		{"POST", "/api/recommend", `{"playlist_name": "Favorites", "engine": "psychic"}`, http.StatusBadRequest},
+		{"GET", "/api/usage", "", http.StatusOK},
+		{"GET", "/api/artists?q=test&sort=name", "", http.StatusOK},
+		{"GET", "/api/artists?limit=0", "", http.StatusBadRequest},
+		{"GET", "/api/artists/" + contractMBID, "", http.StatusOK},
+		{"GET", "/api/artists/00000000-0000-0000-0000-000000000000", "", http.StatusNotFound},
+		{"GET", "/api/artists/not-an-mbid", "", http.StatusBadRequest},
+		{"GET", "/api/artists/" + contractMBID + "/releases", "", http.StatusOK},
+		{"GET", "/api/artists/" + contractMBID + "/similar?min_match=0.1", "", http.StatusOK},
+		{"GET", "/api/images/" + contractMBID, "", http.StatusServiceUnavailable},
+		{"GET", "/api/plex/playlists", "", http.StatusBadGateway},
+		{"GET", "/api/plex/test", "", http.StatusBadGateway},
+		{"GET", "/api/cache/stats", "", http.StatusOK},
+		{"POST", "/api/cache/clear?type=invalidate&mbid=" + contractMBID, "", http.StatusOK},
+		{"POST", "/api/cache/clear?type=refresh&mbid=" + contractMBID, "", http.StatusServiceUnavailable},
+		{"POST", "/api/cache/clear?type=expired", "", http.StatusOK},
+		{"POST", "/api/admin/import", "not json\n", http.StatusBadRequest},
+		{"POST", "/api/admin/backup", "", http.StatusServiceUnavailable},
+		{"GET", "/api/admin/export", "", http.StatusOK},
+		{"GET", "/metrics", "", http.StatusOK},
+		{"POST", "/api/cache/clear?type=all", "", http.StatusOK},
+		{"POST", "/api/admin/import?on_conflict=skip", "", http.StatusOK},
+	}
+
+	for _, exchange := range exchanges {
+		name := exchange.method + " " + exchange.target
+		req := httptest.NewRequest(exchange.method, exchange.target, strings.NewReader(exchange.body))
+		w := httptest.NewRecorder()
+		server.mux.ServeHTTP(w, req)
+
+		if w.Code != exchange.status {
+			t.Errorf("%s: expected status %d, got %d: %s", name, exchange.status, w.Code, w.Body.String())
+			continue
+		}
+
+		path, op := doc.Find(exchange.method, req.URL.Path)
+		if op == nil {
+			t.Errorf("%s: not documented", name)
+			continue
+		}
+		response := op.Responses[strconv.Itoa(w.Code)]
+		if response == nil {
+			t.Errorf("%s: status %d is not documented for %s", name, w.Code, path)
+			continue
+		}
+
+		contentType := w.Header().Get("Content-Type")
+		media, ok := response.Content[strings.TrimSpace(strings.Split(contentType, ";")[0])]
+		if !ok {
+			t.Errorf("%s: content type %q is not documented", name, contentType)
+			continue
+		}
+		if !strings.HasPrefix(contentType, "application/json") {
+			continue
+		}
+
+		var body interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Errorf("%s: invalid JSON: %v", name, err)
+			continue
+		}
+		if err := doc.Validate(media.Schema, body); err != nil {
+			t.Errorf("%s: response does not match the document: %v", name, err)
+		}
+	}
+}
+
+// TestOpenAPITypeScript fails when the web UI's generated types are stale
+func TestOpenAPITypeScript(t *testing.T) {
+	generated, err := os.ReadFile("../../web/src/types/schema.gen.ts")
+	if err != nil {
+		t.Fatalf("Failed to read generated types: %v", err)
+	}
+	if string(generated) != openapi.TypeScript(OpenAPIDocument("dev")) {
+		t.Error("web/src/types/schema.gen.ts is out of date, run go generate ./internal/api")
+	}
+}
@@ -0,0 +1,217 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gocommender/internal/models"
+	"gocommender/internal/services"
+)
+
+// idleBucketTTL is how long an unused bucket is kept before it is swept
+const idleBucketTTL = 10 * time.Minute
+
+// RateLimiter is a token bucket per client. Each bucket holds up to burst tokens
+// and refills at rate tokens per second; a request takes one token.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket is the state of one client's token bucket
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing rate requests per second with bursts of up to burst
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token for the client. If none is left it returns false and
+// how long until the next token is available.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// refund returns a token taken by Allow, for a request another limiter rejected
+func (l *RateLimiter) refund(client string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b, ok := l.buckets[client]; ok {
+		b.tokens = math.Min(l.burst, b.tokens+1)
+	}
+}
+
+// sweep drops buckets that have been idle long enough to be full again
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	l.lastSweep = now
+
+	for client, b := range l.buckets {
+		if now.Sub(b.last) > idleBucketTTL {
+			delete(l.buckets, client)
+		}
+	}
+}
+
+// RateLimits are the limiters applied by the API. Nil limiters are not applied.
+type RateLimits struct {
+	Address   *RateLimiter   // Every authenticated endpoint by client address, before authentication
+	General   *RateLimiter   // Every authenticated endpoint and images
+	Recommend *RateLimiter   // Additionally POST /api/recommend, which calls the LLM
+	Trusted   []netip.Prefix // Proxies whose X-Forwarded-For is used as the client address
+}
+
+// UseRateLimits limits requests per API key, proxy user or client address
+func (s *Server) UseRateLimits(limits RateLimits) {
+	s.rateLimits = &limits
+}
+
+// rateLimited wraps a handler with the general limit, and the recommend limit if recommend is set.
+// Callers authenticated by requireRole are limited by identity, others by address.
+func (s *Server) rateLimited(recommend bool, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.rateLimits == nil {
+			next(w, r)
+			return
+		}
+
+		client := s.rateLimitClient(r)
+		limiters := []*RateLimiter{s.rateLimits.General}
+		if recommend {
+			limiters = append(limiters, s.rateLimits.Recommend)
+		}
+
+		if allow(w, client, limiters...) {
+			next(w, r)
+		}
+	}
+}
+
+// addressLimited wraps a handler with the address limit. It runs before requireRole,
+// so requests with missing or wrong credentials are throttled too.
+func (s *Server) addressLimited(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.rateLimits == nil || allow(w, "ip:"+clientAddr(r, s.rateLimits.Trusted), s.rateLimits.Address) {
+			next(w, r)
+		}
+	}
+}
+
+// allow takes a token for the client from every limiter, or writes a 429 and returns false.
+// A rejected request gets back the tokens it took from the limiters before, so retries
+// against a tighter limit do not drain the looser ones.
+func allow(w http.ResponseWriter, client string, limiters ...*RateLimiter) bool {
+	for i, limiter := range limiters {
+		if limiter == nil {
+			continue
+		}
+		if ok, wait := limiter.Allow(client); !ok {
+			for _, taken := range limiters[:i] {
+				if taken != nil {
+					taken.refund(client)
+				}
+			}
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			writeErrorCode(w, http.StatusTooManyRequests, services.CodeRateLimited,
+				fmt.Sprintf("Rate limit exceeded, retry in %ds", retryAfter),
+				map[string]interface{}{"retry_after": retryAfter})
+			return false
+		}
+	}
+	return true
+}
+
+// rateLimitClient names the bucket of a request
+func (s *Server) rateLimitClient(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok {
+		return principal.Method + ":" + principal.Name
+	}
+	return "ip:" + clientAddr(r, s.rateLimits.Trusted)
+}
+
+// clientAddr returns the peer address, or for a trusted proxy the last
+// X-Forwarded-For entry that is not itself a trusted proxy
+func clientAddr(r *http.Request, trusted []netip.Prefix) string {
+	host := peerAddr(r)
+	if !isTrustedAddr(host, trusted) {
+		return host
+	}
+
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(forwarded[i])
+		if addr == "" {
+			continue
+		}
+		if !isTrustedAddr(addr, trusted) {
+			return addr
+		}
+		host = addr
+	}
+	return host
+}
+
+// isTrustedAddr reports whether an address is within any of the prefixes
+func isTrustedAddr(host string, trusted []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// protect applies the address limit, authentication and the general rate limit to a handler
+func (s *Server) protect(role models.Role, next http.HandlerFunc) http.HandlerFunc {
+	return s.addressLimited(s.requireRole(role, s.rateLimited(false, next)))
+}
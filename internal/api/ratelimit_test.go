@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"gocommender/internal/db"
+	"gocommender/internal/services"
+	"gocommender/internal/testutil"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(0.5, 2) // One token every 2 seconds
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := limiter.Allow("a"); !ok {
+			t.Fatalf("Expected request %d of the burst to be allowed", i+1)
+		}
+	}
+
+	ok, wait := limiter.Allow("a")
+	if ok || wait != 2*time.Second {
+		t.Errorf("Expected to wait 2s after the burst, got %v, %v", ok, wait)
+	}
+	if ok, _ := limiter.Allow("b"); !ok {
+		t.Error("Expected other clients to have their own bucket")
+	}
+
+	now = now.Add(2 * time.Second)
+	if ok, _ := limiter.Allow("a"); !ok {
+		t.Error("Expected a token to be refilled")
+	}
+	if ok, _ := limiter.Allow("a"); ok {
+		t.Error("Expected only one token to be refilled")
+	}
+
+	// Idle buckets are swept
+	now = now.Add(2 * idleBucketTTL)
+	limiter.Allow("c")
+	if len(limiter.buckets) != 1 {
+		t.Errorf("Expected idle buckets to be swept, have %d", len(limiter.buckets))
+	}
+}
+
+func TestRateLimitedEndpoints(t *testing.T) {
+	server, keys := createAuthTestServer(t)
+	server.UseRateLimits(RateLimits{
+		General:   NewRateLimiter(0.001, 3),
+		Recommend: NewRateLimiter(0.001, 1),
+		Trusted:   []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	})
+
+	request := func(method, path, key, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		server.mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// Recommendations have their own, smaller limit on top of the general one
+	recommendKey := keys["recommend"]
+	testutil.AssertEqual(t, http.StatusMethodNotAllowed, request("GET", "/api/recommend", recommendKey, "192.0.2.1:1", "").Code)
+	limited := request("GET", "/api/recommend", recommendKey, "192.0.2.1:1", "")
+	testutil.AssertEqual(t, http.StatusTooManyRequests, limited.Code)
+	testutil.AssertTrue(t, limited.Header().Get("Retry-After") != "")
+
+	var body errorResponse
+	testutil.AssertNoError(t, json.Unmarshal(limited.Body.Bytes(), &body))
+	testutil.AssertEqual(t, services.CodeRateLimited, body.Code)
+	testutil.AssertTrue(t, body.Details["retry_after"].(float64) > 0)
+
+	// Rejected retries do not drain the general limit, only the first recommend request counted
+	for i := 0; i < 3; i++ {
+		testutil.AssertEqual(t, http.StatusTooManyRequests, request("GET", "/api/recommend", recommendKey, "192.0.2.1:1", "").Code)
+	}
+	testutil.AssertEqual(t, http.StatusMethodNotAllowed, request("POST", "/api/usage", recommendKey, "192.0.2.1:1", "").Code)
+	testutil.AssertEqual(t, http.StatusMethodNotAllowed, request("POST", "/api/usage", recommendKey, "192.0.2.1:1", "").Code)
+	testutil.AssertEqual(t, http.StatusTooManyRequests, request("POST", "/api/usage", recommendKey, "192.0.2.1:1", "").Code)
+
+	// Other keys are not affected
+	testutil.AssertEqual(t, http.StatusMethodNotAllowed, request("POST", "/api/usage", keys["admin"], "192.0.2.1:1", "").Code)
+
+	// Unauthenticated image requests are limited per client address, behind a trusted proxy by X-Forwarded-For
+	for i := 0; i < 3; i++ {
+		testutil.AssertEqual(t, http.StatusMethodNotAllowed, request("POST", "/api/images/x", "", "10.0.0.1:1", "198.51.100.1, 10.0.0.2").Code)
+	}
+	testutil.AssertEqual(t, http.StatusTooManyRequests, request("POST", "/api/images/x", "", "10.0.0.1:1", "198.51.100.1").Code)
+	testutil.AssertEqual(t, http.StatusMethodNotAllowed, request("POST", "/api/images/x", "", "10.0.0.1:1", "198.51.100.2").Code)
+}
+
+func TestAddressLimitBeforeAuthentication(t *testing.T) {
+	server, keys := createAuthTestServer(t)
+	server.UseRateLimits(RateLimits{Address: NewRateLimiter(0.001, 2)})
+
+	request := func(key, remoteAddr string) int {
+		req := httptest.NewRequest("GET", "/api/usage", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		server.mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Guessed keys use up the address's tokens
+	testutil.AssertEqual(t, http.StatusUnauthorized, request("gc_guess1", "192.0.2.1:1"))
+	testutil.AssertEqual(t, http.StatusUnauthorized, request("gc_guess2", "192.0.2.1:1"))
+	testutil.AssertEqual(t, http.StatusTooManyRequests, request("gc_guess3", "192.0.2.1:1"))
+	testutil.AssertEqual(t, http.StatusTooManyRequests, request(keys["admin"], "192.0.2.1:1"))
+
+	// Other addresses are not affected
+	testutil.AssertEqual(t, http.StatusUnauthorized, request("gc_guess4", "192.0.2.2:1"))
+}
+
+func TestUsageEndpoint(t *testing.T) {
+	database, cleanup := testutil.CreateTestDB(t)
+	defer cleanup()
+
+	server := createTestServer()
+
+	req := httptest.NewRequest("GET", "/api/usage", nil)
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+	testutil.AssertEqual(t, http.StatusServiceUnavailable, w.Code)
+
+	usage := db.NewUsageDB(database)
+	testutil.AssertNoError(t, usage.AddLLMUsage(time.Now(), "gpt-4o", db.LLMUsage{Requests: 1, PromptTokens: 900, CompletionTokens: 100, CostUSD: 0.00325}))
+	server.UseQuota(services.NewLLMQuota(usage, services.QuotaConfig{DailyTokens: 1000}, services.DefaultModelPrices()))
+
+	w = httptest.NewRecorder()
+	server.mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/usage", nil))
+	testutil.AssertEqual(t, http.StatusOK, w.Code)
+
+	var response struct {
+		LLM services.QuotaStatus `json:"llm"`
+	}
+	testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	testutil.AssertEqual(t, int64(1000), response.LLM.Daily.TotalTokens)
+	testutil.AssertEqual(t, int64(1000), response.LLM.Daily.TokenLimit)
+	testutil.AssertTrue(t, response.LLM.Daily.Exceeded)
+	testutil.AssertEqual(t, int64(1), response.LLM.Monthly.Requests)
+}
@@ -2,17 +2,30 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"gocommender/internal/db"
+	"gocommender/internal/images"
+	"gocommender/internal/logging"
+	"gocommender/internal/metrics"
 	"gocommender/internal/models"
+	"gocommender/internal/openapi"
 	"gocommender/internal/services"
+	"gocommender/internal/taxonomy"
+	"gocommender/internal/tracing"
 )
 
+var logger = logging.For("api")
+
 // Server holds the HTTP server and dependencies
 type Server struct {
 	mux                   *http.ServeMux
@@ -20,7 +33,16 @@ type Server struct {
 	enrichmentService     *services.EnrichmentService
 	plexClient            *services.PlexClient
 	cacheManager          *db.CacheManager
+	imageCache            *images.Cache
+	refreshService        *db.RefreshService
+	backupService         *db.BackupService
 	buildInfo             *BuildInfo
+	auth                  *Authenticator // nil leaves the API open
+	rateLimits            *RateLimits    // nil disables rate limiting
+	quota                 *services.LLMQuota
+	ui                    *uiHandler  // nil redirects / to the API overview
+	routes                []string    // Registered patterns
+	draining              atomic.Bool // Set by Drain, fails the readiness probe
 }
 
 // BuildInfo contains application build information
@@ -37,6 +59,9 @@ func NewServer(recommendationService *services.RecommendationService,
 	enrichmentService *services.EnrichmentService,
 	plexClient *services.PlexClient,
 	cacheManager *db.CacheManager,
+	imageCache *images.Cache,
+	refreshService *db.RefreshService,
+	backupService *db.BackupService,
 	buildInfo *BuildInfo) *Server {
 
 	server := &Server{
@@ -45,6 +70,9 @@ func NewServer(recommendationService *services.RecommendationService,
 		enrichmentService:     enrichmentService,
 		plexClient:            plexClient,
 		cacheManager:          cacheManager,
+		imageCache:            imageCache,
+		refreshService:        refreshService,
+		backupService:         backupService,
 		buildInfo:             buildInfo,
 	}
 
@@ -52,34 +80,108 @@ func NewServer(recommendationService *services.RecommendationService,
 	return server
 }
 
+// UseAuth requires authentication for every endpoint except health, info and images
+func (s *Server) UseAuth(auth *Authenticator) {
+	s.auth = auth
+}
+
+// UseQuota reports LLM usage from the quota tracker at /api/usage
+func (s *Server) UseQuota(quota *services.LLMQuota) {
+	s.quota = quota
+}
+
+// Drain makes the readiness probe fail so load balancers stop sending requests
+// before the server shuts down. Requests are still served.
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
+
 // ServeHTTP implements http.Handler
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Add CORS middleware
-	s.corsMiddleware(s.loggingMiddleware(s.mux)).ServeHTTP(w, r)
+	s.corsMiddleware(s.requestIDMiddleware(s.loggingMiddleware(s.mux))).ServeHTTP(w, r)
 }
 
 // setupRoutes configures all API routes
 func (s *Server) setupRoutes() {
-	// Health and info endpoints
-	s.mux.HandleFunc("/api/health", s.handleHealth)
-	s.mux.HandleFunc("/api/info", s.handleInfo)
+	// Health and info endpoints, open for probes
+	s.handle("/api/health", s.handleHealth)
+	s.handle("/api/health/live", s.handleLive)
+	s.handle("/api/health/ready", s.handleReady)
+	s.handle("/api/info", s.handleInfo)
+	s.handle("/api/openapi.json", s.handleOpenAPI)
 
 	// Recommendation endpoints
-	s.mux.HandleFunc("/api/recommend", s.handleRecommend)
+	s.handle("/api/recommend", s.addressLimited(s.requireRole(models.RoleRecommend, s.rateLimited(true, s.handleRecommend))))
 
 	// Artist endpoints
-	s.mux.HandleFunc("/api/artists/", s.handleArtist) // Path with trailing slash for ID capture
+	s.handle("/api/artists", s.protect(models.RoleReadOnly, s.handleArtists))
+	s.handle("/api/artists/", s.protect(models.RoleReadOnly, s.handleArtist)) // Path with trailing slash for ID capture
+
+	// Image endpoints, open because <img> tags cannot send an API key
+	s.handle("/api/images/", s.rateLimited(false, s.handleImage))
 
 	// Plex endpoints
-	s.mux.HandleFunc("/api/plex/playlists", s.handlePlexPlaylists)
-	s.mux.HandleFunc("/api/plex/test", s.handlePlexTest)
+	s.handle("/api/plex/playlists", s.protect(models.RoleReadOnly, s.handlePlexPlaylists))
+	s.handle("/api/plex/test", s.protect(models.RoleReadOnly, s.handlePlexTest))
+
+	// LLM usage against the quotas
+	s.handle("/api/usage", s.protect(models.RoleReadOnly, s.handleUsage))
+
+	// Cache endpoints, including invalidation and forced refresh
+	s.handle("/api/cache/stats", s.protect(models.RoleAdmin, s.handleCacheStats))
+	s.handle("/api/cache/clear", s.protect(models.RoleAdmin, s.handleCacheClear))
 
-	// Cache endpoints
-	s.mux.HandleFunc("/api/cache/stats", s.handleCacheStats)
-	s.mux.HandleFunc("/api/cache/clear", s.handleCacheClear)
+	// Admin endpoints
+	s.handle("/api/admin/export", s.protect(models.RoleAdmin, s.handleAdminExport))
+	s.handle("/api/admin/import", s.protect(models.RoleAdmin, s.handleAdminImport))
+	s.handle("/api/admin/backup", s.protect(models.RoleAdmin, s.handleAdminBackup))
+
+	// Prometheus metrics
+	s.handle("/metrics", s.protect(models.RoleReadOnly, metrics.Handler().ServeHTTP))
+
+	// API overview, and the web UI for every other path
+	s.handle("/api", s.handleAPIIndex)
+	s.handle("/", s.handleRoot)
+}
+
+// handle registers a route. Routes are kept for the OpenAPI contract test,
+// which checks that each is documented.
+func (s *Server) handle(pattern string, handler http.HandlerFunc) {
+	s.routes = append(s.routes, pattern)
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// handleLive answers the liveness probe: the process is serving requests
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	// Static route for testing
-	s.mux.HandleFunc("/", s.handleRoot)
+	writeJSONResponse(w, map[string]interface{}{"status": "alive"}, http.StatusOK)
+}
+
+// handleReady answers the readiness probe: the database is reachable and the
+// server is not shutting down. Plex is left out, it does not affect every route.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	checks := map[string]string{"database": "ok"}
+	status, code := "ready", http.StatusOK
+	if _, err := s.cacheManager.GetCacheStats(); err != nil {
+		logger.WarnContext(r.Context(), "Readiness check failed", "check", "database", "error", err)
+		checks["database"] = "error"
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	if s.draining.Load() {
+		status, code = "draining", http.StatusServiceUnavailable
+	}
+
+	writeJSONResponse(w, map[string]interface{}{"status": status, "checks": checks}, code)
 }
 
 // handleHealth provides service health information
@@ -105,19 +207,22 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 			"expired_entries": stats.Expired,
 		}
 	} else {
+		logger.WarnContext(r.Context(), "Health check failed", "check", "database", "error", err)
 		health["database"] = map[string]interface{}{
 			"status": "error",
-			"error":  err.Error(),
+			"error":  errorCode(err),
 		}
 	}
 
-	// Test Plex connection
-	if err := s.plexClient.TestConnection(); err == nil {
-		health["plex"] = map[string]string{"status": "connected"}
+	// Test Plex connection. The endpoint is public and Plex errors may name the server,
+	// so only the code is returned and the error is logged.
+	if err := s.plexClient.TestConnection(r.Context()); err == nil {
+		health["plex"] = map[string]interface{}{"status": "connected"}
 	} else {
-		health["plex"] = map[string]string{
+		logger.WarnContext(r.Context(), "Health check failed", "check", "plex", "error", err)
+		health["plex"] = map[string]interface{}{
 			"status": "error",
-			"error":  err.Error(),
+			"error":  errorCode(err),
 		}
 	}
 
@@ -151,19 +256,51 @@ func (s *Server) handleRecommend(w http.ResponseWriter, r *http.Request) {
 		request.MaxResults = 20 // Limit
 	}
 
-	// Generate recommendations
-	ctx := r.Context()
+	if !models.IsValidEngine(request.Engine) {
+		writeErrorResponse(w, "engine must be one of llm, graph or hybrid", http.StatusBadRequest)
+		return
+	}
+
+	// Generate recommendations, continuing the caller's trace if it sent a traceparent header
+	ctx, span := tracing.StartKind(tracing.Extract(r.Context(), r.Header), "POST /api/recommend", tracing.KindServer,
+		tracing.String("engine", request.Engine))
+	defer span.End()
+	w.Header().Set("X-Trace-ID", span.TraceID())
+
 	result, err := s.recommendationService.GenerateRecommendations(ctx, request)
+	span.RecordError(err)
 	if err != nil {
-		log.Printf("Recommendation error: %v", err)
-		writeErrorResponse(w, fmt.Sprintf("Failed to generate recommendations: %v", err),
-			http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to generate recommendations")
 		return
 	}
 
 	writeJSONResponse(w, result.Response, http.StatusOK)
 }
 
+// handleUsage reports LLM token and cost consumption of the current day and month
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.quota == nil {
+		writeErrorResponse(w, "LLM usage tracking not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	status, err := s.quota.Status()
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Usage error", "error", err)
+		writeErrorResponse(w, "Failed to get LLM usage", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, map[string]interface{}{
+		"llm":       status,
+		"timestamp": time.Now().UTC(),
+	}, http.StatusOK)
+}
+
 // handleArtist retrieves artist information by MBID
 func (s *Server) handleArtist(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -171,13 +308,23 @@ func (s *Server) handleArtist(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract MBID from path: /api/artists/{mbid}
+	// Extract MBID from path: /api/artists/{mbid}[/releases]
 	path := strings.TrimPrefix(r.URL.Path, "/api/artists/")
 	if path == "" {
 		writeErrorResponse(w, "Artist MBID required", http.StatusBadRequest)
 		return
 	}
 
+	if mbid, ok := strings.CutSuffix(path, "/releases"); ok {
+		s.handleArtistReleases(w, mbid)
+		return
+	}
+
+	if mbid, ok := strings.CutSuffix(path, "/similar"); ok {
+		s.handleArtistSimilar(w, r, mbid)
+		return
+	}
+
 	// Validate MBID format (basic UUID validation)
 	if !isValidMBID(path) {
 		writeErrorResponse(w, "Invalid MBID format", http.StatusBadRequest)
@@ -186,12 +333,11 @@ func (s *Server) handleArtist(w http.ResponseWriter, r *http.Request) {
 
 	artist, needsFetch, err := s.cacheManager.GetOrFetchArtist(path)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeErrorResponse(w, "Artist not found", http.StatusNotFound)
-			return
-		}
-		log.Printf("Artist lookup error: %v", err)
-		writeErrorResponse(w, "Failed to retrieve artist", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to retrieve artist")
+		return
+	}
+	if artist == nil {
+		writeErrorResponse(w, "Artist not found", http.StatusNotFound)
 		return
 	}
 
@@ -203,6 +349,231 @@ func (s *Server) handleArtist(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, response, http.StatusOK)
 }
 
+// handleArtistReleases returns the stored discography for an artist
+func (s *Server) handleArtistReleases(w http.ResponseWriter, mbid string) {
+	if !isValidMBID(mbid) {
+		writeErrorResponse(w, "Invalid MBID format", http.StatusBadRequest)
+		return
+	}
+
+	artist, _, err := s.cacheManager.GetOrFetchArtist(mbid)
+	if err != nil {
+		logger.Error("Artist lookup error", "error", err)
+		writeErrorResponse(w, "Failed to retrieve artist", http.StatusInternalServerError)
+		return
+	}
+	if artist == nil {
+		writeErrorResponse(w, "Artist not found", http.StatusNotFound)
+		return
+	}
+
+	discography, err := s.cacheManager.GetDiscography(mbid)
+	if err != nil {
+		logger.Error("Discography lookup error", "error", err)
+		writeErrorResponse(w, "Failed to retrieve releases", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, discography, http.StatusOK)
+}
+
+// handleArtistSimilar returns the similarity graph neighbours of an artist
+func (s *Server) handleArtistSimilar(w http.ResponseWriter, r *http.Request, mbid string) {
+	if !isValidMBID(mbid) {
+		writeErrorResponse(w, "Invalid MBID format", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit, err := parseIntParam(query, "limit", 25)
+	if err != nil || limit < 1 || limit > 100 {
+		writeErrorResponse(w, "limit must be between 1 and 100", http.StatusBadRequest)
+		return
+	}
+
+	minMatch := 0.0
+	if value := query.Get("min_match"); value != "" {
+		minMatch, err = strconv.ParseFloat(value, 64)
+		if err != nil || minMatch < 0 || minMatch > 1 {
+			writeErrorResponse(w, "min_match must be between 0 and 1", http.StatusBadRequest)
+			return
+		}
+	}
+
+	neighbours, err := s.cacheManager.GetSimilarArtists(mbid, minMatch, int(limit))
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Similar artists lookup error", "error", err)
+		writeErrorResponse(w, "Failed to retrieve similar artists", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"mbid":    mbid,
+		"similar": neighbours,
+		"count":   len(neighbours),
+	}
+
+	writeJSONResponse(w, response, http.StatusOK)
+}
+
+// handleArtists searches cached artists with full-text, genre, country, verification and popularity filters.
+// Results are paged with the opaque next_page cursor of the previous response.
+func (s *Server) handleArtists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	minListeners, err := parseIntParam(query, "min_listeners", 0)
+	if err != nil || minListeners < 0 {
+		writeErrorResponse(w, "min_listeners must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+	maxListeners, err := parseIntParam(query, "max_listeners", 0)
+	if err != nil || maxListeners < 0 {
+		writeErrorResponse(w, "max_listeners must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+	limit, err := parseIntParam(query, "limit", 50)
+	if err != nil || limit < 1 || limit > 200 {
+		writeErrorResponse(w, "limit must be between 1 and 200", http.StatusBadRequest)
+		return
+	}
+	offset, err := parseIntParam(query, "offset", 0)
+	if err != nil || offset < 0 {
+		writeErrorResponse(w, "offset must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+
+	search := db.ArtistSearch{
+		Query:        strings.TrimSpace(query.Get("q")),
+		Country:      strings.TrimSpace(query.Get("country")),
+		MinListeners: minListeners,
+		MaxListeners: maxListeners,
+		Sort:         query.Get("sort"),
+		Limit:        int(limit),
+		Offset:       int(offset),
+		Cursor:       query.Get("page"),
+	}
+
+	if search.Country != "" && len(search.Country) != 2 {
+		writeErrorResponse(w, "country must be a two-letter ISO code", http.StatusBadRequest)
+		return
+	}
+
+	// Genres include their subgenres, so "metal" also finds "doom metal"
+	if genre := query.Get("genre"); genre != "" {
+		normalized, ok := taxonomy.Normalize(genre)
+		if !ok {
+			writeErrorResponse(w, fmt.Sprintf("%q is not a searchable genre", genre), http.StatusBadRequest)
+			return
+		}
+		search.Genres = append([]string{normalized}, taxonomy.Default().Descendants(normalized)...)
+	}
+
+	switch verified := strings.ToLower(query.Get("verified")); verified {
+	case "":
+	case "true", db.VerifiedAny:
+		search.Verified = db.VerifiedAny
+	default:
+		if !isSourceName(verified) {
+			writeErrorResponse(w, "verified must be true or a source name such as discogs", http.StatusBadRequest)
+			return
+		}
+		search.Verified = verified
+	}
+
+	if err := db.ValidateSearch(&search); err != nil {
+		writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.cacheManager.SearchArtists(search)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Artist search error", "error", err)
+		writeErrorResponse(w, "Failed to list artists", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"artists":   result.Artists,
+		"count":     len(result.Artists),
+		"limit":     limit,
+		"offset":    offset,
+		"sort":      search.Sort,
+		"next_page": result.NextCursor,
+	}
+
+	writeJSONResponse(w, response, http.StatusOK)
+}
+
+// handleImage serves the cached artist thumbnail, fetching it on first request
+func (s *Server) handleImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Extract MBID from path: /api/images/{mbid}
+	mbid := strings.TrimPrefix(r.URL.Path, "/api/images/")
+	if !isValidMBID(mbid) {
+		writeErrorResponse(w, "Invalid MBID format", http.StatusBadRequest)
+		return
+	}
+
+	if s.imageCache == nil {
+		writeErrorResponse(w, "Image cache not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	artist, _, err := s.cacheManager.GetOrFetchArtist(mbid)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Artist lookup error", "error", err)
+		writeErrorResponse(w, "Failed to retrieve artist", http.StatusInternalServerError)
+		return
+	}
+	if artist == nil {
+		writeErrorResponse(w, "Artist not found", http.StatusNotFound)
+		return
+	}
+
+	discography, err := s.cacheManager.GetDiscography(mbid)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Discography lookup error", "error", err)
+		writeErrorResponse(w, "Failed to retrieve releases", http.StatusInternalServerError)
+		return
+	}
+
+	cached, err := s.imageCache.Get(r.Context(), artist, discography.ReleaseGroups)
+	if errors.Is(err, images.ErrNoImage) {
+		writeErrorResponse(w, "No image available", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Image fetch error", "mbid", mbid, "error", err)
+		writeErrorResponse(w, "Failed to retrieve image", http.StatusBadGateway)
+		return
+	}
+
+	file, err := os.Open(cached.Path)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Image open error", "mbid", mbid, "error", err)
+		writeErrorResponse(w, "Failed to read image", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	w.Header().Set("ETag", fmt.Sprintf(`"%s-%d"`, mbid, cached.ModTime.Unix()))
+
+	// ServeContent handles If-None-Match, If-Modified-Since and range requests
+	http.ServeContent(w, r, mbid+".jpg", cached.ModTime, file)
+}
+
 // handlePlexPlaylists lists available Plex playlists
 func (s *Server) handlePlexPlaylists(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -210,10 +581,9 @@ func (s *Server) handlePlexPlaylists(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	playlists, err := s.plexClient.GetPlaylists()
+	playlists, err := s.plexClient.GetPlaylists(r.Context())
 	if err != nil {
-		log.Printf("Plex playlists error: %v", err)
-		writeErrorResponse(w, "Failed to retrieve playlists", http.StatusInternalServerError)
+		writeError(w, r, err, "Failed to retrieve playlists")
 		return
 	}
 
@@ -232,15 +602,14 @@ func (s *Server) handlePlexTest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.plexClient.TestConnection(); err != nil {
-		writeErrorResponse(w, fmt.Sprintf("Plex connection failed: %v", err),
-			http.StatusServiceUnavailable)
+	if err := s.plexClient.TestConnection(r.Context()); err != nil {
+		writeError(w, r, err, "Plex connection failed")
 		return
 	}
 
-	serverInfo, err := s.plexClient.GetServerInfo()
+	serverInfo, err := s.plexClient.GetServerInfo(r.Context())
 	if err != nil {
-		log.Printf("Plex server info error: %v", err)
+		logger.ErrorContext(r.Context(), "Plex server info error", "error", err)
 		serverInfo = map[string]string{"status": "connected"}
 	}
 
@@ -261,7 +630,7 @@ func (s *Server) handleCacheStats(w http.ResponseWriter, r *http.Request) {
 
 	stats, err := s.cacheManager.GetCacheStats()
 	if err != nil {
-		log.Printf("Cache stats error: %v", err)
+		logger.ErrorContext(r.Context(), "Cache stats error", "error", err)
 		writeErrorResponse(w, "Failed to retrieve cache stats", http.StatusInternalServerError)
 		return
 	}
@@ -269,7 +638,10 @@ func (s *Server) handleCacheStats(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, stats, http.StatusOK)
 }
 
-// handleCacheClear clears expired cache entries
+// handleCacheClear clears or invalidates cache entries.
+// type=expired purges long-expired artists, type=all drops the whole artist cache, and
+// type=invalidate and type=refresh expire the artists selected by mbid, source, genre and
+// updated_after/updated_before; refresh also queues them for an immediate background refresh.
 func (s *Server) handleCacheClear(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -287,7 +659,7 @@ func (s *Server) handleCacheClear(w http.ResponseWriter, r *http.Request) {
 		// Clear entries that have been expired for more than 24 hours
 		count, err := s.cacheManager.CleanupExpiredEntries(24 * time.Hour)
 		if err != nil {
-			log.Printf("Cache clear error: %v", err)
+			logger.ErrorContext(r.Context(), "Cache clear error", "error", err)
 			writeErrorResponse(w, "Failed to clear expired entries", http.StatusInternalServerError)
 			return
 		}
@@ -298,36 +670,236 @@ func (s *Server) handleCacheClear(w http.ResponseWriter, r *http.Request) {
 		}
 		writeJSONResponse(w, response, http.StatusOK)
 	case "all":
-		// This would require additional implementation in cache service
-		writeErrorResponse(w, "Clear all not implemented", http.StatusNotImplemented)
-		return
+		result, err := s.cacheManager.ClearAll()
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Cache clear error", "error", err)
+			writeErrorResponse(w, "Failed to clear cache", http.StatusInternalServerError)
+			return
+		}
+		response := map[string]interface{}{
+			"status":  "success",
+			"message": fmt.Sprintf("Cleared %d cached artists", result.Artists),
+			"type":    "all",
+			"cleared": result,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
+	case "invalidate", "refresh":
+		filter, err := parseInvalidationFilter(r.URL.Query())
+		if err != nil {
+			writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var result db.InvalidationResult
+		if clearType == "refresh" {
+			if s.refreshService == nil {
+				writeErrorResponse(w, "Background refresh is not available", http.StatusServiceUnavailable)
+				return
+			}
+			result, err = s.refreshService.ForceRefresh(filter)
+		} else {
+			result, err = s.cacheManager.Invalidate(filter)
+		}
+		if err != nil {
+			logger.ErrorContext(r.Context(), "Cache invalidation error", "error", err)
+			writeErrorResponse(w, "Failed to invalidate cache entries", http.StatusInternalServerError)
+			return
+		}
+
+		response := map[string]interface{}{
+			"status":      "success",
+			"message":     fmt.Sprintf("Invalidated %d cached artists", result.Artists),
+			"type":        clearType,
+			"invalidated": result,
+		}
+		writeJSONResponse(w, response, http.StatusOK)
 	default:
-		writeErrorResponse(w, "Invalid clear type (use 'expired' or 'all')", http.StatusBadRequest)
+		writeErrorResponse(w, "Invalid clear type (use 'expired', 'all', 'invalidate' or 'refresh')", http.StatusBadRequest)
+		return
+	}
+}
+
+// parseInvalidationFilter reads the artists selected by a cache invalidation request.
+// mbid may be repeated or comma separated; genres include their subgenres like in search.
+func parseInvalidationFilter(query url.Values) (db.InvalidationFilter, error) {
+	var filter db.InvalidationFilter
+
+	for _, value := range query["mbid"] {
+		for _, mbid := range strings.Split(value, ",") {
+			mbid = strings.TrimSpace(mbid)
+			if !isValidMBID(mbid) {
+				return filter, fmt.Errorf("invalid MBID %q", mbid)
+			}
+			filter.MBIDs = append(filter.MBIDs, mbid)
+		}
+	}
+
+	if source := strings.ToLower(query.Get("source")); source != "" {
+		if !isSourceName(source) {
+			return filter, fmt.Errorf("source must be a source name such as discogs")
+		}
+		filter.Source = source
+	}
+
+	if genre := query.Get("genre"); genre != "" {
+		normalized, ok := taxonomy.Normalize(genre)
+		if !ok {
+			return filter, fmt.Errorf("%q is not a known genre", genre)
+		}
+		filter.Genres = append([]string{normalized}, taxonomy.Default().Descendants(normalized)...)
+	}
+
+	var err error
+	if filter.UpdatedAfter, err = parseTimeParam(query, "updated_after"); err != nil {
+		return filter, err
+	}
+	if filter.UpdatedBefore, err = parseTimeParam(query, "updated_before"); err != nil {
+		return filter, err
+	}
+	if !filter.UpdatedAfter.IsZero() && !filter.UpdatedBefore.IsZero() && !filter.UpdatedAfter.Before(filter.UpdatedBefore) {
+		return filter, fmt.Errorf("updated_after must be before updated_before")
+	}
+
+	return filter, filter.Validate()
+}
+
+// handleAdminExport streams the artist cache as NDJSON
+func (s *Server) handleAdminExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+
+	filename := fmt.Sprintf("gocommender-%s.ndjson", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// A large cache takes longer to stream than the write timeout allows
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		logger.WarnContext(r.Context(), "Failed to clear the write deadline", "error", err)
+	}
+
+	// Headers are already sent once streaming starts, so failures can only be logged
+	stats, err := s.cacheManager.Export(w)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Export error", "artists", stats.Artists, "error", err)
+		return
+	}
+	logger.InfoContext(r.Context(), "Exported artists", "artists", stats.Artists)
 }
 
-// handleRoot provides API information
+// handleAdminImport reads an NDJSON export from the request body into the artist cache
+func (s *Server) handleAdminImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	policy := r.URL.Query().Get("on_conflict")
+	if policy == "" {
+		policy = db.ConflictNewestWins
+	}
+	if err := db.ValidateConflictPolicy(policy); err != nil {
+		writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// A large upload takes longer than the read and write timeouts allow
+	controller := http.NewResponseController(w)
+	if err := controller.SetReadDeadline(time.Time{}); err != nil {
+		logger.WarnContext(r.Context(), "Failed to clear the read deadline", "error", err)
+	}
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil {
+		logger.WarnContext(r.Context(), "Failed to clear the write deadline", "error", err)
+	}
+
+	stats, err := s.cacheManager.Import(r.Body, policy, s.cacheManager.TTLPolicy())
+	if err != nil {
+		// Batches before the failure stay imported, report them with the error
+		logger.ErrorContext(r.Context(), "Import error", "error", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, db.ErrInvalidExport) {
+			status = http.StatusBadRequest
+		}
+		writeErrorCode(w, status, "", err.Error(), map[string]interface{}{
+			"imported": stats.Imported,
+			"skipped":  stats.Skipped,
+		})
+		return
+	}
+
+	writeJSONResponse(w, map[string]interface{}{
+		"status":      "success",
+		"on_conflict": policy,
+		"imported":    stats.Imported,
+		"skipped":     stats.Skipped,
+		"ignored":     stats.Ignored,
+	}, http.StatusOK)
+}
+
+// handleAdminBackup writes a database snapshot to the backup directory
+func (s *Server) handleAdminBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.backupService == nil {
+		writeErrorResponse(w, "Backups are not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	result, err := s.backupService.Backup()
+	if errors.Is(err, db.ErrBackupUnsupported) {
+		writeErrorResponse(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Backup error", "error", err)
+		writeErrorResponse(w, "Failed to back up database", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, result, http.StatusOK)
+}
+
+// handleRoot serves the web UI. Binaries built without it redirect / to the API overview.
 func (s *Server) handleRoot(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
+	// Unknown API paths must not get index.html
+	if strings.HasPrefix(r.URL.Path, "/api/") || (s.ui == nil && r.URL.Path != "/") {
 		writeErrorResponse(w, "Not found", http.StatusNotFound)
 		return
 	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.ui == nil {
+		http.Redirect(w, r, "/api", http.StatusFound)
+		return
+	}
+	s.ui.ServeHTTP(w, r)
+}
+
+// handleAPIIndex provides API information
+func (s *Server) handleAPIIndex(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Routes are listed from the OpenAPI document, which has the details
+	endpoints := make(map[string]string)
+	OpenAPIDocument(s.buildInfo.Version).Operations(func(method, path string, op *openapi.Operation) {
+		endpoints[method+" "+path] = op.Summary
+	})
 
 	info := map[string]interface{}{
 		"service":     "GoCommender API",
 		"version":     s.buildInfo.Version,
 		"description": "Music discovery backend using Plex, LLMs, and external APIs",
-		"endpoints": map[string]string{
-			"POST /api/recommend":     "Generate artist recommendations",
-			"GET /api/artists/{mbid}": "Get artist information by MusicBrainz ID",
-			"GET /api/health":         "Service health check",
-			"GET /api/info":           "Detailed API and build information",
-			"GET /api/plex/playlists": "List Plex playlists",
-			"GET /api/plex/test":      "Test Plex connection",
-			"GET /api/cache/stats":    "Cache performance statistics",
-			"POST /api/cache/clear":   "Clear cache entries",
-		},
+		"endpoints":   endpoints,
 	}
 
 	writeJSONResponse(w, info, http.StatusOK)
@@ -367,7 +939,8 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, X-Request-ID, traceparent")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, X-Trace-ID, Retry-After")
 
 		// Handle preflight requests
 		if r.Method == http.MethodOptions {
@@ -379,7 +952,43 @@ func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// loggingMiddleware logs HTTP requests
+// requestIDMiddleware puts a request ID into the request context, so every log line
+// of the request carries it, and returns it in the X-Request-ID header. A well-formed
+// ID sent by the client or a proxy is kept, otherwise a new one is generated.
+func (s *Server) requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !isValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	})
+}
+
+// isValidRequestID accepts up to 64 letters, digits, dashes and underscores
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// HTTP metrics, labelled by the matched route pattern to keep cardinality bounded
+var (
+	httpRequests = metrics.NewCounter("gocommender_http_requests_total",
+		"HTTP requests by route and status code.", "method", "route", "status")
+	httpDuration = metrics.NewHistogram("gocommender_http_request_duration_seconds",
+		"Latency of HTTP requests by route.", nil, "method", "route")
+)
+
+// loggingMiddleware logs HTTP requests and records their metrics
 func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -390,8 +999,27 @@ func (s *Server) loggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(rw, r)
 
 		duration := time.Since(start)
-		log.Printf("%s %s %d %v %s", r.Method, r.URL.Path, rw.statusCode,
-			duration, r.UserAgent())
+
+		// The mux sets the pattern on the request it was given
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		// Successful probes arrive every few seconds, keep them out of the info log
+		level := slog.LevelInfo
+		if strings.HasPrefix(route, "/api/health/") && rw.statusCode < http.StatusBadRequest {
+			level = slog.LevelDebug
+		}
+		logger.Log(r.Context(), level, "HTTP request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", route,
+			"status", rw.statusCode,
+			"duration", duration,
+			"user_agent", r.UserAgent(),
+		)
+		httpRequests.Inc(r.Method, route, strconv.Itoa(rw.statusCode))
+		httpDuration.Observe(duration.Seconds(), r.Method, route)
 	})
 }
 
@@ -406,25 +1034,56 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap lets http.ResponseController reach the connection, to flush or change deadlines
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // writeJSONResponse writes a JSON response with proper headers
 func writeJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
 	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Printf("Failed to encode JSON response: %v", err)
+		logger.Error("Failed to encode JSON response", "error", err)
 	}
 }
 
-// writeErrorResponse writes a standardized error response
-func writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
-	errorResponse := map[string]interface{}{
-		"error":     message,
-		"status":    "error",
-		"timestamp": time.Now().UTC(),
+// parseIntParam parses an optional integer query parameter
+func parseIntParam(query url.Values, name string, defaultValue int64) (int64, error) {
+	value := query.Get(name)
+	if value == "" {
+		return defaultValue, nil
 	}
+	return strconv.ParseInt(value, 10, 64)
+}
 
-	writeJSONResponse(w, errorResponse, statusCode)
+// parseTimeParam parses an optional RFC 3339 timestamp or YYYY-MM-DD date query parameter
+func parseTimeParam(query url.Values, name string) (time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp or a YYYY-MM-DD date", name)
+}
+
+// isSourceName accepts lowercase enrichment source names like "discogs" or "lastfm"
+func isSourceName(name string) bool {
+	if name == "" || len(name) > 32 {
+		return false
+	}
+	for _, r := range name {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
 }
 
 // isValidMBID performs basic MBID format validation
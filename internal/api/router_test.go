@@ -5,7 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"gocommender/internal/db"
+	"gocommender/internal/logging"
+	"gocommender/internal/services"
+	"gocommender/internal/testutil"
 )
 
 // createTestServer creates a Server instance with test buildInfo for testing
@@ -89,19 +95,19 @@ func TestWriteErrorResponse(t *testing.T) {
 		t.Errorf("Failed to unmarshal response: %v", err)
 	}
 
-	if response["error"] != "Test error" {
-		t.Errorf("Expected error 'Test error', got %s", response["error"])
+	if response["message"] != "Test error" {
+		t.Errorf("Expected message 'Test error', got %s", response["message"])
 	}
 
-	if response["status"] != "error" {
-		t.Errorf("Expected status 'error', got %s", response["status"])
+	if response["code"] != "invalid_request" {
+		t.Errorf("Expected code 'invalid_request', got %s", response["code"])
 	}
 }
 
-func TestHandleRoot(t *testing.T) {
+func TestHandleAPIIndex(t *testing.T) {
 	server := createTestServer()
 
-	req := httptest.NewRequest("GET", "/", nil)
+	req := httptest.NewRequest("GET", "/api", nil)
 	w := httptest.NewRecorder()
 
 	server.mux.ServeHTTP(w, req)
@@ -196,8 +202,8 @@ func TestHandleRecommendMissingPlaylistName(t *testing.T) {
 		t.Errorf("Failed to unmarshal response: %v", err)
 	}
 
-	if response["error"] != "playlist_name is required" {
-		t.Errorf("Expected error about playlist_name, got %s", response["error"])
+	if response["message"] != "playlist_name is required" {
+		t.Errorf("Expected message about playlist_name, got %s", response["message"])
 	}
 }
 
@@ -271,3 +277,283 @@ func TestCorsPreflightRequest(t *testing.T) {
 		t.Errorf("Expected status %d for preflight request, got %d", http.StatusOK, w.Code)
 	}
 }
+
+func TestHandleArtistReleasesInvalidMBID(t *testing.T) {
+	server := createTestServer()
+
+	req := httptest.NewRequest("GET", "/api/artists/invalid-mbid/releases", nil)
+	w := httptest.NewRecorder()
+
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleImageInvalidMBID(t *testing.T) {
+	server := createTestServer()
+
+	req := httptest.NewRequest("GET", "/api/images/not-an-mbid", nil)
+	w := httptest.NewRecorder()
+
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleArtistSimilarInvalidParams(t *testing.T) {
+	server := createTestServer()
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"invalid MBID", "/api/artists/not-an-mbid/similar"},
+		{"invalid limit", "/api/artists/b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d/similar?limit=0"},
+		{"invalid min_match", "/api/artists/b10bbbfc-cf9e-42e0-be17-e2c3e1d2600d/similar?min_match=2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+
+			server.mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleArtistsInvalidParams(t *testing.T) {
+	server := createTestServer()
+
+	for _, query := range []string{
+		"min_listeners=abc", "max_listeners=-1", "limit=500", "offset=-5",
+		"sort=random", "sort=relevance", "country=GBR", "verified=last.fm", "genre=seen+live", "page=not-a-cursor",
+	} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/artists?"+query, nil)
+			w := httptest.NewRecorder()
+
+			server.mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleRecommendInvalidEngine(t *testing.T) {
+	server := createTestServer()
+
+	body := bytes.NewBufferString(`{"playlist_name": "Favorites", "engine": "magic"}`)
+	req := httptest.NewRequest("POST", "/api/recommend", body)
+	w := httptest.NewRecorder()
+
+	server.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandleAdminInvalidRequests(t *testing.T) {
+	server := createTestServer()
+
+	tests := []struct {
+		method   string
+		path     string
+		expected int
+	}{
+		{"GET", "/api/admin/import", http.StatusMethodNotAllowed},
+		{"POST", "/api/admin/import?on_conflict=merge", http.StatusBadRequest},
+		{"POST", "/api/admin/export", http.StatusMethodNotAllowed},
+		{"GET", "/api/admin/backup", http.StatusMethodNotAllowed},
+		{"POST", "/api/admin/backup", http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			server.mux.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleCacheClearInvalidParams(t *testing.T) {
+	server := createTestServer()
+
+	for _, query := range []string{
+		"type=invalidate", "type=invalidate&mbid=not-an-mbid", "type=invalidate&source=last.fm",
+		"type=invalidate&genre=seen+live", "type=invalidate&updated_after=yesterday",
+		"type=refresh&updated_after=2025-02-01&updated_before=2025-01-01", "type=everything",
+		"type=invalidate&source=discogs&genre=shoegaze",
+	} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/cache/clear?"+query, nil)
+			w := httptest.NewRecorder()
+
+			server.mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	server := createTestServer()
+
+	// Requests are recorded by route pattern, not by path
+	req := httptest.NewRequest("GET", "/api/artists/not-an-mbid", nil)
+	server.ServeHTTP(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	body := w.Body.String()
+	for _, series := range []string{
+		`gocommender_http_requests_total{method="GET",route="/api/artists/",status="400"}`,
+		`gocommender_http_request_duration_seconds_count{method="GET",route="/api/artists/"}`,
+	} {
+		if !strings.Contains(body, series) {
+			t.Errorf("Expected metrics to contain %s", series)
+		}
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	server := createTestServer()
+
+	var seen string
+	handler := server.requestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+	}))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{"generated", "", false},
+		{"from client", "abc-123_XYZ", true},
+		{"invalid characters", "abc 123\n", false},
+		{"too long", strings.Repeat("a", 65), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/info", nil)
+			if tt.incoming != "" {
+				req.Header.Set("X-Request-ID", tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			id := w.Header().Get("X-Request-ID")
+			if id == "" || id != seen {
+				t.Errorf("Expected the header %q to match the context %q", id, seen)
+			}
+			if tt.keep != (id == tt.incoming) {
+				t.Errorf("Unexpected request ID %q for incoming %q", id, tt.incoming)
+			}
+		})
+	}
+}
+
+func TestProbes(t *testing.T) {
+	database, cleanup := testutil.CreateTestDB(t)
+	defer cleanup()
+
+	server := createTestServer()
+	server.cacheManager = db.NewCacheManager(database)
+
+	probe := func(path string) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to unmarshal %s response: %v", path, err)
+		}
+		return w.Code, response
+	}
+
+	if code, response := probe("/api/health/ready"); code != http.StatusOK || response["status"] != "ready" {
+		t.Errorf("Expected ready, got %d %v", code, response)
+	}
+
+	// Draining fails readiness while the server stays alive
+	server.Drain()
+	if code, response := probe("/api/health/ready"); code != http.StatusServiceUnavailable || response["status"] != "draining" {
+		t.Errorf("Expected draining, got %d %v", code, response)
+	}
+	if code, response := probe("/api/health/live"); code != http.StatusOK || response["status"] != "alive" {
+		t.Errorf("Expected alive, got %d %v", code, response)
+	}
+
+	// A closed database fails readiness
+	database.Close()
+	server.draining.Store(false)
+	if code, response := probe("/api/health/ready"); code != http.StatusServiceUnavailable || response["status"] != "unavailable" {
+		t.Errorf("Expected unavailable, got %d %v", code, response)
+	}
+}
+
+func TestHealthHidesPlexToken(t *testing.T) {
+	database, cleanup := testutil.CreateTestDB(t)
+	defer cleanup()
+
+	plex := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	defer plex.Close()
+
+	for _, tt := range []struct {
+		url  string
+		code services.ErrorCode
+	}{
+		{plex.URL, services.CodePlexUnauthorized},
+		{unreachable.URL, services.CodeUpstreamUnavailable},
+	} {
+		server := createTestServer()
+		server.cacheManager = db.NewCacheManager(database)
+		server.plexClient = services.NewPlexClient(tt.url, "plex-s3cret")
+
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, httptest.NewRequest("GET", "/api/health", nil))
+		if strings.Contains(w.Body.String(), "plex-s3cret") {
+			t.Errorf("Health response leaks the Plex token: %s", w.Body.String())
+		}
+
+		var response struct {
+			Plex struct {
+				Status string `json:"status"`
+				Error  string `json:"error"`
+			} `json:"plex"`
+		}
+		testutil.AssertNoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		testutil.AssertEqual(t, "error", response.Plex.Status)
+		testutil.AssertEqual(t, string(tt.code), response.Plex.Error)
+	}
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// uiAsset is a file of the built web UI, held in memory with its precompressed variants
+type uiAsset struct {
+	name        string
+	contentType string
+	etag        string
+	data        []byte
+	encoded     map[string][]byte // Body by Content-Encoding, from the .br and .gz files next to it
+}
+
+// uiHandler serves the web UI. Paths that are not files fall back to index.html,
+// so client-side routes survive a reload.
+type uiHandler struct {
+	assets map[string]*uiAsset
+	index  *uiAsset
+}
+
+// uiEncodings are the precompressed variants, by preference
+var uiEncodings = []struct {
+	name, ext string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// UseUI serves the built web UI from / instead of redirecting to the API overview
+func (s *Server) UseUI(assets fs.FS) error {
+	ui, err := newUIHandler(assets)
+	if err != nil {
+		return err
+	}
+	s.ui = ui
+	return nil
+}
+
+// newUIHandler loads every file of the UI, which needs an index.html
+func newUIHandler(assets fs.FS) (*uiHandler, error) {
+	files := make(map[string][]byte)
+	err := fs.WalkDir(assets, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(assets, name)
+		if err != nil {
+			return err
+		}
+		files[name] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load web UI: %w", err)
+	}
+
+	ui := &uiHandler{assets: make(map[string]*uiAsset)}
+	for name, data := range files {
+		if isUIVariant(name, files) {
+			continue
+		}
+
+		sum := sha256.Sum256(data)
+		asset := &uiAsset{
+			name:        name,
+			contentType: mime.TypeByExtension(path.Ext(name)),
+			etag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+			data:        data,
+			encoded:     make(map[string][]byte),
+		}
+		if asset.contentType == "" {
+			asset.contentType = http.DetectContentType(data)
+		}
+		for _, encoding := range uiEncodings {
+			if variant, ok := files[name+encoding.ext]; ok {
+				asset.encoded[encoding.name] = variant
+			}
+		}
+		ui.assets[name] = asset
+	}
+
+	ui.index = ui.assets["index.html"]
+	if ui.index == nil {
+		return nil, fmt.Errorf("failed to load web UI: index.html not found")
+	}
+	return ui, nil
+}
+
+// isUIVariant reports whether a file is a precompressed copy of another file
+func isUIVariant(name string, files map[string][]byte) bool {
+	for _, encoding := range uiEncodings {
+		if original, ok := strings.CutSuffix(name, encoding.ext); ok {
+			if _, exists := files[original]; exists {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ServeHTTP serves a file of the UI, picking a precompressed variant the client accepts
+func (u *uiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	asset, ok := u.assets[name]
+	if !ok {
+		// A missing script or image is an error, anything else is a client-side route
+		if path.Ext(name) != "" {
+			writeErrorResponse(w, "Not found", http.StatusNotFound)
+			return
+		}
+		asset = u.index
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", asset.contentType)
+	header.Set("X-Content-Type-Options", "nosniff")
+	if strings.HasPrefix(asset.name, "assets/") {
+		// Vite puts a content hash in these names, so they never change
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+
+	body, etag := asset.data, asset.etag
+	if len(asset.encoded) > 0 {
+		header.Add("Vary", "Accept-Encoding")
+		for _, encoding := range uiEncodings {
+			variant, ok := asset.encoded[encoding.name]
+			if ok && acceptsEncoding(r.Header.Get("Accept-Encoding"), encoding.name) {
+				header.Set("Content-Encoding", encoding.name)
+				body, etag = variant, strings.TrimSuffix(asset.etag, `"`)+"-"+encoding.name+`"`
+				break
+			}
+		}
+	}
+	header.Set("ETag", etag)
+
+	// Handles If-None-Match and ranges
+	http.ServeContent(w, r, asset.name, time.Time{}, bytes.NewReader(body))
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows an encoding
+func acceptsEncoding(accept, encoding string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				weight, err := strconv.ParseFloat(q, 64)
+				return err == nil && weight > 0
+			}
+		}
+		return true
+	}
+	return false
+}
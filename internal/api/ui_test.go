@@ -0,0 +1,127 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"gocommender/internal/testutil"
+)
+
+func createUITestServer(t *testing.T) *Server {
+	server := createTestServer()
+	testutil.AssertNoError(t, server.UseUI(fstest.MapFS{
+		"index.html":            {Data: []byte("<!doctype html><title>GoCommender</title>")},
+		"vite.svg":              {Data: []byte("<svg></svg>")},
+		"assets/index-a1.js":    {Data: []byte("console.log('app')")},
+		"assets/index-a1.js.br": {Data: []byte("brotli")},
+		"assets/index-a1.js.gz": {Data: []byte("gzip")},
+	}))
+	return server
+}
+
+func TestUI(t *testing.T) {
+	server := createUITestServer(t)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		acceptEncoding string
+		status         int
+		contentType    string
+		encoding       string
+		cacheControl   string
+		body           string
+	}{
+		{"index", "GET", "/", "", http.StatusOK, "text/html; charset=utf-8", "", "no-cache", "<!doctype html><title>GoCommender</title>"},
+		{"client route", "GET", "/artists/42", "", http.StatusOK, "text/html; charset=utf-8", "", "no-cache", "<!doctype html><title>GoCommender</title>"},
+		{"public file", "GET", "/vite.svg", "gzip", http.StatusOK, "image/svg+xml", "", "no-cache", "<svg></svg>"},
+		{"hashed asset", "GET", "/assets/index-a1.js", "", http.StatusOK, "text/javascript; charset=utf-8", "", "public, max-age=31536000, immutable", "console.log('app')"},
+		{"brotli", "GET", "/assets/index-a1.js", "gzip, deflate, br", http.StatusOK, "text/javascript; charset=utf-8", "br", "public, max-age=31536000, immutable", "brotli"},
+		{"gzip", "GET", "/assets/index-a1.js", "gzip, br;q=0", http.StatusOK, "text/javascript; charset=utf-8", "gzip", "public, max-age=31536000, immutable", "gzip"},
+		{"missing asset", "GET", "/assets/missing.js", "", http.StatusNotFound, "application/json", "", "", ""},
+		{"unknown API path", "GET", "/api/unknown", "", http.StatusNotFound, "application/json", "", "", ""},
+		{"head", "HEAD", "/", "", http.StatusOK, "text/html; charset=utf-8", "", "no-cache", ""},
+		{"post", "POST", "/", "", http.StatusMethodNotAllowed, "application/json", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			server.mux.ServeHTTP(w, req)
+
+			testutil.AssertEqual(t, tt.status, w.Code)
+			testutil.AssertEqual(t, tt.contentType, w.Header().Get("Content-Type"))
+			testutil.AssertEqual(t, tt.encoding, w.Header().Get("Content-Encoding"))
+			testutil.AssertEqual(t, tt.cacheControl, w.Header().Get("Cache-Control"))
+			if tt.body != "" {
+				testutil.AssertEqual(t, tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestUIConditionalRequest(t *testing.T) {
+	server := createUITestServer(t)
+
+	req := httptest.NewRequest("GET", "/assets/index-a1.js", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	etag := w.Header().Get("ETag")
+	testutil.AssertTrue(t, etag != "")
+	testutil.AssertEqual(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+	req = httptest.NewRequest("GET", "/assets/index-a1.js", nil)
+	req.Header.Set("Accept-Encoding", "br")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	testutil.AssertEqual(t, http.StatusNotModified, w.Code)
+
+	// Each encoding is a different representation
+	req = httptest.NewRequest("GET", "/assets/index-a1.js", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	testutil.AssertEqual(t, http.StatusOK, w.Code)
+}
+
+func TestUIWithoutIndex(t *testing.T) {
+	server := createTestServer()
+	testutil.AssertError(t, server.UseUI(fstest.MapFS{"vite.svg": {Data: []byte("<svg></svg>")}}))
+}
+
+func TestRootWithoutUI(t *testing.T) {
+	server := createTestServer()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	testutil.AssertEqual(t, http.StatusFound, w.Code)
+	testutil.AssertEqual(t, "/api", w.Header().Get("Location"))
+
+	req = httptest.NewRequest("GET", "/artists/42", nil)
+	w = httptest.NewRecorder()
+	server.mux.ServeHTTP(w, req)
+
+	testutil.AssertEqual(t, http.StatusNotFound, w.Code)
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	testutil.AssertTrue(t, acceptsEncoding("gzip, br", "br"))
+	testutil.AssertTrue(t, acceptsEncoding("BR;q=0.5", "br"))
+	testutil.AssertFalse(t, acceptsEncoding("gzip, br;q=0", "br"))
+	testutil.AssertFalse(t, acceptsEncoding("gzip", "br"))
+	testutil.AssertFalse(t, acceptsEncoding("", "gzip"))
+}
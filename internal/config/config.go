@@ -2,10 +2,17 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
 	"net/url"
 	"strings"
 	"time"
 
+	"gocommender/internal/db"
+	"gocommender/internal/dialect"
+	"gocommender/internal/logging"
+	"gocommender/internal/models"
+	"gocommender/internal/notify"
+
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
@@ -18,12 +25,35 @@ type Config struct {
 	External ExternalConfig `mapstructure:"external"`
 	Database DatabaseConfig `mapstructure:"database"`
 	Cache    CacheConfig    `mapstructure:"cache"`
+	Images   ImageConfig    `mapstructure:"images"`
+	Backup   BackupConfig   `mapstructure:"backup"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	LLMQuota  LLMQuotaConfig  `mapstructure:"llm_quota"`
+	Tracing   TracingConfig   `mapstructure:"tracing"`
+	Logging   LoggingConfig   `mapstructure:"logging"`
+
+	ResponseCache ResponseCacheConfig `mapstructure:"response_cache"`
+	Notifications NotificationsConfig `mapstructure:"notifications"`
 }
 
 // ServerConfig contains HTTP server settings
 type ServerConfig struct {
 	Host string `mapstructure:"host"`
 	Port string `mapstructure:"port"`
+
+	// Timeouts of the http.Server; the write timeout must cover a recommendation
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+
+	// On SIGINT or SIGTERM readiness fails for ShutdownDelay so load balancers stop
+	// sending requests, then in-flight requests get up to ShutdownTimeout to finish.
+	// It must cover the write timeout so a running recommendation is not cut off.
+	ShutdownDelay   time.Duration `mapstructure:"shutdown_delay"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 }
 
 // PlexConfig contains Plex server settings
@@ -37,23 +67,241 @@ type OpenAIConfig struct {
 	APIKey             string `mapstructure:"api_key"`
 	Model              string `mapstructure:"model"`
 	PromptTemplatePath string `mapstructure:"prompt_template_path"`
+
+	// USD per million tokens for the configured model, overriding the built-in price list
+	InputPrice  float64 `mapstructure:"input_price"`
+	OutputPrice float64 `mapstructure:"output_price"`
 }
 
 // ExternalConfig contains optional external API configurations
 type ExternalConfig struct {
-	DiscogsToken string `mapstructure:"discogs_token"`
-	LastFMAPIKey string `mapstructure:"lastfm_api_key"`
+	DiscogsToken string   `mapstructure:"discogs_token"`
+	LastFMAPIKey string   `mapstructure:"lastfm_api_key"`
+	Languages    []string `mapstructure:"languages"` // Preferred biography languages, e.g. "fi,de"
 }
 
 // DatabaseConfig contains database settings
 type DatabaseConfig struct {
-	Path string `mapstructure:"path"`
+	Driver string `mapstructure:"driver"` // sqlite or postgres
+	Path   string `mapstructure:"path"`   // SQLite database file
+	URL    string `mapstructure:"url"`    // PostgreSQL connection string
+
+	// SQLite connection settings
+	JournalMode string        `mapstructure:"journal_mode"` // wal, delete or truncate
+	BusyTimeout time.Duration `mapstructure:"busy_timeout"` // How long to wait for a lock before failing
+}
+
+// Dialect returns the SQL dialect of the configured driver, SQLite if it is not recognised
+func (d DatabaseConfig) Dialect() dialect.Dialect {
+	parsed, err := dialect.Parse(d.Driver)
+	if err != nil {
+		return dialect.SQLite
+	}
+	return parsed
 }
 
 // CacheConfig contains caching behavior settings
 type CacheConfig struct {
 	TTLSuccess time.Duration `mapstructure:"ttl_success"`
 	TTLFailure time.Duration `mapstructure:"ttl_failure"`
+
+	// Per-source TTLs for verified artists, 0 falls back to TTLSuccess
+	TTLMusicBrainz time.Duration `mapstructure:"ttl_musicbrainz"`
+	TTLDiscogs     time.Duration `mapstructure:"ttl_discogs"`
+	TTLLastFM      time.Duration `mapstructure:"ttl_lastfm"`
+	TTLWikipedia   time.Duration `mapstructure:"ttl_wikipedia"`
+
+	Jitter float64 `mapstructure:"jitter"` // Fraction of the TTL to spread expiries by, e.g. 0.1
+}
+
+// Policy returns the artist cache TTL policy
+func (c CacheConfig) Policy() db.CacheConfig {
+	policy := db.DefaultCacheConfig()
+	policy.VerifiedTTL = c.TTLSuccess
+	policy.UnverifiedTTL = c.TTLFailure
+	policy.Jitter = c.Jitter
+	policy.SourceTTLs = map[string]time.Duration{
+		"musicbrainz": c.TTLMusicBrainz,
+		"discogs":     c.TTLDiscogs,
+		"lastfm":      c.TTLLastFM,
+		"wikipedia":   c.TTLWikipedia,
+	}
+	return policy
+}
+
+// AuthConfig contains HTTP API authentication settings
+type AuthConfig struct {
+	Enabled bool `mapstructure:"enabled"` // Require an API key or trusted proxy identity
+
+	// Identity headers set by an authenticating reverse proxy, e.g. for SSO
+	ProxyUserHeader  string   `mapstructure:"proxy_user_header"`  // Empty disables proxy authentication
+	ProxyRoleHeader  string   `mapstructure:"proxy_role_header"`  // Optional role or comma-separated groups
+	ProxyDefaultRole string   `mapstructure:"proxy_default_role"` // Role when the role header names none
+	TrustedProxies   []string `mapstructure:"trusted_proxies"`    // Proxy IPs or CIDRs allowed to set the headers
+}
+
+// TrustedProxyPrefixes parses TrustedProxies; single addresses become one-address prefixes
+func (c AuthConfig) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, value := range c.TrustedProxies {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// RateLimitConfig contains per-client token bucket settings
+type RateLimitConfig struct {
+	Enabled            bool    `mapstructure:"enabled"`
+	RequestsPerSecond  float64 `mapstructure:"requests_per_second"` // All limited endpoints
+	Burst              int     `mapstructure:"burst"`
+	RecommendPerMinute float64 `mapstructure:"recommend_per_minute"` // POST /api/recommend on top of the general limit
+	RecommendBurst     int     `mapstructure:"recommend_burst"`
+	AddressPerSecond   float64 `mapstructure:"address_per_second"` // Per client address before authentication, across keys
+	AddressBurst       int     `mapstructure:"address_burst"`
+}
+
+// LLMQuotaConfig contains LLM spend limits per UTC day and month, 0 disables a limit
+type LLMQuotaConfig struct {
+	DailyTokens   int64   `mapstructure:"daily_tokens"`
+	MonthlyTokens int64   `mapstructure:"monthly_tokens"`
+	DailyCost     float64 `mapstructure:"daily_cost"`   // USD
+	MonthlyCost   float64 `mapstructure:"monthly_cost"` // USD
+}
+
+// TracingConfig selects where spans are exported
+type TracingConfig struct {
+	Exporter     string `mapstructure:"exporter"`      // none, stdout or otlp
+	OTLPEndpoint string `mapstructure:"otlp_endpoint"` // OTLP/HTTP collector base URL
+	ServiceName  string `mapstructure:"service_name"`
+}
+
+// LoggingConfig contains log output settings
+type LoggingConfig struct {
+	Format string `mapstructure:"format"` // json or text
+	Level  string `mapstructure:"level"`  // debug, info, warn or error
+	Levels string `mapstructure:"levels"` // Per component, e.g. "refresh=debug,api=warn"
+}
+
+// Options returns the logging configuration; levels were checked by validate
+func (c LoggingConfig) Options() logging.Config {
+	level, _ := logging.ParseLevel(c.Level)
+	levels, _ := logging.ParseLevels(c.Levels)
+	return logging.Config{Format: c.Format, Level: level, Levels: levels}
+}
+
+// ImageConfig contains artist image cache settings
+type ImageConfig struct {
+	CacheDir string        `mapstructure:"cache_dir"`
+	MaxSize  int           `mapstructure:"max_size"` // Maximum thumbnail width/height in pixels
+	TTL      time.Duration `mapstructure:"ttl"`
+}
+
+// BackupConfig contains scheduled SQLite backup settings
+type BackupConfig struct {
+	Dir      string        `mapstructure:"dir"`
+	Interval time.Duration `mapstructure:"interval"` // 0 disables scheduled backups
+	Retain   int           `mapstructure:"retain"`   // Number of backups to keep, 0 keeps all
+}
+
+// ResponseCacheConfig contains raw upstream response cache settings
+type ResponseCacheConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	MusicBrainzTTL time.Duration `mapstructure:"musicbrainz_ttl"`
+	DiscogsTTL     time.Duration `mapstructure:"discogs_ttl"`
+	LastFMTTL      time.Duration `mapstructure:"lastfm_ttl"`
+	WikipediaTTL   time.Duration `mapstructure:"wikipedia_ttl"`
+}
+
+// TTLs returns the response TTL per enrichment source
+func (c ResponseCacheConfig) TTLs() map[string]time.Duration {
+	return map[string]time.Duration{
+		"musicbrainz": c.MusicBrainzTTL,
+		"discogs":     c.DiscogsTTL,
+		"lastfm":      c.LastFMTTL,
+		"wikipedia":   c.WikipediaTTL,
+	}
+}
+
+// NotificationsConfig contains the notification targets of recommendation runs.
+// Targets are a list, so they are set in config.yaml rather than the environment.
+type NotificationsConfig struct {
+	Targets       []NotifierConfig `mapstructure:"targets"`
+	Default       []string         `mapstructure:"default"` // Targets of runs that name none
+	Retries       int              `mapstructure:"retries"`
+	RetryDelay    time.Duration    `mapstructure:"retry_delay"` // Doubled for each further retry
+	RetryMaxDelay time.Duration    `mapstructure:"retry_max_delay"`
+	Timeout       time.Duration    `mapstructure:"timeout"` // Of each attempt
+}
+
+// NotifierConfig contains one notification target
+type NotifierConfig struct {
+	Name     string `mapstructure:"name"`
+	Type     string `mapstructure:"type"`     // webhook, discord, slack, ntfy, gotify or email
+	URL      string `mapstructure:"url"`      // Webhook URL, ntfy topic URL or Gotify server URL
+	Secret   string `mapstructure:"secret"`   // HMAC key of generic webhooks
+	Token    string `mapstructure:"token"`    // ntfy access token or Gotify application token
+	Priority int    `mapstructure:"priority"` // ntfy and Gotify message priority
+	Title    string `mapstructure:"title"`    // Go text/template, see notify.Event for the fields
+	Template string `mapstructure:"template"` // Message body template
+
+	// Email settings
+	SMTPHost     string   `mapstructure:"smtp_host"`
+	SMTPPort     int      `mapstructure:"smtp_port"`
+	SMTPUsername string   `mapstructure:"smtp_username"`
+	SMTPPassword string   `mapstructure:"smtp_password"`
+	From         string   `mapstructure:"from"`
+	To           []string `mapstructure:"to"`
+}
+
+// Options returns the notification targets and retry policy
+func (c NotificationsConfig) Options() notify.Config {
+	targets := make([]notify.TargetConfig, 0, len(c.Targets))
+	for _, target := range c.Targets {
+		targets = append(targets, notify.TargetConfig{
+			Name:     target.Name,
+			Type:     target.Type,
+			URL:      target.URL,
+			Secret:   target.Secret,
+			Token:    target.Token,
+			Priority: target.Priority,
+			Title:    target.Title,
+			Template: target.Template,
+			SMTP: notify.SMTPConfig{
+				Host:     target.SMTPHost,
+				Port:     target.SMTPPort,
+				Username: target.SMTPUsername,
+				Password: target.SMTPPassword,
+				From:     target.From,
+				To:       target.To,
+			},
+		})
+	}
+	return notify.Config{
+		Targets: targets,
+		Default: c.Default,
+		Retry: notify.RetryPolicy{
+			Retries:  c.Retries,
+			Delay:    c.RetryDelay,
+			MaxDelay: c.RetryMaxDelay,
+			Timeout:  c.Timeout,
+		},
+	}
 }
 
 // Load loads configuration from environment variables and files
@@ -99,17 +347,76 @@ func setDefaults() {
 	// Server defaults
 	viper.SetDefault("server.host", "localhost")
 	viper.SetDefault("server.port", "8080")
+	viper.SetDefault("server.read_header_timeout", "10s")
+	viper.SetDefault("server.read_timeout", "60s")
+	viper.SetDefault("server.write_timeout", "180s") // Recommendations wait for the LLM
+	viper.SetDefault("server.idle_timeout", "120s")
+	viper.SetDefault("server.shutdown_delay", "0s")
+	viper.SetDefault("server.shutdown_timeout", "180s") // At least the write timeout
 
 	// OpenAI defaults
 	viper.SetDefault("openai.model", "gpt-4o")
 	viper.SetDefault("openai.prompt_template_path", "./prompts/openai_recommendation.tmpl")
 
 	// Database defaults
+	viper.SetDefault("database.driver", "sqlite")
 	viper.SetDefault("database.path", "./gocommender.db")
+	viper.SetDefault("database.journal_mode", DefaultJournalMode)
+	viper.SetDefault("database.busy_timeout", DefaultBusyTimeout)
+
+	// Backup defaults
+	viper.SetDefault("backup.dir", "./backups")
+	viper.SetDefault("backup.interval", "24h")
+	viper.SetDefault("backup.retain", 7)
 
 	// Cache defaults
-	viper.SetDefault("cache.ttl_success", "720h") // 30 days
-	viper.SetDefault("cache.ttl_failure", "168h") // 7 days
+	viper.SetDefault("cache.ttl_success", "720h")      // 30 days
+	viper.SetDefault("cache.ttl_failure", "168h")      // 7 days
+	viper.SetDefault("cache.ttl_musicbrainz", "2160h") // 90 days, core data rarely changes
+	viper.SetDefault("cache.ttl_lastfm", "720h")       // 30 days for listener counts and bios
+	viper.SetDefault("cache.jitter", 0.1)
+
+	// Auth defaults; the API is closed unless AUTH_ENABLED=false, and proxy users
+	// without a role header may only read
+	viper.SetDefault("auth.enabled", true)
+	viper.SetDefault("auth.proxy_default_role", "read-only")
+
+	// Rate limit defaults; recommendations call the LLM and are limited further
+	viper.SetDefault("rate_limit.enabled", true)
+	viper.SetDefault("rate_limit.requests_per_second", 10)
+	viper.SetDefault("rate_limit.burst", 40)
+	viper.SetDefault("rate_limit.recommend_per_minute", 6)
+	viper.SetDefault("rate_limit.recommend_burst", 3)
+	viper.SetDefault("rate_limit.address_per_second", 20)
+	viper.SetDefault("rate_limit.address_burst", 80)
+
+	// Tracing defaults; spans are only exported when an exporter is chosen
+	viper.SetDefault("tracing.exporter", "none")
+	viper.SetDefault("tracing.otlp_endpoint", "http://localhost:4318")
+	viper.SetDefault("tracing.service_name", "gocommender")
+
+	// Logging defaults
+	viper.SetDefault("logging.format", "json")
+	viper.SetDefault("logging.level", "info")
+	viper.SetDefault("logging.levels", "")
+
+	// Image cache defaults
+	viper.SetDefault("images.cache_dir", "./data/images")
+	viper.SetDefault("images.max_size", 500)
+	viper.SetDefault("images.ttl", "720h") // 30 days
+
+	// Raw response cache defaults; Last.fm popularity changes fastest
+	viper.SetDefault("response_cache.enabled", true)
+	viper.SetDefault("response_cache.musicbrainz_ttl", "720h") // 30 days
+	viper.SetDefault("response_cache.discogs_ttl", "720h")     // 30 days
+	viper.SetDefault("response_cache.lastfm_ttl", "24h")
+	viper.SetDefault("response_cache.wikipedia_ttl", "720h") // 30 days
+
+	// Notification defaults; retries wait 5s, 10s and 20s
+	viper.SetDefault("notifications.retries", 3)
+	viper.SetDefault("notifications.retry_delay", "5s")
+	viper.SetDefault("notifications.retry_max_delay", "1m")
+	viper.SetDefault("notifications.timeout", "10s")
 
 	// Map environment variables
 	viper.BindEnv("plex.url", "PLEX_URL")
@@ -118,11 +425,65 @@ func setDefaults() {
 	viper.BindEnv("openai.prompt_template_path", "OPENAI_PROMPT_TEMPLATE_PATH")
 	viper.BindEnv("external.discogs_token", "DISCOGS_TOKEN")
 	viper.BindEnv("external.lastfm_api_key", "LASTFM_API_KEY")
+	viper.BindEnv("external.languages", "ENRICHMENT_LANGUAGES")
+	viper.BindEnv("database.driver", "DATABASE_DRIVER")
 	viper.BindEnv("database.path", "DATABASE_PATH")
+	viper.BindEnv("database.url", "DATABASE_URL")
+	viper.BindEnv("database.journal_mode", "DATABASE_JOURNAL_MODE")
+	viper.BindEnv("database.busy_timeout", "DATABASE_BUSY_TIMEOUT")
+	viper.BindEnv("backup.dir", "BACKUP_DIR")
+	viper.BindEnv("backup.interval", "BACKUP_INTERVAL")
+	viper.BindEnv("backup.retain", "BACKUP_RETAIN")
 	viper.BindEnv("server.port", "PORT")
 	viper.BindEnv("server.host", "HOST")
+	viper.BindEnv("server.read_header_timeout", "SERVER_READ_HEADER_TIMEOUT")
+	viper.BindEnv("server.read_timeout", "SERVER_READ_TIMEOUT")
+	viper.BindEnv("server.write_timeout", "SERVER_WRITE_TIMEOUT")
+	viper.BindEnv("server.idle_timeout", "SERVER_IDLE_TIMEOUT")
+	viper.BindEnv("server.shutdown_delay", "SERVER_SHUTDOWN_DELAY")
+	viper.BindEnv("server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT")
 	viper.BindEnv("cache.ttl_success", "CACHE_TTL_SUCCESS")
 	viper.BindEnv("cache.ttl_failure", "CACHE_TTL_FAILURE")
+	viper.BindEnv("cache.ttl_musicbrainz", "CACHE_TTL_MUSICBRAINZ")
+	viper.BindEnv("cache.ttl_discogs", "CACHE_TTL_DISCOGS")
+	viper.BindEnv("cache.ttl_lastfm", "CACHE_TTL_LASTFM")
+	viper.BindEnv("cache.ttl_wikipedia", "CACHE_TTL_WIKIPEDIA")
+	viper.BindEnv("cache.jitter", "CACHE_TTL_JITTER")
+	viper.BindEnv("auth.enabled", "AUTH_ENABLED")
+	viper.BindEnv("auth.proxy_user_header", "AUTH_PROXY_USER_HEADER")
+	viper.BindEnv("auth.proxy_role_header", "AUTH_PROXY_ROLE_HEADER")
+	viper.BindEnv("auth.proxy_default_role", "AUTH_PROXY_DEFAULT_ROLE")
+	viper.BindEnv("auth.trusted_proxies", "AUTH_TRUSTED_PROXIES")
+	viper.BindEnv("openai.input_price", "OPENAI_INPUT_PRICE")
+	viper.BindEnv("openai.output_price", "OPENAI_OUTPUT_PRICE")
+	viper.BindEnv("rate_limit.enabled", "RATE_LIMIT_ENABLED")
+	viper.BindEnv("rate_limit.requests_per_second", "RATE_LIMIT_RPS")
+	viper.BindEnv("rate_limit.burst", "RATE_LIMIT_BURST")
+	viper.BindEnv("rate_limit.recommend_per_minute", "RATE_LIMIT_RECOMMEND_PER_MINUTE")
+	viper.BindEnv("rate_limit.recommend_burst", "RATE_LIMIT_RECOMMEND_BURST")
+	viper.BindEnv("rate_limit.address_per_second", "RATE_LIMIT_ADDRESS_RPS")
+	viper.BindEnv("rate_limit.address_burst", "RATE_LIMIT_ADDRESS_BURST")
+	viper.BindEnv("llm_quota.daily_tokens", "LLM_QUOTA_DAILY_TOKENS")
+	viper.BindEnv("llm_quota.monthly_tokens", "LLM_QUOTA_MONTHLY_TOKENS")
+	viper.BindEnv("llm_quota.daily_cost", "LLM_QUOTA_DAILY_COST")
+	viper.BindEnv("llm_quota.monthly_cost", "LLM_QUOTA_MONTHLY_COST")
+	viper.BindEnv("tracing.exporter", "TRACING_EXPORTER")
+	viper.BindEnv("tracing.otlp_endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT")
+	viper.BindEnv("tracing.service_name", "OTEL_SERVICE_NAME")
+	viper.BindEnv("logging.format", "LOG_FORMAT")
+	viper.BindEnv("logging.level", "LOG_LEVEL")
+	viper.BindEnv("logging.levels", "LOG_LEVELS")
+	viper.BindEnv("images.cache_dir", "IMAGE_CACHE_DIR")
+	viper.BindEnv("response_cache.enabled", "RESPONSE_CACHE_ENABLED")
+	viper.BindEnv("response_cache.musicbrainz_ttl", "RESPONSE_CACHE_TTL_MUSICBRAINZ")
+	viper.BindEnv("response_cache.discogs_ttl", "RESPONSE_CACHE_TTL_DISCOGS")
+	viper.BindEnv("response_cache.lastfm_ttl", "RESPONSE_CACHE_TTL_LASTFM")
+	viper.BindEnv("response_cache.wikipedia_ttl", "RESPONSE_CACHE_TTL_WIKIPEDIA")
+	viper.BindEnv("notifications.default", "NOTIFY_DEFAULT")
+	viper.BindEnv("notifications.retries", "NOTIFY_RETRIES")
+	viper.BindEnv("notifications.retry_delay", "NOTIFY_RETRY_DELAY")
+	viper.BindEnv("notifications.retry_max_delay", "NOTIFY_RETRY_MAX_DELAY")
+	viper.BindEnv("notifications.timeout", "NOTIFY_TIMEOUT")
 }
 
 func validate(config *Config) error {
@@ -135,15 +496,115 @@ func validate(config *Config) error {
 	if config.Plex.Token == "" {
 		errors = append(errors, "PLEX_TOKEN is required")
 	}
-	if config.OpenAI.APIKey == "" {
-		errors = append(errors, "OPENAI_API_KEY is required")
-	}
+	// OPENAI_API_KEY is optional: without it recommendations use the similar-artist graph
 
 	// Validate URLs
 	if config.Plex.URL != "" && !isValidURL(config.Plex.URL) {
 		errors = append(errors, "PLEX_URL must be a valid URL")
 	}
 
+	// Validate database
+	if d, err := dialect.Parse(config.Database.Driver); err != nil {
+		errors = append(errors, err.Error())
+	} else if d == dialect.Postgres && config.Database.URL == "" {
+		errors = append(errors, "DATABASE_URL is required for the postgres driver")
+	}
+	switch strings.ToLower(config.Database.JournalMode) {
+	case "", "wal", "delete", "truncate":
+	default:
+		errors = append(errors, "DATABASE_JOURNAL_MODE must be wal, delete or truncate")
+	}
+	if config.Database.BusyTimeout < 0 {
+		errors = append(errors, "DATABASE_BUSY_TIMEOUT cannot be negative")
+	}
+
+	// Validate cache TTLs
+	if config.Cache.TTLSuccess <= 0 || config.Cache.TTLFailure <= 0 {
+		errors = append(errors, "CACHE_TTL_SUCCESS and CACHE_TTL_FAILURE must be positive")
+	}
+	if config.Cache.Jitter < 0 || config.Cache.Jitter >= 1 {
+		errors = append(errors, "CACHE_TTL_JITTER must be between 0 and 1")
+	}
+
+	// Validate auth
+	if _, err := models.ParseRole(config.Auth.ProxyDefaultRole); err != nil {
+		errors = append(errors, "AUTH_PROXY_DEFAULT_ROLE: "+err.Error())
+	}
+	if proxies, err := config.Auth.TrustedProxyPrefixes(); err != nil {
+		errors = append(errors, "AUTH_TRUSTED_PROXIES: "+err.Error())
+	} else if config.Auth.ProxyUserHeader != "" && len(proxies) == 0 {
+		errors = append(errors, "AUTH_TRUSTED_PROXIES is required with AUTH_PROXY_USER_HEADER")
+	}
+
+	// Validate rate limits and quotas
+	if config.RateLimit.Enabled && (config.RateLimit.RequestsPerSecond <= 0 || config.RateLimit.RecommendPerMinute <= 0 ||
+		config.RateLimit.AddressPerSecond <= 0) {
+		errors = append(errors, "RATE_LIMIT_RPS, RATE_LIMIT_RECOMMEND_PER_MINUTE and RATE_LIMIT_ADDRESS_RPS must be positive")
+	}
+	if config.LLMQuota.DailyTokens < 0 || config.LLMQuota.MonthlyTokens < 0 ||
+		config.LLMQuota.DailyCost < 0 || config.LLMQuota.MonthlyCost < 0 {
+		errors = append(errors, "LLM_QUOTA_* limits cannot be negative")
+	}
+	if config.OpenAI.InputPrice < 0 || config.OpenAI.OutputPrice < 0 {
+		errors = append(errors, "OPENAI_INPUT_PRICE and OPENAI_OUTPUT_PRICE cannot be negative")
+	}
+
+	// Validate tracing
+	switch config.Tracing.Exporter {
+	case "", "none", "stdout", "otlp":
+	default:
+		errors = append(errors, "TRACING_EXPORTER must be none, stdout or otlp")
+	}
+
+	// Validate logging
+	if config.Logging.Format != "json" && config.Logging.Format != "text" {
+		errors = append(errors, "LOG_FORMAT must be json or text")
+	}
+	if _, err := logging.ParseLevel(config.Logging.Level); err != nil {
+		errors = append(errors, fmt.Sprintf("LOG_LEVEL: %v", err))
+	}
+	if _, err := logging.ParseLevels(config.Logging.Levels); err != nil {
+		errors = append(errors, fmt.Sprintf("LOG_LEVELS: %v", err))
+	}
+
+	// Validate server timeouts; 0 means no timeout except for the shutdown
+	timeouts := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"SERVER_READ_HEADER_TIMEOUT", config.Server.ReadHeaderTimeout},
+		{"SERVER_READ_TIMEOUT", config.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", config.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", config.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_DELAY", config.Server.ShutdownDelay},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value < 0 {
+			errors = append(errors, timeout.name+" cannot be negative")
+		}
+	}
+	if config.Server.ShutdownTimeout <= 0 {
+		errors = append(errors, "SERVER_SHUTDOWN_TIMEOUT must be positive")
+	} else if config.Server.WriteTimeout > 0 && config.Server.ShutdownTimeout < config.Server.WriteTimeout {
+		errors = append(errors, "SERVER_SHUTDOWN_TIMEOUT must be at least SERVER_WRITE_TIMEOUT so requests in flight can finish")
+	}
+
+	// Validate backups
+	if config.Backup.Interval < 0 {
+		errors = append(errors, "BACKUP_INTERVAL cannot be negative")
+	}
+	if config.Backup.Retain < 0 {
+		errors = append(errors, "BACKUP_RETAIN cannot be negative")
+	}
+
+	// Validate notifications; targets are checked when the dispatcher is created
+	if config.Notifications.Retries < 0 || config.Notifications.RetryDelay < 0 || config.Notifications.RetryMaxDelay < 0 {
+		errors = append(errors, "NOTIFY_RETRIES, NOTIFY_RETRY_DELAY and NOTIFY_RETRY_MAX_DELAY cannot be negative")
+	}
+	if config.Notifications.Timeout <= 0 {
+		errors = append(errors, "NOTIFY_TIMEOUT must be positive")
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("validation errors: %s", strings.Join(errors, ", "))
 	}
@@ -3,62 +3,95 @@ package config
 import (
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"gocommender/internal/dialect"
+	"gocommender/internal/migrations"
+
+	_ "github.com/lib/pq"
 	_ "modernc.org/sqlite"
 )
 
-// InitDatabase initializes the SQLite database with schema
+// SQLite connection defaults, used when DatabaseConfig leaves them empty
+const (
+	DefaultJournalMode = "wal"
+	DefaultBusyTimeout = 5 * time.Second
+)
+
+// InitDatabase opens the SQLite database and applies pending schema migrations
 func InitDatabase(dbPath string) (*sql.DB, error) {
-	// Ensure directory exists
-	dir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	return ConnectDatabase(DatabaseConfig{Driver: string(dialect.SQLite), Path: dbPath})
+}
+
+// ConnectDatabase opens the configured database and applies pending schema migrations
+func ConnectDatabase(cfg DatabaseConfig) (*sql.DB, error) {
+	db, err := OpenDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := migrations.Up(db, cfg.Dialect()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return db, nil
+}
+
+// OpenDatabase opens the configured database without touching its schema
+func OpenDatabase(cfg DatabaseConfig) (*sql.DB, error) {
+	d := cfg.Dialect()
+
+	dsn := cfg.URL
+	if d == dialect.SQLite {
+		// Ensure directory exists
+		if err := os.MkdirAll(filepath.Dir(cfg.Path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+		dsn = sqliteDSN(cfg)
 	}
 
 	// Open database
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open(d.DriverName(), dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Test connection
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	// Create schema
-	if err := createSchema(db); err != nil {
-		return nil, fmt.Errorf("failed to create schema: %w", err)
-	}
-
 	return db, nil
 }
 
-func createSchema(db *sql.DB) error {
-	schema := `
-CREATE TABLE IF NOT EXISTS artists (
-    mbid TEXT PRIMARY KEY,                    -- MusicBrainz ID
-    name TEXT NOT NULL,
-    verified_json TEXT DEFAULT '{}',         -- JSON: {"discogs": true, "musicbrainz": true, "lastfm": false}
-    album_count INTEGER DEFAULT 0,
-    years_active TEXT DEFAULT '',
-    description TEXT DEFAULT '',
-    genres_json TEXT DEFAULT '[]',           -- JSON array: ["rock", "alternative"]
-    country TEXT DEFAULT '',
-    image_url TEXT DEFAULT '',
-    external_urls_json TEXT DEFAULT '{}',    -- JSON: {"discogs": "url", "musicbrainz": "url"}
-    last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
-    cache_expiry DATETIME NOT NULL
-);
-
--- Indexes for performance
-CREATE INDEX IF NOT EXISTS idx_cache_expiry ON artists(cache_expiry);
-CREATE INDEX IF NOT EXISTS idx_last_updated ON artists(last_updated);
-CREATE INDEX IF NOT EXISTS idx_name ON artists(name);
-CREATE INDEX IF NOT EXISTS idx_verified ON artists(verified_json);
-`
-	_, err := db.Exec(schema)
-	return err
+// sqliteDSN adds the connection pragmas to the database path. The driver runs them on
+// every new connection, since busy_timeout and synchronous are per connection.
+func sqliteDSN(cfg DatabaseConfig) string {
+	journalMode := strings.ToLower(cfg.JournalMode)
+	if journalMode == "" {
+		journalMode = DefaultJournalMode
+	}
+	busyTimeout := cfg.BusyTimeout
+	if busyTimeout == 0 {
+		busyTimeout = DefaultBusyTimeout
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()))
+	params.Add("_pragma", fmt.Sprintf("journal_mode(%s)", journalMode))
+	if journalMode == "wal" {
+		// WAL is safe against corruption with NORMAL; only the last commits can be lost on power failure
+		params.Add("_pragma", "synchronous(normal)")
+	}
+	// Take the write lock when a transaction starts. A deferred transaction that reads first
+	// cannot wait for the lock when it upgrades and fails with SQLITE_BUSY right away.
+	params.Set("_txlock", "immediate")
+
+	return cfg.Path + "?" + params.Encode()
 }
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gocommender/internal/models"
+)
+
+// AliasDB handles database operations for artist name aliases
+type AliasDB struct {
+	db conn
+}
+
+// NewAliasDB creates a new AliasDB instance
+func NewAliasDB(db *sql.DB) *AliasDB {
+	return &AliasDB{db: sqliteConn(db)}
+}
+
+// SaveAliases replaces the aliases of an artist from one source
+func (adb *AliasDB) SaveAliases(artistMBID, source string, aliases []models.ArtistAlias) error {
+	tx, err := adb.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM artist_aliases WHERE artist_mbid = ? AND source = ?", artistMBID, source); err != nil {
+		return fmt.Errorf("failed to clear aliases: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+INSERT INTO artist_aliases (artist_mbid, alias, source)
+VALUES (?, ?, ?)
+ON CONFLICT(artist_mbid, alias) DO NOTHING
+`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, alias := range aliases {
+		name := strings.TrimSpace(alias.Name)
+		if name == "" {
+			continue
+		}
+
+		if _, err := stmt.Exec(artistMBID, name, source); err != nil {
+			return fmt.Errorf("failed to save alias %s: %w", name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAliases returns all stored aliases of an artist
+func (adb *AliasDB) GetAliases(artistMBID string) ([]models.ArtistAlias, error) {
+	rows, err := adb.db.Query(`
+SELECT alias, source
+FROM artist_aliases
+WHERE artist_mbid = ?
+ORDER BY alias ASC
+`, artistMBID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := make([]models.ArtistAlias, 0)
+	for rows.Next() {
+		var alias models.ArtistAlias
+		if err := rows.Scan(&alias.Name, &alias.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan alias: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, rows.Err()
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"testing"
+
+	"gocommender/internal/models"
+)
+
+func TestCacheManager_AliasesAndCredits(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	cm := NewCacheManager(db)
+	config := DefaultCacheConfig()
+
+	artist := &models.Artist{
+		MBID:    "slowdive-mbid",
+		Name:    "Slowdive",
+		Members: models.ArtistLinks{{Name: "Rachel Goswell", DiscogsID: 101, Active: true}},
+		Labels:  models.LabelAffiliations{{Name: "Creation Records", Releases: 3}},
+		Aliases: []models.ArtistAlias{
+			{Name: "Slow Dive", Source: models.AliasSourceDiscogs},
+			{Name: " ", Source: models.AliasSourceDiscogs},
+		},
+	}
+	if err := cm.CacheArtist(artist, config); err != nil {
+		t.Fatalf("Failed to cache artist: %v", err)
+	}
+
+	// An artist whose real name collides with the alias wins over the alias match
+	namesake := &models.Artist{MBID: "namesake-mbid", Name: "Slow Dive"}
+	other := &models.Artist{MBID: "other-mbid", Name: "Other", Listeners: 1000}
+	if err := cm.BulkCacheArtists([]models.Artist{*namesake, *other}, config); err != nil {
+		t.Fatalf("Failed to cache artists: %v", err)
+	}
+
+	aliases, err := cm.GetAliases("slowdive-mbid")
+	if err != nil {
+		t.Fatalf("Failed to get aliases: %v", err)
+	}
+	if len(aliases) != 1 || aliases[0].Name != "Slow Dive" {
+		t.Errorf("Expected blank aliases to be skipped, got %+v", aliases)
+	}
+
+	found, err := cm.FindArtistByName("slow dive")
+	if err != nil {
+		t.Fatalf("FindArtistByName failed: %v", err)
+	}
+	if found == nil || found.MBID != "namesake-mbid" {
+		t.Errorf("Expected exact name match to win, got %+v", found)
+	}
+
+	// Removing the namesake makes the alias resolve
+	if _, err := db.Exec("DELETE FROM artists WHERE mbid = 'namesake-mbid'"); err != nil {
+		t.Fatalf("Failed to delete namesake: %v", err)
+	}
+	found, err = cm.FindArtistByName("SLOW DIVE")
+	if err != nil {
+		t.Fatalf("FindArtistByName failed: %v", err)
+	}
+	if found == nil || found.MBID != "slowdive-mbid" {
+		t.Fatalf("Expected alias lookup to find Slowdive, got %+v", found)
+	}
+
+	if len(found.Members) != 1 || !found.Members[0].Active || found.Members[0].DiscogsID != 101 {
+		t.Errorf("Expected members to round-trip, got %+v", found.Members)
+	}
+	if len(found.Labels) != 1 || found.Labels[0].Releases != 3 {
+		t.Errorf("Expected labels to round-trip, got %+v", found.Labels)
+	}
+	if found.Groups == nil || len(found.Groups) != 0 {
+		t.Errorf("Expected empty groups, got %+v", found.Groups)
+	}
+}
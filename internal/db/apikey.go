@@ -0,0 +1,165 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+// APIKeyPrefix starts every generated key, so leaked keys are easy to search for
+const APIKeyPrefix = "gck_"
+
+// apiKeyPrefixLength is how much of a key is stored in the clear to identify it
+const apiKeyPrefixLength = len(APIKeyPrefix) + 6
+
+// APIKeyDB handles database operations for API keys
+type APIKeyDB struct {
+	db conn
+}
+
+// NewAPIKeyDB creates a new APIKeyDB instance
+func NewAPIKeyDB(db *sql.DB) *APIKeyDB {
+	return &APIKeyDB{db: sqliteConn(db)}
+}
+
+// NewAPIKey generates a random key and the record to store for it.
+// The key is returned only here; the record keeps its hash.
+func NewAPIKey(name string, role models.Role) (string, *models.APIKey, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return key, &models.APIKey{
+		Name:      name,
+		Prefix:    key[:apiKeyPrefixLength],
+		Hash:      HashAPIKey(key),
+		Role:      role,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// HashAPIKey returns the stored form of a key. Keys are random, so a plain SHA-256 is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// SaveAPIKey stores a new key and sets its ID
+func (kdb *APIKeyDB) SaveAPIKey(key *models.APIKey) error {
+	query := `
+INSERT INTO api_keys (name, prefix, key_hash, role, created_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING id
+`
+
+	err := kdb.db.QueryRow(query, key.Name, key.Prefix, key.Hash, key.Role, key.CreatedAt).Scan(&key.ID)
+	if err != nil {
+		return fmt.Errorf("failed to save API key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKeyByHash returns the key with the given hash, or nil if there is none.
+// Revoked keys are returned too; callers check IsActive.
+func (kdb *APIKeyDB) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	query := `
+SELECT id, name, prefix, key_hash, role, created_at, last_used_at, revoked_at
+FROM api_keys
+WHERE key_hash = ?
+`
+
+	key, err := scanAPIKey(kdb.db.QueryRow(query, hash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns all keys, oldest first
+func (kdb *APIKeyDB) ListAPIKeys() ([]models.APIKey, error) {
+	query := `
+SELECT id, name, prefix, key_hash, role, created_at, last_used_at, revoked_at
+FROM api_keys
+ORDER BY id
+`
+
+	rows, err := kdb.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revokes a key. Returns false if no active key has the ID.
+func (kdb *APIKeyDB) RevokeAPIKey(id int64) (bool, error) {
+	count, err := kdb.db.ExecCount(
+		"UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL",
+		time.Now(), id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	return count > 0, nil
+}
+
+// TouchAPIKey records that a key was used. To avoid a write per request the
+// timestamp is only moved when it is older than the given interval.
+func (kdb *APIKeyDB) TouchAPIKey(id int64, usedAt time.Time, interval time.Duration) error {
+	_, err := kdb.db.Exec(
+		"UPDATE api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)",
+		usedAt, id, usedAt.Add(-interval),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update API key usage: %w", err)
+	}
+	return nil
+}
+
+// scanAPIKey reads a row selected with the api_keys column list used above
+func scanAPIKey(row interface{ Scan(...any) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	var lastUsed, revoked sql.NullTime
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&key.Role,
+		&key.CreatedAt,
+		&lastUsed,
+		&revoked,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastUsed.Valid {
+		key.LastUsedAt = &lastUsed.Time
+	}
+	if revoked.Valid {
+		key.RevokedAt = &revoked.Time
+	}
+	return &key, nil
+}
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+func TestAPIKeyDB(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	keys := NewAPIKeyDB(database)
+
+	key, record, err := NewAPIKey("ci", models.RoleRecommend)
+	if err != nil {
+		t.Fatalf("NewAPIKey failed: %v", err)
+	}
+	if !strings.HasPrefix(key, APIKeyPrefix) || !strings.HasPrefix(key, record.Prefix) {
+		t.Errorf("Expected key %q to start with %q and its prefix %q", key, APIKeyPrefix, record.Prefix)
+	}
+	if strings.Contains(record.Hash, key) || record.Hash != HashAPIKey(key) {
+		t.Errorf("Expected only the hash of the key to be kept, got %q", record.Hash)
+	}
+
+	if err := keys.SaveAPIKey(record); err != nil {
+		t.Fatalf("SaveAPIKey failed: %v", err)
+	}
+	if record.ID == 0 {
+		t.Error("Expected SaveAPIKey to set the ID")
+	}
+
+	stored, err := keys.GetAPIKeyByHash(HashAPIKey(key))
+	if err != nil || stored == nil {
+		t.Fatalf("GetAPIKeyByHash failed: %v, %v", stored, err)
+	}
+	if stored.Name != "ci" || stored.Role != models.RoleRecommend || !stored.IsActive() || stored.LastUsedAt != nil {
+		t.Errorf("Key did not round-trip: %+v", stored)
+	}
+	if missing, err := keys.GetAPIKeyByHash(HashAPIKey("gck_unknown")); err != nil || missing != nil {
+		t.Errorf("Expected nil for an unknown key, got %v, %v", missing, err)
+	}
+
+	// Usage is recorded at most once per interval
+	first := time.Now().Add(-time.Hour)
+	if err := keys.TouchAPIKey(record.ID, first, time.Minute); err != nil {
+		t.Fatalf("TouchAPIKey failed: %v", err)
+	}
+	if err := keys.TouchAPIKey(record.ID, first.Add(time.Second), time.Minute); err != nil {
+		t.Fatalf("TouchAPIKey failed: %v", err)
+	}
+	stored, _ = keys.GetAPIKeyByHash(record.Hash)
+	if stored.LastUsedAt == nil || !stored.LastUsedAt.Equal(first) {
+		t.Errorf("Expected last use at %v, got %v", first, stored.LastUsedAt)
+	}
+
+	revoked, err := keys.RevokeAPIKey(record.ID)
+	if err != nil || !revoked {
+		t.Fatalf("RevokeAPIKey failed: %v, %v", revoked, err)
+	}
+	if again, _ := keys.RevokeAPIKey(record.ID); again {
+		t.Error("Expected a revoked key not to be revoked twice")
+	}
+
+	listed, err := keys.ListAPIKeys()
+	if err != nil || len(listed) != 1 {
+		t.Fatalf("ListAPIKeys failed: %v, %v", listed, err)
+	}
+	if listed[0].IsActive() {
+		t.Error("Expected listed key to be revoked")
+	}
+}
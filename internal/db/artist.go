@@ -3,29 +3,20 @@ package db
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"gocommender/internal/models"
 )
 
-// ArtistDB handles database operations for Artist entities
-type ArtistDB struct {
-	db *sql.DB
-}
-
-// NewArtistDB creates a new ArtistDB instance
-func NewArtistDB(db *sql.DB) *ArtistDB {
-	return &ArtistDB{db: db}
-}
-
-// SaveArtist saves or updates an artist in the database
-func (adb *ArtistDB) SaveArtist(artist *models.Artist) error {
-	query := `
+// upsertArtistQuery inserts an artist or replaces all its columns
+const upsertArtistQuery = `
 INSERT INTO artists (
     mbid, name, verified_json, album_count, years_active, 
     description, genres_json, country, image_url, 
-    external_urls_json, last_updated, cache_expiry
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    external_urls_json, listeners, playcount, members_json, groups_json,
+    labels_json, last_updated, cache_expiry
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(mbid) DO UPDATE SET
     name = excluded.name,
     verified_json = excluded.verified_json,
@@ -36,11 +27,29 @@ ON CONFLICT(mbid) DO UPDATE SET
     country = excluded.country,
     image_url = excluded.image_url,
     external_urls_json = excluded.external_urls_json,
+    listeners = excluded.listeners,
+    playcount = excluded.playcount,
+    members_json = excluded.members_json,
+    groups_json = excluded.groups_json,
+    labels_json = excluded.labels_json,
     last_updated = excluded.last_updated,
     cache_expiry = excluded.cache_expiry
 `
 
-	_, err := adb.db.Exec(query,
+// ArtistDB handles database operations for Artist entities
+type ArtistDB struct {
+	db conn
+}
+
+// NewArtistDB creates a new ArtistDB instance
+func NewArtistDB(db *sql.DB) *ArtistDB {
+	return &ArtistDB{db: sqliteConn(db)}
+}
+
+// SaveArtist saves or updates an artist in the database
+func (adb *ArtistDB) SaveArtist(artist *models.Artist) error {
+
+	_, err := adb.db.Exec(upsertArtistQuery,
 		artist.MBID,
 		artist.Name,
 		artist.Verified,
@@ -51,6 +60,11 @@ ON CONFLICT(mbid) DO UPDATE SET
 		artist.Country,
 		artist.ImageURL,
 		artist.ExternalURLs,
+		artist.Listeners,
+		artist.Playcount,
+		artist.Members,
+		artist.Groups,
+		artist.Labels,
 		artist.LastUpdated,
 		artist.CacheExpiry,
 	)
@@ -58,12 +72,55 @@ ON CONFLICT(mbid) DO UPDATE SET
 	return err
 }
 
+// SaveArtists saves or updates several artists in one transaction
+func (adb *ArtistDB) SaveArtists(artists []models.Artist) error {
+	tx, err := adb.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(upsertArtistQuery)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, artist := range artists {
+		_, err := stmt.Exec(
+			artist.MBID,
+			artist.Name,
+			artist.Verified,
+			artist.AlbumCount,
+			artist.YearsActive,
+			artist.Description,
+			artist.Genres,
+			artist.Country,
+			artist.ImageURL,
+			artist.ExternalURLs,
+			artist.Listeners,
+			artist.Playcount,
+			artist.Members,
+			artist.Groups,
+			artist.Labels,
+			artist.LastUpdated,
+			artist.CacheExpiry,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to execute statement for artist %s: %w", artist.MBID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // GetArtist retrieves an artist by MBID
 func (adb *ArtistDB) GetArtist(mbid string) (*models.Artist, error) {
 	query := `
 SELECT mbid, name, verified_json, album_count, years_active,
        description, genres_json, country, image_url, 
-       external_urls_json, last_updated, cache_expiry
+       external_urls_json, listeners, playcount, members_json, groups_json,
+       labels_json, last_updated, cache_expiry
 FROM artists 
 WHERE mbid = ?
 `
@@ -80,6 +137,11 @@ WHERE mbid = ?
 		&artist.Country,
 		&artist.ImageURL,
 		&artist.ExternalURLs,
+		&artist.Listeners,
+		&artist.Playcount,
+		&artist.Members,
+		&artist.Groups,
+		&artist.Labels,
 		&artist.LastUpdated,
 		&artist.CacheExpiry,
 	)
@@ -94,6 +156,53 @@ WHERE mbid = ?
 	return &artist, nil
 }
 
+// GetArtistByName retrieves an artist by case-insensitive name or alias.
+// Exact name matches win over aliases; when several artists share a name the most popular one is returned.
+func (adb *ArtistDB) GetArtistByName(name string) (*models.Artist, error) {
+	nameMatches := adb.db.dialect.EqualFold("name")
+	query := `
+SELECT mbid, name, verified_json, album_count, years_active,
+       description, genres_json, country, image_url, 
+       external_urls_json, listeners, playcount, members_json, groups_json,
+       labels_json, last_updated, cache_expiry
+FROM artists 
+WHERE ` + nameMatches + `
+   OR mbid IN (SELECT artist_mbid FROM artist_aliases WHERE ` + adb.db.dialect.EqualFold("alias") + `)
+ORDER BY ` + nameMatches + ` DESC, listeners DESC
+LIMIT 1
+`
+
+	var artist models.Artist
+	err := adb.db.QueryRow(query, name, name, name).Scan(
+		&artist.MBID,
+		&artist.Name,
+		&artist.Verified,
+		&artist.AlbumCount,
+		&artist.YearsActive,
+		&artist.Description,
+		&artist.Genres,
+		&artist.Country,
+		&artist.ImageURL,
+		&artist.ExternalURLs,
+		&artist.Listeners,
+		&artist.Playcount,
+		&artist.Members,
+		&artist.Groups,
+		&artist.Labels,
+		&artist.LastUpdated,
+		&artist.CacheExpiry,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Artist not found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artist by name: %w", err)
+	}
+
+	return &artist, nil
+}
+
 // IsExpired checks if an artist's cache has expired
 func (adb *ArtistDB) IsExpired(mbid string) (bool, error) {
 	query := "SELECT cache_expiry FROM artists WHERE mbid = ?"
@@ -115,7 +224,8 @@ func (adb *ArtistDB) GetExpiredArtists(limit int) ([]models.Artist, error) {
 	query := `
 SELECT mbid, name, verified_json, album_count, years_active,
        description, genres_json, country, image_url, 
-       external_urls_json, last_updated, cache_expiry
+       external_urls_json, listeners, playcount, members_json, groups_json,
+       labels_json, last_updated, cache_expiry
 FROM artists 
 WHERE cache_expiry < ? 
 ORDER BY cache_expiry ASC
@@ -142,6 +252,73 @@ LIMIT ?
 			&artist.Country,
 			&artist.ImageURL,
 			&artist.ExternalURLs,
+			&artist.Listeners,
+			&artist.Playcount,
+			&artist.Members,
+			&artist.Groups,
+			&artist.Labels,
+			&artist.LastUpdated,
+			&artist.CacheExpiry,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan artist: %w", err)
+		}
+		artists = append(artists, artist)
+	}
+
+	return artists, rows.Err()
+}
+
+// PopularityFilter selects artists by Last.fm listener count
+type PopularityFilter struct {
+	MinListeners int64 // Inclusive lower bound
+	MaxListeners int64 // Inclusive upper bound, 0 means unbounded
+	Limit        int
+	Offset       int
+}
+
+// GetArtistsByPopularity returns artists within a listener range, most popular first
+func (adb *ArtistDB) GetArtistsByPopularity(filter PopularityFilter) ([]models.Artist, error) {
+	query := `
+SELECT mbid, name, verified_json, album_count, years_active,
+       description, genres_json, country, image_url, 
+       external_urls_json, listeners, playcount, members_json, groups_json,
+       labels_json, last_updated, cache_expiry
+FROM artists 
+WHERE listeners >= ? AND (? = 0 OR listeners <= ?)
+ORDER BY listeners DESC, name ASC
+LIMIT ? OFFSET ?
+`
+
+	rows, err := adb.db.Query(query,
+		filter.MinListeners,
+		filter.MaxListeners, filter.MaxListeners,
+		filter.Limit, filter.Offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artists by popularity: %w", err)
+	}
+	defer rows.Close()
+
+	artists := make([]models.Artist, 0)
+	for rows.Next() {
+		var artist models.Artist
+		err := rows.Scan(
+			&artist.MBID,
+			&artist.Name,
+			&artist.Verified,
+			&artist.AlbumCount,
+			&artist.YearsActive,
+			&artist.Description,
+			&artist.Genres,
+			&artist.Country,
+			&artist.ImageURL,
+			&artist.ExternalURLs,
+			&artist.Listeners,
+			&artist.Playcount,
+			&artist.Members,
+			&artist.Groups,
+			&artist.Labels,
 			&artist.LastUpdated,
 			&artist.CacheExpiry,
 		)
@@ -161,6 +338,85 @@ func (adb *ArtistDB) DeleteArtist(mbid string) error {
 	return err
 }
 
+// DeleteExpiredBefore removes artists whose cache expired before cutoff and returns how many were removed
+func (adb *ArtistDB) DeleteExpiredBefore(cutoff time.Time) (int, error) {
+	result, err := adb.db.Exec("DELETE FROM artists WHERE cache_expiry < ?", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup expired entries: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// DeleteAllArtists removes every cached artist with its releases, similarity edges and
+// aliases in one transaction, so a failure leaves no orphaned rows behind
+func (adb *ArtistDB) DeleteAllArtists() (ClearResult, error) {
+	var result ClearResult
+	tx, err := adb.db.Begin()
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deletes := []struct {
+		table string
+		count *int
+	}{
+		{"artist_aliases", &result.Aliases},
+		{"release_groups", &result.Releases},
+		{"artist_similarity", &result.Similar},
+		{"artists", &result.Artists},
+	}
+	for _, d := range deletes {
+		if *d.count, err = tx.ExecCount("DELETE FROM " + d.table); err != nil {
+			return ClearResult{}, fmt.Errorf("failed to delete %s: %w", d.table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ClearResult{}, fmt.Errorf("failed to commit: %w", err)
+	}
+	return result, nil
+}
+
+// ExpireArtists sets the cache expiry of the artists matching the filter and returns their MBIDs
+func (adb *ArtistDB) ExpireArtists(filter InvalidationFilter, expiry time.Time) ([]string, error) {
+	where, args := filter.conditions(adb.db.dialect)
+	if len(where) == 0 {
+		return nil, ErrEmptyInvalidation
+	}
+
+	query := "UPDATE artists SET cache_expiry = ? WHERE " + strings.Join(where, " AND ") + " RETURNING mbid"
+	rows, err := adb.db.Query(query, append([]any{expiry}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire artists: %w", err)
+	}
+	defer rows.Close()
+
+	mbids := make([]string, 0)
+	for rows.Next() {
+		var mbid string
+		if err := rows.Scan(&mbid); err != nil {
+			return nil, fmt.Errorf("failed to scan expired artist: %w", err)
+		}
+		mbids = append(mbids, mbid)
+	}
+
+	return mbids, rows.Err()
+}
+
+// UpdateCacheExpiry updates the cache expiry for an artist without changing other data
+func (adb *ArtistDB) UpdateCacheExpiry(mbid string, expiry time.Time) error {
+	query := "UPDATE artists SET cache_expiry = ?, last_updated = ? WHERE mbid = ?"
+	_, err := adb.db.Exec(query, expiry, time.Now(), mbid)
+	return err
+}
+
 // GetArtistCount returns the total number of artists in the database
 func (adb *ArtistDB) GetArtistCount() (int, error) {
 	query := "SELECT COUNT(*) FROM artists"
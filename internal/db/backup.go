@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"gocommender/internal/dialect"
+	"gocommender/internal/logging"
+)
+
+// ErrBackupUnsupported is returned when backing up a database other than SQLite
+var ErrBackupUnsupported = errors.New("online backups are only supported for SQLite, use pg_dump for PostgreSQL")
+
+// backupFileName matches the files written by Backup, oldest sorts first
+var backupFileName = regexp.MustCompile(`^gocommender-\d{8}-\d{6}\.\d{3}\.db$`)
+
+// BackupConfig defines where backups are written and how many are kept
+type BackupConfig struct {
+	Dir      string        // Directory for backup files
+	Interval time.Duration // Time between scheduled backups
+	Retain   int           // Number of backups to keep, 0 keeps all
+}
+
+// BackupResult describes a finished backup
+type BackupResult struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	CreatedAt  time.Time `json:"created_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Removed    []string  `json:"removed,omitempty"` // Old backups deleted by retention
+}
+
+var backupLog = logging.For("backup")
+
+// BackupService writes consistent snapshots of a live SQLite database
+type BackupService struct {
+	db     conn
+	config BackupConfig
+
+	// Only one backup runs at a time
+	backupMu sync.Mutex
+
+	// Internal state
+	running bool
+	stopCh  chan struct{}
+	mu      sync.RWMutex
+}
+
+// NewBackupService creates a backup service for a database of the given dialect
+func NewBackupService(db *sql.DB, d dialect.Dialect, config BackupConfig) *BackupService {
+	return &BackupService{
+		db:     conn{db: db, dialect: d},
+		config: config,
+	}
+}
+
+// Start runs scheduled backups until the context is cancelled or Stop is called
+func (bs *BackupService) Start(ctx context.Context) error {
+	bs.mu.Lock()
+	if bs.running {
+		bs.mu.Unlock()
+		return fmt.Errorf("backup service is already running")
+	}
+	if bs.config.Interval <= 0 {
+		bs.mu.Unlock()
+		return fmt.Errorf("backup interval must be positive")
+	}
+	bs.running = true
+	stopCh := make(chan struct{})
+	bs.stopCh = stopCh
+	bs.mu.Unlock()
+
+	defer func() {
+		bs.mu.Lock()
+		bs.running = false
+		bs.stopCh = nil
+		bs.mu.Unlock()
+	}()
+
+	ticker := time.NewTicker(bs.config.Interval)
+	defer ticker.Stop()
+
+	backupLog.Info("Backup service started",
+		"interval", bs.config.Interval, "dir", bs.config.Dir, "retain", bs.config.Retain)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-stopCh:
+			return nil
+
+		case <-ticker.C:
+			result, err := bs.Backup()
+			if err != nil {
+				backupLog.Error("Scheduled backup failed", "error", err)
+				continue
+			}
+			backupLog.Info("Backed up database",
+				"path", result.Path, "bytes", result.Size, "removed", len(result.Removed))
+		}
+	}
+}
+
+// Stop stops scheduled backups after the one in progress; calling it more than
+// once or while the service is not running does nothing
+func (bs *BackupService) Stop() {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if bs.stopCh != nil {
+		close(bs.stopCh)
+		bs.stopCh = nil
+	}
+}
+
+// Backup writes a snapshot with VACUUM INTO and then applies retention.
+// Readers and writers keep working while it runs; the snapshot is a single read transaction.
+func (bs *BackupService) Backup() (*BackupResult, error) {
+	if bs.db.dialect != dialect.SQLite {
+		return nil, ErrBackupUnsupported
+	}
+
+	bs.backupMu.Lock()
+	defer bs.backupMu.Unlock()
+
+	if err := os.MkdirAll(bs.config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	start := time.Now()
+	name := "gocommender-" + start.UTC().Format("20060102-150405.000") + ".db"
+	path := filepath.Join(bs.config.Dir, name)
+
+	// Write to a temporary name so an interrupted backup never looks complete
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	if _, err := bs.db.Exec("VACUUM INTO ?", tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to back up database: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("failed to finish backup: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat backup: %w", err)
+	}
+
+	removed, err := bs.prune()
+	if err != nil {
+		return nil, err
+	}
+
+	return &BackupResult{
+		Path:       path,
+		Size:       info.Size(),
+		CreatedAt:  start.UTC(),
+		DurationMs: time.Since(start).Milliseconds(),
+		Removed:    removed,
+	}, nil
+}
+
+// prune deletes the oldest backups beyond the retention count
+func (bs *BackupService) prune() ([]string, error) {
+	if bs.config.Retain <= 0 {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(bs.config.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() && backupFileName.MatchString(entry.Name()) {
+			backups = append(backups, entry.Name())
+		}
+	}
+	if len(backups) <= bs.config.Retain {
+		return nil, nil
+	}
+
+	sort.Strings(backups)
+	var removed []string
+	for _, name := range backups[:len(backups)-bs.config.Retain] {
+		path := filepath.Join(bs.config.Dir, name)
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove old backup %s: %w", name, err)
+		}
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gocommender/internal/dialect"
+	"gocommender/internal/models"
+)
+
+func TestBackupServiceBackupAndRetention(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	cm := NewCacheManager(database)
+	artist := &models.Artist{MBID: "slowdive-mbid", Name: "Slowdive"}
+	if err := cm.CacheArtist(artist, DefaultCacheConfig()); err != nil {
+		t.Fatalf("CacheArtist failed: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "backups")
+	service := NewBackupService(database, dialect.SQLite, BackupConfig{Dir: dir, Retain: 2})
+
+	var results []*BackupResult
+	for i := 0; i < 3; i++ {
+		result, err := service.Backup()
+		if err != nil {
+			t.Fatalf("Backup %d failed: %v", i, err)
+		}
+		results = append(results, result)
+		time.Sleep(2 * time.Millisecond) // Backup names have millisecond resolution
+	}
+
+	if len(results[2].Removed) != 1 || results[2].Removed[0] != results[0].Path {
+		t.Errorf("Expected the oldest backup to be removed, got %v", results[2].Removed)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 backups to be kept, got %d", len(entries))
+	}
+
+	// The snapshot is a complete database
+	restored, err := sql.Open("sqlite", results[2].Path)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+	defer restored.Close()
+
+	cached, err := NewArtistDB(restored).GetArtist("slowdive-mbid")
+	if err != nil || cached == nil || cached.Name != "Slowdive" {
+		t.Errorf("Expected artist in backup, got %v, %v", cached, err)
+	}
+}
+
+func TestBackupServiceRejectsPostgres(t *testing.T) {
+	service := NewBackupService(nil, dialect.Postgres, BackupConfig{Dir: t.TempDir()})
+
+	if _, err := service.Backup(); !errors.Is(err, ErrBackupUnsupported) {
+		t.Errorf("Expected ErrBackupUnsupported, got %v", err)
+	}
+}
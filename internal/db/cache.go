@@ -5,28 +5,56 @@ import (
 	"fmt"
 	"time"
 
+	"gocommender/internal/dialect"
+	"gocommender/internal/metrics"
 	"gocommender/internal/models"
 )
 
+// artistCacheLookups counts artist cache reads by lookup kind (mbid or name) and result
+var artistCacheLookups = metrics.NewCounter("gocommender_artist_cache_lookups_total",
+	"Artist cache lookups by kind (mbid or name) and result (hit, miss or expired).", "lookup", "result")
+
 // CacheManager provides high-level caching operations with TTL management
 type CacheManager struct {
-	artistDB *ArtistDB
-	db       *sql.DB
+	artistDB     ArtistRepository
+	releaseDB    ReleaseRepository
+	similarityDB SimilarityRepository
+	aliasDB      AliasRepository
+	responseDB   ResponseRepository
+
+	// TTL policy used by callers without their own, see TTLPolicy
+	policy CacheConfig
 }
 
-// NewCacheManager creates a new cache manager
+// NewCacheManager creates a new cache manager for a SQLite database
 func NewCacheManager(db *sql.DB) *CacheManager {
+	return NewCacheManagerWithStore(NewStore(db, dialect.SQLite))
+}
+
+// NewCacheManagerWithStore creates a new cache manager on top of any storage backend
+func NewCacheManagerWithStore(store *Store) *CacheManager {
 	return &CacheManager{
-		artistDB: NewArtistDB(db),
-		db:       db,
+		artistDB:     store.Artists,
+		releaseDB:    store.Releases,
+		similarityDB: store.Similarity,
+		aliasDB:      store.Aliases,
+		responseDB:   store.Responses,
+		policy:       DefaultCacheConfig(),
 	}
 }
 
-// CacheConfig defines TTL policies for different scenarios
+// CacheConfig defines TTL policies for different scenarios, see Expiry
 type CacheConfig struct {
 	VerifiedTTL   time.Duration // TTL for successfully verified artists
 	UnverifiedTTL time.Duration // TTL for failed verification attempts
 	RefreshTTL    time.Duration // TTL for background refresh
+
+	// SourceTTLs overrides VerifiedTTL per enrichment source. A verified artist expires
+	// with the shortest TTL of the sources that verified it.
+	SourceTTLs map[string]time.Duration
+	// Jitter spreads expiries randomly by up to this fraction of the TTL in either
+	// direction, so artists cached together are not all refreshed together
+	Jitter float64
 }
 
 // DefaultCacheConfig returns the default cache configuration
@@ -35,6 +63,11 @@ func DefaultCacheConfig() CacheConfig {
 		VerifiedTTL:   30 * 24 * time.Hour, // 30 days for verified artists
 		UnverifiedTTL: 7 * 24 * time.Hour,  // 7 days for failed lookups
 		RefreshTTL:    24 * time.Hour,      // 24 hours for background refresh
+		SourceTTLs: map[string]time.Duration{
+			"musicbrainz": 90 * 24 * time.Hour, // Core data rarely changes
+			"lastfm":      30 * 24 * time.Hour, // Listener counts and bios
+		},
+		Jitter: 0.1,
 	}
 }
 
@@ -47,6 +80,7 @@ func (cm *CacheManager) GetOrFetchArtist(mbid string) (*models.Artist, bool, err
 
 	// Artist not in cache
 	if artist == nil {
+		artistCacheLookups.Inc("mbid", "miss")
 		return nil, true, nil // needsFetch = true
 	}
 
@@ -57,9 +91,11 @@ func (cm *CacheManager) GetOrFetchArtist(mbid string) (*models.Artist, bool, err
 	}
 
 	if expired {
+		artistCacheLookups.Inc("mbid", "expired")
 		return artist, true, nil // Return cached data but indicate refresh needed
 	}
 
+	artistCacheLookups.Inc("mbid", "hit")
 	return artist, false, nil // Fresh cache hit
 }
 
@@ -71,55 +107,108 @@ func (cm *CacheManager) CacheArtist(artist *models.Artist, config CacheConfig) e
 
 	// Set cache expiry based on verification status
 	artist.LastUpdated = time.Now()
-	artist.CacheExpiry = cm.calculateExpiry(artist, config)
+	artist.CacheExpiry = config.Expiry(artist, artist.LastUpdated)
 
-	return cm.artistDB.SaveArtist(artist)
+	if err := cm.artistDB.SaveArtist(artist); err != nil {
+		return err
+	}
+
+	// Only replace the stored discography when enrichment produced one
+	if len(artist.Releases) > 0 {
+		if err := cm.releaseDB.SaveDiscography(artist.MBID, artist.Releases); err != nil {
+			return fmt.Errorf("failed to save discography: %w", err)
+		}
+	}
+
+	if err := cm.saveSimilarArtists(artist); err != nil {
+		return fmt.Errorf("failed to save similar artists: %w", err)
+	}
+
+	if err := cm.saveAliases(artist); err != nil {
+		return fmt.Errorf("failed to save aliases: %w", err)
+	}
+
+	return nil
 }
 
-// calculateExpiry determines the cache expiry time based on artist verification status
-func (cm *CacheManager) calculateExpiry(artist *models.Artist, config CacheConfig) time.Time {
-	now := time.Now()
+// saveSimilarArtists replaces the stored similarity edges per source present on the artist
+func (cm *CacheManager) saveSimilarArtists(artist *models.Artist) error {
+	bySource := make(map[string][]models.SimilarArtist)
+	for _, edge := range artist.Similar {
+		bySource[edge.Source] = append(bySource[edge.Source], edge)
+	}
 
-	// If artist has any successful verifications, use longer TTL
-	hasVerification := false
-	if artist.Verified != nil {
-		for _, verified := range artist.Verified {
-			if verified {
-				hasVerification = true
-				break
-			}
+	for source, edges := range bySource {
+		if err := cm.similarityDB.SaveSimilarArtists(artist.MBID, source, edges); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	if hasVerification {
-		return now.Add(config.VerifiedTTL)
+// saveAliases replaces the stored aliases per source present on the artist
+func (cm *CacheManager) saveAliases(artist *models.Artist) error {
+	bySource := make(map[string][]models.ArtistAlias)
+	for _, alias := range artist.Aliases {
+		bySource[alias.Source] = append(bySource[alias.Source], alias)
 	}
 
-	// No successful verifications, use shorter TTL
-	return now.Add(config.UnverifiedTTL)
+	for source, aliases := range bySource {
+		if err := cm.aliasDB.SaveAliases(artist.MBID, source, aliases); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// RefreshExpiredArtists gets a batch of expired artists for background refresh
-func (cm *CacheManager) RefreshExpiredArtists(limit int) ([]models.Artist, error) {
-	return cm.artistDB.GetExpiredArtists(limit)
+// GetAliases returns the stored name variations of an artist
+func (cm *CacheManager) GetAliases(mbid string) ([]models.ArtistAlias, error) {
+	return cm.aliasDB.GetAliases(mbid)
 }
 
-// CleanupExpiredEntries removes entries that have been expired for too long
-func (cm *CacheManager) CleanupExpiredEntries(maxAge time.Duration) (int, error) {
-	cutoff := time.Now().Add(-maxAge)
-	query := "DELETE FROM artists WHERE cache_expiry < ?"
-
-	result, err := cm.db.Exec(query, cutoff)
+// FindArtistByName returns a cached artist by name or alias, or nil if it is not cached
+func (cm *CacheManager) FindArtistByName(name string) (*models.Artist, error) {
+	artist, err := cm.artistDB.GetArtistByName(name)
 	if err != nil {
-		return 0, fmt.Errorf("failed to cleanup expired entries: %w", err)
+		return nil, err
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	if artist == nil {
+		artistCacheLookups.Inc("name", "miss")
+	} else {
+		artistCacheLookups.Inc("name", "hit")
 	}
+	return artist, nil
+}
+
+// GetSimilarArtists returns the stored similarity neighbours of an artist
+func (cm *CacheManager) GetSimilarArtists(mbid string, minMatch float64, limit int) ([]models.SimilarArtist, error) {
+	return cm.similarityDB.GetSimilarArtists(mbid, minMatch, limit)
+}
 
-	return int(rowsAffected), nil
+// GetArtistsByPopularity returns cached artists within a listener range
+func (cm *CacheManager) GetArtistsByPopularity(filter PopularityFilter) ([]models.Artist, error) {
+	return cm.artistDB.GetArtistsByPopularity(filter)
+}
+
+// SearchArtists returns one page of cached artists matching a search
+func (cm *CacheManager) SearchArtists(search ArtistSearch) (ArtistSearchResult, error) {
+	return cm.artistDB.SearchArtists(search)
+}
+
+// GetDiscography returns the stored discography for an artist
+func (cm *CacheManager) GetDiscography(mbid string) (*models.Discography, error) {
+	return cm.releaseDB.GetDiscography(mbid)
+}
+
+// RefreshExpiredArtists gets a batch of expired artists for background refresh
+func (cm *CacheManager) RefreshExpiredArtists(limit int) ([]models.Artist, error) {
+	return cm.artistDB.GetExpiredArtists(limit)
+}
+
+// CleanupExpiredEntries removes entries that have been expired for too long
+func (cm *CacheManager) CleanupExpiredEntries(maxAge time.Duration) (int, error) {
+	return cm.artistDB.DeleteExpiredBefore(time.Now().Add(-maxAge))
 }
 
 // GetCacheStats returns comprehensive cache statistics
@@ -129,9 +218,7 @@ func (cm *CacheManager) GetCacheStats() (CacheStats, error) {
 
 // UpdateCacheExpiry updates the cache expiry for an artist without changing other data
 func (cm *CacheManager) UpdateCacheExpiry(mbid string, newExpiry time.Time) error {
-	query := "UPDATE artists SET cache_expiry = ?, last_updated = ? WHERE mbid = ?"
-	_, err := cm.db.Exec(query, newExpiry, time.Now(), mbid)
-	return err
+	return cm.artistDB.UpdateCacheExpiry(mbid, newExpiry)
 }
 
 // BulkCacheArtists efficiently caches multiple artists in a transaction
@@ -140,62 +227,39 @@ func (cm *CacheManager) BulkCacheArtists(artists []models.Artist, config CacheCo
 		return nil
 	}
 
-	tx, err := cm.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	stmt, err := tx.Prepare(`
-INSERT INTO artists (
-    mbid, name, verified_json, album_count, years_active, 
-    description, genres_json, country, image_url, 
-    external_urls_json, last_updated, cache_expiry
-) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-ON CONFLICT(mbid) DO UPDATE SET
-    name = excluded.name,
-    verified_json = excluded.verified_json,
-    album_count = excluded.album_count,
-    years_active = excluded.years_active,
-    description = excluded.description,
-    genres_json = excluded.genres_json,
-    country = excluded.country,
-    image_url = excluded.image_url,
-    external_urls_json = excluded.external_urls_json,
-    last_updated = excluded.last_updated,
-    cache_expiry = excluded.cache_expiry
-`)
-	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
-	}
-	defer stmt.Close()
-
+	// Skip artists without MBID
+	toSave := make([]models.Artist, 0, len(artists))
 	for _, artist := range artists {
 		if artist.MBID == "" {
-			continue // Skip artists without MBID
+			continue
 		}
-
 		artist.LastUpdated = time.Now()
-		artist.CacheExpiry = cm.calculateExpiry(&artist, config)
-
-		_, err := stmt.Exec(
-			artist.MBID,
-			artist.Name,
-			artist.Verified,
-			artist.AlbumCount,
-			artist.YearsActive,
-			artist.Description,
-			artist.Genres,
-			artist.Country,
-			artist.ImageURL,
-			artist.ExternalURLs,
-			artist.LastUpdated,
-			artist.CacheExpiry,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to execute statement for artist %s: %w", artist.MBID, err)
+		artist.CacheExpiry = config.Expiry(&artist, artist.LastUpdated)
+		toSave = append(toSave, artist)
+	}
+
+	return cm.saveBatch(toSave)
+}
+
+// saveBatch stores artists as given, including their expiry, with their related data
+func (cm *CacheManager) saveBatch(artists []models.Artist) error {
+	if err := cm.artistDB.SaveArtists(artists); err != nil {
+		return err
+	}
+
+	for _, artist := range artists {
+		if len(artist.Releases) > 0 {
+			if err := cm.releaseDB.SaveDiscography(artist.MBID, artist.Releases); err != nil {
+				return fmt.Errorf("failed to save discography for artist %s: %w", artist.MBID, err)
+			}
+		}
+		if err := cm.saveSimilarArtists(&artist); err != nil {
+			return fmt.Errorf("failed to save similar artists for artist %s: %w", artist.MBID, err)
+		}
+		if err := cm.saveAliases(&artist); err != nil {
+			return fmt.Errorf("failed to save aliases for artist %s: %w", artist.MBID, err)
 		}
 	}
 
-	return tx.Commit()
+	return nil
 }
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"gocommender/internal/dialect"
+	"gocommender/internal/migrations"
 	"gocommender/internal/models"
 
 	_ "modernc.org/sqlite"
@@ -20,30 +22,10 @@ func setupTestDB(t *testing.T) *sql.DB {
 		t.Fatalf("Failed to open test database: %v", err)
 	}
 
-	// Create schema
-	schema := `
-CREATE TABLE artists (
-    mbid TEXT PRIMARY KEY,
-    name TEXT NOT NULL,
-    verified_json TEXT DEFAULT '{}',
-    album_count INTEGER DEFAULT 0,
-    years_active TEXT DEFAULT '',
-    description TEXT DEFAULT '',
-    genres_json TEXT DEFAULT '[]',
-    country TEXT DEFAULT '',
-    image_url TEXT DEFAULT '',
-    external_urls_json TEXT DEFAULT '{}',
-    last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
-    cache_expiry DATETIME NOT NULL
-);
-
-CREATE INDEX idx_cache_expiry ON artists(cache_expiry);
-CREATE INDEX idx_last_updated ON artists(last_updated);
-CREATE INDEX idx_name ON artists(name);
-CREATE INDEX idx_verified ON artists(verified_json);
-`
-
-	if _, err := db.Exec(schema); err != nil {
+	// Each connection to ":memory:" is a separate database
+	db.SetMaxOpenConns(1)
+
+	if _, err := migrations.Up(db, dialect.SQLite); err != nil {
 		t.Fatalf("Failed to create test schema: %v", err)
 	}
 
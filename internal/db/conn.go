@@ -0,0 +1,74 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gocommender/internal/dialect"
+)
+
+// conn runs queries written with ? placeholders against a database of any supported dialect
+type conn struct {
+	db      *sql.DB
+	dialect dialect.Dialect
+}
+
+// sqliteConn wraps a SQLite database, the default backend
+func sqliteConn(db *sql.DB) conn {
+	return conn{db: db, dialect: dialect.SQLite}
+}
+
+func (c conn) Exec(query string, args ...any) (sql.Result, error) {
+	return c.db.Exec(c.dialect.Rebind(query), args...)
+}
+
+// ExecCount runs a statement and returns the number of affected rows
+func (c conn) ExecCount(query string, args ...any) (int, error) {
+	return rowsAffected(c.Exec(query, args...))
+}
+
+func (c conn) Query(query string, args ...any) (*sql.Rows, error) {
+	return c.db.Query(c.dialect.Rebind(query), args...)
+}
+
+func (c conn) QueryRow(query string, args ...any) *sql.Row {
+	return c.db.QueryRow(c.dialect.Rebind(query), args...)
+}
+
+func (c conn) Begin() (*tx, error) {
+	sqlTx, err := c.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &tx{Tx: sqlTx, dialect: c.dialect}, nil
+}
+
+// tx is a transaction that rebinds queries like conn
+type tx struct {
+	*sql.Tx
+	dialect dialect.Dialect
+}
+
+func (t *tx) Exec(query string, args ...any) (sql.Result, error) {
+	return t.Tx.Exec(t.dialect.Rebind(query), args...)
+}
+
+// ExecCount runs a statement and returns the number of affected rows
+func (t *tx) ExecCount(query string, args ...any) (int, error) {
+	return rowsAffected(t.Exec(query, args...))
+}
+
+func (t *tx) Prepare(query string) (*sql.Stmt, error) {
+	return t.Tx.Prepare(t.dialect.Rebind(query))
+}
+
+func rowsAffected(result sql.Result, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(count), nil
+}
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gocommender/internal/dialect"
+)
+
+// ErrEmptyInvalidation is returned for an invalidation filter that matches nothing specific.
+// Use ClearAll to drop the whole cache.
+var ErrEmptyInvalidation = errors.New("invalidation needs an MBID, source, genre or last_updated range")
+
+// ErrNarrowedSource is returned for a source combined with other criteria. A source
+// invalidation expires all of its stored responses, which are not linked to artists.
+var ErrNarrowedSource = errors.New("source cannot be combined with other criteria, its stored responses are not linked to artists")
+
+// InvalidationFilter selects cached artists. Set fields are combined with AND.
+type InvalidationFilter struct {
+	MBIDs         []string
+	Source        string    // Artists looked up in this enrichment source, e.g. "discogs"
+	Genres        []string  // Artists tagged with any of these genres
+	UpdatedAfter  time.Time // Inclusive lower bound of last_updated
+	UpdatedBefore time.Time // Exclusive upper bound of last_updated
+}
+
+// IsEmpty reports whether the filter has no conditions
+func (f InvalidationFilter) IsEmpty() bool {
+	return len(f.MBIDs) == 0 && f.Source == "" && len(f.Genres) == 0 &&
+		f.UpdatedAfter.IsZero() && f.UpdatedBefore.IsZero()
+}
+
+// Validate checks that the filter selects something and that a source is not narrowed down
+func (f InvalidationFilter) Validate() error {
+	if f.IsEmpty() {
+		return ErrEmptyInvalidation
+	}
+	if f.Source != "" && (len(f.MBIDs) > 0 || len(f.Genres) > 0 || !f.UpdatedAfter.IsZero() || !f.UpdatedBefore.IsZero()) {
+		return ErrNarrowedSource
+	}
+	return nil
+}
+
+// conditions returns the WHERE conditions and arguments for the artists table
+func (f InvalidationFilter) conditions(d dialect.Dialect) ([]string, []any) {
+	var where []string
+	var args []any
+
+	if len(f.MBIDs) > 0 {
+		where = append(where, "mbid IN ("+placeholders(len(f.MBIDs))+")")
+		for _, mbid := range f.MBIDs {
+			args = append(args, mbid)
+		}
+	}
+
+	// Every source that was queried leaves a key in verified_json, verified or not
+	if f.Source != "" {
+		if d == dialect.Postgres {
+			where = append(where, "(verified_json::jsonb -> ?) IS NOT NULL")
+		} else {
+			where = append(where, "json_extract(verified_json, '$.' || ?) IS NOT NULL")
+		}
+		args = append(args, f.Source)
+	}
+
+	if len(f.Genres) > 0 {
+		where = append(where, genreCondition(d, "genres_json", len(f.Genres)))
+		for _, genre := range f.Genres {
+			args = append(args, genre)
+		}
+	}
+
+	if !f.UpdatedAfter.IsZero() {
+		where = append(where, "last_updated >= ?")
+		args = append(args, f.UpdatedAfter)
+	}
+	if !f.UpdatedBefore.IsZero() {
+		where = append(where, "last_updated < ?")
+		args = append(args, f.UpdatedBefore)
+	}
+
+	return where, args
+}
+
+// ClearResult counts the rows removed by ClearAll
+type ClearResult struct {
+	Artists  int `json:"artists"`
+	Releases int `json:"releases"`
+	Similar  int `json:"similar"`
+	Aliases  int `json:"aliases"`
+}
+
+// InvalidationResult counts what an invalidation changed
+type InvalidationResult struct {
+	Artists   int      `json:"artists"`             // Artists marked as expired
+	Responses int      `json:"responses,omitempty"` // Stored upstream responses marked as expired
+	Queued    int      `json:"queued,omitempty"`    // Artists queued for an immediate refresh
+	MBIDs     []string `json:"-"`
+}
+
+// ClearAll removes every cached artist with its releases, similarity edges and aliases.
+// Stored upstream responses are kept, so the cache can be rebuilt with reprocess.
+func (cm *CacheManager) ClearAll() (ClearResult, error) {
+	return cm.artistDB.DeleteAllArtists()
+}
+
+// Invalidate marks the artists matching the filter as expired, so they are re-enriched the
+// next time they are requested or by the background refresh. Cached data stays readable.
+// With a source, which cannot be combined with other criteria, that source's stored upstream
+// responses are expired too, so re-enrichment fetches the source again while the other sources
+// are answered from the response cache.
+func (cm *CacheManager) Invalidate(filter InvalidationFilter) (InvalidationResult, error) {
+	var result InvalidationResult
+	if err := filter.Validate(); err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	mbids, err := cm.artistDB.ExpireArtists(filter, now)
+	if err != nil {
+		return result, err
+	}
+	result.Artists = len(mbids)
+	result.MBIDs = mbids
+
+	if filter.Source != "" && cm.responseDB != nil {
+		if result.Responses, err = cm.responseDB.ExpireResponses(filter.Source, now); err != nil {
+			return result, fmt.Errorf("artists expired but responses were not: %w", err)
+		}
+	}
+
+	return result, nil
+}
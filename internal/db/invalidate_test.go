@@ -0,0 +1,280 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+// seedInvalidationArtists caches three artists updated a day apart, newest first
+func seedInvalidationArtists(t *testing.T, cm *CacheManager) time.Time {
+	t.Helper()
+
+	now := time.Now()
+	artists := []models.Artist{
+		{
+			MBID:        "slowdive-mbid",
+			Name:        "Slowdive",
+			Verified:    models.VerificationMap{"musicbrainz": true, "discogs": true},
+			Genres:      models.Genres{"shoegaze"},
+			LastUpdated: now,
+			Aliases:     []models.ArtistAlias{{Name: "Slow Dive", Source: models.AliasSourceDiscogs}},
+			Releases:    []models.ReleaseGroup{{MBID: "rg-1", Title: "Souvlaki", PrimaryType: "Album"}},
+			Similar:     []models.SimilarArtist{{Name: "Ride", MBID: "ride-mbid", Match: 0.9, Source: "lastfm"}},
+		},
+		{
+			MBID:        "ride-mbid",
+			Name:        "Ride",
+			Verified:    models.VerificationMap{"musicbrainz": true, "discogs": false},
+			Genres:      models.Genres{"dream pop"},
+			LastUpdated: now.Add(-24 * time.Hour),
+		},
+		{
+			MBID:        "sunn-mbid",
+			Name:        "Sunn O)))",
+			Verified:    models.VerificationMap{"musicbrainz": true, "lastfm": true},
+			Genres:      models.Genres{"drone metal"},
+			LastUpdated: now.Add(-48 * time.Hour),
+		},
+	}
+	for i := range artists {
+		artists[i].CacheExpiry = now.Add(30 * 24 * time.Hour)
+	}
+	if err := cm.saveBatch(artists); err != nil {
+		t.Fatalf("Failed to seed artists: %v", err)
+	}
+
+	return now
+}
+
+func TestCacheManagerInvalidate(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	cm := NewCacheManager(database)
+	now := seedInvalidationArtists(t, cm)
+
+	tests := []struct {
+		name     string
+		filter   InvalidationFilter
+		expected []string
+	}{
+		{"mbid", InvalidationFilter{MBIDs: []string{"ride-mbid", "unknown-mbid"}}, []string{"ride-mbid"}},
+		{"source", InvalidationFilter{Source: "discogs"}, []string{"ride-mbid", "slowdive-mbid"}},
+		{"genre", InvalidationFilter{Genres: []string{"shoegaze", "drone metal"}}, []string{"slowdive-mbid", "sunn-mbid"}},
+		{"updated range", InvalidationFilter{UpdatedAfter: now.Add(-36 * time.Hour), UpdatedBefore: now.Add(-time.Hour)}, []string{"ride-mbid"}},
+		{"combined", InvalidationFilter{Genres: []string{"shoegaze", "dream pop"}, UpdatedBefore: now.Add(-time.Hour)}, []string{"ride-mbid"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Restore expiry so every case starts from a fresh cache
+			for _, mbid := range []string{"slowdive-mbid", "ride-mbid", "sunn-mbid"} {
+				if _, err := cm.artistDB.ExpireArtists(InvalidationFilter{MBIDs: []string{mbid}}, now.Add(time.Hour)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := cm.Invalidate(tt.filter)
+			if err != nil {
+				t.Fatalf("Invalidate failed: %v", err)
+			}
+			sort.Strings(result.MBIDs)
+			if result.Artists != len(tt.expected) || len(result.MBIDs) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, result.MBIDs)
+			}
+			for i, mbid := range tt.expected {
+				if result.MBIDs[i] != mbid {
+					t.Errorf("Expected %v, got %v", tt.expected, result.MBIDs)
+				}
+				if expired, _ := cm.artistDB.IsExpired(mbid); !expired {
+					t.Errorf("Expected %s to be expired", mbid)
+				}
+			}
+		})
+	}
+
+	if _, err := cm.Invalidate(InvalidationFilter{}); !errors.Is(err, ErrEmptyInvalidation) {
+		t.Errorf("Expected ErrEmptyInvalidation for an empty filter, got %v", err)
+	}
+	if _, err := cm.Invalidate(InvalidationFilter{Source: "discogs", MBIDs: []string{"ride-mbid"}}); !errors.Is(err, ErrNarrowedSource) {
+		t.Errorf("Expected ErrNarrowedSource for a source with an MBID, got %v", err)
+	}
+}
+
+func TestCacheManagerInvalidateSourceExpiresResponses(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	cm := NewCacheManager(database)
+	seedInvalidationArtists(t, cm)
+
+	responses := NewResponseDB(database)
+	for _, source := range []string{"discogs", "lastfm"} {
+		err := responses.SaveResponse(&models.UpstreamResponse{
+			Source:     source,
+			Key:        "/artists/1",
+			StatusCode: 200,
+			Body:       []byte("{}"),
+			FetchedAt:  time.Now(),
+			ExpiresAt:  time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := cm.Invalidate(InvalidationFilter{Source: "discogs"})
+	if err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if result.Responses != 1 {
+		t.Errorf("Expected 1 response to be expired, got %d", result.Responses)
+	}
+
+	if discogs, _ := responses.GetResponse("discogs", "/artists/1"); discogs.IsFresh() {
+		t.Error("Expected Discogs response to be expired")
+	}
+	if lastfm, _ := responses.GetResponse("lastfm", "/artists/1"); !lastfm.IsFresh() {
+		t.Error("Expected Last.fm response to stay fresh")
+	}
+}
+
+func TestCacheManagerClearAll(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	cm := NewCacheManager(database)
+	seedInvalidationArtists(t, cm)
+
+	result, err := cm.ClearAll()
+	if err != nil {
+		t.Fatalf("ClearAll failed: %v", err)
+	}
+	expected := ClearResult{Artists: 3, Releases: 1, Similar: 1, Aliases: 1}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	if count, _ := cm.artistDB.GetArtistCount(); count != 0 {
+		t.Errorf("Expected empty cache, got %d artists", count)
+	}
+	if found, _ := cm.FindArtistByName("Slow Dive"); found != nil {
+		t.Errorf("Expected aliases to be cleared, found %+v", found)
+	}
+}
+
+func TestCacheManagerClearAllIsAtomic(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	cm := NewCacheManager(database)
+	seedInvalidationArtists(t, cm)
+
+	// Artists are deleted last, after their aliases, releases and edges
+	if _, err := database.Exec(`CREATE TRIGGER fail_artist_delete BEFORE DELETE ON artists
+BEGIN SELECT RAISE(ABORT, 'artists are locked'); END`); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+
+	if _, err := cm.ClearAll(); err == nil {
+		t.Fatal("Expected ClearAll to fail")
+	}
+	if aliases, _ := cm.aliasDB.GetAliases("slowdive-mbid"); len(aliases) != 1 {
+		t.Errorf("Expected the aliases deleted before the failure to be restored, got %v", aliases)
+	}
+	if releases, _ := cm.releaseDB.GetReleaseGroups("slowdive-mbid"); len(releases) != 1 {
+		t.Errorf("Expected the releases deleted before the failure to be restored, got %v", releases)
+	}
+}
+
+func TestRefreshServiceForceRefresh(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	cm := NewCacheManager(database)
+	seedInvalidationArtists(t, cm)
+
+	config := DefaultRefreshConfig()
+	config.BatchSize = 1 // Drain the queue over several batches
+	rs := NewRefreshService(cm, config)
+
+	refreshed := make(chan string, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go rs.Start(ctx, func(ctx context.Context, artist models.Artist) (*models.Artist, error) {
+		refreshed <- artist.MBID
+		return nil, nil
+	})
+
+	result, err := rs.ForceRefresh(InvalidationFilter{Source: "musicbrainz"})
+	if err != nil {
+		t.Fatalf("ForceRefresh failed: %v", err)
+	}
+	if result.Artists != 3 || result.Queued != 3 {
+		t.Errorf("Expected 3 artists to be expired and queued, got %+v", result)
+	}
+
+	seen := make(map[string]bool)
+	for len(seen) < 3 {
+		select {
+		case mbid := <-refreshed:
+			seen[mbid] = true
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for queued refreshes, got %v", seen)
+		}
+	}
+
+	// Queued artists are not queued twice
+	if added := rs.Enqueue([]string{"x", "x", "y"}); added != 2 {
+		t.Errorf("Expected duplicates to be ignored, added %d", added)
+	}
+}
+
+func TestRefreshServiceStop(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	rs := NewRefreshService(NewCacheManager(database), DefaultRefreshConfig())
+	rs.Stop() // Not running yet
+
+	done := make(chan error, 1)
+	go func() {
+		done <- rs.Start(context.Background(), func(ctx context.Context, artist models.Artist) (*models.Artist, error) {
+			return nil, nil
+		})
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !rs.IsRunning() {
+		if time.Now().After(deadline) {
+			t.Fatal("Refresh service did not start")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	rs.Stop()
+	rs.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected nil after Stop, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Refresh service did not stop")
+	}
+	if rs.IsRunning() {
+		t.Error("Expected service to report stopped")
+	}
+
+	// It can be started again after stopping
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rs.Start(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected restart to run until cancelled, got %v", err)
+	}
+}
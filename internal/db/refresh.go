@@ -3,22 +3,43 @@ package db
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
+	"gocommender/internal/logging"
+	"gocommender/internal/metrics"
 	"gocommender/internal/models"
 )
 
+var refreshLog = logging.For("refresh")
+
+// Refresh metrics. Batches are labelled by trigger: scheduled for expired artists, queued for ForceRefresh.
+var (
+	refreshArtists = metrics.NewCounter("gocommender_refresh_artists_total",
+		"Artists refreshed in the background by result (refreshed or failed).", "result")
+	refreshBatches = metrics.NewCounter("gocommender_refresh_batches_total",
+		"Background refresh batches by trigger and outcome (success, partial or failed).", "trigger", "outcome")
+	refreshBatchDuration = metrics.NewHistogram("gocommender_refresh_batch_duration_seconds",
+		"Duration of background refresh batches.", []float64{1, 5, 15, 30, 60, 120, 300, 600}, "trigger")
+	refreshQueueDepth = metrics.NewGauge("gocommender_refresh_queue_depth",
+		"Artists waiting for a queued refresh.")
+)
+
 // RefreshService manages background refresh of expired cache entries
 type RefreshService struct {
 	cacheManager *CacheManager
 	config       RefreshConfig
 
-	// Internal state
+	// Internal state; stopCh is replaced on each Start and closed once by Stop
 	running bool
 	stopCh  chan struct{}
 	mu      sync.RWMutex
+
+	// Artists queued for an immediate refresh, drained by Start
+	queue   []string
+	queued  map[string]bool
+	queueCh chan struct{}
+	queueMu sync.Mutex
 }
 
 // RefreshConfig defines refresh behavior
@@ -49,20 +70,30 @@ func NewRefreshService(cacheManager *CacheManager, config RefreshConfig) *Refres
 	return &RefreshService{
 		cacheManager: cacheManager,
 		config:       config,
-		stopCh:       make(chan struct{}),
+		queued:       make(map[string]bool),
+		queueCh:      make(chan struct{}, 1),
 	}
 }
 
-// Start begins the background refresh service
+// Start runs the background refresh service until the context is cancelled or
+// Stop is called. A batch in progress is finished before it returns.
 func (rs *RefreshService) Start(ctx context.Context, refreshFunc RefreshFunc) error {
 	rs.mu.Lock()
-	defer rs.mu.Unlock()
-
 	if rs.running {
+		rs.mu.Unlock()
 		return fmt.Errorf("refresh service is already running")
 	}
-
 	rs.running = true
+	stopCh := make(chan struct{})
+	rs.stopCh = stopCh
+	rs.mu.Unlock()
+
+	defer func() {
+		rs.mu.Lock()
+		rs.running = false
+		rs.stopCh = nil
+		rs.mu.Unlock()
+	}()
 
 	// Start refresh ticker
 	refreshTicker := time.NewTicker(rs.config.Interval)
@@ -72,39 +103,45 @@ func (rs *RefreshService) Start(ctx context.Context, refreshFunc RefreshFunc) er
 	cleanupTicker := time.NewTicker(rs.config.CleanupInterval)
 	defer cleanupTicker.Stop()
 
-	log.Printf("Background refresh service started (interval: %v, batch: %d)",
-		rs.config.Interval, rs.config.BatchSize)
+	refreshLog.Info("Background refresh service started",
+		"interval", rs.config.Interval, "batch_size", rs.config.BatchSize)
 
 	for {
 		select {
 		case <-ctx.Done():
-			rs.running = false
 			return ctx.Err()
 
-		case <-rs.stopCh:
-			rs.running = false
+		case <-stopCh:
+			refreshLog.Info("Background refresh service stopped")
 			return nil
 
 		case <-refreshTicker.C:
 			if err := rs.refreshExpiredBatch(ctx, refreshFunc); err != nil {
-				log.Printf("Refresh batch error: %v", err)
+				refreshLog.Error("Refresh batch failed", "trigger", "scheduled", "error", err)
+			}
+
+		case <-rs.queueCh:
+			if err := rs.refreshQueued(ctx, refreshFunc); err != nil {
+				refreshLog.Error("Queued refresh failed", "error", err)
 			}
 
 		case <-cleanupTicker.C:
 			if err := rs.cleanupOldEntries(); err != nil {
-				log.Printf("Cleanup error: %v", err)
+				refreshLog.Error("Cleanup failed", "error", err)
 			}
 		}
 	}
 }
 
-// Stop gracefully stops the refresh service
+// Stop asks the refresh service to stop after the current batch; calling it
+// more than once or while the service is not running does nothing
 func (rs *RefreshService) Stop() {
 	rs.mu.Lock()
 	defer rs.mu.Unlock()
 
-	if rs.running {
+	if rs.stopCh != nil {
 		close(rs.stopCh)
+		rs.stopCh = nil
 	}
 }
 
@@ -115,6 +152,91 @@ func (rs *RefreshService) IsRunning() bool {
 	return rs.running
 }
 
+// Enqueue queues artists for a refresh as soon as the service is idle and returns how many
+// were added. Artists that are already queued are not added twice.
+func (rs *RefreshService) Enqueue(mbids []string) int {
+	rs.queueMu.Lock()
+	defer rs.queueMu.Unlock()
+
+	added := 0
+	for _, mbid := range mbids {
+		if !rs.queued[mbid] {
+			rs.queued[mbid] = true
+			rs.queue = append(rs.queue, mbid)
+			added++
+		}
+	}
+
+	refreshQueueDepth.Set(float64(len(rs.queue)))
+
+	if added > 0 {
+		select {
+		case rs.queueCh <- struct{}{}:
+		default: // A drain is already pending
+		}
+	}
+
+	return added
+}
+
+// QueueLength returns the number of artists waiting for a queued refresh
+func (rs *RefreshService) QueueLength() int {
+	rs.queueMu.Lock()
+	defer rs.queueMu.Unlock()
+	return len(rs.queue)
+}
+
+// ForceRefresh expires the artists matching the filter and queues them for an immediate refresh.
+// Expired artists are also refreshed on their next request if the service is not running.
+func (rs *RefreshService) ForceRefresh(filter InvalidationFilter) (InvalidationResult, error) {
+	result, err := rs.cacheManager.Invalidate(filter)
+	if err != nil {
+		return result, err
+	}
+
+	result.Queued = rs.Enqueue(result.MBIDs)
+	return result, nil
+}
+
+// refreshQueued refreshes queued artists in batches until the queue is empty
+func (rs *RefreshService) refreshQueued(ctx context.Context, refreshFunc RefreshFunc) error {
+	for {
+		rs.queueMu.Lock()
+		n := min(rs.config.BatchSize, len(rs.queue))
+		batch := append([]string(nil), rs.queue[:n]...)
+		rs.queue = rs.queue[n:]
+		for _, mbid := range batch {
+			delete(rs.queued, mbid)
+		}
+		refreshQueueDepth.Set(float64(len(rs.queue)))
+		rs.queueMu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		artists := make([]models.Artist, 0, len(batch))
+		for _, mbid := range batch {
+			artist, err := rs.cacheManager.artistDB.GetArtist(mbid)
+			if err != nil {
+				return fmt.Errorf("failed to load queued artist %s: %w", mbid, err)
+			}
+			if artist != nil { // Removed since it was queued
+				artists = append(artists, *artist)
+			}
+		}
+
+		refreshLog.Info("Refreshing queued artists", "count", len(artists))
+		if err := rs.processArtistsBatch(ctx, "queued", artists, refreshFunc); err != nil {
+			refreshLog.Error("Refresh batch failed", "trigger", "queued", "error", err)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
 // refreshExpiredBatch processes a batch of expired artists
 func (rs *RefreshService) refreshExpiredBatch(ctx context.Context, refreshFunc RefreshFunc) error {
 	// Get expired artists
@@ -127,14 +249,15 @@ func (rs *RefreshService) refreshExpiredBatch(ctx context.Context, refreshFunc R
 		return nil // Nothing to refresh
 	}
 
-	log.Printf("Refreshing %d expired artists", len(expiredArtists))
+	refreshLog.Info("Refreshing expired artists", "count", len(expiredArtists))
 
 	// Process with limited concurrency
-	return rs.processArtistsBatch(ctx, expiredArtists, refreshFunc)
+	return rs.processArtistsBatch(ctx, "scheduled", expiredArtists, refreshFunc)
 }
 
 // processArtistsBatch processes artists with controlled concurrency
-func (rs *RefreshService) processArtistsBatch(ctx context.Context, artists []models.Artist, refreshFunc RefreshFunc) error {
+func (rs *RefreshService) processArtistsBatch(ctx context.Context, trigger string, artists []models.Artist, refreshFunc RefreshFunc) error {
+	start := time.Now()
 	semaphore := make(chan struct{}, rs.config.MaxConcurrency)
 	var wg sync.WaitGroup
 	errors := make(chan error, len(artists))
@@ -171,6 +294,19 @@ func (rs *RefreshService) processArtistsBatch(ctx context.Context, artists []mod
 		}
 	}
 
+	refreshArtists.Add(float64(len(artists)-len(refreshErrors)), "refreshed")
+	refreshArtists.Add(float64(len(refreshErrors)), "failed")
+	refreshBatchDuration.Observe(time.Since(start).Seconds(), trigger)
+
+	switch {
+	case len(refreshErrors) == 0:
+		refreshBatches.Inc(trigger, "success")
+	case len(refreshErrors) < len(artists):
+		refreshBatches.Inc(trigger, "partial")
+	default:
+		refreshBatches.Inc(trigger, "failed")
+	}
+
 	if len(refreshErrors) > 0 {
 		return fmt.Errorf("refresh completed with %d errors: %v", len(refreshErrors), refreshErrors[0])
 	}
@@ -184,20 +320,19 @@ func (rs *RefreshService) refreshSingleArtist(ctx context.Context, artist models
 	refreshedArtist, err := refreshFunc(ctx, artist)
 	if err != nil {
 		// Even if refresh fails, update cache expiry to avoid constant retries
-		newExpiry := time.Now().Add(DefaultCacheConfig().UnverifiedTTL)
+		newExpiry := rs.cacheManager.TTLPolicy().RetryExpiry(time.Now())
 		if updateErr := rs.cacheManager.UpdateCacheExpiry(artist.MBID, newExpiry); updateErr != nil {
-			log.Printf("Failed to update cache expiry for failed refresh of %s: %v", artist.MBID, updateErr)
+			refreshLog.Error("Failed to update cache expiry after failed refresh", "mbid", artist.MBID, "error", updateErr)
 		}
 		return fmt.Errorf("refresh function failed: %w", err)
 	}
 
 	// Cache the refreshed data
 	if refreshedArtist != nil {
-		cacheConfig := DefaultCacheConfig()
-		if err := rs.cacheManager.CacheArtist(refreshedArtist, cacheConfig); err != nil {
+		if err := rs.cacheManager.CacheArtist(refreshedArtist, rs.cacheManager.TTLPolicy()); err != nil {
 			return fmt.Errorf("failed to cache refreshed artist: %w", err)
 		}
-		log.Printf("Successfully refreshed artist: %s (%s)", refreshedArtist.Name, refreshedArtist.MBID)
+		refreshLog.Debug("Refreshed artist", "name", refreshedArtist.Name, "mbid", refreshedArtist.MBID)
 	}
 
 	return nil
@@ -211,7 +346,7 @@ func (rs *RefreshService) cleanupOldEntries() error {
 	}
 
 	if deleted > 0 {
-		log.Printf("Cleaned up %d old expired cache entries", deleted)
+		refreshLog.Info("Cleaned up old expired cache entries", "count", deleted)
 	}
 
 	return nil
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gocommender/internal/models"
+)
+
+// ReleaseDB handles database operations for artist discographies
+type ReleaseDB struct {
+	db conn
+}
+
+// NewReleaseDB creates a new ReleaseDB instance
+func NewReleaseDB(db *sql.DB) *ReleaseDB {
+	return &ReleaseDB{db: sqliteConn(db)}
+}
+
+// SaveDiscography replaces all stored release groups for an artist
+func (rdb *ReleaseDB) SaveDiscography(artistMBID string, groups []models.ReleaseGroup) error {
+	tx, err := rdb.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM release_groups WHERE artist_mbid = ?", artistMBID); err != nil {
+		return fmt.Errorf("failed to clear release groups: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+INSERT INTO release_groups (
+    mbid, artist_mbid, title, primary_type,
+    secondary_types_json, first_release_date, year
+) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(artist_mbid, mbid) DO NOTHING
+`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, rg := range groups {
+		if rg.MBID == "" {
+			continue // Skip release groups without MBID
+		}
+
+		_, err := stmt.Exec(
+			rg.MBID,
+			artistMBID,
+			rg.Title,
+			rg.PrimaryType,
+			rg.SecondaryTypes,
+			rg.FirstReleaseDate,
+			rg.Year,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save release group %s: %w", rg.MBID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetReleaseGroups returns all release groups for an artist in chronological order
+func (rdb *ReleaseDB) GetReleaseGroups(artistMBID string) ([]models.ReleaseGroup, error) {
+	query := `
+SELECT mbid, artist_mbid, title, primary_type,
+       secondary_types_json, first_release_date, year
+FROM release_groups
+WHERE artist_mbid = ?
+ORDER BY year = 0, year ASC, first_release_date ASC, title ASC
+`
+
+	rows, err := rdb.db.Query(query, artistMBID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make([]models.ReleaseGroup, 0)
+	for rows.Next() {
+		var rg models.ReleaseGroup
+		err := rows.Scan(
+			&rg.MBID,
+			&rg.ArtistMBID,
+			&rg.Title,
+			&rg.PrimaryType,
+			&rg.SecondaryTypes,
+			&rg.FirstReleaseDate,
+			&rg.Year,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan release group: %w", err)
+		}
+		groups = append(groups, rg)
+	}
+
+	return groups, rows.Err()
+}
+
+// GetDiscography returns the discography summary for an artist
+func (rdb *ReleaseDB) GetDiscography(artistMBID string) (*models.Discography, error) {
+	groups, err := rdb.GetReleaseGroups(artistMBID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewDiscography(artistMBID, groups), nil
+}
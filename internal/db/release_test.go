@@ -0,0 +1,94 @@
+package db
+
+import (
+	"testing"
+
+	"gocommender/internal/models"
+)
+
+func TestCacheManager_Discography(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	cm := NewCacheManager(db)
+	config := DefaultCacheConfig()
+
+	artist := &models.Artist{
+		MBID:     "disco-mbid",
+		Name:     "Discography Artist",
+		Verified: models.VerificationMap{"musicbrainz": true},
+		Releases: []models.ReleaseGroup{
+			{MBID: "rg-2", Title: "Second", PrimaryType: models.ReleaseTypeAlbum, Year: 1999},
+			{MBID: "rg-1", Title: "First", PrimaryType: models.ReleaseTypeAlbum, Year: 1995},
+			{MBID: "rg-3", Title: "Live", PrimaryType: models.ReleaseTypeAlbum, SecondaryTypes: models.ReleaseTypes{"Live"}, Year: 2001},
+			{MBID: "rg-4", Title: "Single", PrimaryType: models.ReleaseTypeSingle, Year: 1995},
+		},
+	}
+
+	if err := cm.CacheArtist(artist, config); err != nil {
+		t.Fatalf("Failed to cache artist: %v", err)
+	}
+
+	discography, err := cm.GetDiscography("disco-mbid")
+	if err != nil {
+		t.Fatalf("Failed to get discography: %v", err)
+	}
+
+	if len(discography.ReleaseGroups) != 4 {
+		t.Fatalf("Expected 4 release groups, got %d", len(discography.ReleaseGroups))
+	}
+	if discography.ReleaseGroups[0].Year != 1995 {
+		t.Errorf("Expected release groups ordered by year, first is %d", discography.ReleaseGroups[0].Year)
+	}
+	if discography.Albums != 2 {
+		t.Errorf("Expected 2 studio albums, got %d", discography.Albums)
+	}
+	if discography.ReleaseGroups[3].SecondaryTypes[0] != "Live" {
+		t.Errorf("Expected secondary types to round-trip, got %v", discography.ReleaseGroups[3].SecondaryTypes)
+	}
+
+	// Re-caching with a new discography replaces the old one
+	artist.Releases = []models.ReleaseGroup{
+		{MBID: "rg-1", Title: "First", PrimaryType: models.ReleaseTypeAlbum, Year: 1995},
+	}
+	if err := cm.CacheArtist(artist, config); err != nil {
+		t.Fatalf("Failed to re-cache artist: %v", err)
+	}
+
+	discography, err = cm.GetDiscography("disco-mbid")
+	if err != nil {
+		t.Fatalf("Failed to get discography: %v", err)
+	}
+	if len(discography.ReleaseGroups) != 1 {
+		t.Errorf("Expected discography to be replaced, got %d release groups", len(discography.ReleaseGroups))
+	}
+
+	// Caching without releases keeps the stored discography
+	artist.Releases = nil
+	if err := cm.CacheArtist(artist, config); err != nil {
+		t.Fatalf("Failed to re-cache artist: %v", err)
+	}
+
+	discography, err = cm.GetDiscography("disco-mbid")
+	if err != nil {
+		t.Fatalf("Failed to get discography: %v", err)
+	}
+	if len(discography.ReleaseGroups) != 1 {
+		t.Errorf("Expected discography to be kept, got %d release groups", len(discography.ReleaseGroups))
+	}
+}
+
+func TestCacheManager_DiscographyUnknownArtist(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	cm := NewCacheManager(db)
+
+	discography, err := cm.GetDiscography("unknown-mbid")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(discography.ReleaseGroups) != 0 {
+		t.Errorf("Expected empty discography, got %d release groups", len(discography.ReleaseGroups))
+	}
+}
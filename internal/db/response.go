@@ -0,0 +1,98 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+// ResponseDB handles database operations for raw upstream API responses
+type ResponseDB struct {
+	db conn
+}
+
+// NewResponseDB creates a new ResponseDB instance
+func NewResponseDB(db *sql.DB) *ResponseDB {
+	return &ResponseDB{db: sqliteConn(db)}
+}
+
+// GetResponse returns a stored response, or nil if the request was never cached.
+// Expired responses are returned too; callers decide whether to revalidate them.
+func (rdb *ResponseDB) GetResponse(source, key string) (*models.UpstreamResponse, error) {
+	query := `
+SELECT source, key, status_code, content_type, body, etag, last_modified, fetched_at, expires_at
+FROM upstream_responses
+WHERE source = ? AND key = ?
+`
+
+	var response models.UpstreamResponse
+	err := rdb.db.QueryRow(query, source, key).Scan(
+		&response.Source,
+		&response.Key,
+		&response.StatusCode,
+		&response.ContentType,
+		&response.Body,
+		&response.ETag,
+		&response.LastModified,
+		&response.FetchedAt,
+		&response.ExpiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Not cached
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upstream response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// SaveResponse stores or replaces a response
+func (rdb *ResponseDB) SaveResponse(response *models.UpstreamResponse) error {
+	query := `
+INSERT INTO upstream_responses (
+    source, key, status_code, content_type, body, etag, last_modified, fetched_at, expires_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(source, key) DO UPDATE SET
+    status_code = excluded.status_code,
+    content_type = excluded.content_type,
+    body = excluded.body,
+    etag = excluded.etag,
+    last_modified = excluded.last_modified,
+    fetched_at = excluded.fetched_at,
+    expires_at = excluded.expires_at
+`
+
+	_, err := rdb.db.Exec(query,
+		response.Source,
+		response.Key,
+		response.StatusCode,
+		response.ContentType,
+		response.Body,
+		response.ETag,
+		response.LastModified,
+		response.FetchedAt,
+		response.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save upstream response: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireResponses marks the fresh responses of a source as expiring at the given time,
+// so they are revalidated upstream on next use. Returns how many were changed.
+func (rdb *ResponseDB) ExpireResponses(source string, expiry time.Time) (int, error) {
+	count, err := rdb.db.ExecCount(
+		"UPDATE upstream_responses SET expires_at = ? WHERE source = ? AND expires_at > ?",
+		expiry, source, expiry,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire upstream responses: %w", err)
+	}
+	return count, nil
+}
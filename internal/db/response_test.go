@@ -0,0 +1,59 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+func TestResponseDB_SaveAndGet(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	rdb := NewResponseDB(db)
+
+	missing, err := rdb.GetResponse("musicbrainz", "/artist/none")
+	if err != nil || missing != nil {
+		t.Fatalf("Expected nil for uncached response, got %v, %v", missing, err)
+	}
+
+	response := &models.UpstreamResponse{
+		Source:      "musicbrainz",
+		Key:         "/artist/abc?fmt=json",
+		StatusCode:  200,
+		ContentType: "application/json",
+		Body:        []byte(`{"id":"abc"}`),
+		ETag:        `"v1"`,
+		FetchedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(-time.Hour),
+	}
+	if err := rdb.SaveResponse(response); err != nil {
+		t.Fatalf("Failed to save response: %v", err)
+	}
+
+	// Expired responses are still returned for revalidation and offline use
+	stored, err := rdb.GetResponse("musicbrainz", "/artist/abc?fmt=json")
+	if err != nil || stored == nil {
+		t.Fatalf("Failed to get response: %v", err)
+	}
+	if string(stored.Body) != `{"id":"abc"}` || stored.ETag != `"v1"` || stored.IsFresh() {
+		t.Errorf("Unexpected stored response %+v", stored)
+	}
+
+	// Saving again replaces the body
+	response.Body = []byte(`{"id":"abc","name":"New"}`)
+	response.ExpiresAt = time.Now().Add(time.Hour)
+	if err := rdb.SaveResponse(response); err != nil {
+		t.Fatalf("Failed to replace response: %v", err)
+	}
+	stored, _ = rdb.GetResponse("musicbrainz", "/artist/abc?fmt=json")
+	if string(stored.Body) != `{"id":"abc","name":"New"}` || !stored.IsFresh() {
+		t.Errorf("Expected replaced fresh response, got %+v", stored)
+	}
+
+	// Keys are scoped per source
+	if other, _ := rdb.GetResponse("discogs", "/artist/abc?fmt=json"); other != nil {
+		t.Error("Expected responses to be scoped by source")
+	}
+}
@@ -0,0 +1,289 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gocommender/internal/dialect"
+	"gocommender/internal/models"
+)
+
+// Sort orders for artist search
+const (
+	SortRelevance = "relevance" // Best full-text match first, needs a query
+	SortName      = "name"      // Alphabetical
+	SortListeners = "listeners" // Most Last.fm listeners first
+)
+
+// VerifiedAny matches artists verified by at least one source
+const VerifiedAny = "any"
+
+// ArtistSearch filters and orders the cached artist listing
+type ArtistSearch struct {
+	Query        string   // Full-text query over name, aliases, description and genres
+	Genres       []string // Matches artists tagged with any of these genres
+	Country      string   // ISO 3166 country code
+	Verified     string   // Source that must have verified the artist, or VerifiedAny
+	MinListeners int64    // Inclusive lower bound
+	MaxListeners int64    // Inclusive upper bound, 0 means unbounded
+	Sort         string   // SortRelevance, SortName or SortListeners; empty picks a default
+	Limit        int
+	Offset       int    // Rows to skip after the cursor
+	Cursor       string // NextCursor of the previous page
+}
+
+// ArtistSearchResult is one page of search results
+type ArtistSearchResult struct {
+	Artists    []models.Artist `json:"artists"`
+	NextCursor string          `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// searchCursor is the position after the last artist of a page.
+// Name and listener orders resume after the last sort key; relevance resumes by position.
+type searchCursor struct {
+	Sort     string `json:"s"`
+	Key      string `json:"k,omitempty"`
+	MBID     string `json:"m,omitempty"`
+	Position int    `json:"p,omitempty"`
+}
+
+// encode returns the opaque cursor token
+func (c searchCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSearchCursor parses a cursor token
+func decodeSearchCursor(token string) (searchCursor, error) {
+	var cursor searchCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, fmt.Errorf("invalid cursor")
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("invalid cursor")
+	}
+	return cursor, nil
+}
+
+// searchTerms splits a query into letter and digit runs, the only characters both
+// full-text engines agree on. Everything else is treated as a separator.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// placeholders returns n comma separated ? placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// genreCondition matches rows whose genre list column contains any of n genres
+func genreCondition(d dialect.Dialect, column string, n int) string {
+	if d == dialect.Postgres {
+		return "EXISTS (SELECT 1 FROM jsonb_array_elements_text(" + column + "::jsonb) g WHERE g IN (" + placeholders(n) + "))"
+	}
+	return "EXISTS (SELECT 1 FROM json_each(" + column + ") WHERE value IN (" + placeholders(n) + "))"
+}
+
+// ValidateSearch fills in the default sort and rejects unusable searches
+func ValidateSearch(search *ArtistSearch) error {
+	hasQuery := len(searchTerms(search.Query)) > 0
+
+	switch search.Sort {
+	case "":
+		search.Sort = SortListeners
+		if hasQuery {
+			search.Sort = SortRelevance
+		}
+	case SortRelevance:
+		if !hasQuery {
+			return fmt.Errorf("sort=relevance requires a search query")
+		}
+	case SortName, SortListeners:
+	default:
+		return fmt.Errorf("sort must be one of %s, %s, %s", SortRelevance, SortName, SortListeners)
+	}
+
+	if search.Cursor != "" {
+		cursor, err := decodeSearchCursor(search.Cursor)
+		if err != nil {
+			return err
+		}
+		if cursor.Sort != search.Sort {
+			return fmt.Errorf("cursor belongs to sort=%s", cursor.Sort)
+		}
+	}
+
+	return nil
+}
+
+// SearchArtists returns one page of artists matching the search, see ArtistSearch
+func (adb *ArtistDB) SearchArtists(search ArtistSearch) (ArtistSearchResult, error) {
+	if err := ValidateSearch(&search); err != nil {
+		return ArtistSearchResult{}, err
+	}
+
+	var cursor searchCursor
+	if search.Cursor != "" {
+		cursor, _ = decodeSearchCursor(search.Cursor)
+	}
+
+	d := adb.db.dialect
+	from := "artists a"
+	var where []string
+	var args []any
+	var rank string
+	var rankArgs []any
+
+	if terms := searchTerms(search.Query); len(terms) > 0 {
+		if d == dialect.Postgres {
+			// Prefix match on every term: slow:* & div:*
+			tsQuery := strings.Join(terms, ":* & ") + ":*"
+			where = append(where, "a.search_document @@ to_tsquery('simple', ?)")
+			args = append(args, tsQuery)
+			rank = "ts_rank(a.search_document, to_tsquery('simple', ?)) DESC"
+			rankArgs = append(rankArgs, tsQuery)
+		} else {
+			// Quoted prefix terms: "slow"* "div"*
+			ftsQuery := `"` + strings.Join(terms, `"* "`) + `"*`
+			from = "artist_search JOIN artists a ON a.rowid = artist_search.rowid"
+			where = append(where, "artist_search MATCH ?")
+			args = append(args, ftsQuery)
+			rank = "bm25(artist_search, 10.0, 8.0, 1.0, 3.0) ASC"
+		}
+	}
+
+	if len(search.Genres) > 0 {
+		where = append(where, genreCondition(d, "a.genres_json", len(search.Genres)))
+		for _, genre := range search.Genres {
+			args = append(args, genre)
+		}
+	}
+
+	if search.Country != "" {
+		where = append(where, "UPPER(a.country) = UPPER(?)")
+		args = append(args, search.Country)
+	}
+
+	switch {
+	case search.Verified == VerifiedAny && d == dialect.Postgres:
+		where = append(where, "EXISTS (SELECT 1 FROM jsonb_each(a.verified_json::jsonb) v WHERE v.value = 'true'::jsonb)")
+	case search.Verified == VerifiedAny:
+		where = append(where, "EXISTS (SELECT 1 FROM json_each(a.verified_json) WHERE value = 1)")
+	case search.Verified != "" && d == dialect.Postgres:
+		where = append(where, "(a.verified_json::jsonb ->> ?) = 'true'")
+		args = append(args, search.Verified)
+	case search.Verified != "":
+		where = append(where, "json_extract(a.verified_json, '$.' || ?) = 1")
+		args = append(args, search.Verified)
+	}
+
+	if search.MinListeners > 0 {
+		where = append(where, "a.listeners >= ?")
+		args = append(args, search.MinListeners)
+	}
+	if search.MaxListeners > 0 {
+		where = append(where, "a.listeners <= ?")
+		args = append(args, search.MaxListeners)
+	}
+
+	// Keyset conditions resume after the last row of the previous page
+	offset := search.Offset
+	var order string
+	switch search.Sort {
+	case SortName:
+		order = "LOWER(a.name) ASC, a.mbid ASC"
+		if cursor.MBID != "" {
+			where = append(where, "(LOWER(a.name) > ? OR (LOWER(a.name) = ? AND a.mbid > ?))")
+			args = append(args, cursor.Key, cursor.Key, cursor.MBID)
+		}
+	case SortListeners:
+		order = "a.listeners DESC, a.mbid ASC"
+		if cursor.MBID != "" {
+			listeners, _ := strconv.ParseInt(cursor.Key, 10, 64)
+			where = append(where, "(a.listeners < ? OR (a.listeners = ? AND a.mbid > ?))")
+			args = append(args, listeners, listeners, cursor.MBID)
+		}
+	case SortRelevance:
+		order = rank + ", a.mbid ASC"
+		args = append(args, rankArgs...)
+		offset += cursor.Position
+	}
+
+	query := `
+SELECT a.mbid, a.name, a.verified_json, a.album_count, a.years_active,
+       a.description, a.genres_json, a.country, a.image_url,
+       a.external_urls_json, a.listeners, a.playcount, a.members_json, a.groups_json,
+       a.labels_json, a.last_updated, a.cache_expiry,
+       LOWER(a.name)
+FROM ` + from
+	if len(where) > 0 {
+		query += "\nWHERE " + strings.Join(where, "\n  AND ")
+	}
+	// Fetch one extra row to know whether there is a next page
+	query += "\nORDER BY " + order + "\nLIMIT ? OFFSET ?"
+	args = append(args, search.Limit+1, offset)
+
+	rows, err := adb.db.Query(query, args...)
+	if err != nil {
+		return ArtistSearchResult{}, fmt.Errorf("failed to search artists: %w", err)
+	}
+	defer rows.Close()
+
+	result := ArtistSearchResult{Artists: make([]models.Artist, 0)}
+	var lastName string
+	for rows.Next() {
+		var artist models.Artist
+		var lowerName string
+		err := rows.Scan(
+			&artist.MBID,
+			&artist.Name,
+			&artist.Verified,
+			&artist.AlbumCount,
+			&artist.YearsActive,
+			&artist.Description,
+			&artist.Genres,
+			&artist.Country,
+			&artist.ImageURL,
+			&artist.ExternalURLs,
+			&artist.Listeners,
+			&artist.Playcount,
+			&artist.Members,
+			&artist.Groups,
+			&artist.Labels,
+			&artist.LastUpdated,
+			&artist.CacheExpiry,
+			&lowerName,
+		)
+		if err != nil {
+			return ArtistSearchResult{}, fmt.Errorf("failed to scan artist: %w", err)
+		}
+
+		if len(result.Artists) == search.Limit {
+			// The extra row only signals that another page exists
+			last := result.Artists[len(result.Artists)-1]
+			next := searchCursor{Sort: search.Sort, MBID: last.MBID}
+			switch search.Sort {
+			case SortName:
+				next.Key = lastName
+			case SortListeners:
+				next.Key = strconv.FormatInt(last.Listeners, 10)
+			case SortRelevance:
+				next = searchCursor{Sort: search.Sort, Position: offset + search.Limit}
+			}
+			result.NextCursor = next.encode()
+			break
+		}
+
+		result.Artists = append(result.Artists, artist)
+		lastName = lowerName
+	}
+
+	return result, rows.Err()
+}
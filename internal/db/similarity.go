@@ -0,0 +1,97 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+// SimilarityDB handles database operations for the artist similarity graph
+type SimilarityDB struct {
+	db conn
+}
+
+// NewSimilarityDB creates a new SimilarityDB instance
+func NewSimilarityDB(db *sql.DB) *SimilarityDB {
+	return &SimilarityDB{db: sqliteConn(db)}
+}
+
+// SaveSimilarArtists replaces the outgoing edges of an artist from one source
+func (sdb *SimilarityDB) SaveSimilarArtists(artistMBID, source string, edges []models.SimilarArtist) error {
+	tx, err := sdb.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM artist_similarity WHERE artist_mbid = ? AND source = ?", artistMBID, source); err != nil {
+		return fmt.Errorf("failed to clear similar artists: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+INSERT INTO artist_similarity (
+    artist_mbid, similar_name, similar_mbid, match, source, updated_at
+) VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(artist_mbid, source, similar_name) DO NOTHING
+`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, edge := range edges {
+		if edge.Name == "" || edge.MBID == artistMBID {
+			continue // Skip unnamed neighbours and self loops
+		}
+
+		if _, err := stmt.Exec(artistMBID, edge.Name, edge.MBID, edge.Match, source, now); err != nil {
+			return fmt.Errorf("failed to save similar artist %s: %w", edge.Name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSimilarArtists returns the strongest outgoing edges of an artist.
+// Neighbours that are in the artist cache carry their listener count.
+func (sdb *SimilarityDB) GetSimilarArtists(artistMBID string, minMatch float64, limit int) ([]models.SimilarArtist, error) {
+	query := `
+SELECT s.artist_mbid, s.similar_name, s.similar_mbid, s.match, s.source, s.updated_at,
+       COALESCE(a.listeners, 0), a.mbid IS NOT NULL
+FROM artist_similarity s
+LEFT JOIN artists a ON s.similar_mbid != '' AND a.mbid = s.similar_mbid
+WHERE s.artist_mbid = ? AND s.match >= ?
+ORDER BY s.match DESC, s.similar_name ASC
+LIMIT ?
+`
+
+	rows, err := sdb.db.Query(query, artistMBID, minMatch, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get similar artists: %w", err)
+	}
+	defer rows.Close()
+
+	edges := make([]models.SimilarArtist, 0)
+	for rows.Next() {
+		var edge models.SimilarArtist
+		err := rows.Scan(
+			&edge.ArtistMBID,
+			&edge.Name,
+			&edge.MBID,
+			&edge.Match,
+			&edge.Source,
+			&edge.UpdatedAt,
+			&edge.Listeners,
+			&edge.Cached,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan similar artist: %w", err)
+		}
+		edges = append(edges, edge)
+	}
+
+	return edges, rows.Err()
+}
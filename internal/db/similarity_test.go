@@ -0,0 +1,128 @@
+package db
+
+import (
+	"testing"
+
+	"gocommender/internal/models"
+)
+
+func TestCacheManager_SimilarArtists(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	cm := NewCacheManager(db)
+	config := DefaultCacheConfig()
+
+	neighbour := &models.Artist{
+		MBID:      "neighbour-mbid",
+		Name:      "Cached Neighbour",
+		Verified:  models.VerificationMap{"lastfm": true},
+		Listeners: 5000,
+	}
+	if err := cm.CacheArtist(neighbour, config); err != nil {
+		t.Fatalf("Failed to cache neighbour: %v", err)
+	}
+
+	artist := &models.Artist{
+		MBID:      "graph-mbid",
+		Name:      "Graph Artist",
+		Verified:  models.VerificationMap{"lastfm": true},
+		Listeners: 100000,
+		Playcount: 2500000,
+		Similar: []models.SimilarArtist{
+			{Name: "Uncached Neighbour", Match: 0.4, Source: models.SimilaritySourceLastFM},
+			{Name: "Cached Neighbour", MBID: "neighbour-mbid", Match: 0.9, Source: models.SimilaritySourceLastFM},
+			{Name: "Graph Artist", MBID: "graph-mbid", Match: 1, Source: models.SimilaritySourceLastFM}, // Self loop
+		},
+	}
+	if err := cm.CacheArtist(artist, config); err != nil {
+		t.Fatalf("Failed to cache artist: %v", err)
+	}
+
+	edges, err := cm.GetSimilarArtists("graph-mbid", 0, 10)
+	if err != nil {
+		t.Fatalf("Failed to get similar artists: %v", err)
+	}
+	if len(edges) != 2 {
+		t.Fatalf("Expected 2 edges (self loop skipped), got %d", len(edges))
+	}
+	if edges[0].Name != "Cached Neighbour" || !edges[0].Cached || edges[0].Listeners != 5000 {
+		t.Errorf("Expected strongest edge to be the cached neighbour, got %+v", edges[0])
+	}
+	if edges[1].Cached {
+		t.Errorf("Expected uncached neighbour to be marked as such, got %+v", edges[1])
+	}
+
+	// minMatch filters weak edges
+	edges, err = cm.GetSimilarArtists("graph-mbid", 0.5, 10)
+	if err != nil {
+		t.Fatalf("Failed to get similar artists: %v", err)
+	}
+	if len(edges) != 1 {
+		t.Errorf("Expected 1 edge above 0.5, got %d", len(edges))
+	}
+
+	// Re-caching without edges keeps the stored graph
+	artist.Similar = nil
+	if err := cm.CacheArtist(artist, config); err != nil {
+		t.Fatalf("Failed to re-cache artist: %v", err)
+	}
+	edges, _ = cm.GetSimilarArtists("graph-mbid", 0, 10)
+	if len(edges) != 2 {
+		t.Errorf("Expected stored edges to be kept, got %d", len(edges))
+	}
+
+	// Popularity is stored with the artist
+	cached, _, err := cm.GetOrFetchArtist("graph-mbid")
+	if err != nil {
+		t.Fatalf("Failed to get artist: %v", err)
+	}
+	if cached.Listeners != 100000 || cached.Playcount != 2500000 {
+		t.Errorf("Expected popularity to round-trip, got listeners=%d playcount=%d", cached.Listeners, cached.Playcount)
+	}
+}
+
+func TestCacheManager_GetArtistsByPopularity(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	cm := NewCacheManager(db)
+	config := DefaultCacheConfig()
+
+	artists := []models.Artist{
+		{MBID: "small", Name: "Small", Listeners: 100},
+		{MBID: "medium", Name: "Medium", Listeners: 10000},
+		{MBID: "large", Name: "Large", Listeners: 1000000},
+	}
+	if err := cm.BulkCacheArtists(artists, config); err != nil {
+		t.Fatalf("Failed to cache artists: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   PopularityFilter
+		expected []string
+	}{
+		{"all", PopularityFilter{Limit: 10}, []string{"large", "medium", "small"}},
+		{"min only", PopularityFilter{MinListeners: 1000, Limit: 10}, []string{"large", "medium"}},
+		{"range", PopularityFilter{MinListeners: 1000, MaxListeners: 50000, Limit: 10}, []string{"medium"}},
+		{"paged", PopularityFilter{Limit: 1, Offset: 1}, []string{"medium"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := cm.GetArtistsByPopularity(tt.filter)
+			if err != nil {
+				t.Fatalf("GetArtistsByPopularity failed: %v", err)
+			}
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Expected %d artists, got %d", len(tt.expected), len(result))
+			}
+			for i, mbid := range tt.expected {
+				if result[i].MBID != mbid {
+					t.Errorf("Position %d: expected %s, got %s", i, mbid, result[i].MBID)
+				}
+			}
+		})
+	}
+}
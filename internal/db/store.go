@@ -0,0 +1,95 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+
+	"gocommender/internal/dialect"
+	"gocommender/internal/models"
+)
+
+// ArtistRepository stores cached artists and their expiry
+type ArtistRepository interface {
+	SaveArtist(artist *models.Artist) error
+	SaveArtists(artists []models.Artist) error
+	GetArtist(mbid string) (*models.Artist, error)
+	GetArtistByName(name string) (*models.Artist, error)
+	IsExpired(mbid string) (bool, error)
+	GetExpiredArtists(limit int) ([]models.Artist, error)
+	GetArtistsByPopularity(filter PopularityFilter) ([]models.Artist, error)
+	SearchArtists(search ArtistSearch) (ArtistSearchResult, error)
+	UpdateCacheExpiry(mbid string, expiry time.Time) error
+	DeleteArtist(mbid string) error
+	DeleteExpiredBefore(cutoff time.Time) (int, error)
+	DeleteAllArtists() (ClearResult, error)
+	ExpireArtists(filter InvalidationFilter, expiry time.Time) ([]string, error)
+	GetArtistCount() (int, error)
+	GetCacheStats() (CacheStats, error)
+}
+
+// ReleaseRepository stores artist discographies
+type ReleaseRepository interface {
+	SaveDiscography(artistMBID string, groups []models.ReleaseGroup) error
+	GetReleaseGroups(artistMBID string) ([]models.ReleaseGroup, error)
+	GetDiscography(artistMBID string) (*models.Discography, error)
+}
+
+// SimilarityRepository stores the similar-artist graph
+type SimilarityRepository interface {
+	SaveSimilarArtists(artistMBID, source string, edges []models.SimilarArtist) error
+	GetSimilarArtists(artistMBID string, minMatch float64, limit int) ([]models.SimilarArtist, error)
+}
+
+// AliasRepository stores artist name variations
+type AliasRepository interface {
+	SaveAliases(artistMBID, source string, aliases []models.ArtistAlias) error
+	GetAliases(artistMBID string) ([]models.ArtistAlias, error)
+}
+
+// ResponseRepository stores raw upstream API responses
+type ResponseRepository interface {
+	GetResponse(source, key string) (*models.UpstreamResponse, error)
+	SaveResponse(response *models.UpstreamResponse) error
+	ExpireResponses(source string, expiry time.Time) (int, error)
+}
+
+// APIKeyRepository stores hashed API keys
+type APIKeyRepository interface {
+	SaveAPIKey(key *models.APIKey) error
+	GetAPIKeyByHash(hash string) (*models.APIKey, error)
+	ListAPIKeys() ([]models.APIKey, error)
+	RevokeAPIKey(id int64) (bool, error)
+	TouchAPIKey(id int64, usedAt time.Time, interval time.Duration) error
+}
+
+// UsageRepository stores daily LLM usage counters
+type UsageRepository interface {
+	AddLLMUsage(at time.Time, model string, usage LLMUsage) error
+	GetLLMUsage(from, to time.Time) (LLMUsage, error)
+}
+
+// Store groups the repositories backed by one database
+type Store struct {
+	Artists    ArtistRepository
+	Releases   ReleaseRepository
+	Similarity SimilarityRepository
+	Aliases    AliasRepository
+	Responses  ResponseRepository
+	APIKeys    APIKeyRepository
+	Usage      UsageRepository
+}
+
+// NewStore creates the SQL repositories for a database of the given dialect.
+// The schema must already be migrated for that dialect.
+func NewStore(db *sql.DB, d dialect.Dialect) *Store {
+	c := conn{db: db, dialect: d}
+	return &Store{
+		Artists:    &ArtistDB{db: c},
+		Releases:   &ReleaseDB{db: c},
+		Similarity: &SimilarityDB{db: c},
+		Aliases:    &AliasDB{db: c},
+		Responses:  &ResponseDB{db: c},
+		APIKeys:    &APIKeyDB{db: c},
+		Usage:      &UsageDB{db: c},
+	}
+}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+// TransferVersion is the version of the NDJSON export format written by Export
+const TransferVersion = 1
+
+// Record types of the NDJSON export format
+const (
+	RecordHeader = "header" // First line, describes the export
+	RecordArtist = "artist" // One cached artist with its aliases, releases and similar edges
+)
+
+// Conflict policies for imported artists that are already cached
+const (
+	ConflictSkip       = "skip"        // Keep the cached artist
+	ConflictOverwrite  = "overwrite"   // Replace the cached artist
+	ConflictNewestWins = "newest-wins" // Keep whichever copy was updated last
+)
+
+// ErrInvalidExport is wrapped by import errors caused by the input rather than the database
+var ErrInvalidExport = errors.New("invalid export")
+
+const (
+	exportPageSize    = 200
+	importBatchSize   = 200
+	exportSimilarEdge = 10000 // Upper bound of similarity edges exported per artist
+)
+
+// TransferRecord is one line of an export. Fields are filled in depending on Type.
+type TransferRecord struct {
+	Type string `json:"type"`
+
+	// Header
+	Version    int        `json:"version,omitempty"`
+	ExportedAt *time.Time `json:"exported_at,omitempty"`
+
+	// Artist
+	Artist      *models.Artist         `json:"artist,omitempty"`
+	CacheExpiry *time.Time             `json:"cache_expiry,omitempty"`
+	Aliases     []models.ArtistAlias   `json:"aliases,omitempty"`
+	Releases    []models.ReleaseGroup  `json:"releases,omitempty"`
+	Similar     []models.SimilarArtist `json:"similar,omitempty"`
+}
+
+// ExportStats counts what an export wrote
+type ExportStats struct {
+	Artists  int `json:"artists"`
+	Aliases  int `json:"aliases"`
+	Releases int `json:"releases"`
+	Similar  int `json:"similar"`
+}
+
+// ImportStats counts what an import did with each record
+type ImportStats struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"` // Already cached and kept by the conflict policy
+	Ignored  int `json:"ignored"` // Record types this version does not store
+}
+
+// ValidateConflictPolicy rejects unknown conflict policies
+func ValidateConflictPolicy(policy string) error {
+	switch policy {
+	case ConflictSkip, ConflictOverwrite, ConflictNewestWins:
+		return nil
+	default:
+		return fmt.Errorf("conflict policy must be one of %s, %s, %s", ConflictSkip, ConflictOverwrite, ConflictNewestWins)
+	}
+}
+
+// Export writes every cached artist as NDJSON, ordered by name, after a header line
+func (cm *CacheManager) Export(w io.Writer) (ExportStats, error) {
+	var stats ExportStats
+	encoder := json.NewEncoder(w)
+
+	now := time.Now().UTC()
+	if err := encoder.Encode(TransferRecord{Type: RecordHeader, Version: TransferVersion, ExportedAt: &now}); err != nil {
+		return stats, fmt.Errorf("failed to write export header: %w", err)
+	}
+
+	search := ArtistSearch{Sort: SortName, Limit: exportPageSize}
+	for {
+		page, err := cm.artistDB.SearchArtists(search)
+		if err != nil {
+			return stats, err
+		}
+
+		for i := range page.Artists {
+			record, err := cm.exportRecord(&page.Artists[i])
+			if err != nil {
+				return stats, err
+			}
+			if err := encoder.Encode(record); err != nil {
+				return stats, fmt.Errorf("failed to write artist %s: %w", record.Artist.MBID, err)
+			}
+
+			stats.Artists++
+			stats.Aliases += len(record.Aliases)
+			stats.Releases += len(record.Releases)
+			stats.Similar += len(record.Similar)
+		}
+
+		if page.NextCursor == "" {
+			return stats, nil
+		}
+		search.Cursor = page.NextCursor
+	}
+}
+
+// exportRecord collects an artist's related data into one record
+func (cm *CacheManager) exportRecord(artist *models.Artist) (TransferRecord, error) {
+	aliases, err := cm.aliasDB.GetAliases(artist.MBID)
+	if err != nil {
+		return TransferRecord{}, err
+	}
+	releases, err := cm.releaseDB.GetReleaseGroups(artist.MBID)
+	if err != nil {
+		return TransferRecord{}, err
+	}
+	similar, err := cm.similarityDB.GetSimilarArtists(artist.MBID, 0, exportSimilarEdge)
+	if err != nil {
+		return TransferRecord{}, err
+	}
+
+	// Neighbour listener counts describe the exporting cache, not the edge
+	for i := range similar {
+		similar[i].Listeners = 0
+		similar[i].Cached = false
+	}
+
+	expiry := artist.CacheExpiry
+	return TransferRecord{
+		Type:        RecordArtist,
+		Artist:      artist,
+		CacheExpiry: &expiry,
+		Aliases:     aliases,
+		Releases:    releases,
+		Similar:     similar,
+	}, nil
+}
+
+// Import reads an NDJSON export and caches its artists in batches. Artists that are
+// already cached are resolved with the conflict policy. Imported artists keep their
+// exported timestamps; records without them are stamped like BulkCacheArtists does.
+func (cm *CacheManager) Import(r io.Reader, policy string, config CacheConfig) (ImportStats, error) {
+	var stats ImportStats
+	if err := ValidateConflictPolicy(policy); err != nil {
+		return stats, err
+	}
+
+	reader := bufio.NewReader(r)
+	batch := make([]models.Artist, 0, importBatchSize)
+	for lineNumber := 1; ; lineNumber++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return stats, fmt.Errorf("failed to read line %d: %w", lineNumber, readErr)
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var record TransferRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				return stats, fmt.Errorf("%w: line %d: %v", ErrInvalidExport, lineNumber, err)
+			}
+
+			switch record.Type {
+			case RecordHeader:
+				if record.Version > TransferVersion {
+					return stats, fmt.Errorf("%w: format version %d is newer than this build (latest %d)", ErrInvalidExport, record.Version, TransferVersion)
+				}
+			case RecordArtist:
+				if record.Artist == nil || record.Artist.MBID == "" {
+					return stats, fmt.Errorf("%w: artist record on line %d has no MBID", ErrInvalidExport, lineNumber)
+				}
+				batch = append(batch, importedArtist(record))
+			default:
+				stats.Ignored++
+			}
+		}
+
+		if len(batch) == importBatchSize || (errors.Is(readErr, io.EOF) && len(batch) > 0) {
+			if err := cm.importBatch(batch, policy, config, &stats); err != nil {
+				return stats, err
+			}
+			batch = batch[:0]
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			return stats, nil
+		}
+	}
+}
+
+// importedArtist attaches a record's related data to its artist
+func importedArtist(record TransferRecord) models.Artist {
+	artist := *record.Artist
+	if record.CacheExpiry != nil {
+		artist.CacheExpiry = *record.CacheExpiry
+	}
+	artist.Aliases = record.Aliases
+	artist.Releases = record.Releases
+	artist.Similar = record.Similar
+	return artist
+}
+
+// importBatch applies the conflict policy to a batch and saves the artists that win
+func (cm *CacheManager) importBatch(batch []models.Artist, policy string, config CacheConfig, stats *ImportStats) error {
+	toSave := make([]models.Artist, 0, len(batch))
+	for _, artist := range batch {
+		if policy != ConflictOverwrite {
+			existing, err := cm.artistDB.GetArtist(artist.MBID)
+			if err != nil {
+				return fmt.Errorf("failed to look up artist %s: %w", artist.MBID, err)
+			}
+			if existing != nil && (policy == ConflictSkip || !artist.LastUpdated.After(existing.LastUpdated)) {
+				stats.Skipped++
+				continue
+			}
+		}
+
+		if artist.LastUpdated.IsZero() {
+			artist.LastUpdated = time.Now()
+		}
+		if artist.CacheExpiry.IsZero() {
+			artist.CacheExpiry = config.Expiry(&artist, time.Now())
+		}
+		toSave = append(toSave, artist)
+	}
+
+	if err := cm.saveBatch(toSave); err != nil {
+		return err
+	}
+	stats.Imported += len(toSave)
+	return nil
+}
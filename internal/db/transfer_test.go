@@ -0,0 +1,176 @@
+package db
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+func seedTransferArtists(t *testing.T, cm *CacheManager, updated time.Time) {
+	t.Helper()
+
+	artists := []models.Artist{
+		{
+			MBID:        "slowdive-mbid",
+			Name:        "Slowdive",
+			Verified:    models.VerificationMap{"musicbrainz": true},
+			Genres:      models.Genres{"shoegaze"},
+			Listeners:   900000,
+			LastUpdated: updated,
+			CacheExpiry: updated.Add(30 * 24 * time.Hour),
+			Releases:    []models.ReleaseGroup{{MBID: "rg-1", Title: "Souvlaki", PrimaryType: "Album", Year: 1993}},
+			Aliases:     []models.ArtistAlias{{Name: "Slow Dive", Source: models.AliasSourceDiscogs}},
+			Similar:     []models.SimilarArtist{{Name: "Ride", MBID: "ride-mbid", Match: 0.9, Source: "lastfm"}},
+		},
+		{
+			MBID:        "ride-mbid",
+			Name:        "Ride",
+			Listeners:   500000,
+			LastUpdated: updated,
+			CacheExpiry: updated.Add(7 * 24 * time.Hour),
+		},
+	}
+	if err := cm.saveBatch(artists); err != nil {
+		t.Fatalf("Failed to seed artists: %v", err)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source := NewCacheManager(setupTestDB(t))
+	updated := time.Now().Add(-48 * time.Hour).UTC().Truncate(time.Second)
+	seedTransferArtists(t, source, updated)
+
+	var buf bytes.Buffer
+	stats, err := source.Export(&buf)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if stats.Artists != 2 || stats.Aliases != 1 || stats.Releases != 1 || stats.Similar != 1 {
+		t.Errorf("Unexpected export stats %+v", stats)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], `"type":"header"`) {
+		t.Fatalf("Expected a header and two artist lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[1], `"name":"Ride"`) {
+		t.Errorf("Expected artists ordered by name, got %s", lines[1])
+	}
+
+	target := NewCacheManager(setupTestDB(t))
+	imported, err := target.Import(strings.NewReader(buf.String()), ConflictNewestWins, DefaultCacheConfig())
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if imported.Imported != 2 || imported.Skipped != 0 {
+		t.Errorf("Unexpected import stats %+v", imported)
+	}
+
+	artist, _, err := target.GetOrFetchArtist("slowdive-mbid")
+	if err != nil || artist == nil {
+		t.Fatalf("Expected imported artist, got %v, %v", artist, err)
+	}
+	if !artist.LastUpdated.Equal(updated) || !artist.CacheExpiry.Equal(updated.Add(30*24*time.Hour)) {
+		t.Errorf("Expected exported timestamps to be kept, got %v / %v", artist.LastUpdated, artist.CacheExpiry)
+	}
+	if discography, _ := target.GetDiscography("slowdive-mbid"); discography.Albums != 1 {
+		t.Errorf("Expected releases to be imported, got %+v", discography)
+	}
+	if aliases, _ := target.GetAliases("slowdive-mbid"); len(aliases) != 1 || aliases[0].Name != "Slow Dive" {
+		t.Errorf("Expected aliases to be imported, got %+v", aliases)
+	}
+	if similar, _ := target.GetSimilarArtists("slowdive-mbid", 0, 10); len(similar) != 1 || !similar[0].Cached {
+		t.Errorf("Expected similar edge to the imported neighbour, got %+v", similar)
+	}
+}
+
+func TestImportConflictPolicies(t *testing.T) {
+	older := time.Now().Add(-72 * time.Hour).UTC().Truncate(time.Second)
+	newer := older.Add(24 * time.Hour)
+
+	source := NewCacheManager(setupTestDB(t))
+	seedTransferArtists(t, source, older)
+	var buf bytes.Buffer
+	if _, err := source.Export(&buf); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	export := buf.String()
+
+	tests := []struct {
+		policy   string
+		imported int
+		skipped  int
+		listener int64 // Expected Slowdive listeners afterwards
+	}{
+		{ConflictSkip, 0, 2, 1},
+		{ConflictNewestWins, 0, 2, 1},
+		{ConflictOverwrite, 2, 0, 900000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			target := NewCacheManager(setupTestDB(t))
+			seedTransferArtists(t, target, newer)
+			local, _ := target.artistDB.GetArtist("slowdive-mbid")
+			local.Listeners = 1
+			if err := target.artistDB.SaveArtist(local); err != nil {
+				t.Fatal(err)
+			}
+
+			stats, err := target.Import(strings.NewReader(export), tt.policy, DefaultCacheConfig())
+			if err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+			if stats.Imported != tt.imported || stats.Skipped != tt.skipped {
+				t.Errorf("Unexpected stats %+v", stats)
+			}
+			if artist, _ := target.artistDB.GetArtist("slowdive-mbid"); artist.Listeners != tt.listener {
+				t.Errorf("Expected %d listeners, got %d", tt.listener, artist.Listeners)
+			}
+		})
+	}
+
+	// A newer export replaces older cached copies under newest-wins
+	target := NewCacheManager(setupTestDB(t))
+	seedTransferArtists(t, target, older.Add(-time.Hour))
+	stats, err := target.Import(strings.NewReader(export), ConflictNewestWins, DefaultCacheConfig())
+	if err != nil || stats.Imported != 2 {
+		t.Errorf("Expected newer export to win, got %+v, %v", stats, err)
+	}
+}
+
+func TestImportRejectsBadInput(t *testing.T) {
+	cm := NewCacheManager(setupTestDB(t))
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"invalid json", `{"type":"artist",`},
+		{"missing mbid", `{"type":"artist","artist":{"name":"Slowdive"}}`},
+		{"newer format", `{"type":"header","version":99}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cm.Import(strings.NewReader(tt.input), ConflictSkip, DefaultCacheConfig())
+			if !errors.Is(err, ErrInvalidExport) {
+				t.Errorf("Expected ErrInvalidExport, got %v", err)
+			}
+		})
+	}
+
+	// Record types from other versions are counted, not stored
+	stats, err := cm.Import(strings.NewReader(`{"type":"header","version":1}`+"\n"+`{"type":"feedback"}`+"\n"), ConflictSkip, DefaultCacheConfig())
+	if err != nil || stats.Ignored != 1 {
+		t.Errorf("Expected unknown record to be ignored, got %+v, %v", stats, err)
+	}
+
+	if _, err := cm.Import(strings.NewReader(""), "merge", DefaultCacheConfig()); err == nil {
+		t.Error("Expected unknown conflict policy to fail")
+	}
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+// TTL returns how long an artist stays fresh before jitter is applied.
+// Artists that no source verified use UnverifiedTTL.
+func (c CacheConfig) TTL(artist *models.Artist) time.Duration {
+	var ttl time.Duration
+	for source, verified := range artist.Verified {
+		if !verified {
+			continue
+		}
+
+		sourceTTL, ok := c.SourceTTLs[source]
+		if !ok || sourceTTL <= 0 {
+			sourceTTL = c.VerifiedTTL
+		}
+		if ttl == 0 || sourceTTL < ttl {
+			ttl = sourceTTL
+		}
+	}
+
+	if ttl == 0 {
+		return c.UnverifiedTTL
+	}
+	return ttl
+}
+
+// Expiry returns when an artist cached at the given time expires, with jitter
+func (c CacheConfig) Expiry(artist *models.Artist, from time.Time) time.Time {
+	return from.Add(c.jitter(c.TTL(artist)))
+}
+
+// RetryExpiry returns when to retry an artist whose refresh failed at the given time
+func (c CacheConfig) RetryExpiry(from time.Time) time.Time {
+	return from.Add(c.jitter(c.UnverifiedTTL))
+}
+
+// jitter moves a TTL randomly within ±Jitter of its length
+func (c CacheConfig) jitter(ttl time.Duration) time.Duration {
+	if c.Jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	spread := float64(ttl) * min(c.Jitter, 1)
+	return ttl + time.Duration((rand.Float64()*2-1)*spread)
+}
+
+// SetTTLPolicy replaces the TTL policy returned by TTLPolicy
+func (cm *CacheManager) SetTTLPolicy(policy CacheConfig) {
+	cm.policy = policy
+}
+
+// TTLPolicy returns the configured TTL policy, DefaultCacheConfig unless set with SetTTLPolicy
+func (cm *CacheManager) TTLPolicy() CacheConfig {
+	return cm.policy
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+func TestCacheConfigTTL(t *testing.T) {
+	config := CacheConfig{
+		VerifiedTTL:   30 * 24 * time.Hour,
+		UnverifiedTTL: 7 * 24 * time.Hour,
+		SourceTTLs: map[string]time.Duration{
+			"musicbrainz": 90 * 24 * time.Hour,
+			"lastfm":      14 * 24 * time.Hour,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		verified models.VerificationMap
+		expected time.Duration
+	}{
+		{"unverified", models.VerificationMap{"musicbrainz": false}, 7 * 24 * time.Hour},
+		{"single source", models.VerificationMap{"musicbrainz": true}, 90 * 24 * time.Hour},
+		{"shortest source wins", models.VerificationMap{"musicbrainz": true, "lastfm": true}, 14 * 24 * time.Hour},
+		{"source without TTL", models.VerificationMap{"musicbrainz": true, "discogs": true}, 30 * 24 * time.Hour},
+		{"failed source ignored", models.VerificationMap{"musicbrainz": true, "lastfm": false}, 90 * 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artist := &models.Artist{Verified: tt.verified}
+			if ttl := config.TTL(artist); ttl != tt.expected {
+				t.Errorf("Expected TTL %v, got %v", tt.expected, ttl)
+			}
+		})
+	}
+}
+
+func TestCacheConfigExpiryJitter(t *testing.T) {
+	config := CacheConfig{VerifiedTTL: 100 * time.Hour, UnverifiedTTL: 10 * time.Hour, Jitter: 0.1}
+	artist := &models.Artist{Verified: models.VerificationMap{"musicbrainz": true}}
+	from := time.Now()
+
+	for i := 0; i < 100; i++ {
+		expiry := config.Expiry(artist, from)
+		if expiry.Before(from.Add(90*time.Hour)) || expiry.After(from.Add(110*time.Hour)) {
+			t.Fatalf("Expiry %v outside ±10%% of 100h", expiry.Sub(from))
+		}
+
+		retry := config.RetryExpiry(from)
+		if retry.Before(from.Add(9*time.Hour)) || retry.After(from.Add(11*time.Hour)) {
+			t.Fatalf("Retry expiry %v outside ±10%% of 10h", retry.Sub(from))
+		}
+	}
+
+	config.Jitter = 0
+	if expiry := config.Expiry(artist, from); !expiry.Equal(from.Add(100 * time.Hour)) {
+		t.Errorf("Expected exact expiry without jitter, got %v", expiry.Sub(from))
+	}
+}
+
+func TestCacheManagerTTLPolicy(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	cm := NewCacheManager(database)
+	policy := CacheConfig{VerifiedTTL: time.Hour, UnverifiedTTL: time.Minute}
+	cm.SetTTLPolicy(policy)
+
+	artist := &models.Artist{
+		MBID:     "slowdive-mbid",
+		Name:     "Slowdive",
+		Verified: models.VerificationMap{"musicbrainz": true},
+	}
+	if err := cm.CacheArtist(artist, cm.TTLPolicy()); err != nil {
+		t.Fatalf("CacheArtist failed: %v", err)
+	}
+
+	ttl := artist.CacheExpiry.Sub(artist.LastUpdated)
+	if ttl != time.Hour {
+		t.Errorf("Expected configured TTL of 1h, got %v", ttl)
+	}
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// usageDayFormat is how days are stored in llm_usage, sortable as text
+const usageDayFormat = time.DateOnly
+
+// LLMUsage sums LLM requests, tokens and estimated cost
+type LLMUsage struct {
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// TotalTokens returns prompt and completion tokens together
+func (u LLMUsage) TotalTokens() int64 {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// UsageDB handles database operations for LLM usage counters
+type UsageDB struct {
+	db conn
+}
+
+// NewUsageDB creates a new UsageDB instance
+func NewUsageDB(db *sql.DB) *UsageDB {
+	return &UsageDB{db: sqliteConn(db)}
+}
+
+// AddLLMUsage adds usage to the counters of a model on the UTC day of at
+func (udb *UsageDB) AddLLMUsage(at time.Time, model string, usage LLMUsage) error {
+	query := `
+INSERT INTO llm_usage (day, model, requests, prompt_tokens, completion_tokens, cost_usd)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(day, model) DO UPDATE SET
+    requests = llm_usage.requests + excluded.requests,
+    prompt_tokens = llm_usage.prompt_tokens + excluded.prompt_tokens,
+    completion_tokens = llm_usage.completion_tokens + excluded.completion_tokens,
+    cost_usd = llm_usage.cost_usd + excluded.cost_usd
+`
+
+	_, err := udb.db.Exec(query,
+		at.UTC().Format(usageDayFormat),
+		model,
+		usage.Requests,
+		usage.PromptTokens,
+		usage.CompletionTokens,
+		usage.CostUSD,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record LLM usage: %w", err)
+	}
+	return nil
+}
+
+// GetLLMUsage sums usage of all models over the UTC days from from up to, but not including, to
+func (udb *UsageDB) GetLLMUsage(from, to time.Time) (LLMUsage, error) {
+	query := `
+SELECT
+    COALESCE(SUM(requests), 0),
+    COALESCE(SUM(prompt_tokens), 0),
+    COALESCE(SUM(completion_tokens), 0),
+    COALESCE(SUM(cost_usd), 0)
+FROM llm_usage
+WHERE day >= ? AND day < ?
+`
+
+	var usage LLMUsage
+	err := udb.db.QueryRow(query, from.UTC().Format(usageDayFormat), to.UTC().Format(usageDayFormat)).Scan(
+		&usage.Requests,
+		&usage.PromptTokens,
+		&usage.CompletionTokens,
+		&usage.CostUSD,
+	)
+	if err != nil {
+		return usage, fmt.Errorf("failed to get LLM usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsageDB(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	usage := NewUsageDB(database)
+	day := time.Date(2026, 3, 14, 23, 30, 0, 0, time.UTC)
+
+	records := []struct {
+		at    time.Time
+		model string
+		usage LLMUsage
+	}{
+		{day, "gpt-4o", LLMUsage{Requests: 1, PromptTokens: 1000, CompletionTokens: 200, CostUSD: 0.0045}},
+		{day.Add(10 * time.Minute), "gpt-4o", LLMUsage{Requests: 1, PromptTokens: 500, CompletionTokens: 100, CostUSD: 0.00225}},
+		{day, "gpt-4o-mini", LLMUsage{Requests: 1, PromptTokens: 100, CompletionTokens: 50}},
+		{day.Add(time.Hour), "gpt-4o", LLMUsage{Requests: 1, PromptTokens: 10, CompletionTokens: 10}}, // Next UTC day
+	}
+	for _, record := range records {
+		if err := usage.AddLLMUsage(record.at, record.model, record.usage); err != nil {
+			t.Fatalf("AddLLMUsage failed: %v", err)
+		}
+	}
+
+	start := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+	daily, err := usage.GetLLMUsage(start, start.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("GetLLMUsage failed: %v", err)
+	}
+	if daily.Requests != 3 || daily.PromptTokens != 1600 || daily.CompletionTokens != 350 || daily.TotalTokens() != 1950 {
+		t.Errorf("Unexpected daily usage: %+v", daily)
+	}
+	if daily.CostUSD < 0.00674 || daily.CostUSD > 0.00676 {
+		t.Errorf("Expected daily cost of $0.00675, got %v", daily.CostUSD)
+	}
+
+	monthly, _ := usage.GetLLMUsage(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	if monthly.Requests != 4 || monthly.TotalTokens() != 1970 {
+		t.Errorf("Unexpected monthly usage: %+v", monthly)
+	}
+
+	empty, err := usage.GetLLMUsage(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), start)
+	if err != nil || empty != (LLMUsage{}) {
+		t.Errorf("Expected no usage before the first record, got %+v, %v", empty, err)
+	}
+}
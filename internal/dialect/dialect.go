@@ -0,0 +1,72 @@
+// Package dialect covers the SQL differences between the supported database backends.
+//
+// Queries are written once with ? placeholders in the SQLite flavour; Rebind and the
+// helpers here adapt them for PostgreSQL.
+package dialect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect identifies a database backend
+type Dialect string
+
+const (
+	SQLite   Dialect = "sqlite"
+	Postgres Dialect = "postgres"
+)
+
+// Parse returns the dialect for a configured driver name
+func Parse(name string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "sqlite", "sqlite3":
+		return SQLite, nil
+	case "postgres", "postgresql", "pg":
+		return Postgres, nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %q (available: sqlite, postgres)", name)
+	}
+}
+
+// DriverName is the database/sql driver registered for the dialect
+func (d Dialect) DriverName() string {
+	return string(d)
+}
+
+// Rebind converts ? placeholders to $1, $2, ... for PostgreSQL.
+// Question marks inside quoted literals are left alone.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	inQuote := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inQuote = !inQuote
+		case r == '?' && !inQuote:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// EqualFold compares column case-insensitively with the next placeholder
+func (d Dialect) EqualFold(column string) string {
+	if d == Postgres {
+		return "LOWER(" + column + ") = LOWER(?)"
+	}
+	return column + " = ? COLLATE NOCASE"
+}
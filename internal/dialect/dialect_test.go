@@ -0,0 +1,52 @@
+package dialect
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		dialect  Dialect
+		query    string
+		expected string
+	}{
+		{SQLite, "SELECT * FROM artists WHERE mbid = ?", "SELECT * FROM artists WHERE mbid = ?"},
+		{Postgres, "SELECT * FROM artists WHERE mbid = ?", "SELECT * FROM artists WHERE mbid = $1"},
+		{Postgres, "INSERT INTO t (a, b, c) VALUES (?, ?, ?)", "INSERT INTO t (a, b, c) VALUES ($1, $2, $3)"},
+		{Postgres, "SELECT '?' AS q, name FROM artists WHERE name = ?", "SELECT '?' AS q, name FROM artists WHERE name = $1"},
+		{Postgres, "SELECT COUNT(*) FROM artists", "SELECT COUNT(*) FROM artists"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.dialect.Rebind(tt.query); got != tt.expected {
+			t.Errorf("%s Rebind(%q) = %q, want %q", tt.dialect, tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]Dialect{
+		"":           SQLite,
+		"sqlite":     SQLite,
+		"Postgres":   Postgres,
+		"postgresql": Postgres,
+	}
+
+	for name, expected := range tests {
+		got, err := Parse(name)
+		if err != nil || got != expected {
+			t.Errorf("Parse(%q) = %q, %v; want %q", name, got, err, expected)
+		}
+	}
+
+	if _, err := Parse("mysql"); err == nil {
+		t.Error("Expected an error for an unsupported driver")
+	}
+}
+
+func TestEqualFold(t *testing.T) {
+	if got := SQLite.EqualFold("name"); got != "name = ? COLLATE NOCASE" {
+		t.Errorf("Unexpected SQLite comparison %q", got)
+	}
+	if got := Postgres.EqualFold("name"); got != "LOWER(name) = LOWER(?)" {
+		t.Errorf("Unexpected Postgres comparison %q", got)
+	}
+}
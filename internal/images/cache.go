@@ -0,0 +1,320 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	// Register decoders for formats served by upstream sources
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+
+	"gocommender/internal/logging"
+	"gocommender/internal/metrics"
+	"gocommender/internal/models"
+)
+
+var logger = logging.For("images")
+
+// ErrNoImage is returned when no source could provide an image for an artist
+var ErrNoImage = errors.New("no image available")
+
+// maxDownloadBytes limits the size of a single upstream image
+const maxDownloadBytes = 10 << 20
+
+// maxSourceDimension limits the declared width and height of an upstream image, since
+// a small compressed file can decode to gigabytes of pixels
+const maxSourceDimension = 8000
+
+// Config defines image cache behavior
+type Config struct {
+	Dir        string        // Directory for cached thumbnails
+	MaxSize    int           // Maximum width/height in pixels
+	TTL        time.Duration // How long a cached image is considered fresh
+	FailureTTL time.Duration // How long to remember that no image was found
+}
+
+// DefaultConfig returns the default image cache configuration
+func DefaultConfig(dir string) Config {
+	return Config{
+		Dir:        dir,
+		MaxSize:    500,
+		TTL:        30 * 24 * time.Hour, // 30 days
+		FailureTTL: 24 * time.Hour,      // Retry missing artwork daily
+	}
+}
+
+// CachedImage describes a thumbnail stored on disk
+type CachedImage struct {
+	Path    string
+	ModTime time.Time
+}
+
+// Cache downloads, resizes and stores artist images on disk
+type Cache struct {
+	config     Config
+	sources    []Source
+	httpClient *http.Client
+
+	locksMu sync.Mutex
+	locks   map[string]*artistLock // Only artists being fetched, see lock
+}
+
+// artistLock serializes the requests for one artist's image
+type artistLock struct {
+	sync.Mutex
+	holders int // Requests holding or waiting for the lock
+}
+
+// NewCache creates an image cache that tries sources in the given order
+func NewCache(config Config, sources ...Source) (*Cache, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("image cache directory is required")
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultConfig(config.Dir).MaxSize
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create image cache directory: %w", err)
+	}
+
+	return &Cache{
+		config:  config,
+		sources: sources,
+		httpClient: &http.Client{
+			Timeout:   15 * time.Second,
+			Transport: metrics.InstrumentTransport("images", nil),
+		},
+		locks: make(map[string]*artistLock),
+	}, nil
+}
+
+// Get returns the cached image for an artist, fetching it from the sources when missing or stale.
+// A stale image is still returned if refreshing it fails.
+func (c *Cache) Get(ctx context.Context, artist *models.Artist, releases []models.ReleaseGroup) (*CachedImage, error) {
+	if artist == nil || artist.MBID == "" {
+		return nil, fmt.Errorf("artist MBID cannot be empty")
+	}
+
+	defer c.lock(artist.MBID)()
+
+	imagePath := c.imagePath(artist.MBID)
+	cached, fresh := c.stat(imagePath, c.config.TTL)
+	if fresh {
+		return cached, nil
+	}
+
+	// Skip the upstream lookups if we recently found nothing
+	if cached == nil {
+		if _, missFresh := c.stat(c.missPath(artist.MBID), c.config.FailureTTL); missFresh {
+			return nil, ErrNoImage
+		}
+	}
+
+	if err := c.fetch(ctx, artist, releases, imagePath); err != nil {
+		if cached != nil {
+			logger.WarnContext(ctx, "Image refresh failed, serving stale copy", "mbid", artist.MBID, "error", err)
+			return cached, nil
+		}
+		if errors.Is(err, ErrNoImage) {
+			c.markMiss(artist.MBID)
+		}
+		return nil, err
+	}
+
+	os.Remove(c.missPath(artist.MBID))
+
+	cached, _ = c.stat(imagePath, c.config.TTL)
+	if cached == nil {
+		return nil, fmt.Errorf("cached image disappeared for %s", artist.MBID)
+	}
+	return cached, nil
+}
+
+// fetch tries every candidate from every source until one can be stored
+func (c *Cache) fetch(ctx context.Context, artist *models.Artist, releases []models.ReleaseGroup, imagePath string) error {
+	var lastErr error
+
+	for _, source := range c.sources {
+		for _, candidate := range source.Candidates(artist, releases) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			img, err := c.download(ctx, candidate)
+			if err != nil {
+				lastErr = fmt.Errorf("%s: %w", candidate.Source, err)
+				continue
+			}
+
+			if err := c.store(resize(img, c.config.MaxSize), imagePath); err != nil {
+				return fmt.Errorf("failed to store image: %w", err)
+			}
+			return nil
+		}
+	}
+
+	if lastErr != nil {
+		logger.InfoContext(ctx, "No usable image", "mbid", artist.MBID, "last_error", lastErr)
+	}
+	return ErrNoImage
+}
+
+// download fetches and decodes a single candidate image
+func (c *Cache) download(ctx context.Context, candidate Candidate) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", candidate.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range candidate.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image request returned status %d", resp.StatusCode)
+	}
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" && !strings.HasPrefix(contentType, "image/") {
+		return nil, fmt.Errorf("unexpected content type %q", contentType)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if config.Width > maxSourceDimension || config.Height > maxSourceDimension {
+		return nil, fmt.Errorf("image of %dx%d exceeds %dx%d", config.Width, config.Height, maxSourceDimension, maxSourceDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	return img, nil
+}
+
+// store encodes the image as JPEG and atomically replaces the cached file
+func (c *Cache) store(img image.Image, imagePath string) error {
+	tmp, err := os.CreateTemp(c.config.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := jpeg.Encode(tmp, img, &jpeg.Options{Quality: 85}); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), imagePath)
+}
+
+// markMiss records that no image could be found for an artist
+func (c *Cache) markMiss(mbid string) {
+	if err := os.WriteFile(c.missPath(mbid), nil, 0644); err != nil {
+		logger.Error("Failed to record missing image", "mbid", mbid, "error", err)
+	}
+}
+
+// stat returns the cached file info and whether it is still within ttl
+func (c *Cache) stat(path string, ttl time.Duration) (*CachedImage, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	cached := &CachedImage{Path: path, ModTime: info.ModTime()}
+	return cached, time.Since(info.ModTime()) < ttl
+}
+
+// Invalidate removes any cached image for an artist
+func (c *Cache) Invalidate(mbid string) error {
+	for _, path := range []string{c.imagePath(mbid), c.missPath(mbid)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) imagePath(mbid string) string {
+	return filepath.Join(c.config.Dir, filepath.Base(mbid)+".jpg")
+}
+
+func (c *Cache) missPath(mbid string) string {
+	return filepath.Join(c.config.Dir, filepath.Base(mbid)+".none")
+}
+
+// lock takes the per-artist mutex so concurrent requests fetch only once, and returns
+// the function releasing it. The mutex is dropped when no request holds it, so the
+// map does not grow with every MBID ever requested.
+func (c *Cache) lock(mbid string) (unlock func()) {
+	c.locksMu.Lock()
+	lock, exists := c.locks[mbid]
+	if !exists {
+		lock = &artistLock{}
+		c.locks[mbid] = lock
+	}
+	lock.holders++
+	c.locksMu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		c.locksMu.Lock()
+		defer c.locksMu.Unlock()
+		if lock.holders--; lock.holders == 0 {
+			delete(c.locks, mbid)
+		}
+	}
+}
+
+// resize scales the image down so neither side exceeds maxSize
+func resize(src image.Image, maxSize int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSize && height <= maxSize {
+		return src
+	}
+
+	if width >= height {
+		height = height * maxSize / width
+		width = maxSize
+	} else {
+		width = width * maxSize / height
+		height = maxSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, max(width, 1), max(height, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
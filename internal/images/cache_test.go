@@ -0,0 +1,303 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gocommender/internal/models"
+)
+
+// stubSource is a local stand-in returning fixed candidates
+type stubSource struct {
+	name       string
+	candidates []Candidate
+}
+
+func (s *stubSource) Name() string { return s.name }
+
+func (s *stubSource) Candidates(artist *models.Artist, releases []models.ReleaseGroup) []Candidate {
+	return s.candidates
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newImageServer(t *testing.T, hits *int32) *httptest.Server {
+	t.Helper()
+	pngData := testPNG(t, 1000, 500)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		switch r.URL.Path {
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngData)
+		case "/auth.png":
+			if r.Header.Get("Authorization") != "Discogs token=secret" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngData)
+		case "/html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestCacheGetDownloadsAndResizes(t *testing.T) {
+	var hits int32
+	server := newImageServer(t, &hits)
+	defer server.Close()
+
+	source := &stubSource{name: "stub", candidates: []Candidate{
+		{URL: server.URL + "/missing.png", Source: "stub"},
+		{URL: server.URL + "/html", Source: "stub"},
+		{URL: server.URL + "/image.png", Source: "stub"},
+	}}
+
+	cache, err := NewCache(DefaultConfig(t.TempDir()), source)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	artist := &models.Artist{MBID: "artist-mbid", Name: "Test Artist"}
+
+	cached, err := cache.Get(context.Background(), artist, nil)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	file, err := os.Open(cached.Path)
+	if err != nil {
+		t.Fatalf("Failed to open cached image: %v", err)
+	}
+	defer file.Close()
+
+	img, err := jpeg.Decode(file)
+	if err != nil {
+		t.Fatalf("Cached image is not a JPEG: %v", err)
+	}
+	if img.Bounds().Dx() != 500 || img.Bounds().Dy() != 250 {
+		t.Errorf("Expected 500x250 thumbnail, got %dx%d", img.Bounds().Dx(), img.Bounds().Dy())
+	}
+
+	if hits != 3 {
+		t.Errorf("Expected 3 upstream requests, got %d", hits)
+	}
+
+	// Second lookup is served from disk
+	if _, err := cache.Get(context.Background(), artist, nil); err != nil {
+		t.Fatalf("Second Get failed: %v", err)
+	}
+	if hits != 3 {
+		t.Errorf("Expected cached lookup to skip upstream, got %d requests", hits)
+	}
+}
+
+func TestCacheGetConcurrent(t *testing.T) {
+	var hits int32
+	server := newImageServer(t, &hits)
+	defer server.Close()
+
+	source := &stubSource{name: "stub", candidates: []Candidate{{URL: server.URL + "/image.png", Source: "stub"}}}
+	cache, err := NewCache(DefaultConfig(t.TempDir()), source)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := cache.Get(context.Background(), &models.Artist{MBID: "artist-mbid"}, nil); err != nil {
+				t.Errorf("Get failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if hits != 1 {
+		t.Errorf("Expected concurrent requests to fetch once, got %d requests", hits)
+	}
+	if len(cache.locks) != 0 {
+		t.Errorf("Expected the artist locks to be released, %d left", len(cache.locks))
+	}
+}
+
+func TestCacheGetNoImage(t *testing.T) {
+	var hits int32
+	server := newImageServer(t, &hits)
+	defer server.Close()
+
+	source := &stubSource{name: "stub", candidates: []Candidate{
+		{URL: server.URL + "/missing.png", Source: "stub"},
+	}}
+
+	cache, err := NewCache(DefaultConfig(t.TempDir()), source)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	artist := &models.Artist{MBID: "artist-mbid", Name: "Test Artist"}
+
+	_, err = cache.Get(context.Background(), artist, nil)
+	if !errors.Is(err, ErrNoImage) {
+		t.Fatalf("Expected ErrNoImage, got %v", err)
+	}
+
+	// The miss is remembered and upstream is not asked again
+	_, err = cache.Get(context.Background(), artist, nil)
+	if !errors.Is(err, ErrNoImage) {
+		t.Fatalf("Expected ErrNoImage, got %v", err)
+	}
+	if hits != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", hits)
+	}
+
+	// Invalidation clears the remembered miss
+	if err := cache.Invalidate(artist.MBID); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	cache.Get(context.Background(), artist, nil)
+	if hits != 2 {
+		t.Errorf("Expected upstream to be retried after invalidation, got %d requests", hits)
+	}
+}
+
+func TestCacheRejectsOversizedImages(t *testing.T) {
+	// A narrow strip compresses to little but declares a width above the limit
+	pngData := testPNG(t, maxSourceDimension+1, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(pngData)
+	}))
+	defer server.Close()
+
+	source := &stubSource{name: "stub", candidates: []Candidate{{URL: server.URL + "/wide.png", Source: "stub"}}}
+	cache, err := NewCache(DefaultConfig(t.TempDir()), source)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	if _, err := cache.download(context.Background(), source.candidates[0]); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("Expected the image to be rejected by its dimensions, got %v", err)
+	}
+	if _, err := cache.Get(context.Background(), &models.Artist{MBID: "artist-mbid"}, nil); !errors.Is(err, ErrNoImage) {
+		t.Errorf("Expected ErrNoImage, got %v", err)
+	}
+}
+
+func TestCacheGetSendsSourceHeaders(t *testing.T) {
+	var hits int32
+	server := newImageServer(t, &hits)
+	defer server.Close()
+
+	artist := &models.Artist{MBID: "artist-mbid", Name: "Test Artist", ImageURL: server.URL + "/auth.png"}
+
+	header := http.Header{}
+	header.Set("Authorization", "Discogs token=secret")
+	source := &stubSource{name: "discogs", candidates: []Candidate{
+		{URL: artist.ImageURL, Source: "discogs", Header: header},
+	}}
+
+	cache, err := NewCache(DefaultConfig(t.TempDir()), source)
+	if err != nil {
+		t.Fatalf("Failed to create cache: %v", err)
+	}
+
+	if _, err := cache.Get(context.Background(), artist, nil); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+}
+
+func TestCoverArtArchiveSourceCandidates(t *testing.T) {
+	source := NewCoverArtArchiveSource("http://caa.local/")
+	releases := []models.ReleaseGroup{
+		{MBID: "single", PrimaryType: models.ReleaseTypeSingle},
+		{MBID: "ep", PrimaryType: models.ReleaseTypeEP},
+		{MBID: "live", PrimaryType: models.ReleaseTypeAlbum, SecondaryTypes: models.ReleaseTypes{"Live"}},
+		{MBID: "album", PrimaryType: models.ReleaseTypeAlbum},
+	}
+
+	candidates := source.Candidates(&models.Artist{MBID: "artist-mbid"}, releases)
+
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 candidates, got %d", len(candidates))
+	}
+	if candidates[0].URL != "http://caa.local/release-group/album/front-500" {
+		t.Errorf("Expected studio album first, got %s", candidates[0].URL)
+	}
+	if candidates[1].URL != "http://caa.local/release-group/ep/front-500" {
+		t.Errorf("Expected EP second, got %s", candidates[1].URL)
+	}
+}
+
+func TestDiscogsSourceCandidates(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		imageURL string
+		expected int
+	}{
+		{"discogs image", "token", "https://i.discogs.com/abc.jpg", 1},
+		{"no token", "", "https://i.discogs.com/abc.jpg", 0},
+		{"other host", "token", "https://lastfm.freetls.fastly.net/i/u/abc.png", 0},
+		{"empty url", "token", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewDiscogsSource(tt.token)
+			candidates := source.Candidates(&models.Artist{ImageURL: tt.imageURL}, nil)
+			if len(candidates) != tt.expected {
+				t.Fatalf("Expected %d candidates, got %d", tt.expected, len(candidates))
+			}
+			if tt.expected > 0 && candidates[0].Header.Get("Authorization") != "Discogs token="+tt.token {
+				t.Errorf("Expected Discogs authorization header, got %q", candidates[0].Header.Get("Authorization"))
+			}
+		})
+	}
+}
+
+func TestLastFMSourceSkipsPlaceholder(t *testing.T) {
+	source := NewLastFMSource()
+
+	placeholder := &models.Artist{ImageURL: "https://lastfm.freetls.fastly.net/i/u/300x300/2a96cbd8b46e442fc41c2b86b821562f.png"}
+	if candidates := source.Candidates(placeholder, nil); len(candidates) != 0 {
+		t.Errorf("Expected placeholder image to be skipped, got %d candidates", len(candidates))
+	}
+
+	real := &models.Artist{ImageURL: "https://lastfm.freetls.fastly.net/i/u/300x300/0123456789abcdef.png"}
+	if candidates := source.Candidates(real, nil); len(candidates) != 1 {
+		t.Errorf("Expected 1 candidate, got %d", len(candidates))
+	}
+}
@@ -0,0 +1,153 @@
+package images
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gocommender/internal/models"
+)
+
+// Candidate is a single image URL that a source proposes for an artist
+type Candidate struct {
+	URL    string
+	Source string
+	Header http.Header // Extra request headers needed to download the image
+}
+
+// Source proposes artwork candidates for an artist.
+// Sources only build URLs; the Cache downloads them in order until one succeeds.
+type Source interface {
+	Name() string
+	Candidates(artist *models.Artist, releases []models.ReleaseGroup) []Candidate
+}
+
+// CoverArtArchiveSource proposes album covers from the Cover Art Archive via release groups
+type CoverArtArchiveSource struct {
+	baseURL    string
+	maxCovers  int
+	coverWidth int
+}
+
+// NewCoverArtArchiveSource creates a Cover Art Archive source.
+// baseURL may be empty to use the public archive.
+func NewCoverArtArchiveSource(baseURL string) *CoverArtArchiveSource {
+	if baseURL == "" {
+		baseURL = "https://coverartarchive.org"
+	}
+	return &CoverArtArchiveSource{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		maxCovers:  3,
+		coverWidth: 500,
+	}
+}
+
+// Name returns the source identifier
+func (s *CoverArtArchiveSource) Name() string {
+	return "coverartarchive"
+}
+
+// Candidates returns front covers of studio albums first, then EPs
+func (s *CoverArtArchiveSource) Candidates(artist *models.Artist, releases []models.ReleaseGroup) []Candidate {
+	candidates := make([]Candidate, 0, s.maxCovers)
+
+	add := func(match func(models.ReleaseGroup) bool) {
+		for _, rg := range releases {
+			if len(candidates) >= s.maxCovers {
+				return
+			}
+			if rg.MBID == "" || !match(rg) {
+				continue
+			}
+			candidates = append(candidates, Candidate{
+				URL:    fmt.Sprintf("%s/release-group/%s/front-%d", s.baseURL, rg.MBID, s.coverWidth),
+				Source: s.Name(),
+			})
+		}
+	}
+
+	add(func(rg models.ReleaseGroup) bool { return rg.IsStudioAlbum() })
+	add(func(rg models.ReleaseGroup) bool { return rg.PrimaryType == models.ReleaseTypeEP })
+
+	return candidates
+}
+
+// DiscogsSource proposes the Discogs artist image found during enrichment.
+// Discogs requires an authenticated request to download images.
+type DiscogsSource struct {
+	token     string
+	userAgent string
+}
+
+// NewDiscogsSource creates a Discogs image source
+func NewDiscogsSource(token string) *DiscogsSource {
+	return &DiscogsSource{
+		token:     token,
+		userAgent: "GoCommender/1.0 +https://github.com/lepinkainen/gocommender",
+	}
+}
+
+// Name returns the source identifier
+func (s *DiscogsSource) Name() string {
+	return "discogs"
+}
+
+// Candidates returns the artist image if it is hosted by Discogs
+func (s *DiscogsSource) Candidates(artist *models.Artist, releases []models.ReleaseGroup) []Candidate {
+	if s.token == "" || !hostMatches(artist.ImageURL, "discogs.com") {
+		return nil
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Discogs token="+s.token)
+	header.Set("User-Agent", s.userAgent)
+
+	return []Candidate{{URL: artist.ImageURL, Source: s.Name(), Header: header}}
+}
+
+// lastFMPlaceholderHash identifies the grey star image Last.fm returns for all artists
+const lastFMPlaceholderHash = "2a96cbd8b46e442fc41c2b86b821562f"
+
+// LastFMSource proposes the Last.fm artist image, skipping the placeholder star
+type LastFMSource struct{}
+
+// NewLastFMSource creates a Last.fm image source
+func NewLastFMSource() *LastFMSource {
+	return &LastFMSource{}
+}
+
+// Name returns the source identifier
+func (s *LastFMSource) Name() string {
+	return "lastfm"
+}
+
+// Candidates returns the artist image if it is a real Last.fm image
+func (s *LastFMSource) Candidates(artist *models.Artist, releases []models.ReleaseGroup) []Candidate {
+	if !hostMatches(artist.ImageURL, "last.fm", "lastfm.freetls.fastly.net") {
+		return nil
+	}
+	if strings.Contains(artist.ImageURL, lastFMPlaceholderHash) {
+		return nil
+	}
+
+	return []Candidate{{URL: artist.ImageURL, Source: s.Name()}}
+}
+
+// hostMatches reports whether rawURL's host equals or is a subdomain of any of the given domains
+func hostMatches(rawURL string, domains ...string) bool {
+	if rawURL == "" {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
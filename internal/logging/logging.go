@@ -0,0 +1,171 @@
+// Package logging configures slog with a level per component and correlates
+// log lines with the request and trace they belong to.
+//
+// Each component logs through its own logger, usually a package variable:
+//
+//	var logger = logging.For("refresh")
+//	logger.InfoContext(ctx, "Refreshing expired artists", "count", n)
+//
+// Records logged with a context carry its request_id and trace_id.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"gocommender/internal/tracing"
+)
+
+// Config selects the output format and levels
+type Config struct {
+	Format string                // json or text
+	Level  slog.Level            // Level of components without their own
+	Levels map[string]slog.Level // Levels by component name
+}
+
+// settings is the active configuration, swapped atomically by Setup
+type settings struct {
+	base   slog.Handler
+	level  slog.Level
+	levels map[string]slog.Level
+}
+
+var current atomic.Pointer[settings]
+
+func init() {
+	current.Store(&settings{
+		base:  slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		level: slog.LevelInfo,
+	})
+}
+
+// Setup writes logs to w in the configured format. The standard log package and
+// slog's default logger are routed through it at info level, component "default".
+// Loggers returned by For before Setup pick up the new configuration.
+func Setup(w io.Writer, config Config) {
+	options := &slog.HandlerOptions{Level: slog.LevelDebug} // Levels are checked per component
+	var base slog.Handler
+	if config.Format == "text" {
+		base = slog.NewTextHandler(w, options)
+	} else {
+		base = slog.NewJSONHandler(w, options)
+	}
+
+	current.Store(&settings{base: base, level: config.Level, levels: config.Levels})
+	slog.SetDefault(For("default"))
+	log.SetFlags(0) // slog adds the time
+}
+
+// ParseLevel parses debug, info, warn or error
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return level, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// ParseLevels parses per-component levels such as "refresh=debug,api=warn"
+func ParseLevels(spec string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		component, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(component) == "" {
+			return nil, fmt.Errorf("invalid component level %q, expected component=level", entry)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		levels[strings.TrimSpace(component)] = level
+	}
+	return levels, nil
+}
+
+// For returns the logger of a component
+func For(component string) *slog.Logger {
+	return slog.New(&handler{component: component})
+}
+
+// handler filters by the component's level and adds correlation IDs before
+// passing records to the configured base handler
+type handler struct {
+	component string
+	wrap      []func(slog.Handler) slog.Handler // WithAttrs and WithGroup calls, applied in order
+}
+
+// Enabled implements slog.Handler
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	s := current.Load()
+	if componentLevel, ok := s.levels[h.component]; ok {
+		return level >= componentLevel
+	}
+	return level >= s.level
+}
+
+// Handle implements slog.Handler
+func (h *handler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			record.AddAttrs(slog.String("request_id", id))
+		}
+		if id := tracing.TraceIDFromContext(ctx); id != "" {
+			record.AddAttrs(slog.String("trace_id", id))
+		}
+	}
+
+	next := current.Load().base.WithAttrs([]slog.Attr{slog.String("component", h.component)})
+	for _, wrap := range h.wrap {
+		next = wrap(next)
+	}
+	return next.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+// WithGroup implements slog.Handler
+func (h *handler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *handler) with(wrap func(slog.Handler) slog.Handler) slog.Handler {
+	wraps := append(append([]func(slog.Handler) slog.Handler(nil), h.wrap...), wrap)
+	return &handler{component: h.component, wrap: wraps}
+}
+
+// requestIDKey stores the request ID in a context
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying a request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of a context, or "" if there is none
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID creates a random request ID such as req_1f0c6a9e2b7d4c85
+func NewRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return "req_" + hex.EncodeToString(b[:])
+}
@@ -0,0 +1,129 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"gocommender/internal/tracing"
+)
+
+// capture sets up JSON logging into a buffer for the duration of a test
+func capture(t *testing.T, config Config) *bytes.Buffer {
+	t.Helper()
+	previous, previousDefault := current.Load(), slog.Default()
+	t.Cleanup(func() {
+		current.Store(previous)
+		slog.SetDefault(previousDefault)
+	})
+
+	var out bytes.Buffer
+	config.Format = "json"
+	Setup(&out, config)
+	return &out
+}
+
+// lines decodes the JSON lines written to out
+func lines(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestComponentLevels(t *testing.T) {
+	out := capture(t, Config{
+		Level:  slog.LevelInfo,
+		Levels: map[string]slog.Level{"refresh": slog.LevelDebug, "api": slog.LevelWarn},
+	})
+
+	refresh := For("refresh")
+	refresh.Debug("refresh debug")
+	For("api").Info("api info") // Below the api level
+	For("api").Warn("api warn")
+	For("images").Debug("images debug") // Below the default level
+	For("images").Info("images info")
+
+	records := lines(t, out)
+	var messages []string
+	for _, record := range records {
+		messages = append(messages, record["msg"].(string))
+	}
+	if strings.Join(messages, ",") != "refresh debug,api warn,images info" {
+		t.Errorf("Unexpected messages %v", messages)
+	}
+	if records[0]["component"] != "refresh" || records[0]["level"] != "DEBUG" {
+		t.Errorf("Unexpected record %v", records[0])
+	}
+}
+
+func TestCorrelationIDs(t *testing.T) {
+	out := capture(t, Config{Level: slog.LevelInfo})
+
+	ctx := WithRequestID(context.Background(), "req_test")
+	ctx, span := tracing.Start(ctx, "test")
+	defer span.End()
+
+	For("api").With("user", "alice").InfoContext(ctx, "with context", "status", 200)
+	For("api").Info("without context")
+	slog.InfoContext(ctx, "default logger")
+
+	records := lines(t, out)
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+	first := records[0]
+	if first["request_id"] != "req_test" || first["trace_id"] != span.TraceID() {
+		t.Errorf("Expected correlation IDs, got %v", first)
+	}
+	if first["user"] != "alice" || first["status"] != float64(200) || first["component"] != "api" {
+		t.Errorf("Expected attributes to be kept, got %v", first)
+	}
+	if _, ok := records[1]["request_id"]; ok {
+		t.Errorf("Expected no request ID without a context, got %v", records[1])
+	}
+	if records[2]["component"] != "default" || records[2]["request_id"] != "req_test" {
+		t.Errorf("Expected the default logger to be routed through Setup, got %v", records[2])
+	}
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels(" refresh=debug, api=WARN,,")
+	if err != nil {
+		t.Fatalf("ParseLevels failed: %v", err)
+	}
+	if len(levels) != 2 || levels["refresh"] != slog.LevelDebug || levels["api"] != slog.LevelWarn {
+		t.Errorf("Unexpected levels %v", levels)
+	}
+
+	for _, invalid := range []string{"refresh", "=debug", "refresh=loud"} {
+		if _, err := ParseLevels(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected an unknown level to be rejected")
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	id := NewRequestID()
+	if !strings.HasPrefix(id, "req_") || len(id) != 20 || id == NewRequestID() {
+		t.Errorf("Unexpected request ID %q", id)
+	}
+	if RequestID(context.Background()) != "" {
+		t.Error("Expected no request ID in an empty context")
+	}
+}
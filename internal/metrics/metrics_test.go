@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	registry := NewRegistry()
+
+	counter := registry.NewCounter("test_requests_total", "Requests.\nSecond line.", "route", "code")
+	counter.Inc("/b", "200")
+	counter.Add(2, "/a", "500")
+	counter.Inc("/quote\"d\\", "200")
+
+	gauge := registry.NewGauge("test_queue_depth", "Queue depth.")
+	gauge.Set(5)
+	gauge.Add(-2)
+
+	registry.NewGaugeFunc("test_answer", "The answer.", func() float64 { return 42 })
+
+	histogram := registry.NewHistogram("test_duration_seconds", "Duration.", []float64{1, 0.1}, "route")
+	histogram.Observe(0.05, "/a")
+	histogram.Observe(0.5, "/a")
+	histogram.Observe(3, "/a")
+
+	var out strings.Builder
+	if err := registry.Write(&out); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	expected := `# HELP test_answer The answer.
+# TYPE test_answer gauge
+test_answer 42
+# HELP test_duration_seconds Duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/a",le="0.1"} 1
+test_duration_seconds_bucket{route="/a",le="1"} 2
+test_duration_seconds_bucket{route="/a",le="+Inf"} 3
+test_duration_seconds_sum{route="/a"} 3.55
+test_duration_seconds_count{route="/a"} 3
+# HELP test_queue_depth Queue depth.
+# TYPE test_queue_depth gauge
+test_queue_depth 3
+# HELP test_requests_total Requests.\nSecond line.
+# TYPE test_requests_total counter
+test_requests_total{route="/a",code="500"} 2
+test_requests_total{route="/b",code="200"} 1
+test_requests_total{route="/quote\"d\\",code="200"} 1
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected exposition:\n%s\nwant:\n%s", out.String(), expected)
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounter("test_total", "Test.", "label")
+
+	expectPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected %s to panic", name)
+			}
+		}()
+		fn()
+	}
+
+	expectPanic("duplicate registration", func() { registry.NewGauge("test_total", "Again.") })
+	expectPanic("wrong label count", func() { counter.Inc() })
+	expectPanic("negative counter increment", func() { counter.Add(-1, "x") })
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestInstrumentTransport(t *testing.T) {
+	status := http.StatusOK
+	var failure error
+	transport := InstrumentTransport("test-upstream", roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if failure != nil {
+			return nil, failure
+		}
+		return &http.Response{StatusCode: status, Body: http.NoBody, Request: req}, nil
+	}))
+
+	send := func() {
+		req := httptest.NewRequest("GET", "http://upstream.test/", nil)
+		if resp, err := transport.RoundTrip(req); err == nil {
+			resp.Body.Close()
+		}
+	}
+
+	send()
+	status = http.StatusBadGateway
+	send()
+	failure = errors.New("connection refused")
+	send()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %q", ct)
+	}
+
+	body := w.Body.String()
+	for _, line := range []string{
+		`gocommender_upstream_requests_total{upstream="test-upstream",code="200"} 1`,
+		`gocommender_upstream_requests_total{upstream="test-upstream",code="502"} 1`,
+		`gocommender_upstream_requests_total{upstream="test-upstream",code="error"} 1`,
+		`gocommender_upstream_errors_total{upstream="test-upstream"} 2`,
+		`gocommender_upstream_request_duration_seconds_count{upstream="test-upstream"} 3`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected metrics to contain %q", line)
+		}
+	}
+}
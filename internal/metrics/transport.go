@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Upstream call metrics shared by every instrumented client
+var (
+	upstreamRequests = NewCounter("gocommender_upstream_requests_total",
+		"Requests to upstream services by response status code.", "upstream", "code")
+	upstreamErrors = NewCounter("gocommender_upstream_errors_total",
+		"Upstream requests that failed in transport or returned a 5xx status.", "upstream")
+	upstreamDuration = NewHistogram("gocommender_upstream_request_duration_seconds",
+		"Latency of upstream requests.", nil, "upstream")
+)
+
+// instrumentedTransport records metrics for each round trip to an upstream service
+type instrumentedTransport struct {
+	upstream string
+	next     http.RoundTripper
+}
+
+// InstrumentTransport wraps a transport, http.DefaultTransport if nil, to count
+// requests, errors and latency of an upstream such as plex or musicbrainz
+func InstrumentTransport(upstream string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &instrumentedTransport{upstream: upstream, next: next}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	upstreamDuration.Observe(time.Since(start).Seconds(), t.upstream)
+
+	if err != nil {
+		upstreamRequests.Inc(t.upstream, "error")
+		upstreamErrors.Inc(t.upstream)
+		return nil, err
+	}
+
+	upstreamRequests.Inc(t.upstream, strconv.Itoa(resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		upstreamErrors.Inc(t.upstream)
+	}
+	return resp, nil
+}
@@ -0,0 +1,93 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gocommender/internal/dialect"
+)
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// legacyColumns were added to the artists table by releases that predate migrations
+var legacyColumns = []struct {
+	name, definition string
+}{
+	{"listeners", "INTEGER DEFAULT 0"},
+	{"playcount", "INTEGER DEFAULT 0"},
+	{"members_json", "TEXT DEFAULT '[]'"},
+	{"groups_json", "TEXT DEFAULT '[]'"},
+	{"labels_json", "TEXT DEFAULT '[]'"},
+}
+
+// adoptLegacySchema brings an artists table created before migrations up to the 0001 layout.
+// New databases have no artists table yet and are left to the migration itself.
+func adoptLegacySchema(q queryer) error {
+	exists, err := tableExists(q, dialect.SQLite, "artists")
+	if err != nil || !exists {
+		return err
+	}
+
+	for _, col := range legacyColumns {
+		exists, err := columnExists(q, "artists", col.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		if _, err := q.Exec(fmt.Sprintf("ALTER TABLE artists ADD COLUMN %s %s", col.name, col.definition)); err != nil {
+			return fmt.Errorf("failed to add column artists.%s: %w", col.name, err)
+		}
+	}
+
+	return nil
+}
+
+// tableExists checks the catalog of the dialect for a table
+func tableExists(q queryer, d dialect.Dialect, table string) (bool, error) {
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	if d == dialect.Postgres {
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+	}
+
+	var count int
+	err := q.QueryRow(query, table).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check table %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// columnExists checks the table definition for a column
+func columnExists(q queryer, table, column string) (bool, error) {
+	rows, err := q.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to read table info for %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
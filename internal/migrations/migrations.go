@@ -0,0 +1,228 @@
+// Package migrations applies the numbered SQL schema migrations embedded in this directory.
+//
+// Migrations are named NNNN_description.sql and applied in order, each in its own transaction.
+// Every dialect has its own directory (sqlite/, postgres/) with the same version numbers.
+// Applied versions are recorded in the schema_migrations table. Migrations are never edited
+// once released; schema changes go into a new file with the next number in every directory.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gocommender/internal/dialect"
+	"gocommender/internal/logging"
+)
+
+//go:embed sqlite/*.sql postgres/*.sql
+var files embed.FS
+
+// fileName matches migration files and captures the version and description
+var fileName = regexp.MustCompile(`^(\d{4})_([a-z0-9_]+)\.sql$`)
+
+// Migration is a single numbered schema change
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Status describes whether a migration has been applied to a database
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+var logger = logging.For("migrations")
+
+// All returns the embedded migrations of a dialect ordered by version
+func All(d dialect.Dialect) ([]Migration, error) {
+	dir := string(d)
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q: %w", d, err)
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		content, err := files.ReadFile(dir + "/" + entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: match[2], SQL: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies all pending migrations and returns the ones that were applied
+func Up(db *sql.DB, d dialect.Dialect) ([]Migration, error) {
+	migrations, err := All(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ensureMigrationsTable(db, d); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := latestVersion(migrations)
+	for version := range applied {
+		if version > latest {
+			return nil, fmt.Errorf("database schema version %d is newer than this build (latest %d)", version, latest)
+		}
+	}
+
+	var ran []Migration
+	for _, migration := range migrations {
+		if _, done := applied[migration.Version]; done {
+			continue
+		}
+
+		if err := apply(db, d, migration); err != nil {
+			return ran, err
+		}
+		logger.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+		ran = append(ran, migration)
+	}
+
+	return ran, nil
+}
+
+// GetStatus lists every embedded migration with its applied state, without changing the database
+func GetStatus(db *sql.DB, d dialect.Dialect) ([]Status, error) {
+	migrations, err := All(d)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time)
+	exists, err := tableExists(db, d, "schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		if applied, err = appliedVersions(db); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// apply runs one migration and records it in the same transaction
+func apply(db *sql.DB, d dialect.Dialect, migration Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %04d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	// SQLite databases created before migrations existed may lack columns the first migration expects
+	if migration.Version == 1 && d == dialect.SQLite {
+		if err := adoptLegacySchema(tx); err != nil {
+			return fmt.Errorf("failed to adopt existing schema: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(migration.SQL); err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if _, err := tx.Exec(
+		d.Rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+		migration.Version, migration.Name, time.Now(),
+	); err != nil {
+		return fmt.Errorf("failed to record migration %04d: %w", migration.Version, err)
+	}
+
+	return tx.Commit()
+}
+
+// ensureMigrationsTable creates the bookkeeping table
+func ensureMigrationsTable(db *sql.DB, d dialect.Dialect) error {
+	timestamp := "DATETIME"
+	if d == dialect.Postgres {
+		timestamp = "TIMESTAMPTZ"
+	}
+
+	_, err := db.Exec(`
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at ` + timestamp + ` NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// appliedVersions returns the applied migration versions with their timestamps
+func appliedVersions(db *sql.DB) (map[int]time.Time, error) {
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// latestVersion returns the highest embedded migration version
+func latestVersion(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
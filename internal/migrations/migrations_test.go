@@ -0,0 +1,171 @@
+package migrations
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gocommender/internal/dialect"
+
+	_ "modernc.org/sqlite"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "migrations.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestAllMigrations(t *testing.T) {
+	migrations, err := All(dialect.SQLite)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+
+	// Versions must be contiguous so a gap never hides a missing file
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Errorf("Expected migration %d to have version %d, got %d", i, i+1, migration.Version)
+		}
+		if strings.TrimSpace(migration.SQL) == "" {
+			t.Errorf("Migration %04d_%s is empty", migration.Version, migration.Name)
+		}
+	}
+
+	// Every dialect carries the same migrations
+	postgres, err := All(dialect.Postgres)
+	if err != nil {
+		t.Fatalf("Failed to load Postgres migrations: %v", err)
+	}
+	if len(postgres) != len(migrations) {
+		t.Fatalf("Expected %d Postgres migrations, got %d", len(migrations), len(postgres))
+	}
+	for i := range migrations {
+		if postgres[i].Version != migrations[i].Version || postgres[i].Name != migrations[i].Name {
+			t.Errorf("Postgres migration %04d_%s does not match %04d_%s",
+				postgres[i].Version, postgres[i].Name, migrations[i].Version, migrations[i].Name)
+		}
+	}
+}
+
+func TestUpFreshDatabase(t *testing.T) {
+	db := openTestDB(t)
+
+	statuses, err := GetStatus(db, dialect.SQLite)
+	if err != nil {
+		t.Fatalf("Failed to get status: %v", err)
+	}
+	for _, status := range statuses {
+		if status.Applied {
+			t.Errorf("Expected %04d to be pending on a new database", status.Version)
+		}
+	}
+	if exists, _ := tableExists(db, dialect.SQLite, "schema_migrations"); exists {
+		t.Error("Expected GetStatus not to create the migrations table")
+	}
+
+	applied, err := Up(db, dialect.SQLite)
+	if err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	if len(applied) != len(statuses) {
+		t.Errorf("Expected %d applied migrations, got %d", len(statuses), len(applied))
+	}
+
+	for _, table := range []string{"artists", "release_groups", "artist_similarity", "artist_aliases", "upstream_responses"} {
+		if exists, _ := tableExists(db, dialect.SQLite, table); !exists {
+			t.Errorf("Expected table %s to exist", table)
+		}
+	}
+
+	// Running again is a no-op
+	applied, err = Up(db, dialect.SQLite)
+	if err != nil {
+		t.Fatalf("Failed to re-run migrations: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("Expected no migrations on second run, got %d", len(applied))
+	}
+
+	statuses, _ = GetStatus(db, dialect.SQLite)
+	for _, status := range statuses {
+		if !status.Applied || status.AppliedAt == nil {
+			t.Errorf("Expected %04d to be applied", status.Version)
+		}
+	}
+}
+
+func TestUpAdoptsLegacySchema(t *testing.T) {
+	db := openTestDB(t)
+
+	// Layout created by releases before listeners and Discogs credits were stored
+	_, err := db.Exec(`
+CREATE TABLE artists (
+    mbid TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    verified_json TEXT DEFAULT '{}',
+    album_count INTEGER DEFAULT 0,
+    years_active TEXT DEFAULT '',
+    description TEXT DEFAULT '',
+    genres_json TEXT DEFAULT '[]',
+    country TEXT DEFAULT '',
+    image_url TEXT DEFAULT '',
+    external_urls_json TEXT DEFAULT '{}',
+    last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
+    cache_expiry DATETIME NOT NULL
+);
+INSERT INTO artists (mbid, name, cache_expiry) VALUES ('mbid-1', 'Slowdive', '2030-01-01');
+`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	if _, err := Up(db, dialect.SQLite); err != nil {
+		t.Fatalf("Failed to migrate legacy database: %v", err)
+	}
+
+	for _, column := range legacyColumns {
+		if exists, _ := columnExists(db, "artists", column.name); !exists {
+			t.Errorf("Expected column %s to be added", column.name)
+		}
+	}
+
+	var name string
+	var listeners int
+	if err := db.QueryRow("SELECT name, listeners FROM artists WHERE mbid = 'mbid-1'").Scan(&name, &listeners); err != nil {
+		t.Fatalf("Expected existing artist to survive migration: %v", err)
+	}
+	if name != "Slowdive" || listeners != 0 {
+		t.Errorf("Unexpected artist row %q, %d", name, listeners)
+	}
+
+	var indexed int
+	if err := db.QueryRow("SELECT COUNT(*) FROM artist_search WHERE artist_search MATCH 'slowdive'").Scan(&indexed); err != nil || indexed != 1 {
+		t.Errorf("Expected existing artist in the search index, got %d, %v", indexed, err)
+	}
+}
+
+func TestUpRejectsNewerDatabase(t *testing.T) {
+	db := openTestDB(t)
+
+	if _, err := Up(db, dialect.SQLite); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, 'future', CURRENT_TIMESTAMP)"); err != nil {
+		t.Fatalf("Failed to record future migration: %v", err)
+	}
+
+	if _, err := Up(db, dialect.SQLite); err == nil || !strings.Contains(err.Error(), "newer than this build") {
+		t.Errorf("Expected newer schema error, got %v", err)
+	}
+}
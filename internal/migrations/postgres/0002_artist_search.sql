@@ -0,0 +1,39 @@
+-- Full-text document over artist names, aliases, descriptions and genres.
+-- Kept up to date by triggers on artists and artist_aliases.
+
+ALTER TABLE artists ADD COLUMN IF NOT EXISTS search_document TSVECTOR;
+
+CREATE OR REPLACE FUNCTION artist_search_document() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_document :=
+        setweight(to_tsvector('simple', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(
+            (SELECT string_agg(alias, ' ') FROM artist_aliases WHERE artist_mbid = NEW.mbid), '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.genres_json, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.description, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS artist_search_document ON artists;
+CREATE TRIGGER artist_search_document BEFORE INSERT OR UPDATE ON artists
+    FOR EACH ROW EXECUTE FUNCTION artist_search_document();
+
+-- Touching the artist row recomputes its document with the current aliases
+CREATE OR REPLACE FUNCTION artist_search_aliases() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE artists SET search_document = NULL
+    WHERE mbid = CASE WHEN TG_OP = 'DELETE' THEN OLD.artist_mbid ELSE NEW.artist_mbid END;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS artist_search_aliases ON artist_aliases;
+CREATE TRIGGER artist_search_aliases AFTER INSERT OR DELETE ON artist_aliases
+    FOR EACH ROW EXECUTE FUNCTION artist_search_aliases();
+
+-- Index artists cached before this migration
+UPDATE artists SET search_document = NULL;
+
+CREATE INDEX IF NOT EXISTS idx_artists_search ON artists USING GIN (search_document);
+CREATE INDEX IF NOT EXISTS idx_country ON artists(country);
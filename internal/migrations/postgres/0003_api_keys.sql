@@ -0,0 +1,12 @@
+-- API keys for the HTTP API. The secret itself is never stored, only its SHA-256.
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    prefix TEXT NOT NULL,                    -- First characters of the key, shown by `apikey list`
+    key_hash TEXT NOT NULL UNIQUE,           -- Hex SHA-256 of the full key
+    role TEXT NOT NULL,                      -- read-only, recommend or admin
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
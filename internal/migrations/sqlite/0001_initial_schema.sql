@@ -0,0 +1,76 @@
+-- Initial schema: artists with discography, similarity graph, aliases and raw upstream responses.
+-- Statements use IF NOT EXISTS so databases created before migrations can adopt it.
+
+CREATE TABLE IF NOT EXISTS artists (
+    mbid TEXT PRIMARY KEY,                    -- MusicBrainz ID
+    name TEXT NOT NULL,
+    verified_json TEXT DEFAULT '{}',         -- JSON: {"discogs": true, "musicbrainz": true, "lastfm": false}
+    album_count INTEGER DEFAULT 0,
+    years_active TEXT DEFAULT '',
+    description TEXT DEFAULT '',
+    genres_json TEXT DEFAULT '[]',           -- JSON array: ["rock", "alternative"]
+    country TEXT DEFAULT '',
+    image_url TEXT DEFAULT '',
+    external_urls_json TEXT DEFAULT '{}',    -- JSON: {"discogs": "url", "musicbrainz": "url"}
+    listeners INTEGER DEFAULT 0,             -- Last.fm listener count
+    playcount INTEGER DEFAULT 0,             -- Last.fm scrobble count
+    members_json TEXT DEFAULT '[]',          -- JSON array: [{"name": "...", "active": true}]
+    groups_json TEXT DEFAULT '[]',           -- JSON array of groups the artist played in
+    labels_json TEXT DEFAULT '[]',           -- JSON array: [{"name": "Creation Records", "releases": 4}]
+    last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
+    cache_expiry DATETIME NOT NULL
+);
+
+-- Indexes for performance
+CREATE INDEX IF NOT EXISTS idx_cache_expiry ON artists(cache_expiry);
+CREATE INDEX IF NOT EXISTS idx_last_updated ON artists(last_updated);
+CREATE INDEX IF NOT EXISTS idx_name ON artists(name);
+CREATE INDEX IF NOT EXISTS idx_verified ON artists(verified_json);
+CREATE INDEX IF NOT EXISTS idx_listeners ON artists(listeners);
+
+CREATE TABLE IF NOT EXISTS release_groups (
+    mbid TEXT NOT NULL,                       -- MusicBrainz release group ID
+    artist_mbid TEXT NOT NULL,
+    title TEXT NOT NULL,
+    primary_type TEXT DEFAULT '',            -- Album, EP, Single, Broadcast, Other
+    secondary_types_json TEXT DEFAULT '[]',  -- JSON array: ["Compilation", "Live"]
+    first_release_date TEXT DEFAULT '',
+    year INTEGER DEFAULT 0,
+    PRIMARY KEY (artist_mbid, mbid)
+);
+
+CREATE INDEX IF NOT EXISTS idx_release_groups_artist ON release_groups(artist_mbid, year);
+
+CREATE TABLE IF NOT EXISTS artist_similarity (
+    artist_mbid TEXT NOT NULL,               -- Artist the edge starts from
+    similar_name TEXT NOT NULL,
+    similar_mbid TEXT DEFAULT '',            -- Empty when the source has no MBID for the neighbour
+    match REAL DEFAULT 0,                    -- Similarity weight 0..1
+    source TEXT NOT NULL,                    -- lastfm, musicbrainz
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (artist_mbid, source, similar_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_similarity_similar ON artist_similarity(similar_mbid);
+
+CREATE TABLE IF NOT EXISTS artist_aliases (
+    artist_mbid TEXT NOT NULL,
+    alias TEXT NOT NULL,                     -- Name variation or alternative spelling
+    source TEXT NOT NULL,                    -- discogs
+    PRIMARY KEY (artist_mbid, alias)
+);
+
+CREATE INDEX IF NOT EXISTS idx_aliases_alias ON artist_aliases(alias COLLATE NOCASE);
+
+CREATE TABLE IF NOT EXISTS upstream_responses (
+    source TEXT NOT NULL,                    -- musicbrainz, discogs, lastfm, wikipedia
+    key TEXT NOT NULL,                       -- Request path and query without credentials
+    status_code INTEGER DEFAULT 200,
+    content_type TEXT DEFAULT '',
+    body BLOB,                               -- Raw response body
+    etag TEXT DEFAULT '',
+    last_modified TEXT DEFAULT '',
+    fetched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (source, key)
+);
@@ -0,0 +1,50 @@
+-- Full-text index over artist names, aliases, descriptions and genres.
+-- Rows share the rowid of their artist and are kept in sync by triggers.
+
+CREATE VIRTUAL TABLE IF NOT EXISTS artist_search USING fts5(
+    name,
+    aliases,                                 -- Space separated artist_aliases of the artist
+    description,
+    genres,                                  -- genres_json as stored
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS artist_search_insert AFTER INSERT ON artists BEGIN
+    INSERT INTO artist_search (rowid, name, aliases, description, genres)
+    VALUES (
+        new.rowid, new.name,
+        COALESCE((SELECT group_concat(alias, ' ') FROM artist_aliases WHERE artist_mbid = new.mbid), ''),
+        new.description, new.genres_json
+    );
+END;
+
+CREATE TRIGGER IF NOT EXISTS artist_search_update AFTER UPDATE OF name, description, genres_json ON artists BEGIN
+    UPDATE artist_search
+    SET name = new.name, description = new.description, genres = new.genres_json
+    WHERE rowid = new.rowid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS artist_search_delete AFTER DELETE ON artists BEGIN
+    DELETE FROM artist_search WHERE rowid = old.rowid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS artist_search_alias_insert AFTER INSERT ON artist_aliases BEGIN
+    UPDATE artist_search
+    SET aliases = COALESCE((SELECT group_concat(alias, ' ') FROM artist_aliases WHERE artist_mbid = new.artist_mbid), '')
+    WHERE rowid = (SELECT rowid FROM artists WHERE mbid = new.artist_mbid);
+END;
+
+CREATE TRIGGER IF NOT EXISTS artist_search_alias_delete AFTER DELETE ON artist_aliases BEGIN
+    UPDATE artist_search
+    SET aliases = COALESCE((SELECT group_concat(alias, ' ') FROM artist_aliases WHERE artist_mbid = old.artist_mbid), '')
+    WHERE rowid = (SELECT rowid FROM artists WHERE mbid = old.artist_mbid);
+END;
+
+-- Index artists cached before this migration
+INSERT INTO artist_search (rowid, name, aliases, description, genres)
+SELECT a.rowid, a.name,
+       COALESCE((SELECT group_concat(alias, ' ') FROM artist_aliases WHERE artist_mbid = a.mbid), ''),
+       a.description, a.genres_json
+FROM artists a;
+
+CREATE INDEX IF NOT EXISTS idx_country ON artists(country);
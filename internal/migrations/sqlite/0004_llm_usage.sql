@@ -0,0 +1,11 @@
+-- LLM token usage and estimated cost per UTC day and model, summed for spend quotas.
+
+CREATE TABLE IF NOT EXISTS llm_usage (
+    day TEXT NOT NULL,                       -- UTC date, YYYY-MM-DD
+    model TEXT NOT NULL,
+    requests INTEGER DEFAULT 0,
+    prompt_tokens INTEGER DEFAULT 0,
+    completion_tokens INTEGER DEFAULT 0,
+    cost_usd REAL DEFAULT 0,                 -- Estimated from the configured model prices
+    PRIMARY KEY (day, model)
+);
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Role grants access to a group of API endpoints. Each role includes the ones below it.
+type Role string
+
+// API roles, from least to most privileged
+const (
+	RoleReadOnly  Role = "read-only" // Browse cached artists, images and Plex playlists
+	RoleRecommend Role = "recommend" // Also generate recommendations, which can spend LLM credits
+	RoleAdmin     Role = "admin"     // Also manage the cache, backups and refreshes
+)
+
+// roleRanks orders the roles for Allows
+var roleRanks = map[Role]int{
+	RoleReadOnly:  1,
+	RoleRecommend: 2,
+	RoleAdmin:     3,
+}
+
+// ParseRole validates a role name
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("unknown role %q (available: read-only, recommend, admin)", name)
+	}
+	return role, nil
+}
+
+// Allows reports whether the role includes the required role
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// APIKey is a credential for the HTTP API. Only a hash of the secret is stored.
+type APIKey struct {
+	ID         int64      `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"` // First characters of the key, to tell keys apart
+	Hash       string     `json:"-" db:"key_hash"`    // Hex SHA-256 of the full key
+	Role       Role       `json:"role" db:"role"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// IsActive reports whether the key has not been revoked
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt == nil
+}
@@ -8,18 +8,35 @@ import (
 
 // Artist represents a musical artist with enriched metadata
 type Artist struct {
-	MBID         string          `json:"mbid" db:"mbid"` // MusicBrainz ID (primary key)
-	Name         string          `json:"name" db:"name"`
-	Verified     VerificationMap `json:"verified" db:"verified_json"` // Service verification status
-	AlbumCount   int             `json:"album_count" db:"album_count"`
-	YearsActive  string          `json:"years_active" db:"years_active"` // e.g., "1970-present", "1980-1995"
-	Description  string          `json:"description" db:"description"`   // Biography/description
-	Genres       Genres          `json:"genres" db:"genres_json"`
-	Country      string          `json:"country" db:"country"`
-	ImageURL     string          `json:"image_url" db:"image_url"`
-	ExternalURLs ExternalURLs    `json:"external_urls" db:"external_urls_json"`
-	LastUpdated  time.Time       `json:"last_updated" db:"last_updated"`
-	CacheExpiry  time.Time       `json:"-" db:"cache_expiry"`
+	MBID         string            `json:"mbid" db:"mbid"` // MusicBrainz ID (primary key)
+	Name         string            `json:"name" db:"name"`
+	Verified     VerificationMap   `json:"verified" db:"verified_json"` // Service verification status
+	AlbumCount   int               `json:"album_count" db:"album_count"`
+	YearsActive  string            `json:"years_active" db:"years_active"` // e.g., "1970-present", "1980-1995"
+	Description  string            `json:"description" db:"description"`   // Biography/description
+	Genres       Genres            `json:"genres" db:"genres_json"`
+	Country      string            `json:"country" db:"country"`
+	ImageURL     string            `json:"image_url" db:"image_url"`
+	ExternalURLs ExternalURLs      `json:"external_urls" db:"external_urls_json"`
+	Listeners    int64             `json:"listeners" db:"listeners"`            // Last.fm listener count
+	Playcount    int64             `json:"playcount" db:"playcount"`            // Last.fm scrobble count
+	Members      ArtistLinks       `json:"members,omitempty" db:"members_json"` // Band members, from Discogs
+	Groups       ArtistLinks       `json:"groups,omitempty" db:"groups_json"`   // Groups the artist played in, from Discogs
+	Labels       LabelAffiliations `json:"labels,omitempty" db:"labels_json"`   // Record labels, most releases first
+	LastUpdated  time.Time         `json:"last_updated" db:"last_updated"`
+	CacheExpiry  time.Time         `json:"-" db:"cache_expiry"`
+
+	// Releases holds the discography fetched during enrichment.
+	// Stored separately in the release_groups table.
+	Releases []ReleaseGroup `json:"-" db:"-"`
+
+	// Similar holds similarity edges fetched during enrichment.
+	// Stored separately in the artist_similarity table.
+	Similar []SimilarArtist `json:"-" db:"-"`
+
+	// Aliases holds name variations fetched during enrichment.
+	// Stored separately in the artist_aliases table.
+	Aliases []ArtistAlias `json:"-" db:"-"`
 }
 
 // VerificationMap tracks which services have verified this artist
@@ -34,6 +51,8 @@ type ExternalURLs struct {
 	MusicBrainz string `json:"musicbrainz,omitempty"` // Full URL to MB page
 	LastFM      string `json:"lastfm,omitempty"`
 	Spotify     string `json:"spotify,omitempty"`
+	Wikidata    string `json:"wikidata,omitempty"`  // Full URL to Wikidata entity
+	Wikipedia   string `json:"wikipedia,omitempty"` // Article used for the description
 }
 
 // Value implements driver.Valuer for VerificationMap
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// Alias sources
+const (
+	AliasSourceDiscogs = "discogs"
+)
+
+// ArtistLink references a related artist, such as a band member or a group the artist played in
+type ArtistLink struct {
+	Name      string `json:"name"`
+	DiscogsID int    `json:"discogs_id,omitempty"`
+	Active    bool   `json:"active"`
+}
+
+// ArtistLinks represents a slice of related artists for database compatibility
+type ArtistLinks []ArtistLink
+
+// LabelAffiliation is a record label the artist has released on
+type LabelAffiliation struct {
+	Name     string `json:"name"`
+	Releases int    `json:"releases"` // Number of releases seen on this label
+}
+
+// LabelAffiliations represents a slice of labels for database compatibility
+type LabelAffiliations []LabelAffiliation
+
+// ArtistAlias is an alternative spelling or name variation of an artist.
+// Stored in the artist_aliases table and used for name lookups.
+type ArtistAlias struct {
+	Name   string `json:"name" db:"alias"`
+	Source string `json:"source" db:"source"`
+}
+
+// Value implements driver.Valuer for ArtistLinks
+func (l ArtistLinks) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements sql.Scanner for ArtistLinks
+func (l *ArtistLinks) Scan(value interface{}) error {
+	bytes, ok := jsonBytes(value)
+	if !ok {
+		*l = make(ArtistLinks, 0)
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// Value implements driver.Valuer for LabelAffiliations
+func (l LabelAffiliations) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	return json.Marshal(l)
+}
+
+// Scan implements sql.Scanner for LabelAffiliations
+func (l *LabelAffiliations) Scan(value interface{}) error {
+	bytes, ok := jsonBytes(value)
+	if !ok {
+		*l = make(LabelAffiliations, 0)
+		return nil
+	}
+	return json.Unmarshal(bytes, l)
+}
+
+// jsonBytes extracts the raw JSON from a database column value
+func jsonBytes(value interface{}) ([]byte, bool) {
+	switch val := value.(type) {
+	case []byte:
+		return val, true
+	case string:
+		return []byte(val), true
+	default:
+		return nil, false
+	}
+}
@@ -2,17 +2,36 @@ package models
 
 import "time"
 
+// Recommendation engines
+const (
+	EngineLLM    = "llm"    // LLM suggestions from the listening profile
+	EngineGraph  = "graph"  // Similar-artist graph walk, no LLM needed
+	EngineHybrid = "hybrid" // Graph candidates ground the LLM prompt, graph results if the LLM fails
+)
+
 // RecommendRequest represents the API request for recommendations
 type RecommendRequest struct {
-	PlaylistName string  `json:"playlist_name" validate:"required"`
-	Genre        *string `json:"genre,omitempty"`
-	MaxResults   int     `json:"max_results,omitempty"` // Default: 5
+	PlaylistName string   `json:"playlist_name" validate:"required"`
+	Genre        *string  `json:"genre,omitempty"`                          // Includes subgenres: "metal" matches "doom metal"
+	MaxResults   int      `json:"max_results,omitempty"`                    // Default: 5
+	Engine       string   `json:"engine,omitempty" enum:"llm,graph,hybrid"` // llm, graph or hybrid; default llm when configured, else graph
+	Notify       []string `json:"notify,omitempty"`                         // Notification targets to send the result to; default NOTIFY_DEFAULT
+}
+
+// IsValidEngine reports whether engine is empty or a known recommendation engine
+func IsValidEngine(engine string) bool {
+	switch engine {
+	case "", EngineLLM, EngineGraph, EngineHybrid:
+		return true
+	}
+	return false
 }
 
 // RecommendResponse represents the API response
 type RecommendResponse struct {
 	Status      string            `json:"status"`
 	RequestID   string            `json:"request_id"`
+	TraceID     string            `json:"trace_id,omitempty"` // Look up the request's spans in the tracing backend
 	Suggestions []Artist          `json:"suggestions"`
 	Metadata    RecommendMetadata `json:"metadata"`
 	Error       string            `json:"error,omitempty"`
@@ -25,5 +44,8 @@ type RecommendMetadata struct {
 	ProcessingTime   string    `json:"processing_time"`
 	CacheHits        int       `json:"cache_hits"`
 	APICallsMade     int       `json:"api_calls_made"`
+	Engine           string    `json:"engine" enum:"llm,graph,hybrid"`
+	FallbackFrom     string    `json:"fallback_from,omitempty"`   // Default engine that failed, Engine answered instead
+	FallbackReason   string    `json:"fallback_reason,omitempty"` // Error code of the failure, e.g. llm_quota_exceeded
 	GeneratedAt      time.Time `json:"generated_at"`
 }
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+)
+
+// Release group primary types as reported by MusicBrainz
+const (
+	ReleaseTypeAlbum  = "Album"
+	ReleaseTypeEP     = "EP"
+	ReleaseTypeSingle = "Single"
+)
+
+// ReleaseGroup represents a single entry in an artist's discography
+type ReleaseGroup struct {
+	MBID             string       `json:"mbid" db:"mbid"` // MusicBrainz release group ID
+	ArtistMBID       string       `json:"artist_mbid" db:"artist_mbid"`
+	Title            string       `json:"title" db:"title"`
+	PrimaryType      string       `json:"primary_type" db:"primary_type"`            // Album, EP, Single, Broadcast, Other
+	SecondaryTypes   ReleaseTypes `json:"secondary_types" db:"secondary_types_json"` // Compilation, Live, Remix, ...
+	FirstReleaseDate string       `json:"first_release_date" db:"first_release_date"`
+	Year             int          `json:"year" db:"year"`
+}
+
+// ReleaseTypes represents a slice of release type strings for database compatibility
+type ReleaseTypes []string
+
+// Discography summarizes an artist's release groups by type
+type Discography struct {
+	ArtistMBID       string         `json:"artist_mbid"`
+	Albums           int            `json:"albums"` // Studio albums only
+	EPs              int            `json:"eps"`
+	Singles          int            `json:"singles"`
+	Other            int            `json:"other"` // Compilations, live albums, broadcasts, ...
+	FirstReleaseYear int            `json:"first_release_year,omitempty"`
+	LastReleaseYear  int            `json:"last_release_year,omitempty"`
+	ReleaseGroups    []ReleaseGroup `json:"release_groups"`
+}
+
+// NewDiscography builds a discography summary from a list of release groups
+func NewDiscography(artistMBID string, groups []ReleaseGroup) *Discography {
+	d := &Discography{
+		ArtistMBID:    artistMBID,
+		ReleaseGroups: groups,
+	}
+	if d.ReleaseGroups == nil {
+		d.ReleaseGroups = make([]ReleaseGroup, 0)
+	}
+
+	for _, rg := range groups {
+		switch {
+		case rg.IsStudioAlbum():
+			d.Albums++
+		case rg.PrimaryType == ReleaseTypeEP && len(rg.SecondaryTypes) == 0:
+			d.EPs++
+		case rg.PrimaryType == ReleaseTypeSingle && len(rg.SecondaryTypes) == 0:
+			d.Singles++
+		default:
+			d.Other++
+		}
+
+		if rg.Year == 0 {
+			continue
+		}
+		if d.FirstReleaseYear == 0 || rg.Year < d.FirstReleaseYear {
+			d.FirstReleaseYear = rg.Year
+		}
+		if rg.Year > d.LastReleaseYear {
+			d.LastReleaseYear = rg.Year
+		}
+	}
+
+	return d
+}
+
+// IsStudioAlbum reports whether the release group is a regular album
+// (not a compilation, live recording, soundtrack, etc.)
+func (rg ReleaseGroup) IsStudioAlbum() bool {
+	return rg.PrimaryType == ReleaseTypeAlbum && len(rg.SecondaryTypes) == 0
+}
+
+// YearsActive formats the release span, e.g. "1994-2008" or "2001"
+func (d *Discography) YearsActive() string {
+	if d.FirstReleaseYear == 0 {
+		return ""
+	}
+	if d.FirstReleaseYear == d.LastReleaseYear {
+		return strconv.Itoa(d.FirstReleaseYear)
+	}
+	return strconv.Itoa(d.FirstReleaseYear) + "-" + strconv.Itoa(d.LastReleaseYear)
+}
+
+// Value implements driver.Valuer for ReleaseTypes
+func (r ReleaseTypes) Value() (driver.Value, error) {
+	if r == nil {
+		return "[]", nil
+	}
+	return json.Marshal(r)
+}
+
+// Scan implements sql.Scanner for ReleaseTypes
+func (r *ReleaseTypes) Scan(value interface{}) error {
+	if value == nil {
+		*r = make(ReleaseTypes, 0)
+		return nil
+	}
+
+	var bytes []byte
+	switch val := value.(type) {
+	case []byte:
+		bytes = val
+	case string:
+		bytes = []byte(val)
+	default:
+		*r = make(ReleaseTypes, 0)
+		return nil
+	}
+
+	return json.Unmarshal(bytes, r)
+}
@@ -0,0 +1,89 @@
+package models
+
+import "testing"
+
+func TestNewDiscography(t *testing.T) {
+	groups := []ReleaseGroup{
+		{MBID: "rg-1", Title: "Debut", PrimaryType: ReleaseTypeAlbum, Year: 1994},
+		{MBID: "rg-2", Title: "Second", PrimaryType: ReleaseTypeAlbum, Year: 1997},
+		{MBID: "rg-3", Title: "Live at Somewhere", PrimaryType: ReleaseTypeAlbum, SecondaryTypes: ReleaseTypes{"Live"}, Year: 1999},
+		{MBID: "rg-4", Title: "Best Of", PrimaryType: ReleaseTypeAlbum, SecondaryTypes: ReleaseTypes{"Compilation"}, Year: 2008},
+		{MBID: "rg-5", Title: "First EP", PrimaryType: ReleaseTypeEP, Year: 1993},
+		{MBID: "rg-6", Title: "Hit", PrimaryType: ReleaseTypeSingle, Year: 1997},
+		{MBID: "rg-7", Title: "Undated", PrimaryType: ReleaseTypeSingle},
+	}
+
+	d := NewDiscography("artist-mbid", groups)
+
+	if d.Albums != 2 {
+		t.Errorf("Expected 2 studio albums, got %d", d.Albums)
+	}
+	if d.EPs != 1 {
+		t.Errorf("Expected 1 EP, got %d", d.EPs)
+	}
+	if d.Singles != 2 {
+		t.Errorf("Expected 2 singles, got %d", d.Singles)
+	}
+	if d.Other != 2 {
+		t.Errorf("Expected 2 other releases, got %d", d.Other)
+	}
+	if d.FirstReleaseYear != 1993 {
+		t.Errorf("Expected first release year 1993, got %d", d.FirstReleaseYear)
+	}
+	if d.LastReleaseYear != 2008 {
+		t.Errorf("Expected last release year 2008, got %d", d.LastReleaseYear)
+	}
+	if d.YearsActive() != "1993-2008" {
+		t.Errorf("Expected years active '1993-2008', got '%s'", d.YearsActive())
+	}
+}
+
+func TestDiscographyYearsActive(t *testing.T) {
+	tests := []struct {
+		name     string
+		groups   []ReleaseGroup
+		expected string
+	}{
+		{"no releases", nil, ""},
+		{"undated releases", []ReleaseGroup{{PrimaryType: ReleaseTypeAlbum}}, ""},
+		{"single year", []ReleaseGroup{{PrimaryType: ReleaseTypeAlbum, Year: 2001}}, "2001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiscography("artist-mbid", tt.groups)
+			if got := d.YearsActive(); got != tt.expected {
+				t.Errorf("YearsActive() = %q, want %q", got, tt.expected)
+			}
+			if d.ReleaseGroups == nil {
+				t.Error("Expected ReleaseGroups to be non-nil")
+			}
+		})
+	}
+}
+
+func TestReleaseTypesDatabaseTypes(t *testing.T) {
+	types := ReleaseTypes{"Compilation", "Live"}
+
+	value, err := types.Value()
+	if err != nil {
+		t.Fatalf("Failed to get value: %v", err)
+	}
+
+	var scanned ReleaseTypes
+	if err := scanned.Scan(value); err != nil {
+		t.Fatalf("Failed to scan value: %v", err)
+	}
+
+	if len(scanned) != 2 || scanned[0] != "Compilation" || scanned[1] != "Live" {
+		t.Errorf("Unexpected scanned value: %v", scanned)
+	}
+
+	var empty ReleaseTypes
+	if err := empty.Scan(nil); err != nil {
+		t.Fatalf("Failed to scan nil: %v", err)
+	}
+	if empty == nil || len(empty) != 0 {
+		t.Errorf("Expected empty non-nil slice, got %v", empty)
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Similarity sources
+const (
+	SimilaritySourceLastFM      = "lastfm"
+	SimilaritySourceMusicBrainz = "musicbrainz" // Band membership and collaboration relations
+)
+
+// SimilarArtist is a weighted edge in the artist similarity graph
+type SimilarArtist struct {
+	ArtistMBID string    `json:"artist_mbid" db:"artist_mbid"`
+	Name       string    `json:"name" db:"similar_name"`
+	MBID       string    `json:"mbid,omitempty" db:"similar_mbid"` // Empty when the source has no MBID for the neighbour
+	Match      float64   `json:"match" db:"match"`                 // Similarity weight, 0..1
+	Source     string    `json:"source" db:"source"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+
+	// Cached neighbour data, filled in when the neighbour is in the artist cache
+	Listeners int64 `json:"listeners,omitempty" db:"-"`
+	Cached    bool  `json:"cached" db:"-"`
+}
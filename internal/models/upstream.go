@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// UpstreamResponse is a raw response body from an external API, kept so enrichment can be re-run offline
+type UpstreamResponse struct {
+	Source       string    `json:"source" db:"source"` // musicbrainz, discogs, lastfm, wikipedia
+	Key          string    `json:"key" db:"key"`       // Request path and query without credentials
+	StatusCode   int       `json:"status_code" db:"status_code"`
+	ContentType  string    `json:"content_type" db:"content_type"`
+	Body         []byte    `json:"-" db:"body"`
+	ETag         string    `json:"etag,omitempty" db:"etag"`
+	LastModified string    `json:"last_modified,omitempty" db:"last_modified"`
+	FetchedAt    time.Time `json:"fetched_at" db:"fetched_at"`
+	ExpiresAt    time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// IsFresh reports whether the response can be used without revalidation
+func (r *UpstreamResponse) IsFresh() bool {
+	return time.Now().Before(r.ExpiresAt)
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"context"
+)
+
+// discordLimit is the maximum length of a Discord message
+const discordLimit = 2000
+
+// Discord posts to a Discord channel webhook
+type Discord struct {
+	url string
+}
+
+// NewDiscord creates a notifier for a Discord webhook URL
+func NewDiscord(url string) *Discord {
+	return &Discord{url: url}
+}
+
+// Send implements Notifier
+func (d *Discord) Send(ctx context.Context, message Message) error {
+	return postJSON(ctx, "discord", d.url, map[string]interface{}{
+		"username": "GoCommender",
+		"content":  truncate("**"+message.Title+"**\n"+message.Body, discordLimit),
+		// Artist names must not ping anyone
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	}, nil)
+}
+
+// Slack posts to a Slack incoming webhook
+type Slack struct {
+	url string
+}
+
+// NewSlack creates a notifier for a Slack incoming webhook URL
+func NewSlack(url string) *Slack {
+	return &Slack{url: url}
+}
+
+// Send implements Notifier
+func (s *Slack) Send(ctx context.Context, message Message) error {
+	return postJSON(ctx, "slack", s.url, map[string]interface{}{
+		"text": "*" + message.Title + "*\n" + message.Body,
+	}, nil)
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Backend types of TargetConfig
+const (
+	TypeWebhook = "webhook"
+	TypeDiscord = "discord"
+	TypeSlack   = "slack"
+	TypeNtfy    = "ntfy"
+	TypeGotify  = "gotify"
+	TypeEmail   = "email"
+)
+
+// TargetConfig describes a notification target
+type TargetConfig struct {
+	Name     string
+	Type     string // webhook, discord, slack, ntfy, gotify or email
+	URL      string // Webhook URL, ntfy topic URL or Gotify server URL
+	Secret   string // HMAC key of generic webhooks
+	Token    string // ntfy access token or Gotify application token
+	Priority int    // ntfy and Gotify message priority
+	Title    string // Title template, DefaultTitle when empty
+	Template string // Body template, DefaultBody when empty
+	SMTP     SMTPConfig
+}
+
+// Config contains every notification target
+type Config struct {
+	Targets []TargetConfig
+	Default []string // Targets of runs that name none
+	Retry   RetryPolicy
+}
+
+// New creates a dispatcher for the configured targets
+func New(config Config) (*Dispatcher, error) {
+	targets := make([]*Target, 0, len(config.Targets))
+	for _, targetConfig := range config.Targets {
+		if targetConfig.Name == "" {
+			return nil, fmt.Errorf("notification target of type %q has no name", targetConfig.Type)
+		}
+		notifier, err := newNotifier(targetConfig)
+		if err != nil {
+			return nil, fmt.Errorf("notification target %q: %w", targetConfig.Name, err)
+		}
+		target, err := NewTarget(targetConfig.Name, notifier, targetConfig.Title, targetConfig.Template)
+		if err != nil {
+			return nil, fmt.Errorf("notification target %q: %w", targetConfig.Name, err)
+		}
+		targets = append(targets, target)
+	}
+	return NewDispatcher(targets, config.Default, config.Retry)
+}
+
+// newNotifier creates the backend of a target
+func newNotifier(config TargetConfig) (Notifier, error) {
+	if config.Type != TypeEmail {
+		if err := checkURL(config.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	switch config.Type {
+	case TypeWebhook:
+		return NewWebhook(config.URL, config.Secret), nil
+	case TypeDiscord:
+		return NewDiscord(config.URL), nil
+	case TypeSlack:
+		return NewSlack(config.URL), nil
+	case TypeNtfy:
+		return NewNtfy(config.URL, config.Token, config.Priority), nil
+	case TypeGotify:
+		if config.Token == "" {
+			return nil, fmt.Errorf("gotify needs an application token")
+		}
+		return NewGotify(config.URL, config.Token, config.Priority), nil
+	case TypeEmail:
+		return NewEmail(config.SMTP)
+	}
+	return nil, fmt.Errorf("unknown type %q, must be webhook, discord, slack, ntfy, gotify or email", config.Type)
+}
+
+// checkURL requires an absolute http or https URL
+func checkURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid URL, must be an absolute http or https URL") // Not quoted, it may hold a token
+	}
+	return nil
+}
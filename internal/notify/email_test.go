@@ -0,0 +1,196 @@
+package notify
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smtpSession is what a stand-in SMTP server received
+type smtpSession struct {
+	auth string // Decoded AUTH PLAIN credentials
+	from string
+	to   []string
+	data string
+}
+
+// newSMTPStandIn accepts one session, advertising AUTH when auth is set and
+// rejecting recipients with rejectCode
+func newSMTPStandIn(t *testing.T, auth bool, rejectCode int) (host string, port int, sessions chan smtpSession) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	sessions = make(chan smtpSession, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		text := textproto.NewConn(conn)
+		var session smtpSession
+		reply := func(line string) { text.PrintfLine("%s", line) }
+
+		reply("220 stand-in ESMTP")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch command {
+			case "EHLO":
+				if auth {
+					reply("250-stand-in")
+					reply("250 AUTH PLAIN")
+				} else {
+					reply("250 stand-in")
+				}
+			case "AUTH":
+				fields := strings.Fields(line)
+				decoded, _ := base64.StdEncoding.DecodeString(fields[len(fields)-1])
+				session.auth = string(decoded)
+				reply("235 Authenticated")
+			case "MAIL":
+				session.from = line
+				reply("250 OK")
+			case "RCPT":
+				if rejectCode != 0 {
+					reply(strconv.Itoa(rejectCode) + " Recipient rejected")
+					continue
+				}
+				session.to = append(session.to, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 Go ahead")
+				data, err := io.ReadAll(text.DotReader())
+				if err != nil {
+					return
+				}
+				session.data = string(data)
+				reply("250 Queued")
+			case "QUIT":
+				reply("221 Bye")
+				sessions <- session
+				return
+			default:
+				reply("502 Not implemented")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return "127.0.0.1", addr.Port, sessions
+}
+
+func TestEmail(t *testing.T) {
+	host, port, sessions := newSMTPStandIn(t, true, 0)
+	email, err := NewEmail(SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: "bot",
+		Password: "hunter2",
+		From:     "GoCommender <bot@example.com>",
+		To:       []string{"me@example.com", "Friend <friend@example.com>"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create email notifier: %v", err)
+	}
+
+	send(t, email, testMessage(t))
+	session := <-sessions
+
+	if session.auth != "\x00bot\x00hunter2" {
+		t.Errorf("Unexpected credentials %q", session.auth)
+	}
+	if session.from != "MAIL FROM:<bot@example.com>" {
+		t.Errorf("Unexpected sender %q", session.from)
+	}
+	if len(session.to) != 2 || session.to[1] != "RCPT TO:<friend@example.com>" {
+		t.Errorf("Unexpected recipients %v", session.to)
+	}
+
+	// The DotReader turns CRLF into LF
+	headers, body, _ := strings.Cut(session.data, "\n\n")
+	for _, expected := range []string{
+		"From: GoCommender <bot@example.com>",
+		"To: me@example.com, Friend <friend@example.com>",
+		"Subject: 2 new artists for Favorites",
+		"Content-Type: text/plain; charset=utf-8",
+		"X-GoCommender-Request-ID: req_test",
+	} {
+		if !strings.Contains(headers+"\n", expected+"\n") {
+			t.Errorf("Missing header %q in\n%s", expected, headers)
+		}
+	}
+	decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(body)))
+	if err != nil {
+		t.Fatalf("Invalid quoted-printable body: %v", err)
+	}
+	if strings.TrimSpace(string(decoded)) != "- Slowdive (shoegaze, dream pop)\n- Ride" {
+		t.Errorf("Unexpected body %q", decoded)
+	}
+}
+
+func TestEmailEncodesSubject(t *testing.T) {
+	host, port, sessions := newSMTPStandIn(t, false, 0)
+	email, err := NewEmail(SMTPConfig{Host: host, Port: port, From: "bot@example.com", To: []string{"me@example.com"}})
+	if err != nil {
+		t.Fatalf("Failed to create email notifier: %v", err)
+	}
+
+	send(t, email, Message{Title: "Neue Künstler", Body: "Björk"})
+	session := <-sessions
+
+	if !strings.Contains(session.data, "Subject: =?utf-8?q?Neue_K=C3=BCnstler?=\n") {
+		t.Errorf("Expected an encoded subject in\n%s", session.data)
+	}
+}
+
+func TestEmailRejectedRecipient(t *testing.T) {
+	host, port, _ := newSMTPStandIn(t, false, 550)
+	email, err := NewEmail(SMTPConfig{Host: host, Port: port, From: "bot@example.com", To: []string{"nobody@example.com"}})
+	if err != nil {
+		t.Fatalf("Failed to create email notifier: %v", err)
+	}
+
+	err = email.Send(context.Background(), testMessage(t))
+	var smtpErr *textproto.Error
+	if !errors.As(err, &smtpErr) || smtpErr.Code != 550 {
+		t.Fatalf("Expected a 550 error, got %v", err)
+	}
+	if retryable(err) {
+		t.Error("Expected a permanent SMTP error not to be retried")
+	}
+}
+
+func TestEmailUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	email, _ := NewEmail(SMTPConfig{Host: "127.0.0.1", Port: port, From: "bot@example.com", To: []string{"me@example.com"}})
+	err = email.Send(context.Background(), testMessage(t))
+	if err == nil {
+		t.Fatal("Expected a connection error")
+	}
+	if !retryable(err) {
+		t.Error("Expected a connection error to be retried")
+	}
+}
@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// userAgent identifies the notifications to receivers
+const userAgent = "GoCommender-Notify/1.0"
+
+// httpClient has no timeout of its own; each attempt has a context deadline
+var httpClient = &http.Client{}
+
+// jsonBody encodes a request body
+func jsonBody(value interface{}) ([]byte, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+	return body, nil
+}
+
+// postJSON sends value as a JSON body
+func postJSON(ctx context.Context, backend, endpoint string, value interface{}, header http.Header) error {
+	body, err := jsonBody(value)
+	if err != nil {
+		return err
+	}
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Type", "application/json")
+	return post(ctx, backend, endpoint, body, header)
+}
+
+// post sends a body and turns non-2xx responses into a StatusError. Errors
+// leave out the URL, Discord and Slack webhooks carry their token in the path.
+func post(ctx context.Context, backend, endpoint string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create %s request: %w", backend, withoutURL(err))
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", backend, withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return &StatusError{
+		Backend:    backend,
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(excerpt)),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// withoutURL unwraps a *url.Error, whose message quotes the request URL
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// parseRetryAfter reads seconds or an HTTP date, 0 when absent or invalid
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// truncate shortens s to at most limit runes for services with a message size limit
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	if limit < 1 {
+		return ""
+	}
+	return string(runes[:limit-1]) + "…"
+}
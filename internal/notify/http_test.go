@@ -0,0 +1,271 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// receivedRequest is a request captured by a stand-in server
+type receivedRequest struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// newStandIn records the requests it receives and answers with status
+func newStandIn(t *testing.T, status int) (*httptest.Server, chan receivedRequest) {
+	received := make(chan receivedRequest, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST, got %s", r.Method)
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- receivedRequest{path: r.URL.Path, header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func testMessage(t *testing.T) Message {
+	message, err := newTestTarget(t, "test", nil).Render(testEvent())
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	return message
+}
+
+func send(t *testing.T, notifier Notifier, message Message) {
+	if err := notifier.Send(context.Background(), message); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+}
+
+func decode(t *testing.T, body []byte) map[string]interface{} {
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Invalid JSON body %q: %v", body, err)
+	}
+	return payload
+}
+
+func TestWebhook(t *testing.T) {
+	server, received := newStandIn(t, http.StatusOK)
+
+	send(t, NewWebhook(server.URL+"/hook", "s3cret"), testMessage(t))
+	request := <-received
+
+	if request.path != "/hook" {
+		t.Errorf("Expected /hook, got %s", request.path)
+	}
+	if request.header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected a JSON body, got %s", request.header.Get("Content-Type"))
+	}
+	if request.header.Get(HeaderEvent) != EventRecommendationCompleted {
+		t.Errorf("Unexpected event header %q", request.header.Get(HeaderEvent))
+	}
+
+	// The receiver verifies the signature of the timestamp and body
+	timestamp := request.header.Get(HeaderTimestamp)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		t.Errorf("Unexpected timestamp %q", timestamp)
+	}
+	signature := []byte(request.header.Get(HeaderSignature))
+	if !hmac.Equal([]byte(Sign("s3cret", timestamp, request.body)), signature) {
+		t.Errorf("Signature %s does not match the body", signature)
+	}
+	if hmac.Equal([]byte(Sign("other", timestamp, request.body)), signature) {
+		t.Error("Signature matches another secret")
+	}
+
+	payload := decode(t, request.body)
+	if payload["event"] != EventRecommendationCompleted || payload["title"] != "2 new artists for Favorites" {
+		t.Errorf("Unexpected payload %v", payload)
+	}
+	data, _ := payload["data"].(map[string]interface{})
+	suggestions, _ := data["suggestions"].([]interface{})
+	if data["playlist"] != "Favorites" || data["request_id"] != "req_test" || len(suggestions) != 2 {
+		t.Errorf("Unexpected event data %v", data)
+	}
+}
+
+func TestWebhookWithoutSecret(t *testing.T) {
+	server, received := newStandIn(t, http.StatusNoContent)
+
+	send(t, NewWebhook(server.URL, ""), testMessage(t))
+	request := <-received
+
+	if request.header.Get(HeaderSignature) != "" || request.header.Get(HeaderTimestamp) != "" {
+		t.Error("Expected an unsigned request without a secret")
+	}
+}
+
+func TestSign(t *testing.T) {
+	// HMAC-SHA256 of "1700000000.{}" with the key "key"
+	expected := "sha256=9d713ed406bb7076d4123f0dc2c39d2df5c654ed4b0cd56b52c8b4c940bd63ae"
+	if signature := Sign("key", "1700000000", []byte("{}")); signature != expected {
+		t.Errorf("Expected %s, got %s", expected, signature)
+	}
+	if Sign("key", "1700000000", []byte("{}")) == Sign("key", "1700000001", []byte("{}")) {
+		t.Error("Expected the timestamp to be signed")
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	server, received := newStandIn(t, http.StatusNoContent)
+
+	send(t, NewDiscord(server.URL), testMessage(t))
+	payload := decode(t, (<-received).body)
+
+	if payload["content"] != "**2 new artists for Favorites**\n- Slowdive (shoegaze, dream pop)\n- Ride" {
+		t.Errorf("Unexpected content %q", payload["content"])
+	}
+	mentions, _ := payload["allowed_mentions"].(map[string]interface{})
+	if parse, ok := mentions["parse"].([]interface{}); !ok || len(parse) != 0 {
+		t.Errorf("Expected mentions to be disabled, got %v", payload["allowed_mentions"])
+	}
+}
+
+func TestDiscordTruncates(t *testing.T) {
+	server, received := newStandIn(t, http.StatusNoContent)
+
+	send(t, NewDiscord(server.URL), Message{Title: "Long", Body: strings.Repeat("é", 3000)})
+	payload := decode(t, (<-received).body)
+
+	content, _ := payload["content"].(string)
+	if length := len([]rune(content)); length != discordLimit {
+		t.Errorf("Expected %d characters, got %d", discordLimit, length)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	server, received := newStandIn(t, http.StatusOK)
+
+	send(t, NewSlack(server.URL), testMessage(t))
+	payload := decode(t, (<-received).body)
+
+	if payload["text"] != "*2 new artists for Favorites*\n- Slowdive (shoegaze, dream pop)\n- Ride" {
+		t.Errorf("Unexpected text %q", payload["text"])
+	}
+}
+
+func TestNtfy(t *testing.T) {
+	server, received := newStandIn(t, http.StatusOK)
+
+	send(t, NewNtfy(server.URL+"/discoveries", "tk_secret", 4), testMessage(t))
+	request := <-received
+
+	if request.path != "/discoveries" {
+		t.Errorf("Expected the topic path, got %s", request.path)
+	}
+	if request.header.Get("Title") != "2 new artists for Favorites" || request.header.Get("Priority") != "4" {
+		t.Errorf("Unexpected headers %v", request.header)
+	}
+	if request.header.Get("Authorization") != "Bearer tk_secret" {
+		t.Errorf("Expected the access token, got %q", request.header.Get("Authorization"))
+	}
+	if string(request.body) != "- Slowdive (shoegaze, dream pop)\n- Ride" {
+		t.Errorf("Unexpected body %q", request.body)
+	}
+}
+
+func TestNtfyDefaults(t *testing.T) {
+	server, received := newStandIn(t, http.StatusOK)
+
+	send(t, NewNtfy(server.URL+"/open", "", 0), testMessage(t))
+	request := <-received
+
+	if request.header.Get("Priority") != "" || request.header.Get("Authorization") != "" {
+		t.Errorf("Expected no priority or token, got %v", request.header)
+	}
+}
+
+func TestGotify(t *testing.T) {
+	server, received := newStandIn(t, http.StatusOK)
+
+	send(t, NewGotify(server.URL+"/", "app-token", 5), testMessage(t))
+	request := <-received
+
+	if request.path != "/message" {
+		t.Errorf("Expected /message, got %s", request.path)
+	}
+	if request.header.Get("X-Gotify-Key") != "app-token" {
+		t.Errorf("Expected the application token, got %q", request.header.Get("X-Gotify-Key"))
+	}
+	payload := decode(t, request.body)
+	if payload["title"] != "2 new artists for Favorites" || payload["priority"] != float64(5) {
+		t.Errorf("Unexpected payload %v", payload)
+	}
+}
+
+func TestDispatcherAgainstFlakyServer(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dispatcher, err := New(Config{
+		Targets: []TargetConfig{{Name: "hook", Type: TypeWebhook, URL: server.URL, Secret: "s3cret"}},
+		Retry:   testPolicy(3),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+
+	if err := dispatcher.Send(context.Background(), "hook", testEvent()); err != nil {
+		t.Errorf("Expected the third attempt to succeed, got %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestErrorsLeaveOutURL(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	unreachable := server.URL
+	server.Close()
+
+	for _, notifier := range []Notifier{
+		NewDiscord(unreachable + "/api/webhooks/1/s3cret-token"),
+		NewSlack("http://hooks.example.com:notaport/services/s3cret-token"),
+	} {
+		err := notifier.Send(context.Background(), Message{Title: "Title", Body: "Body"})
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+		if strings.Contains(err.Error(), "s3cret-token") {
+			t.Errorf("Error leaks the webhook token: %v", err)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":     0,
+		"30":   30 * time.Second,
+		"soon": 0,
+	}
+	for value, expected := range tests {
+		if delay := parseRetryAfter(value); delay != expected {
+			t.Errorf("Retry-After %q: expected %v, got %v", value, expected, delay)
+		}
+	}
+	if delay := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)); delay < 50*time.Second {
+		t.Errorf("Expected about a minute for an HTTP date, got %v", delay)
+	}
+}
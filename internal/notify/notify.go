@@ -0,0 +1,336 @@
+// Package notify sends the results of recommendation runs to webhooks, chat
+// services, push notification servers and email.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"gocommender/internal/logging"
+	"gocommender/internal/metrics"
+	"gocommender/internal/models"
+	"gocommender/internal/tracing"
+)
+
+var logger = logging.For("notify")
+
+// Notification metrics, labelled by target and result (sent, retried, failed)
+var notifications = metrics.NewCounter("gocommender_notifications_total",
+	"Notification attempts by target and result (sent, retried, failed).", "target", "result")
+
+// EventRecommendationCompleted is sent when a recommendation run succeeds
+const EventRecommendationCompleted = "recommendation.completed"
+
+// Event is a completed recommendation run, the data of every message template
+type Event struct {
+	Type        string                   `json:"type"`
+	RequestID   string                   `json:"request_id"`
+	Playlist    string                   `json:"playlist"`
+	Genre       string                   `json:"genre,omitempty"`
+	Suggestions []models.Artist          `json:"suggestions"`
+	Metadata    models.RecommendMetadata `json:"metadata"`
+	Time        time.Time                `json:"time"`
+}
+
+// RecommendationEvent describes a successful recommendation
+func RecommendationEvent(request models.RecommendRequest, response *models.RecommendResponse) Event {
+	event := Event{
+		Type:        EventRecommendationCompleted,
+		RequestID:   response.RequestID,
+		Playlist:    request.PlaylistName,
+		Suggestions: response.Suggestions,
+		Metadata:    response.Metadata,
+		Time:        response.Metadata.GeneratedAt,
+	}
+	if request.Genre != nil {
+		event.Genre = *request.Genre
+	}
+	return event
+}
+
+// Message is a rendered notification
+type Message struct {
+	Title string
+	Body  string
+	Event Event // For backends that send structured data
+}
+
+// Notifier delivers messages to one backend
+type Notifier interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// Default message templates, used when a target sets none
+const (
+	DefaultTitle = `{{len .Suggestions}} new artists for {{.Playlist}}`
+	DefaultBody  = `{{range .Suggestions}}- {{.Name}}{{with .Genres}} ({{join . ", "}}){{end}}
+{{else}}No new artists.
+{{end}}`
+)
+
+var templateFuncs = template.FuncMap{
+	"join":     func(values []string, sep string) string { return strings.Join(values, sep) },
+	"truncate": func(length int, s string) string { return truncate(s, length) },
+}
+
+// Target is a named notifier with its message templates
+type Target struct {
+	Name     string
+	Notifier Notifier
+	title    *template.Template
+	body     *template.Template
+}
+
+// NewTarget parses the title and body templates; empty ones use the defaults
+func NewTarget(name string, notifier Notifier, title, body string) (*Target, error) {
+	if title == "" {
+		title = DefaultTitle
+	}
+	if body == "" {
+		body = DefaultBody
+	}
+
+	target := &Target{Name: name, Notifier: notifier}
+	var err error
+	if target.title, err = template.New(name + " title").Funcs(templateFuncs).Parse(title); err != nil {
+		return nil, fmt.Errorf("invalid title template: %w", err)
+	}
+	if target.body, err = template.New(name + " body").Funcs(templateFuncs).Parse(body); err != nil {
+		return nil, fmt.Errorf("invalid body template: %w", err)
+	}
+	return target, nil
+}
+
+// Render fills the templates with an event
+func (t *Target) Render(event Event) (Message, error) {
+	var title, body bytes.Buffer
+	if err := t.title.Execute(&title, event); err != nil {
+		return Message{}, fmt.Errorf("failed to render title: %w", err)
+	}
+	if err := t.body.Execute(&body, event); err != nil {
+		return Message{}, fmt.Errorf("failed to render body: %w", err)
+	}
+	return Message{
+		Title: strings.TrimSpace(title.String()),
+		Body:  strings.TrimSpace(body.String()),
+		Event: event,
+	}, nil
+}
+
+// RetryPolicy controls how failed sends are retried
+type RetryPolicy struct {
+	Retries  int           // Attempts after the first
+	Delay    time.Duration // Before the first retry, doubled for each further one
+	MaxDelay time.Duration // Cap on the delay, also on a Retry-After sent by the backend
+	Timeout  time.Duration // Of each attempt
+}
+
+// DefaultRetryPolicy retries three times over about a minute
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Retries:  3,
+		Delay:    5 * time.Second,
+		MaxDelay: time.Minute,
+		Timeout:  10 * time.Second,
+	}
+}
+
+// delay returns how long to wait before a retry; attempt is 1 for the first retry
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	delay := p.Delay << (attempt - 1)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > delay {
+		delay = statusErr.RetryAfter
+	}
+	if p.MaxDelay > 0 && (delay > p.MaxDelay || delay < 0) {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// StatusError is a notification rejected by the backend
+type StatusError struct {
+	Backend    string
+	StatusCode int
+	Body       string        // Start of the response, to explain the rejection
+	RetryAfter time.Duration // From the Retry-After header
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("%s returned status %d", e.Backend, e.StatusCode)
+	}
+	return fmt.Sprintf("%s returned status %d: %s", e.Backend, e.StatusCode, e.Body)
+}
+
+// retryable reports whether a failed send may succeed later. Rejected requests
+// and permanent SMTP errors are not retried.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == 408 || statusErr.StatusCode == 429 || statusErr.StatusCode >= 500
+	}
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code < 500
+	}
+	return true
+}
+
+// Dispatcher sends events to named targets, retrying with backoff
+type Dispatcher struct {
+	targets  map[string]*Target
+	defaults []string // Targets of runs that name none
+	policy   RetryPolicy
+
+	ctx    context.Context // Cancelled by Close
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher checks that the default targets exist
+func NewDispatcher(targets []*Target, defaults []string, policy RetryPolicy) (*Dispatcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		targets:  make(map[string]*Target, len(targets)),
+		defaults: defaults,
+		policy:   policy,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	for _, target := range targets {
+		if _, exists := d.targets[target.Name]; exists {
+			cancel()
+			return nil, fmt.Errorf("duplicate notification target %q", target.Name)
+		}
+		d.targets[target.Name] = target
+	}
+	if err := d.Check(defaults); err != nil {
+		cancel()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Check returns an error naming the first target that is not configured
+func (d *Dispatcher) Check(names []string) error {
+	for _, name := range names {
+		if _, ok := d.targets[name]; !ok {
+			return fmt.Errorf("unknown notification target %q", name)
+		}
+	}
+	return nil
+}
+
+// Notify sends an event to the named targets, or the default targets when names
+// is empty, in the background. Failures are logged.
+func (d *Dispatcher) Notify(ctx context.Context, names []string, event Event) {
+	if len(names) == 0 {
+		names = d.defaults
+	}
+
+	// Keep the request ID and trace for the logs, but outlive the request
+	ctx = context.WithoutCancel(ctx)
+	for _, name := range names {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			if err := d.Send(ctx, name, event); err != nil {
+				logger.ErrorContext(ctx, "Notification failed", "target", name, "error", err)
+			}
+		}()
+	}
+}
+
+// Send delivers an event to one target, retrying failures the policy allows
+func (d *Dispatcher) Send(ctx context.Context, name string, event Event) (err error) {
+	target, ok := d.targets[name]
+	if !ok {
+		return fmt.Errorf("unknown notification target %q", name)
+	}
+
+	ctx, stop := mergeCancel(ctx, d.ctx)
+	defer stop()
+	ctx, span := tracing.Start(ctx, "notify.Send", tracing.String("target", name))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	message, err := target.Render(event)
+	if err != nil {
+		notifications.Inc(name, "failed")
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			delay := d.policy.delay(attempt, err)
+			logger.WarnContext(ctx, "Notification failed, retrying", "target", name, "attempt", attempt, "delay", delay, "error", err)
+			notifications.Inc(name, "retried")
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				notifications.Inc(name, "failed")
+				return fmt.Errorf("notification to %s cancelled: %w", name, err)
+			}
+		}
+
+		err = d.attempt(ctx, target, message)
+		if err == nil {
+			notifications.Inc(name, "sent")
+			logger.InfoContext(ctx, "Notification sent", "target", name, "attempts", attempt+1)
+			return nil
+		}
+		if attempt >= d.policy.Retries || !retryable(err) {
+			notifications.Inc(name, "failed")
+			return fmt.Errorf("notification to %s failed after %d attempts: %w", name, attempt+1, err)
+		}
+	}
+}
+
+// attempt sends a message once within the policy's timeout
+func (d *Dispatcher) attempt(ctx context.Context, target *Target, message Message) error {
+	if d.policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.policy.Timeout)
+		defer cancel()
+	}
+	return target.Notifier.Send(ctx, message)
+}
+
+// Close waits for notifications in flight until ctx is done, then cancels them
+func (d *Dispatcher) Close(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// mergeCancel returns ctx, also cancelled when other is
+func mergeCancel(ctx, other context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(other, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
@@ -0,0 +1,299 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gocommender/internal/models"
+)
+
+func testEvent() Event {
+	genre := "shoegaze"
+	return RecommendationEvent(
+		models.RecommendRequest{PlaylistName: "Favorites", Genre: &genre},
+		&models.RecommendResponse{
+			RequestID: "req_test",
+			Suggestions: []models.Artist{
+				{MBID: "mbid-1", Name: "Slowdive", Genres: models.Genres{"shoegaze", "dream pop"}},
+				{MBID: "mbid-2", Name: "Ride"},
+			},
+			Metadata: models.RecommendMetadata{Engine: models.EngineGraph, GeneratedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)},
+		},
+	)
+}
+
+// testPolicy retries quickly
+func testPolicy(retries int) RetryPolicy {
+	return RetryPolicy{Retries: retries, Delay: time.Millisecond, MaxDelay: 10 * time.Millisecond, Timeout: time.Second}
+}
+
+func TestRenderDefaultTemplates(t *testing.T) {
+	target, err := NewTarget("test", nil, "", "")
+	if err != nil {
+		t.Fatalf("Failed to parse default templates: %v", err)
+	}
+
+	message, err := target.Render(testEvent())
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if message.Title != "2 new artists for Favorites" {
+		t.Errorf("Unexpected title %q", message.Title)
+	}
+	if message.Body != "- Slowdive (shoegaze, dream pop)\n- Ride" {
+		t.Errorf("Unexpected body %q", message.Body)
+	}
+	if message.Event.RequestID != "req_test" {
+		t.Errorf("Expected the event in the message, got request ID %q", message.Event.RequestID)
+	}
+}
+
+func TestRenderCustomTemplates(t *testing.T) {
+	target, err := NewTarget("test", nil,
+		`{{.Genre}} picks from {{.Playlist}} ({{.Metadata.Engine}})`,
+		`{{range $i, $a := .Suggestions}}{{if $i}}, {{end}}{{$a.Name | truncate 4}}{{end}}`)
+	if err != nil {
+		t.Fatalf("Failed to parse templates: %v", err)
+	}
+
+	message, err := target.Render(testEvent())
+	if err != nil {
+		t.Fatalf("Failed to render: %v", err)
+	}
+	if message.Title != "shoegaze picks from Favorites (graph)" {
+		t.Errorf("Unexpected title %q", message.Title)
+	}
+	if message.Body != "Slo…, Ride" {
+		t.Errorf("Unexpected body %q", message.Body)
+	}
+}
+
+func TestInvalidTemplates(t *testing.T) {
+	if _, err := NewTarget("test", nil, "{{.Playlist", ""); err == nil {
+		t.Error("Expected an error for an unclosed action")
+	}
+
+	target, err := NewTarget("test", nil, "{{.Missing}}", "")
+	if err != nil {
+		t.Fatalf("Failed to parse template: %v", err)
+	}
+	if _, err := target.Render(testEvent()); err == nil {
+		t.Error("Expected an error for an unknown field")
+	}
+}
+
+// countingNotifier fails until it has been called failures times
+type countingNotifier struct {
+	calls    atomic.Int32
+	failures int32
+	err      error
+}
+
+func (n *countingNotifier) Send(ctx context.Context, message Message) error {
+	if n.calls.Add(1) <= n.failures {
+		return n.err
+	}
+	return nil
+}
+
+func newTestTarget(t *testing.T, name string, notifier Notifier) *Target {
+	target, err := NewTarget(name, notifier, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create target: %v", err)
+	}
+	return target
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int32
+		err      error
+		retries  int
+		calls    int32
+		success  bool
+	}{
+		{"first attempt", 0, nil, 3, 1, true},
+		{"server error", 2, &StatusError{Backend: "test", StatusCode: http.StatusServiceUnavailable}, 3, 3, true},
+		{"rate limited", 1, &StatusError{Backend: "test", StatusCode: http.StatusTooManyRequests}, 3, 2, true},
+		{"network error", 1, errors.New("connection refused"), 3, 2, true},
+		{"temporary SMTP error", 1, &textproto.Error{Code: 451, Msg: "try again"}, 3, 2, true},
+		{"retries exhausted", 5, &StatusError{Backend: "test", StatusCode: http.StatusBadGateway}, 2, 3, false},
+		{"rejected", 5, &StatusError{Backend: "test", StatusCode: http.StatusBadRequest}, 3, 1, false},
+		{"permanent SMTP error", 5, &textproto.Error{Code: 550, Msg: "no such user"}, 3, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &countingNotifier{failures: tt.failures, err: tt.err}
+			dispatcher, err := NewDispatcher([]*Target{newTestTarget(t, "test", notifier)}, nil, testPolicy(tt.retries))
+			if err != nil {
+				t.Fatalf("Failed to create dispatcher: %v", err)
+			}
+
+			err = dispatcher.Send(context.Background(), "test", testEvent())
+			if (err == nil) != tt.success {
+				t.Errorf("Expected success %v, got error %v", tt.success, err)
+			}
+			if calls := notifier.calls.Load(); calls != tt.calls {
+				t.Errorf("Expected %d attempts, got %d", tt.calls, calls)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := RetryPolicy{Delay: time.Second, MaxDelay: 30 * time.Second}
+
+	tests := []struct {
+		attempt  int
+		err      error
+		expected time.Duration
+	}{
+		{1, errors.New("failed"), time.Second},
+		{3, errors.New("failed"), 4 * time.Second},
+		{10, errors.New("failed"), 30 * time.Second},
+		{1, &StatusError{StatusCode: 429, RetryAfter: 20 * time.Second}, 20 * time.Second},
+		{1, &StatusError{StatusCode: 429, RetryAfter: time.Hour}, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		if delay := policy.delay(tt.attempt, tt.err); delay != tt.expected {
+			t.Errorf("Attempt %d after %v: expected %v, got %v", tt.attempt, tt.err, tt.expected, delay)
+		}
+	}
+}
+
+func TestDispatcherTargets(t *testing.T) {
+	first := newTestTarget(t, "first", &countingNotifier{})
+	second := newTestTarget(t, "second", &countingNotifier{})
+
+	if _, err := NewDispatcher([]*Target{first, second}, []string{"third"}, testPolicy(0)); err == nil {
+		t.Error("Expected an error for an unknown default target")
+	}
+	if _, err := NewDispatcher([]*Target{first, first}, nil, testPolicy(0)); err == nil {
+		t.Error("Expected an error for a duplicate target")
+	}
+
+	dispatcher, err := NewDispatcher([]*Target{first, second}, []string{"second"}, testPolicy(0))
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+	if err := dispatcher.Check([]string{"first", "second"}); err != nil {
+		t.Errorf("Expected configured targets to pass, got %v", err)
+	}
+	if err := dispatcher.Check([]string{"first", "third"}); err == nil {
+		t.Error("Expected an error for an unknown target")
+	}
+	if err := dispatcher.Send(context.Background(), "third", testEvent()); err == nil {
+		t.Error("Expected an error sending to an unknown target")
+	}
+}
+
+func TestNotifyDefaults(t *testing.T) {
+	named, fallback := &countingNotifier{}, &countingNotifier{}
+	dispatcher, err := NewDispatcher([]*Target{newTestTarget(t, "named", named), newTestTarget(t, "fallback", fallback)},
+		[]string{"fallback"}, testPolicy(0))
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+
+	// The request's context ends before the notifications are sent
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher.Notify(ctx, []string{"named"}, testEvent())
+	dispatcher.Notify(ctx, nil, testEvent())
+	cancel()
+
+	if err := dispatcher.Close(context.Background()); err != nil {
+		t.Fatalf("Failed to close dispatcher: %v", err)
+	}
+	if named.calls.Load() != 1 || fallback.calls.Load() != 1 {
+		t.Errorf("Expected one notification to each target, got %d named and %d fallback",
+			named.calls.Load(), fallback.calls.Load())
+	}
+}
+
+func TestCloseCancelsRetries(t *testing.T) {
+	notifier := &countingNotifier{failures: 100, err: errors.New("unreachable")}
+	dispatcher, err := NewDispatcher([]*Target{newTestTarget(t, "test", notifier)}, nil, RetryPolicy{Retries: 100, Delay: time.Hour})
+	if err != nil {
+		t.Fatalf("Failed to create dispatcher: %v", err)
+	}
+
+	dispatcher.Notify(context.Background(), []string{"test"}, testEvent())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := dispatcher.Close(ctx); err == nil {
+		t.Error("Expected Close to report the cancelled notification")
+	}
+	if calls := notifier.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 attempt before the cancellation, got %d", calls)
+	}
+}
+
+func TestNew(t *testing.T) {
+	valid := []TargetConfig{
+		{Name: "hook", Type: TypeWebhook, URL: "https://example.com/hook", Secret: "s3cret"},
+		{Name: "discord", Type: TypeDiscord, URL: "https://discord.com/api/webhooks/1/token"},
+		{Name: "slack", Type: TypeSlack, URL: "https://hooks.slack.com/services/T/B/X"},
+		{Name: "ntfy", Type: TypeNtfy, URL: "https://ntfy.sh/gocommender", Priority: 3},
+		{Name: "gotify", Type: TypeGotify, URL: "https://gotify.example.com", Token: "app"},
+		{Name: "email", Type: TypeEmail, SMTP: SMTPConfig{Host: "mail.example.com", From: "GoCommender <bot@example.com>", To: []string{"me@example.com"}}},
+	}
+	if _, err := New(Config{Targets: valid, Default: []string{"hook", "email"}, Retry: testPolicy(0)}); err != nil {
+		t.Errorf("Expected valid targets, got %v", err)
+	}
+
+	invalid := []TargetConfig{
+		{Name: "", Type: TypeWebhook, URL: "https://example.com/hook"},
+		{Name: "unknown", Type: "pager", URL: "https://example.com"},
+		{Name: "relative", Type: TypeSlack, URL: "/services/T/B/X"},
+		{Name: "gotify", Type: TypeGotify, URL: "https://gotify.example.com"},
+		{Name: "no recipients", Type: TypeEmail, SMTP: SMTPConfig{Host: "mail.example.com", From: "bot@example.com"}},
+		{Name: "bad sender", Type: TypeEmail, SMTP: SMTPConfig{Host: "mail.example.com", From: "bot", To: []string{"me@example.com"}}},
+		{Name: "template", Type: TypeSlack, URL: "https://hooks.slack.com/x", Template: "{{range}}"},
+	}
+	for _, target := range invalid {
+		if _, err := New(Config{Targets: []TargetConfig{target}}); err == nil {
+			t.Errorf("Expected an error for target %q", target.Name)
+		}
+	}
+}
+
+func TestInvalidURLIsNotLogged(t *testing.T) {
+	_, err := New(Config{Targets: []TargetConfig{{Name: "discord", Type: TypeDiscord, URL: "discord.com/api/webhooks/1/s3cret"}}})
+	if err == nil {
+		t.Fatal("Expected an error for a URL without a scheme")
+	}
+	if strings.Contains(err.Error(), "s3cret") {
+		t.Errorf("The error leaks the URL: %v", err)
+	}
+}
+
+func TestStatusErrorFromServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	err := NewSlack(server.URL).Send(context.Background(), Message{Title: "t", Body: "b"})
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		t.Fatalf("Expected a StatusError, got %v", err)
+	}
+	if statusErr.StatusCode != http.StatusTooManyRequests || statusErr.RetryAfter != 7*time.Second || statusErr.Body != "slow down" {
+		t.Errorf("Unexpected error %+v", statusErr)
+	}
+	if !retryable(err) {
+		t.Error("Expected 429 to be retried")
+	}
+}
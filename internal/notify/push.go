@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Ntfy publishes to an ntfy topic
+type Ntfy struct {
+	url      string // Server and topic, e.g. https://ntfy.sh/my-topic
+	token    string // Access token, empty for open topics
+	priority int    // 1 (min) to 5 (max), 0 for the server default
+}
+
+// NewNtfy creates a notifier for an ntfy topic URL
+func NewNtfy(url, token string, priority int) *Ntfy {
+	return &Ntfy{url: url, token: token, priority: priority}
+}
+
+// Send implements Notifier
+func (n *Ntfy) Send(ctx context.Context, message Message) error {
+	header := make(http.Header)
+	header.Set("Content-Type", "text/plain; charset=utf-8")
+	header.Set("Title", message.Title)
+	header.Set("Tags", "musical_note")
+	if n.priority > 0 {
+		header.Set("Priority", strconv.Itoa(n.priority))
+	}
+	if n.token != "" {
+		header.Set("Authorization", "Bearer "+n.token)
+	}
+	return post(ctx, "ntfy", n.url, []byte(message.Body), header)
+}
+
+// Gotify sends a message to a Gotify server
+type Gotify struct {
+	url      string // Server base URL
+	token    string // Application token
+	priority int
+}
+
+// NewGotify creates a notifier for a Gotify server and application token
+func NewGotify(url, token string, priority int) *Gotify {
+	return &Gotify{url: strings.TrimSuffix(url, "/"), token: token, priority: priority}
+}
+
+// Send implements Notifier
+func (g *Gotify) Send(ctx context.Context, message Message) error {
+	header := make(http.Header)
+	header.Set("X-Gotify-Key", g.token)
+	return postJSON(ctx, "gotify", g.url+"/message", map[string]interface{}{
+		"title":    message.Title,
+		"message":  message.Body,
+		"priority": g.priority,
+	}, header)
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of generic webhook requests
+const (
+	HeaderEvent     = "X-GoCommender-Event"
+	HeaderTimestamp = "X-GoCommender-Timestamp"
+	HeaderSignature = "X-GoCommender-Signature"
+)
+
+// Webhook POSTs the event as JSON. With a secret, the body is signed so the
+// receiver can verify it came from this server.
+type Webhook struct {
+	url    string
+	secret string
+}
+
+// NewWebhook creates a generic webhook notifier; secret may be empty
+func NewWebhook(url, secret string) *Webhook {
+	return &Webhook{url: url, secret: secret}
+}
+
+// webhookPayload is the body of a generic webhook request
+type webhookPayload struct {
+	Event   string `json:"event"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Data    Event  `json:"data"`
+}
+
+// Send implements Notifier
+func (w *Webhook) Send(ctx context.Context, message Message) error {
+	body, err := jsonBody(webhookPayload{
+		Event:   message.Event.Type,
+		Title:   message.Title,
+		Message: message.Body,
+		Data:    message.Event,
+	})
+	if err != nil {
+		return err
+	}
+
+	header := make(http.Header)
+	header.Set("Content-Type", "application/json")
+	header.Set(HeaderEvent, message.Event.Type)
+	if w.secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		header.Set(HeaderTimestamp, timestamp)
+		header.Set(HeaderSignature, Sign(w.secret, timestamp, body))
+	}
+	return post(ctx, "webhook", w.url, body, header)
+}
+
+// Sign returns the signature header of a webhook body: "sha256=" and the hex
+// HMAC-SHA256 of the timestamp, a dot and the body. Receivers should compare it
+// with hmac.Equal and reject old timestamps to prevent replays.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,285 @@
+// Package openapi builds OpenAPI 3 documents, deriving component schemas from
+// Go types so the document follows the models it describes.
+//
+//	doc := openapi.New("GoCommender API", version)
+//	doc.Add("GET", "/api/artists/{mbid}", &openapi.Operation{
+//		Responses: map[string]*openapi.Response{"200": openapi.JSON("Artist", doc.SchemaOf(models.Artist{}))},
+//	})
+//
+// Validate checks JSON values against the document's schemas, see the API contract tests.
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Version is the OpenAPI version documents are written in
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []SecurityRequirement `json:"security,omitempty"`
+	Tags       []Tag                 `json:"tags,omitempty"`
+
+	types map[string]string // Go type of each component schema, to detect name clashes
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem holds the operations of a path by lowercase HTTP method
+type PathItem map[string]*Operation
+
+// Operation is one method of a path
+type Operation struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary"`
+	Description string                 `json:"description,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []Parameter            `json:"parameters,omitempty"`
+	RequestBody *RequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]*Response   `json:"responses"`
+	Security    *[]SecurityRequirement `json:"security,omitempty"` // Overrides the document's security, see NoSecurity
+}
+
+// Parameter is a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path or query
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody describes the body an operation accepts
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response describes a response of one status code
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header describes a response header
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType holds the schema of a content type
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components holds reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme describes how clients authenticate
+type SecurityScheme struct {
+	Type        string `json:"type"` // http or apiKey
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// SecurityRequirement lists the schemes an operation accepts, by name
+type SecurityRequirement map[string][]string
+
+// Schema is the subset of JSON Schema used by the API
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// New creates an empty document
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI:    Version,
+		Info:       Info{Title: title, Version: version},
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: make(map[string]*Schema)},
+		types:      make(map[string]string),
+	}
+}
+
+// Add documents an operation; adding a method twice panics
+func (d *Document) Add(method, path string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		d.Paths[path] = item
+	}
+
+	method = strings.ToLower(method)
+	if _, exists := item[method]; exists {
+		panic(fmt.Sprintf("openapi: %s %s documented twice", method, path))
+	}
+	if op.Responses == nil {
+		op.Responses = make(map[string]*Response)
+	}
+	item[method] = op
+}
+
+// Define adds a hand-written component schema and returns a reference to it
+func (d *Document) Define(name string, schema *Schema) *Schema {
+	if _, exists := d.Components.Schemas[name]; exists {
+		panic(fmt.Sprintf("openapi: schema %s defined twice", name))
+	}
+	d.Components.Schemas[name] = schema
+	return Ref(name)
+}
+
+// Operations calls fn for every operation, sorted by path and method
+func (d *Document) Operations(fn func(method, path string, op *Operation)) {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		methods := make([]string, 0, len(d.Paths[path]))
+		for method := range d.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			fn(strings.ToUpper(method), path, d.Paths[path][method])
+		}
+	}
+}
+
+// Find returns the documented path and operation serving a request path, or nil
+// if there is none. Templated segments such as {mbid} match any single segment.
+func (d *Document) Find(method, requestPath string) (string, *Operation) {
+	segments := strings.Split(requestPath, "/")
+	for path, item := range d.Paths {
+		op, ok := item[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+		template := strings.Split(path, "/")
+		if len(template) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, segment := range template {
+			if !isTemplate(segment) && segment != segments[i] || isTemplate(segment) && segments[i] == "" {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return path, op
+		}
+	}
+	return "", nil
+}
+
+func isTemplate(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+// Resolve follows a reference to its component schema
+func (d *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// Ref refers to a component schema
+func Ref(name string) *Schema { return &Schema{Ref: "#/components/schemas/" + name} }
+
+// String is a string schema
+func String() *Schema { return &Schema{Type: "string"} }
+
+// Enum is a string schema allowing only the given values
+func Enum(values ...string) *Schema { return &Schema{Type: "string", Enum: values} }
+
+// DateTime is an RFC 3339 timestamp schema
+func DateTime() *Schema { return &Schema{Type: "string", Format: "date-time"} }
+
+// Integer is an integer schema
+func Integer() *Schema { return &Schema{Type: "integer"} }
+
+// Number is a floating point schema
+func Number() *Schema { return &Schema{Type: "number"} }
+
+// Boolean is a boolean schema
+func Boolean() *Schema { return &Schema{Type: "boolean"} }
+
+// Any allows any value
+func Any() *Schema { return &Schema{} }
+
+// Array is an array schema
+func Array(items *Schema) *Schema { return &Schema{Type: "array", Items: items} }
+
+// Map is an object schema with arbitrary keys
+func Map(values *Schema) *Schema { return &Schema{Type: "object", AdditionalProperties: values} }
+
+// Object is an object schema with the given properties and no others
+func Object(properties map[string]*Schema, required ...string) *Schema {
+	sort.Strings(required)
+	return &Schema{Type: "object", Properties: properties, Required: required}
+}
+
+// Describe sets the schema's description and returns it
+func (s *Schema) Describe(description string) *Schema {
+	s.Description = description
+	return s
+}
+
+// JSON is a response with a JSON body
+func JSON(description string, schema *Schema) *Response {
+	return &Response{
+		Description: description,
+		Content:     map[string]MediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// Body is a response with a non-JSON body such as an image
+func Body(description, contentType string, schema *Schema) *Response {
+	return &Response{
+		Description: description,
+		Content:     map[string]MediaType{contentType: {Schema: schema}},
+	}
+}
+
+// NoSecurity marks an operation as open to unauthenticated clients
+func NoSecurity() *[]SecurityRequirement {
+	return &[]SecurityRequirement{}
+}
@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testItem struct {
+	testBase
+	Name    string            `json:"name"`
+	Kind    string            `json:"kind" enum:"a,b"`
+	Tags    []string          `json:"tags,omitempty"`
+	Extra   map[string]int    `json:"extra,omitempty"`
+	Created time.Time         `json:"created"`
+	Parent  *testItem         `json:"parent,omitempty"`
+	Hidden  string            `json:"-"`
+	Labels  map[string]string `json:"labels"`
+}
+
+func TestSchemaOf(t *testing.T) {
+	doc := New("Test", "1")
+	ref := doc.SchemaOf(testItem{})
+	if ref.Ref != "#/components/schemas/testItem" {
+		t.Fatalf("Expected a reference to testItem, got %+v", ref)
+	}
+
+	schema := doc.Components.Schemas["testItem"]
+	for _, name := range []string{"id", "name", "kind", "tags", "extra", "created", "parent", "labels"} {
+		if schema.Properties[name] == nil {
+			t.Errorf("Missing property %s", name)
+		}
+	}
+	if schema.Properties["Hidden"] != nil || schema.Properties["-"] != nil {
+		t.Error("Ignored field should not be documented")
+	}
+	if got := strings.Join(schema.Required, ","); got != "created,id,kind,labels,name" {
+		t.Errorf("Unexpected required properties %s", got)
+	}
+	if got := schema.Properties["kind"].Enum; len(got) != 2 || got[1] != "b" {
+		t.Errorf("Expected enum from tag, got %v", got)
+	}
+	if schema.Properties["created"].Format != "date-time" {
+		t.Error("time.Time should be a date-time string")
+	}
+	if schema.Properties["parent"].Ref != ref.Ref {
+		t.Error("Recursive field should reference the component")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := New("Test", "1")
+	schema := doc.SchemaOf(testItem{})
+	valid := map[string]interface{}{
+		"id": "1", "name": "x", "kind": "a", "created": "2024-01-02T03:04:05Z", "labels": nil,
+		"tags": []interface{}{"t"}, "extra": map[string]interface{}{"n": 2.0},
+	}
+	if err := doc.Validate(schema, valid); err != nil {
+		t.Fatalf("Expected valid, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(map[string]interface{})
+		want   string
+	}{
+		{"missing required", func(v map[string]interface{}) { delete(v, "name") }, `$: missing required property "name"`},
+		{"undocumented property", func(v map[string]interface{}) { v["surprise"] = true }, `$: undocumented property "surprise"`},
+		{"enum", func(v map[string]interface{}) { v["kind"] = "c" }, "$.kind"},
+		{"date-time", func(v map[string]interface{}) { v["created"] = "yesterday" }, "$.created"},
+		{"array item", func(v map[string]interface{}) { v["tags"] = []interface{}{1.0} }, "$.tags[0]"},
+		{"map value", func(v map[string]interface{}) { v["extra"] = map[string]interface{}{"n": 1.5} }, "$.extra.n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := make(map[string]interface{})
+			for k, v := range valid {
+				value[k] = v
+			}
+			tt.change(value)
+			err := doc.Validate(schema, value)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("Expected error %s, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	doc := New("Test", "1")
+	doc.Add("GET", "/api/items/{id}", &Operation{OperationID: "getItem"})
+	doc.Add("GET", "/api/items/{id}/children", &Operation{OperationID: "listChildren"})
+
+	if path, op := doc.Find("GET", "/api/items/42/children"); op == nil || path != "/api/items/{id}/children" {
+		t.Errorf("Expected listChildren, got %s", path)
+	}
+	if _, op := doc.Find("GET", "/api/items/"); op != nil {
+		t.Error("Empty segment should not match a template")
+	}
+	if _, op := doc.Find("POST", "/api/items/42"); op != nil {
+		t.Error("Undocumented method should not match")
+	}
+}
+
+func TestTypeScript(t *testing.T) {
+	doc := New("Test", "1")
+	doc.Add("GET", "/api/items/{id}", &Operation{
+		OperationID: "getItem",
+		Summary:     "Get an item",
+		Parameters: []Parameter{
+			{Name: "id", In: "path", Required: true, Schema: String()},
+			{Name: "full", In: "query", Schema: Boolean()},
+		},
+		Responses: map[string]*Response{"200": JSON("Item", doc.SchemaOf(testItem{}))},
+	})
+	doc.Add("GET", "/metrics", &Operation{
+		OperationID: "metrics",
+		Responses:   map[string]*Response{"200": Body("Metrics", "text/plain", String())},
+	})
+
+	ts := TypeScript(doc)
+	for _, want := range []string{
+		"export interface testItem {",
+		"  kind: 'a' | 'b';",
+		"  tags?: string[];",
+		"  extra?: Record<string, number>;",
+		"  getItem: {",
+		"    params: {\n      id: string;\n    };",
+		"    query?: {\n      full?: boolean;\n    };",
+		"    response: testItem;",
+		"  getItem: { method: 'GET', path: '/api/items/{id}' },",
+	} {
+		if !strings.Contains(ts, want) {
+			t.Errorf("Expected %q in:\n%s", want, ts)
+		}
+	}
+	if strings.Contains(ts, "metrics") {
+		t.Error("Operations outside /api/ should not be in the client")
+	}
+}
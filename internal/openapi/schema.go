@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaOf returns the schema of a Go value's type as encoding/json would write it.
+// Named structs become component schemas named after the type and are referenced;
+// other types are described inline. String fields may list their allowed values
+// in an enum tag such as `enum:"llm,graph,hybrid"`.
+func (d *Document) SchemaOf(v interface{}) *Schema {
+	return d.schemaOf(reflect.TypeOf(v))
+}
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return DateTime()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Integer()
+	case reflect.Float32, reflect.Float64:
+		return Number()
+	case reflect.String:
+		return String()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"} // Base64, like encoding/json
+		}
+		return Array(d.schemaOf(t.Elem()))
+	case reflect.Map:
+		return Map(d.schemaOf(t.Elem()))
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		return d.component(t)
+	default:
+		return Any()
+	}
+}
+
+// component registers a named struct as a component schema
+func (d *Document) component(t reflect.Type) *Schema {
+	name, goType := t.Name(), t.PkgPath()+"."+t.Name()
+	if existing, ok := d.types[name]; ok {
+		if existing != goType {
+			panic(fmt.Sprintf("openapi: schema %s is both %s and %s", name, existing, goType))
+		}
+		return Ref(name)
+	}
+	if _, ok := d.Components.Schemas[name]; ok {
+		panic(fmt.Sprintf("openapi: schema %s clashes with a defined schema", name))
+	}
+
+	d.types[name] = goType
+	d.Components.Schemas[name] = &Schema{} // Placeholder for recursive types
+	d.Components.Schemas[name] = d.structSchema(t)
+	return Ref(name)
+}
+
+// structSchema describes the JSON object of a struct. Fields without omitempty are required.
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	d.addFields(schema, t)
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func (d *Document) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		// Embedded structs without a name contribute their fields, like in encoding/json
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			d.addFields(schema, fieldType)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := d.schemaOf(field.Type)
+		if values := field.Tag.Get("enum"); values != "" {
+			property.Enum = strings.Split(values, ",")
+		}
+		schema.Properties[name] = property
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TypeScript generates TypeScript declarations for a document: an interface per
+// component schema, and the Endpoints and endpoints tables of the operations under
+// /api/ that exchange JSON, for a typed client.
+func TypeScript(d *Document) string {
+	var b strings.Builder
+	b.WriteString("// Code generated from the OpenAPI document by cmd/openapi; DO NOT EDIT.\n")
+	b.WriteString("// Regenerate with: go generate ./internal/api\n")
+
+	names := make([]string, 0, len(d.Components.Schemas))
+	for name := range d.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		schema := d.Components.Schemas[name]
+		b.WriteString("\n")
+		writeComment(&b, "", schema.Description)
+		if schema.Type == "object" && schema.Properties != nil {
+			fmt.Fprintf(&b, "export interface %s ", name)
+			writeObject(&b, "", schema)
+			b.WriteString("\n")
+		} else {
+			fmt.Fprintf(&b, "export type %s = %s;\n", name, tsType("", schema))
+		}
+	}
+
+	writeEndpoints(&b, d)
+	return b.String()
+}
+
+// clientOperation is an operation included in the typed client
+type clientOperation struct {
+	method, path string
+	op           *Operation
+	response     *Schema
+}
+
+func writeEndpoints(b *strings.Builder, d *Document) {
+	var operations []clientOperation
+	d.Operations(func(method, path string, op *Operation) {
+		response := op.Responses["200"]
+		if response == nil || !strings.HasPrefix(path, "/api/") || op.OperationID == "" {
+			return
+		}
+		if body := op.RequestBody; body != nil && body.Content["application/json"].Schema == nil {
+			return // Uploads such as NDJSON imports need their own code
+		}
+		if content, ok := response.Content["application/json"]; ok {
+			operations = append(operations, clientOperation{method, path, op, content.Schema})
+		}
+	})
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].op.OperationID < operations[j].op.OperationID
+	})
+
+	b.WriteString("\n// Endpoints holds the path parameters, query, body and response of each operation\n")
+	b.WriteString("export interface Endpoints {\n")
+	for _, operation := range operations {
+		writeComment(b, "  ", operation.op.Summary)
+		fmt.Fprintf(b, "  %s: {\n", operation.op.OperationID)
+
+		params, query := &Schema{Type: "object", Properties: map[string]*Schema{}}, &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, parameter := range operation.op.Parameters {
+			target := query
+			if parameter.In == "path" {
+				target = params
+			}
+			schema := *parameter.Schema
+			if schema.Description == "" {
+				schema.Description = parameter.Description
+			}
+			target.Properties[parameter.Name] = &schema
+			if parameter.Required {
+				target.Required = append(target.Required, parameter.Name)
+			}
+		}
+
+		if len(params.Properties) > 0 {
+			b.WriteString("    params: ")
+			writeObject(b, "    ", params)
+			b.WriteString(";\n")
+		}
+		if len(query.Properties) > 0 {
+			optional := "?"
+			if len(query.Required) > 0 {
+				optional = ""
+			}
+			fmt.Fprintf(b, "    query%s: ", optional)
+			writeObject(b, "    ", query)
+			b.WriteString(";\n")
+		}
+		if body := operation.op.RequestBody; body != nil {
+			if content, ok := body.Content["application/json"]; ok {
+				fmt.Fprintf(b, "    body: %s;\n", tsType("    ", content.Schema))
+			}
+		}
+		fmt.Fprintf(b, "    response: %s;\n", tsType("    ", operation.response))
+		b.WriteString("  };\n")
+	}
+	b.WriteString("}\n")
+
+	b.WriteString("\n// endpoints maps each operation to its method and path template\n")
+	b.WriteString("export const endpoints = {\n")
+	for _, operation := range operations {
+		fmt.Fprintf(b, "  %s: { method: '%s', path: '%s' },\n", operation.op.OperationID, operation.method, operation.path)
+	}
+	b.WriteString("} as const satisfies { [K in keyof Endpoints]: { method: string; path: string } };\n")
+
+	b.WriteString("\n// RequestOf is what a client sends for an operation\n")
+	b.WriteString("export type RequestOf<K extends keyof Endpoints> = Omit<Endpoints[K], 'response'>;\n")
+	b.WriteString("\n// ResponseOf is what an operation answers with\n")
+	b.WriteString("export type ResponseOf<K extends keyof Endpoints> = Endpoints[K]['response'];\n")
+}
+
+// writeObject writes the properties of an object schema, sorted by name
+func writeObject(b *strings.Builder, indent string, schema *Schema) {
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteString("{\n")
+	for _, name := range names {
+		property := schema.Properties[name]
+		optional := "?"
+		for _, required := range schema.Required {
+			if required == name {
+				optional = ""
+			}
+		}
+		fmt.Fprintf(b, "%s  %s%s: %s;", indent, name, optional, tsType(indent+"  ", property))
+		if property.Description != "" && property.Ref == "" {
+			b.WriteString(" // " + firstLine(property.Description))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + "}")
+}
+
+// tsType returns the TypeScript type of a schema
+func tsType(indent string, schema *Schema) string {
+	var t string
+	switch {
+	case schema.Ref != "":
+		t = strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+	case len(schema.Enum) > 0:
+		values := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			values[i] = "'" + value + "'"
+		}
+		t = strings.Join(values, " | ")
+	case schema.Type == "string":
+		t = "string"
+	case schema.Type == "integer" || schema.Type == "number":
+		t = "number"
+	case schema.Type == "boolean":
+		t = "boolean"
+	case schema.Type == "array":
+		t = tsType(indent, schema.Items)
+		if strings.Contains(t, " ") {
+			t = "(" + t + ")"
+		}
+		t += "[]"
+	case schema.Type == "object" && schema.Properties != nil:
+		var b strings.Builder
+		writeObject(&b, indent, schema)
+		t = b.String()
+	case schema.Type == "object" && schema.AdditionalProperties != nil:
+		t = "Record<string, " + tsType(indent, schema.AdditionalProperties) + ">"
+	case schema.Type == "object":
+		t = "Record<string, unknown>"
+	default:
+		t = "unknown"
+	}
+
+	if schema.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+func writeComment(b *strings.Builder, indent, text string) {
+	if text != "" {
+		fmt.Fprintf(b, "%s// %s\n", indent, firstLine(text))
+	}
+}
+
+func firstLine(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	return line
+}
@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Validate checks a value decoded by encoding/json against a schema. Objects with
+// properties must not have others, so responses that grow fields fail until
+// the document is updated. null is accepted for arrays and maps, which Go
+// encodes as null when they are nil.
+func (d *Document) Validate(schema *Schema, value interface{}) error {
+	return d.validate("$", schema, value)
+}
+
+func (d *Document) validate(path string, schema *Schema, value interface{}) error {
+	if schema.Ref != "" {
+		resolved := d.Resolve(schema)
+		if resolved == nil {
+			return fmt.Errorf("%s: unknown schema %s", path, schema.Ref)
+		}
+		schema = resolved
+	}
+	if schema.Type == "" {
+		return nil
+	}
+
+	if value == nil {
+		if schema.Nullable || schema.Type == "array" || schema.Type == "object" {
+			return nil
+		}
+		return fmt.Errorf("%s: expected %s, got null", path, schema.Type)
+	}
+
+	switch schema.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return mismatch(path, schema.Type, value)
+		}
+		if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, s) {
+			return fmt.Errorf("%s: %q is not one of %v", path, s, schema.Enum)
+		}
+		if schema.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: %q is not a date-time", path, s)
+			}
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok || n != math.Trunc(n) {
+			return mismatch(path, schema.Type, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch(path, schema.Type, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch(path, schema.Type, value)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return mismatch(path, schema.Type, value)
+		}
+		for i, item := range items {
+			if err := d.validate(fmt.Sprintf("%s[%d]", path, i), schema.Items, item); err != nil {
+				return err
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch(path, schema.Type, value)
+		}
+		return d.validateObject(path, schema, object)
+	}
+	return nil
+}
+
+func (d *Document) validateObject(path string, schema *Schema, object map[string]interface{}) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, ok := schema.Properties[key]
+		if !ok {
+			property = schema.AdditionalProperties
+		}
+		if property == nil {
+			if schema.Properties == nil {
+				continue // A free-form object
+			}
+			return fmt.Errorf("%s: undocumented property %q", path, key)
+		}
+		if err := d.validate(path+"."+key, property, object[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mismatch(path, expected string, value interface{}) error {
+	return fmt.Errorf("%s: expected %s, got %T", path, expected, value)
+}
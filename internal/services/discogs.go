@@ -5,10 +5,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
+	"gocommender/internal/metrics"
 	"gocommender/internal/models"
+	"gocommender/internal/taxonomy"
 )
 
 // DiscogsClient handles Discogs API interactions
@@ -22,14 +26,23 @@ type DiscogsClient struct {
 
 // DiscogsArtist represents artist data from Discogs API
 type DiscogsArtist struct {
-	ID          int            `json:"id"`
-	Name        string         `json:"name"`
-	Profile     string         `json:"profile"`
-	Images      []DiscogsImage `json:"images"`
-	URLs        []string       `json:"urls"`
-	NameVars    []string       `json:"namevariations"`
-	RealName    string         `json:"realname"`
-	DataQuality string         `json:"data_quality"`
+	ID          int                `json:"id"`
+	Name        string             `json:"name"`
+	Profile     string             `json:"profile"`
+	Images      []DiscogsImage     `json:"images"`
+	URLs        []string           `json:"urls"`
+	NameVars    []string           `json:"namevariations"`
+	RealName    string             `json:"realname"`
+	DataQuality string             `json:"data_quality"`
+	Members     []DiscogsArtistRef `json:"members"`
+	Groups      []DiscogsArtistRef `json:"groups"`
+}
+
+// DiscogsArtistRef references another Discogs artist, e.g. a band member
+type DiscogsArtistRef struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
 }
 
 type DiscogsImage struct {
@@ -53,12 +66,37 @@ type DiscogsSearchArtist struct {
 	CoverImage string `json:"cover_image"`
 }
 
+// DiscogsReleaseSearchResult represents master release search results from Discogs API
+type DiscogsReleaseSearchResult struct {
+	Results []DiscogsSearchRelease `json:"results"`
+}
+
+// DiscogsSearchRelease is a master release with its style and label facets
+type DiscogsSearchRelease struct {
+	ID    int      `json:"id"`
+	Title string   `json:"title"` // "Artist - Title"
+	Year  string   `json:"year"`
+	Genre []string `json:"genre"`
+	Style []string `json:"style"`
+	Label []string `json:"label"`
+}
+
+const (
+	discogsReleasePageSize = 100 // Master releases inspected for styles and labels
+	maxDiscogsStyles       = 6
+	maxDiscogsLabels       = 10
+)
+
+// discogsNameSuffix matches the "(2)" disambiguation Discogs appends to duplicate names
+var discogsNameSuffix = regexp.MustCompile(`\s*\(\d+\)$`)
+
 // NewDiscogsClient creates a new Discogs API client
 func NewDiscogsClient(token string) *DiscogsClient {
 	return &DiscogsClient{
 		baseURL: "https://api.discogs.com",
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: metrics.InstrumentTransport("discogs", nil),
 		},
 		userAgent:   "GoCommender/1.0 +https://github.com/lepinkainen/gocommender",
 		token:       token,
@@ -72,8 +110,6 @@ func (c *DiscogsClient) SearchArtist(name string) (*DiscogsSearchArtist, error)
 		return nil, fmt.Errorf("discogs token not configured")
 	}
 
-	<-c.rateLimiter.C // Rate limiting
-
 	query := url.QueryEscape(name)
 	urlStr := fmt.Sprintf("%s/database/search?q=%s&type=artist&token=%s", c.baseURL, query, c.token)
 
@@ -84,6 +120,7 @@ func (c *DiscogsClient) SearchArtist(name string) (*DiscogsSearchArtist, error)
 
 	req.Header.Set("User-Agent", c.userAgent)
 
+	waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -124,8 +161,6 @@ func (c *DiscogsClient) GetArtistByID(id int) (*DiscogsArtist, error) {
 		return nil, fmt.Errorf("discogs token not configured")
 	}
 
-	<-c.rateLimiter.C // Rate limiting
-
 	urlStr := fmt.Sprintf("%s/artists/%d?token=%s", c.baseURL, id, c.token)
 
 	req, err := http.NewRequest("GET", urlStr, nil)
@@ -135,6 +170,7 @@ func (c *DiscogsClient) GetArtistByID(id int) (*DiscogsArtist, error) {
 
 	req.Header.Set("User-Agent", c.userAgent)
 
+	waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -161,6 +197,56 @@ func (c *DiscogsClient) GetArtistByID(id int) (*DiscogsArtist, error) {
 	return &artist, nil
 }
 
+// SearchArtistReleases returns the master releases credited to an artist.
+// name is the Discogs artist name including any "(2)" suffix, so namesakes are not mixed in.
+// Search results carry style and label facets, which saves fetching every release.
+func (c *DiscogsClient) SearchArtistReleases(name string) ([]DiscogsSearchRelease, error) {
+	if c.token == "" {
+		return nil, fmt.Errorf("discogs token not configured")
+	}
+
+	urlStr := fmt.Sprintf("%s/database/search?artist=%s&type=master&per_page=%d&token=%s",
+		c.baseURL, url.QueryEscape(name), discogsReleasePageSize, c.token)
+
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+
+	waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("invalid discogs token")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var searchResult DiscogsReleaseSearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&searchResult); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// The artist filter is fuzzy, keep only releases credited to this exact name
+	releases := make([]DiscogsSearchRelease, 0, len(searchResult.Results))
+	for _, release := range searchResult.Results {
+		credited, _, _ := strings.Cut(release.Title, " - ")
+		if strings.EqualFold(strings.TrimSpace(credited), name) {
+			releases = append(releases, release)
+		}
+	}
+
+	return releases, nil
+}
+
 // EnrichArtist enriches an existing Artist model with Discogs data
 func (c *DiscogsClient) EnrichArtist(artist *models.Artist) error {
 	if c.token == "" {
@@ -216,9 +302,165 @@ func (c *DiscogsClient) EnrichArtist(artist *models.Artist) error {
 	// Add Discogs URL
 	artist.ExternalURLs.Discogs = fmt.Sprintf("https://www.discogs.com/artist/%d", discogsArtist.ID)
 
+	artist.Members = artistLinks(discogsArtist.Members)
+	artist.Groups = artistLinks(discogsArtist.Groups)
+	artist.Aliases = mergeAliases(artist.Aliases, models.AliasSourceDiscogs, artist.Name, discogsArtist.NameVars)
+
+	// Styles and labels come from the release list; missing them is not fatal
+	releases, err := c.SearchArtistReleases(discogsArtist.Name)
+	if err != nil {
+		enrichmentLog.Warn("Failed to get Discogs releases", "artist", artist.Name, "error", err)
+		return nil
+	}
+
+	artist.Genres = mergeGenres(artist.Genres, topStyles(releases, maxDiscogsStyles))
+	if labels := labelAffiliations(releases, maxDiscogsLabels); len(labels) > 0 {
+		artist.Labels = labels
+	}
+
 	return nil
 }
 
+// artistLinks converts Discogs artist references, active members first
+func artistLinks(refs []DiscogsArtistRef) models.ArtistLinks {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	links := make(models.ArtistLinks, 0, len(refs))
+	for _, ref := range refs {
+		name := cleanDiscogsName(ref.Name)
+		if name == "" {
+			continue
+		}
+		links = append(links, models.ArtistLink{Name: name, DiscogsID: ref.ID, Active: ref.Active})
+	}
+
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].Active && !links[j].Active
+	})
+	return links
+}
+
+// mergeAliases replaces the aliases from one source, skipping the artist's own name
+func mergeAliases(existing []models.ArtistAlias, source, artistName string, names []string) []models.ArtistAlias {
+	merged := make([]models.ArtistAlias, 0, len(existing)+len(names))
+	for _, alias := range existing {
+		if alias.Source != source {
+			merged = append(merged, alias)
+		}
+	}
+
+	seen := map[string]bool{strings.ToLower(artistName): true}
+	for _, name := range names {
+		name = cleanDiscogsName(name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, models.ArtistAlias{Name: name, Source: source})
+	}
+
+	return merged
+}
+
+// topStyles returns the most common styles across releases, normalized to match MusicBrainz genres.
+// Styles seen on a single release are dropped unless the artist has only one release.
+func topStyles(releases []DiscogsSearchRelease, limit int) []string {
+	counts := make(map[string]int)
+	var order []string
+	for _, release := range releases {
+		for _, style := range release.Style {
+			style, ok := taxonomy.Normalize(style)
+			if !ok {
+				continue
+			}
+			if counts[style] == 0 {
+				order = append(order, style)
+			}
+			counts[style]++
+		}
+	}
+
+	minCount := 2
+	if len(releases) <= 1 {
+		minCount = 1
+	}
+
+	styles := make([]string, 0, len(order))
+	for _, style := range order {
+		if counts[style] >= minCount {
+			styles = append(styles, style)
+		}
+	}
+
+	sort.SliceStable(styles, func(i, j int) bool {
+		return counts[styles[i]] > counts[styles[j]]
+	})
+
+	if len(styles) > limit {
+		styles = styles[:limit]
+	}
+	return styles
+}
+
+// labelAffiliations counts releases per label, most used labels first
+func labelAffiliations(releases []DiscogsSearchRelease, limit int) models.LabelAffiliations {
+	counts := make(map[string]int)
+	names := make(map[string]string)
+	for _, release := range releases {
+		seen := make(map[string]bool)
+		for _, label := range release.Label {
+			name := cleanDiscogsName(label)
+			key := strings.ToLower(name)
+			if name == "" || seen[key] || isPlaceholderLabel(key) {
+				continue
+			}
+			seen[key] = true
+			if _, exists := names[key]; !exists {
+				names[key] = name
+			}
+			counts[key]++
+		}
+	}
+
+	labels := make(models.LabelAffiliations, 0, len(counts))
+	for key, count := range counts {
+		labels = append(labels, models.LabelAffiliation{Name: names[key], Releases: count})
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Releases != labels[j].Releases {
+			return labels[i].Releases > labels[j].Releases
+		}
+		return labels[i].Name < labels[j].Name
+	})
+
+	if len(labels) > limit {
+		labels = labels[:limit]
+	}
+	return labels
+}
+
+// isPlaceholderLabel filters the catch-all labels Discogs uses for unofficial releases
+func isPlaceholderLabel(key string) bool {
+	return key == "not on label" || strings.HasPrefix(key, "not on label ")
+}
+
+// mergeGenres appends new genres and normalizes the result, so Discogs spellings match MusicBrainz ones
+func mergeGenres(existing models.Genres, additions []string) models.Genres {
+	merged := make([]string, 0, len(existing)+len(additions))
+	merged = append(merged, existing...)
+	merged = append(merged, additions...)
+	return taxonomy.NormalizeAll(merged)
+}
+
+// cleanDiscogsName strips the numeric disambiguation suffix from Discogs names
+func cleanDiscogsName(name string) string {
+	return strings.TrimSpace(discogsNameSuffix.ReplaceAllString(strings.TrimSpace(name), ""))
+}
+
 // getBestImage selects the best image from Discogs images
 func getBestImage(images []DiscogsImage) string {
 	if len(images) == 0 {
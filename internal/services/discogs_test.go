@@ -1,6 +1,10 @@
 package services
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -176,3 +180,116 @@ func TestDiscogsClientClose(t *testing.T) {
 	// Multiple calls should be safe
 	client.Close()
 }
+
+// newDiscogsStandIn serves canned artist, artist search and master search responses
+func newDiscogsStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/database/search" && r.URL.Query().Get("type") == "artist":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"results": []map[string]interface{}{{"id": 1, "title": "Slowdive", "type": "artist"}},
+			})
+		case r.URL.Path == "/database/search" && r.URL.Query().Get("type") == "master":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"results": []map[string]interface{}{
+					{"id": 10, "title": "Slowdive - Souvlaki", "style": []string{"Shoegaze", "Dream Pop"}, "label": []string{"Creation Records", "SBK Records"}},
+					{"id": 11, "title": "Slowdive - Just For A Day", "style": []string{"Shoegaze", "Dream Pop"}, "label": []string{"Creation Records"}},
+					{"id": 12, "title": "Slowdive - Pygmalion", "style": []string{"Ambient", "Shoegaze"}, "label": []string{"Creation Records"}},
+					{"id": 13, "title": "Slowdive (2) - Other Band", "style": []string{"Techno"}, "label": []string{"Not On Label"}},
+					{"id": 14, "title": "Slowdive Tribute - Covers", "style": []string{"Punk", "Punk"}, "label": []string{"Other"}},
+				},
+			})
+		case r.URL.Path == "/artists/1":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id":             1,
+				"name":           "Slowdive",
+				"namevariations": []string{"Slow Dive", "slowdive", "SlowDive (2)"},
+				"members": []map[string]interface{}{
+					{"id": 100, "name": "Nick Chaplin", "active": false},
+					{"id": 101, "name": "Rachel Goswell", "active": true},
+					{"id": 102, "name": "Neil Halstead (3)", "active": true},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestDiscogsClientEnrichArtistDetails(t *testing.T) {
+	server := newDiscogsStandIn(t)
+	defer server.Close()
+
+	client := NewDiscogsClient("token")
+	defer client.Close()
+	client.baseURL = server.URL
+	client.rateLimiter.Reset(time.Millisecond)
+
+	artist := &models.Artist{
+		MBID:    "slowdive-mbid",
+		Name:    "Slowdive",
+		Genres:  models.Genres{"rock", "shoegaze"},
+		Aliases: []models.ArtistAlias{{Name: "Old Alias", Source: models.AliasSourceDiscogs}},
+	}
+
+	if err := client.EnrichArtist(artist); err != nil {
+		t.Fatalf("EnrichArtist failed: %v", err)
+	}
+
+	if !artist.Verified["discogs"] {
+		t.Error("Expected discogs verification to be true")
+	}
+
+	// Styles are merged once, ordered by release count; single-release styles are dropped
+	expectedGenres := []string{"rock", "shoegaze", "dream pop"}
+	if strings.Join(artist.Genres, ",") != strings.Join(expectedGenres, ",") {
+		t.Errorf("Expected genres %v, got %v", expectedGenres, artist.Genres)
+	}
+
+	if len(artist.Members) != 3 || artist.Members[0].Name != "Rachel Goswell" || artist.Members[1].Name != "Neil Halstead" || artist.Members[2].Active {
+		t.Errorf("Expected active members first with clean names, got %+v", artist.Members)
+	}
+
+	if len(artist.Aliases) != 1 || artist.Aliases[0].Name != "Slow Dive" {
+		t.Errorf("Expected stale aliases replaced by name variations, got %+v", artist.Aliases)
+	}
+
+	if len(artist.Labels) != 2 || artist.Labels[0].Name != "Creation Records" || artist.Labels[0].Releases != 3 {
+		t.Errorf("Expected Creation Records as main label, got %+v", artist.Labels)
+	}
+}
+
+func TestTopStyles(t *testing.T) {
+	releases := []DiscogsSearchRelease{
+		{Style: []string{"Shoegaze", "Dream Pop"}},
+		{Style: []string{"Shoegaze", "Ambient"}},
+		{Style: []string{"Dream Pop", "Shoegaze"}},
+	}
+
+	if styles := topStyles(releases, 5); strings.Join(styles, ",") != "shoegaze,dream pop" {
+		t.Errorf("Unexpected styles %v", styles)
+	}
+	if styles := topStyles(releases, 1); len(styles) != 1 || styles[0] != "shoegaze" {
+		t.Errorf("Expected limit to apply, got %v", styles)
+	}
+	if styles := topStyles(releases[:1], 5); len(styles) != 2 {
+		t.Errorf("Expected all styles of a single release, got %v", styles)
+	}
+}
+
+func TestCleanDiscogsName(t *testing.T) {
+	tests := map[string]string{
+		"Neil Halstead (3)": "Neil Halstead",
+		" Slowdive ":        "Slowdive",
+		"Band (The)":        "Band (The)",
+	}
+
+	for input, expected := range tests {
+		if result := cleanDiscogsName(input); result != expected {
+			t.Errorf("cleanDiscogsName(%q) = %q, want %q", input, result, expected)
+		}
+	}
+}
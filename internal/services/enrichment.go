@@ -1,56 +1,92 @@
 package services
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"net/http"
 	"strings"
 	"time"
 
+	"gocommender/internal/db"
+	"gocommender/internal/logging"
 	"gocommender/internal/models"
+	"gocommender/internal/taxonomy"
+	"gocommender/internal/tracing"
 )
 
+var enrichmentLog = logging.For("enrichment")
+
 // EnrichmentService orchestrates artist data enrichment from multiple sources
 type EnrichmentService struct {
 	musicbrainz *MusicBrainzClient
 	discogs     *DiscogsClient
 	lastfm      *LastFMClient
+	wikipedia   *WikipediaClient
+	languages   []string // Default biography language preference
+	ttlPolicy   db.CacheConfig
+
+	responseCaches map[string]*CachingTransport // Raw response caches by source, nil when disabled
 }
 
 // EnrichmentOptions configures the enrichment process
 type EnrichmentOptions struct {
 	ForceUpdate    bool     // Force update even if recently cached
 	SourcePriority []string // Order of sources for data precedence
+	Languages      []string // Preferred biography languages, e.g. ["fi", "de"]; English is the fallback
+
+	relationsLoaded bool // MusicBrainz relations were already fetched for this artist
 }
 
-// NewEnrichmentService creates a new enrichment service with all clients
-func NewEnrichmentService(discogsToken, lastfmAPIKey, lastfmSecret string) *EnrichmentService {
+// NewEnrichmentService creates a new enrichment service with all clients.
+// languages sets the default biography language preference; English is used when empty.
+func NewEnrichmentService(discogsToken, lastfmAPIKey, lastfmSecret string, languages ...string) *EnrichmentService {
 	return &EnrichmentService{
 		musicbrainz: NewMusicBrainzClient(),
 		discogs:     NewDiscogsClient(discogsToken),
 		lastfm:      NewLastFMClient(lastfmAPIKey, lastfmSecret),
+		wikipedia:   NewWikipediaClient(),
+		languages:   languages,
+		ttlPolicy:   db.DefaultCacheConfig(),
 	}
 }
 
 // EnrichArtistByName performs full artist enrichment starting from just a name
-func (s *EnrichmentService) EnrichArtistByName(name string, options *EnrichmentOptions) (*models.Artist, error) {
+func (s *EnrichmentService) EnrichArtistByName(ctx context.Context, name string, options *EnrichmentOptions) (_ *models.Artist, err error) {
+	ctx, span := tracing.Start(ctx, "enrich.ArtistByName", tracing.String("artist.name", name))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	if options == nil {
 		options = &EnrichmentOptions{
-			SourcePriority: []string{"musicbrainz", "discogs", "lastfm"},
+			SourcePriority: []string{"musicbrainz", "wikipedia", "discogs", "lastfm"},
 		}
 	}
 
 	// Start with MusicBrainz to get the MBID and basic data
-	mbArtist, err := s.musicbrainz.SearchArtist(name)
+	var mbArtist *MusicBrainzArtist
+	err = traceCall(ctx, "musicbrainz.SearchArtist", func() (err error) {
+		mbArtist, err = s.musicbrainz.SearchArtist(name)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to find artist in MusicBrainz: %w", err)
 	}
+	span.SetAttributes(tracing.String("artist.mbid", mbArtist.ID))
+
+	// Search results carry no releases or relations, so fetch them separately
+	s.loadDiscography(ctx, mbArtist)
+	s.loadRelations(ctx, mbArtist)
 
 	// Convert to our internal model
 	artist := mbArtist.ToArtistModel()
 
 	// Enrich with additional sources
-	if err := s.EnrichExistingArtist(artist, options); err != nil {
-		log.Printf("Warning: enrichment failed for %s: %v", name, err)
+	withRelations := *options
+	withRelations.relationsLoaded = true
+	if err := s.EnrichExistingArtist(ctx, artist, &withRelations); err != nil {
+		enrichmentLog.WarnContext(ctx, "Enrichment partially failed", "artist", name, "error", err)
 		// Don't return error - we have basic data from MusicBrainz
 	}
 
@@ -58,42 +94,92 @@ func (s *EnrichmentService) EnrichArtistByName(name string, options *EnrichmentO
 }
 
 // EnrichArtistByMBID performs full artist enrichment starting from an MBID
-func (s *EnrichmentService) EnrichArtistByMBID(mbid string, options *EnrichmentOptions) (*models.Artist, error) {
+func (s *EnrichmentService) EnrichArtistByMBID(ctx context.Context, mbid string, options *EnrichmentOptions) (_ *models.Artist, err error) {
+	ctx, span := tracing.Start(ctx, "enrich.ArtistByMBID", tracing.String("artist.mbid", mbid))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	if options == nil {
 		options = &EnrichmentOptions{
-			SourcePriority: []string{"musicbrainz", "discogs", "lastfm"},
+			SourcePriority: []string{"musicbrainz", "wikipedia", "discogs", "lastfm"},
 		}
 	}
 
 	// Get detailed data from MusicBrainz
-	mbArtist, err := s.musicbrainz.GetArtistByMBID(mbid)
+	var mbArtist *MusicBrainzArtist
+	err = traceCall(ctx, "musicbrainz.GetArtistByMBID", func() (err error) {
+		mbArtist, err = s.musicbrainz.GetArtistByMBID(mbid)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get artist from MusicBrainz: %w", err)
 	}
 
+	// Lookups only include the first page of release groups
+	s.loadDiscography(ctx, mbArtist)
+
 	// Convert to our internal model
 	artist := mbArtist.ToArtistModel()
 
-	// Enrich with additional sources
-	if err := s.EnrichExistingArtist(artist, options); err != nil {
-		log.Printf("Warning: enrichment failed for MBID %s: %v", mbid, err)
+	// Enrich with additional sources; the lookup already included relations
+	withRelations := *options
+	withRelations.relationsLoaded = true
+	if err := s.EnrichExistingArtist(ctx, artist, &withRelations); err != nil {
+		enrichmentLog.WarnContext(ctx, "Enrichment partially failed", "mbid", mbid, "error", err)
 		// Don't return error - we have basic data from MusicBrainz
 	}
 
 	return artist, nil
 }
 
+// loadDiscography replaces the artist's release groups with the full browse result.
+// Failures are logged and leave any release groups from the lookup in place.
+func (s *EnrichmentService) loadDiscography(ctx context.Context, mbArtist *MusicBrainzArtist) {
+	var groups []MusicBrainzReleaseGroup
+	err := traceCall(ctx, "musicbrainz.GetReleaseGroups", func() (err error) {
+		groups, err = s.musicbrainz.GetReleaseGroups(mbArtist.ID)
+		return err
+	})
+	if err != nil {
+		enrichmentLog.WarnContext(ctx, "Failed to load discography", "artist", mbArtist.Name, "error", err)
+		return
+	}
+	mbArtist.ReleaseGroups = groups
+}
+
+// loadRelations fetches URL and artist relations missing from search results.
+// Failures are logged and leave the artist without external links.
+func (s *EnrichmentService) loadRelations(ctx context.Context, mbArtist *MusicBrainzArtist) {
+	var relations []MusicBrainzRelation
+	err := traceCall(ctx, "musicbrainz.GetRelations", func() (err error) {
+		relations, err = s.musicbrainz.GetRelations(mbArtist.ID)
+		return err
+	})
+	if err != nil {
+		enrichmentLog.WarnContext(ctx, "Failed to load relations", "artist", mbArtist.Name, "error", err)
+		return
+	}
+	mbArtist.Relations = relations
+}
+
 // EnrichExistingArtist enriches an existing artist model with additional sources
-func (s *EnrichmentService) EnrichExistingArtist(artist *models.Artist, options *EnrichmentOptions) error {
+func (s *EnrichmentService) EnrichExistingArtist(ctx context.Context, artist *models.Artist, options *EnrichmentOptions) error {
 	if options == nil {
 		options = &EnrichmentOptions{
-			SourcePriority: []string{"discogs", "lastfm"},
+			SourcePriority: []string{"wikipedia", "discogs", "lastfm"},
 		}
 	}
 
+	languages := options.Languages
+	if len(languages) == 0 {
+		languages = s.languages
+	}
+
 	// Check if we need to update based on cache expiry
 	if !options.ForceUpdate && time.Now().Before(artist.CacheExpiry) {
-		log.Printf("Artist %s is still cached, skipping enrichment", artist.Name)
+		enrichmentLog.DebugContext(ctx, "Artist is still cached, skipping enrichment", "artist", artist.Name)
 		return nil
 	}
 
@@ -103,11 +189,17 @@ func (s *EnrichmentService) EnrichExistingArtist(artist *models.Artist, options
 	for _, source := range options.SourcePriority {
 		switch source {
 		case "discogs":
-			if err := s.enrichWithDiscogs(artist); err != nil {
+			if err := traceCall(ctx, "discogs.EnrichArtist", func() error { return s.enrichWithDiscogs(artist) }); err != nil {
 				enrichmentErrors = append(enrichmentErrors, fmt.Sprintf("discogs: %v", err))
 			}
+		case "wikipedia":
+			if err := traceCall(ctx, "wikipedia.EnrichArtist", func() error {
+				return s.enrichWithWikipedia(artist, languages, options.relationsLoaded)
+			}); err != nil {
+				enrichmentErrors = append(enrichmentErrors, fmt.Sprintf("wikipedia: %v", err))
+			}
 		case "lastfm":
-			if err := s.enrichWithLastFM(artist); err != nil {
+			if err := traceCall(ctx, "lastfm.EnrichArtist", func() error { return s.enrichWithLastFM(artist, languages) }); err != nil {
 				enrichmentErrors = append(enrichmentErrors, fmt.Sprintf("lastfm: %v", err))
 			}
 		case "musicbrainz":
@@ -118,13 +210,12 @@ func (s *EnrichmentService) EnrichExistingArtist(artist *models.Artist, options
 		}
 	}
 
+	// Sources disagree on spelling and mix in non-genre tags
+	artist.Genres = taxonomy.NormalizeAll(artist.Genres)
+
 	// Update cache expiry
 	artist.LastUpdated = time.Now()
-	if hasSuccessfulVerification(artist) {
-		artist.CacheExpiry = time.Now().Add(30 * 24 * time.Hour) // 30 days for verified
-	} else {
-		artist.CacheExpiry = time.Now().Add(7 * 24 * time.Hour) // 7 days for failed lookups
-	}
+	artist.CacheExpiry = s.ttlPolicy.Expiry(artist, artist.LastUpdated)
 
 	// Return combined errors if any
 	if len(enrichmentErrors) > 0 {
@@ -143,13 +234,47 @@ func (s *EnrichmentService) enrichWithDiscogs(artist *models.Artist) error {
 	return s.discogs.EnrichArtist(artist)
 }
 
-// enrichWithLastFM enriches artist with Last.fm data
-func (s *EnrichmentService) enrichWithLastFM(artist *models.Artist) error {
+// enrichWithLastFM enriches artist with Last.fm data, asking for the bio in the first preferred language
+func (s *EnrichmentService) enrichWithLastFM(artist *models.Artist, languages []string) error {
 	if s.lastfm == nil {
 		return fmt.Errorf("lastfm client not initialized")
 	}
 
-	return s.lastfm.EnrichArtist(artist)
+	lang := ""
+	if len(languages) > 0 {
+		lang = preferredLanguages(languages)[0]
+	}
+
+	return s.lastfm.EnrichArtistInLanguage(artist, lang)
+}
+
+// enrichWithWikipedia enriches artist with the Wikipedia intro found via its Wikidata relation
+func (s *EnrichmentService) enrichWithWikipedia(artist *models.Artist, languages []string, relationsLoaded bool) error {
+	if s.wikipedia == nil {
+		return fmt.Errorf("wikipedia client not initialized")
+	}
+
+	// Cached artists from before relations were fetched have no Wikidata link yet
+	if !relationsLoaded && artist.ExternalURLs.Wikidata == "" && artist.ExternalURLs.Wikipedia == "" && artist.MBID != "" {
+		relations, err := s.musicbrainz.GetRelations(artist.MBID)
+		if err != nil {
+			enrichmentLog.Warn("Failed to load relations", "artist", artist.Name, "error", err)
+		} else {
+			applyRelations(artist, relations)
+		}
+	}
+
+	return s.wikipedia.EnrichArtist(artist, languages)
+}
+
+// traceCall runs a call to an upstream source in a span of its own
+func traceCall(ctx context.Context, name string, call func() error) error {
+	_, span := tracing.Start(ctx, name)
+	defer span.End()
+
+	err := call()
+	span.RecordError(err)
+	return err
 }
 
 // hasSuccessfulVerification checks if artist has at least one successful verification
@@ -193,6 +318,10 @@ func (s *EnrichmentService) GetEnrichmentStatus(artist *models.Artist) map[strin
 		sources["lastfm"] = true
 	}
 
+	if artist.ExternalURLs.Wikipedia != "" {
+		sources["wikipedia"] = true
+	}
+
 	// Data completeness indicators
 	status["has_description"] = artist.Description != ""
 	status["has_image"] = artist.ImageURL != ""
@@ -202,12 +331,56 @@ func (s *EnrichmentService) GetEnrichmentStatus(artist *models.Artist) map[strin
 	return status
 }
 
+// UseResponseCache routes client requests through raw response caches stored in store.
+// ttls sets freshness per source ("musicbrainz", "discogs", "lastfm", "wikipedia"); sources without
+// a TTL are not cached. In offline mode no network calls are made and uncached requests fail.
+func (s *EnrichmentService) UseResponseCache(store ResponseStore, ttls map[string]time.Duration, offline bool) {
+	clients := map[string]*http.Client{
+		"musicbrainz": s.musicbrainz.httpClient,
+		"discogs":     s.discogs.httpClient,
+		"lastfm":      s.lastfm.httpClient,
+		"wikipedia":   s.wikipedia.httpClient,
+	}
+
+	s.responseCaches = make(map[string]*CachingTransport, len(clients))
+	for source, client := range clients {
+		ttl, ok := ttls[source]
+		if !ok && !offline {
+			continue
+		}
+
+		next := client.Transport
+		if existing, ok := next.(*CachingTransport); ok {
+			next = existing.next // Replace rather than stack caches
+		}
+
+		transport := NewCachingTransport(source, ttl, store, next, offline)
+		client.Transport = transport
+		s.responseCaches[source] = transport
+	}
+}
+
+// UseTTLPolicy sets the policy that decides when enriched artists expire
+func (s *EnrichmentService) UseTTLPolicy(policy db.CacheConfig) {
+	s.ttlPolicy = policy
+}
+
+// GetResponseCacheStats returns request counters per cached source
+func (s *EnrichmentService) GetResponseCacheStats() map[string]ResponseCacheStats {
+	stats := make(map[string]ResponseCacheStats, len(s.responseCaches))
+	for source, transport := range s.responseCaches {
+		stats[source] = transport.Stats()
+	}
+	return stats
+}
+
 // ValidateEnrichmentConfig validates that required API credentials are available
 func (s *EnrichmentService) ValidateEnrichmentConfig() map[string]bool {
 	config := map[string]bool{
 		"musicbrainz": s.musicbrainz != nil,
 		"discogs":     s.discogs != nil && s.discogs.token != "",
 		"lastfm":      s.lastfm != nil && s.lastfm.apiKey != "",
+		"wikipedia":   s.wikipedia != nil,
 	}
 
 	return config
@@ -224,4 +397,7 @@ func (s *EnrichmentService) Close() {
 	if s.lastfm != nil {
 		s.lastfm.Close()
 	}
+	if s.wikipedia != nil {
+		s.wikipedia.Close()
+	}
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -291,7 +292,7 @@ func TestEnrichExistingArtistCacheValid(t *testing.T) {
 		ForceUpdate: false,
 	}
 
-	err := service.EnrichExistingArtist(artist, options)
+	err := service.EnrichExistingArtist(context.Background(), artist, options)
 	if err != nil {
 		t.Errorf("EnrichExistingArtist should not return error for valid cache, got: %v", err)
 	}
@@ -314,7 +315,7 @@ func TestEnrichExistingArtistForceUpdate(t *testing.T) {
 	}
 
 	// Should not fail even with empty tokens (graceful degradation)
-	err := service.EnrichExistingArtist(artist, options)
+	err := service.EnrichExistingArtist(context.Background(), artist, options)
 	if err != nil {
 		// Errors are expected but shouldn't panic
 		t.Logf("Expected enrichment errors with empty tokens: %v", err)
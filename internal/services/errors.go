@@ -0,0 +1,76 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrorCode identifies a kind of failure. Codes are part of the API: clients
+// match on them, so existing codes must not be renamed.
+type ErrorCode string
+
+const (
+	CodeInvalidRequest      ErrorCode = "invalid_request"
+	CodeUnauthorized        ErrorCode = "unauthorized"
+	CodeForbidden           ErrorCode = "forbidden"
+	CodeNotFound            ErrorCode = "not_found"
+	CodeMethodNotAllowed    ErrorCode = "method_not_allowed"
+	CodeRateLimited         ErrorCode = "rate_limited"
+	CodeInternal            ErrorCode = "internal_error"
+	CodeNotImplemented      ErrorCode = "not_implemented"
+	CodeUnavailable         ErrorCode = "unavailable"
+	CodePlaylistNotFound    ErrorCode = "playlist_not_found"
+	CodeNoSeedTracks        ErrorCode = "no_seed_tracks"
+	CodeNoRecommendations   ErrorCode = "no_recommendations"
+	CodeEngineUnavailable   ErrorCode = "engine_unavailable"
+	CodeQuotaExceeded       ErrorCode = "llm_quota_exceeded"
+	CodePlexUnauthorized    ErrorCode = "plex_unauthorized"
+	CodePlexError           ErrorCode = "plex_error"
+	CodeUpstreamUnavailable ErrorCode = "upstream_unavailable"
+	CodeUpstreamTimeout     ErrorCode = "upstream_timeout"
+)
+
+// ErrorCodes lists every code, for API documentation
+var ErrorCodes = []ErrorCode{
+	CodeInvalidRequest, CodeUnauthorized, CodeForbidden, CodeNotFound, CodeMethodNotAllowed,
+	CodeRateLimited, CodeInternal, CodeNotImplemented, CodeUnavailable, CodePlaylistNotFound,
+	CodeNoSeedTracks, CodeNoRecommendations, CodeEngineUnavailable, CodeQuotaExceeded,
+	CodePlexUnauthorized, CodePlexError, CodeUpstreamUnavailable, CodeUpstreamTimeout,
+}
+
+// Error is a failure the caller can act on, such as a missing playlist. Message
+// is safe to show to API clients; Details holds machine-readable context.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewError creates a service error
+func NewError(code ErrorCode, message string, details map[string]interface{}) *Error {
+	return &Error{Code: code, Message: message, Details: details}
+}
+
+// Code returns the code of the first *Error, *PlexError or *QuotaExceededError
+// in err's chain, or "" if there is none
+func Code(err error) ErrorCode {
+	var serviceErr *Error
+	var plexErr *PlexError
+	var quotaErr *QuotaExceededError
+	switch {
+	case errors.As(err, &serviceErr):
+		return serviceErr.Code
+	case errors.As(err, &quotaErr):
+		return CodeQuotaExceeded
+	case errors.As(err, &plexErr):
+		if plexErr.StatusCode == http.StatusUnauthorized || plexErr.StatusCode == http.StatusForbidden {
+			return CodePlexUnauthorized
+		}
+		return CodePlexError
+	}
+	return ""
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	playlist := fmt.Errorf("failed to find playlist: %w", NewError(CodePlaylistNotFound, "playlist 'x' not found", nil))
+	tests := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{"service error", playlist, CodePlaylistNotFound},
+		{"quota", fmt.Errorf("failed: %w", &QuotaExceededError{Period: "daily"}), CodeQuotaExceeded},
+		{"plex unauthorized", &PlexError{StatusCode: http.StatusUnauthorized}, CodePlexUnauthorized},
+		{"plex forbidden", &PlexError{StatusCode: http.StatusForbidden}, CodePlexUnauthorized},
+		{"plex other", fmt.Errorf("failed: %w", &PlexError{StatusCode: http.StatusInternalServerError}), CodePlexError},
+		{"plain", errors.New("boom"), ""},
+	}
+	for _, tt := range tests {
+		if got := Code(tt.err); got != tt.want {
+			t.Errorf("%s: Code() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if !IsNotFound(playlist) {
+		t.Error("Missing playlist should be not found")
+	}
+	if !IsUnauthorized(fmt.Errorf("wrapped: %w", &PlexError{StatusCode: http.StatusUnauthorized})) {
+		t.Error("Wrapped Plex 401 should be unauthorized")
+	}
+}
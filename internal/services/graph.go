@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gocommender/internal/db"
+	"gocommender/internal/models"
+	"gocommender/internal/taxonomy"
+	"gocommender/internal/tracing"
+)
+
+// GraphRecommender suggests artists by walking the similarity graph outward from seed artists.
+// It needs no LLM: candidates are scored by how many seeds reach them and by the edge weights on the way.
+type GraphRecommender struct {
+	cacheManager      *db.CacheManager
+	enrichmentService *EnrichmentService // Optional, resolves seeds that are not cached yet
+
+	maxSeeds int     // Seed artists resolved per request
+	maxDepth int     // Hops walked from each seed
+	fanOut   int     // Strongest edges followed per artist
+	decay    float64 // Weight multiplier applied per extra hop
+}
+
+// GraphCandidate is an artist reached from one or more seeds
+type GraphCandidate struct {
+	Name      string   `json:"name"`
+	MBID      string   `json:"mbid,omitempty"`
+	Score     float64  `json:"score"`
+	SeedLinks int      `json:"seed_links"` // Number of seeds that reach this artist
+	Seeds     []string `json:"seeds"`
+}
+
+// NewGraphRecommender creates a graph recommender.
+// enrichment may be nil, in which case only already cached seeds are used.
+func NewGraphRecommender(cache *db.CacheManager, enrichment *EnrichmentService) *GraphRecommender {
+	return &GraphRecommender{
+		cacheManager:      cache,
+		enrichmentService: enrichment,
+		maxSeeds:          10,
+		maxDepth:          2,
+		fanOut:            25,
+		decay:             0.5,
+	}
+}
+
+// Recommend returns up to limit candidates reachable from the seed artists, excluding known artists
+func (g *GraphRecommender) Recommend(ctx context.Context, seedArtists, knownArtists []string, genre string, limit int) (_ []GraphCandidate, err error) {
+	ctx, span := tracing.Start(ctx, "graph.Recommend", tracing.Int("graph.seed_artists", len(seedArtists)))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	if g.cacheManager == nil {
+		return nil, NewError(CodeEngineUnavailable, "graph recommendations require the artist cache",
+			map[string]interface{}{"engine": models.EngineGraph})
+	}
+
+	candidates := make(map[string]*GraphCandidate)
+	seedKeys := make(map[string]bool)
+	resolved := 0
+
+	for _, name := range seedArtists {
+		if resolved >= g.maxSeeds {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		seed, err := g.resolveSeed(ctx, name)
+		if err != nil {
+			recommendationLog.WarnContext(ctx, "Graph seed skipped", "artist", name, "error", err)
+			continue
+		}
+		resolved++
+		seedKeys[candidateKey(seed.Name)] = true
+		seedKeys[candidateKey(name)] = true
+
+		reached, err := g.walk(seed.MBID)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, hit := range reached {
+			candidate, exists := candidates[key]
+			if !exists {
+				candidate = &GraphCandidate{Name: hit.Name}
+				candidates[key] = candidate
+			}
+			if candidate.MBID == "" {
+				candidate.MBID = hit.MBID
+			}
+			candidate.Score += hit.Match
+			candidate.SeedLinks++
+			candidate.Seeds = append(candidate.Seeds, seed.Name)
+		}
+	}
+
+	if resolved == 0 {
+		return nil, NewError(CodeNoRecommendations,
+			fmt.Sprintf("none of the %d seed artists could be found in the similarity graph", len(seedArtists)),
+			map[string]interface{}{"engine": models.EngineGraph, "seed_artists": len(seedArtists)})
+	}
+
+	knownKeys := make(map[string]bool, len(knownArtists))
+	for _, known := range knownArtists {
+		knownKeys[candidateKey(known)] = true
+	}
+
+	ranked := make([]GraphCandidate, 0, len(candidates))
+	for key, candidate := range candidates {
+		if seedKeys[key] || knownKeys[key] || isSimilarToKnown(candidate.Name, knownArtists) {
+			continue
+		}
+		if genre != "" && !g.candidateMatchesGenre(candidate, genre) {
+			continue
+		}
+		ranked = append(ranked, *candidate)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].SeedLinks != ranked[j].SeedLinks {
+			return ranked[i].SeedLinks > ranked[j].SeedLinks
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return ranked, nil
+}
+
+// resolveSeed finds a seed artist in the cache, enriching it first when missing or expired
+func (g *GraphRecommender) resolveSeed(ctx context.Context, name string) (*models.Artist, error) {
+	cached, err := g.cacheManager.FindArtistByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	needsFetch := cached == nil
+	if cached != nil {
+		_, needsFetch, err = g.cacheManager.GetOrFetchArtist(cached.MBID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !needsFetch || g.enrichmentService == nil {
+		if cached == nil {
+			return nil, fmt.Errorf("artist not cached")
+		}
+		return cached, nil
+	}
+
+	artist, err := g.enrichmentService.EnrichArtistByName(ctx, name, nil)
+	if err != nil {
+		if cached != nil {
+			return cached, nil // Stale graph data is better than none
+		}
+		return nil, err
+	}
+
+	if err := g.cacheManager.CacheArtist(artist, g.cacheManager.TTLPolicy()); err != nil {
+		recommendationLog.WarnContext(ctx, "Failed to cache graph seed", "artist", name, "error", err)
+	}
+
+	return artist, nil
+}
+
+// walk returns every artist reachable from mbid within maxDepth hops, keyed by candidateKey,
+// with Match set to the strongest path weight found
+func (g *GraphRecommender) walk(mbid string) (map[string]models.SimilarArtist, error) {
+	reached := make(map[string]models.SimilarArtist)
+	visited := map[string]bool{mbid: true}
+
+	type node struct {
+		mbid   string
+		weight float64
+	}
+	frontier := []node{{mbid: mbid, weight: 1}}
+
+	for depth := 1; depth <= g.maxDepth && len(frontier) > 0; depth++ {
+		hopDecay := 1.0
+		if depth > 1 {
+			hopDecay = g.decay
+		}
+
+		var next []node
+		for _, current := range frontier {
+			edges, err := g.cacheManager.GetSimilarArtists(current.mbid, 0, g.fanOut)
+			if err != nil {
+				return nil, fmt.Errorf("failed to walk similarity graph: %w", err)
+			}
+
+			for _, edge := range edges {
+				weight := current.weight * edge.Match * hopDecay
+				key := candidateKey(edge.Name)
+
+				best, seen := reached[key]
+				if !seen || weight > best.Match {
+					if seen && edge.MBID == "" {
+						edge.MBID = best.MBID
+					}
+					edge.Match = weight
+					reached[key] = edge
+				}
+
+				// Only cached neighbours have outgoing edges worth following
+				if edge.MBID != "" && edge.Cached && !visited[edge.MBID] {
+					visited[edge.MBID] = true
+					next = append(next, node{mbid: edge.MBID, weight: weight})
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return reached, nil
+}
+
+// candidateMatchesGenre keeps candidates whose cached genres fall under the requested genre,
+// including subgenres. Candidates without cached genres are kept since nothing is known about them yet.
+func (g *GraphRecommender) candidateMatchesGenre(candidate *GraphCandidate, genre string) bool {
+	if candidate.MBID == "" {
+		return true
+	}
+
+	artist, _, err := g.cacheManager.GetOrFetchArtist(candidate.MBID)
+	if err != nil || artist == nil || len(artist.Genres) == 0 {
+		return true
+	}
+
+	return taxonomy.MatchesAny(artist.Genres, genre)
+}
+
+// candidateKey normalizes artist names so edges from different sources merge
+func candidateKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gocommender/internal/config"
+	"gocommender/internal/db"
+	"gocommender/internal/models"
+)
+
+// setupGraphCache creates a cache with a small similarity graph:
+//
+//	Seed A -> Shared (0.9), Only A (0.8), Known Band (0.95)
+//	Seed B -> Shared (0.7), Hub (0.6)
+//	Hub    -> Second Hop (1.0)
+func setupGraphCache(t *testing.T) *db.CacheManager {
+	t.Helper()
+
+	database, err := config.InitDatabase(filepath.Join(t.TempDir(), "graph.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	cache := db.NewCacheManager(database)
+	edge := func(name, mbid string, match float64) models.SimilarArtist {
+		return models.SimilarArtist{Name: name, MBID: mbid, Match: match, Source: models.SimilaritySourceLastFM}
+	}
+
+	artists := []models.Artist{
+		{MBID: "seed-a", Name: "Seed A", Genres: models.Genres{"rock"}, Similar: []models.SimilarArtist{
+			edge("Shared", "shared", 0.9), edge("Only A", "", 0.8), edge("Known Band", "", 0.95),
+		}},
+		{MBID: "seed-b", Name: "Seed B", Similar: []models.SimilarArtist{
+			edge("Shared", "shared", 0.7), edge("Hub", "hub", 0.6),
+		}},
+		{MBID: "hub", Name: "Hub", Genres: models.Genres{"jazz"}, Similar: []models.SimilarArtist{
+			edge("Second Hop", "", 1.0), edge("Seed A", "seed-a", 0.5),
+		}},
+		{MBID: "shared", Name: "Shared", Genres: models.Genres{"indie rock"}},
+	}
+	for i := range artists {
+		artists[i].Verified = models.VerificationMap{"lastfm": true}
+		if err := cache.CacheArtist(&artists[i], db.DefaultCacheConfig()); err != nil {
+			t.Fatalf("Failed to cache %s: %v", artists[i].Name, err)
+		}
+	}
+
+	return cache
+}
+
+func TestGraphRecommenderRecommend(t *testing.T) {
+	graph := NewGraphRecommender(setupGraphCache(t), nil)
+
+	candidates, err := graph.Recommend(context.Background(), []string{"Seed A", "seed b", "Not Cached"}, []string{"Known Band"}, "", 10)
+	if err != nil {
+		t.Fatalf("Recommend failed: %v", err)
+	}
+
+	names := candidateNames(candidates)
+	expected := []string{"Shared", "Only A", "Hub", "Second Hop"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Position %d: expected %s, got %s", i, expected[i], names[i])
+		}
+	}
+
+	if candidates[0].SeedLinks != 2 || candidates[0].Score < 1.59 || candidates[0].Score > 1.61 {
+		t.Errorf("Expected Shared to be linked by both seeds with score 1.6, got %+v", candidates[0])
+	}
+
+	// Second hop is discounted: 0.6 * 1.0 * 0.5
+	if hop := candidates[3]; hop.Score < 0.29 || hop.Score > 0.31 {
+		t.Errorf("Expected second hop score 0.3, got %v", hop.Score)
+	}
+}
+
+func TestGraphRecommenderGenreAndLimit(t *testing.T) {
+	graph := NewGraphRecommender(setupGraphCache(t), nil)
+
+	candidates, err := graph.Recommend(context.Background(), []string{"Seed A", "Seed B"}, nil, "rock", 2)
+	if err != nil {
+		t.Fatalf("Recommend failed: %v", err)
+	}
+
+	// Hub is cached as jazz and dropped; uncached candidates are kept
+	names := candidateNames(candidates)
+	if len(names) != 2 || names[0] != "Shared" || names[1] != "Known Band" {
+		t.Errorf("Unexpected candidates: %v", names)
+	}
+}
+
+func TestGraphRecommenderNoSeeds(t *testing.T) {
+	graph := NewGraphRecommender(setupGraphCache(t), nil)
+
+	if _, err := graph.Recommend(context.Background(), []string{"Nobody"}, nil, "", 5); err == nil {
+		t.Error("Expected error when no seed is in the graph")
+	}
+}
+
+func TestGraphRecommenderGenreHierarchy(t *testing.T) {
+	cache := setupGraphCache(t)
+	doom := &models.Artist{MBID: "shared", Name: "Shared", Genres: models.Genres{"Funeral Doom Metal"}}
+	if err := cache.CacheArtist(doom, db.DefaultCacheConfig()); err != nil {
+		t.Fatalf("Failed to re-cache Shared: %v", err)
+	}
+
+	graph := NewGraphRecommender(cache, nil)
+	candidates, err := graph.Recommend(context.Background(), []string{"Seed A", "Seed B"}, nil, "Metal", 10)
+	if err != nil {
+		t.Fatalf("Recommend failed: %v", err)
+	}
+
+	names := candidateNames(candidates)
+	if len(names) == 0 || names[0] != "Shared" {
+		t.Errorf("Expected doom metal artist to match metal, got %v", names)
+	}
+	for _, name := range names {
+		if name == "Hub" {
+			t.Errorf("Expected jazz artist to be filtered, got %v", names)
+		}
+	}
+}
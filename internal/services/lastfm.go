@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"gocommender/internal/metrics"
 	"gocommender/internal/models"
+	"gocommender/internal/taxonomy"
 )
 
 // LastFMClient handles Last.fm API interactions
@@ -72,6 +75,8 @@ type LastFMSimilar struct {
 
 type LastFMSimilarArtist struct {
 	Name  string        `json:"name"`
+	MBID  string        `json:"mbid"`
+	Match json.Number   `json:"match"` // Only present in artist.getsimilar responses
 	URL   string        `json:"url"`
 	Image []LastFMImage `json:"image"`
 }
@@ -86,12 +91,21 @@ type LastFMResponse struct {
 	Artist LastFMArtist `json:"artist"`
 }
 
+// LastFMSimilarResponse wraps the artist.getsimilar response
+type LastFMSimilarResponse struct {
+	SimilarArtists LastFMSimilar `json:"similarartists"`
+}
+
+// similarArtistLimit is how many neighbours are stored per artist
+const similarArtistLimit = 50
+
 // NewLastFMClient creates a new Last.fm API client
 func NewLastFMClient(apiKey, secret string) *LastFMClient {
 	return &LastFMClient{
 		baseURL: "https://ws.audioscrobbler.com/2.0",
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: metrics.InstrumentTransport("lastfm", nil),
 		},
 		apiKey:      apiKey,
 		secret:      secret,
@@ -101,18 +115,32 @@ func NewLastFMClient(apiKey, secret string) *LastFMClient {
 
 // GetArtistInfo fetches detailed artist information by name
 func (c *LastFMClient) GetArtistInfo(name string) (*LastFMArtist, error) {
+	return c.getArtistInfo(map[string]string{"artist": name}, "")
+}
+
+// GetArtistInfoByMBID fetches detailed artist information by MusicBrainz ID
+func (c *LastFMClient) GetArtistInfoByMBID(mbid string) (*LastFMArtist, error) {
+	return c.getArtistInfo(map[string]string{"mbid": mbid}, "")
+}
+
+// getArtistInfo calls artist.getinfo with the given lookup parameters.
+// lang selects the biography language; Last.fm falls back to English when empty.
+func (c *LastFMClient) getArtistInfo(lookup map[string]string, lang string) (*LastFMArtist, error) {
 	if c.apiKey == "" {
 		return nil, fmt.Errorf("last.fm API key not configured")
 	}
 
-	<-c.rateLimiter.C // Rate limiting
-
 	params := map[string]string{
 		"method":  "artist.getinfo",
-		"artist":  name,
 		"api_key": c.apiKey,
 		"format":  "json",
 	}
+	for key, value := range lookup {
+		params[key] = value
+	}
+	if lang != "" {
+		params["lang"] = lang
+	}
 
 	urlStr := c.buildURL(params)
 
@@ -123,6 +151,7 @@ func (c *LastFMClient) GetArtistInfo(name string) (*LastFMArtist, error) {
 
 	req.Header.Set("User-Agent", "GoCommender/1.0")
 
+	waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -133,6 +162,12 @@ func (c *LastFMClient) GetArtistInfo(name string) (*LastFMArtist, error) {
 		return nil, fmt.Errorf("invalid last.fm API key")
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		if mbid, ok := lookup["mbid"]; ok {
+			return nil, fmt.Errorf("artist with MBID %s not found", mbid)
+		}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
@@ -145,58 +180,57 @@ func (c *LastFMClient) GetArtistInfo(name string) (*LastFMArtist, error) {
 	return &response.Artist, nil
 }
 
-// GetArtistInfoByMBID fetches detailed artist information by MusicBrainz ID
-func (c *LastFMClient) GetArtistInfoByMBID(mbid string) (*LastFMArtist, error) {
+// GetSimilarArtists fetches weighted similar artists by MBID, or by name when mbid is empty
+func (c *LastFMClient) GetSimilarArtists(mbid, name string, limit int) ([]LastFMSimilarArtist, error) {
 	if c.apiKey == "" {
 		return nil, fmt.Errorf("last.fm API key not configured")
 	}
 
-	<-c.rateLimiter.C // Rate limiting
-
 	params := map[string]string{
-		"method":  "artist.getinfo",
-		"mbid":    mbid,
+		"method":  "artist.getsimilar",
 		"api_key": c.apiKey,
 		"format":  "json",
+		"limit":   strconv.Itoa(limit),
+	}
+	if mbid != "" {
+		params["mbid"] = mbid
+	} else {
+		params["artist"] = name
 	}
 
-	urlStr := c.buildURL(params)
-
-	req, err := http.NewRequest("GET", urlStr, nil)
+	req, err := http.NewRequest("GET", c.buildURL(params), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("User-Agent", "GoCommender/1.0")
 
+	waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusForbidden {
-		return nil, fmt.Errorf("invalid last.fm API key")
-	}
-
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("artist with MBID %s not found", mbid)
-	}
-
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
 	}
 
-	var response LastFMResponse
+	var response LastFMSimilarResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return &response.Artist, nil
+	return response.SimilarArtists.Artist, nil
 }
 
 // EnrichArtist enriches an existing Artist model with Last.fm data
 func (c *LastFMClient) EnrichArtist(artist *models.Artist) error {
+	return c.EnrichArtistInLanguage(artist, "")
+}
+
+// EnrichArtistInLanguage enriches an artist, requesting the biography in the given language
+func (c *LastFMClient) EnrichArtistInLanguage(artist *models.Artist, lang string) error {
 	if c.apiKey == "" {
 		// Graceful degradation - just mark as not verified
 		if artist.Verified == nil {
@@ -211,11 +245,11 @@ func (c *LastFMClient) EnrichArtist(artist *models.Artist) error {
 
 	// Try by MBID first, then by name
 	if artist.MBID != "" {
-		lastfmArtist, err = c.GetArtistInfoByMBID(artist.MBID)
+		lastfmArtist, err = c.getArtistInfo(map[string]string{"mbid": artist.MBID}, lang)
 	}
 
 	if err != nil || lastfmArtist == nil {
-		lastfmArtist, err = c.GetArtistInfo(artist.Name)
+		lastfmArtist, err = c.getArtistInfo(map[string]string{"artist": artist.Name}, lang)
 	}
 
 	if err != nil {
@@ -248,13 +282,12 @@ func (c *LastFMClient) EnrichArtist(artist *models.Artist) error {
 
 	// Add genres from tags
 	if len(artist.Genres) == 0 && len(lastfmArtist.Tags.Tag) > 0 {
-		genres := make([]string, 0, len(lastfmArtist.Tags.Tag))
+		tags := make([]string, 0, len(lastfmArtist.Tags.Tag))
 		for _, tag := range lastfmArtist.Tags.Tag {
-			if tag.Name != "" {
-				genres = append(genres, tag.Name)
-			}
+			tags = append(tags, tag.Name)
 		}
-		if len(genres) > 0 {
+		// Tags such as "seen live" are dropped by the taxonomy
+		if genres := taxonomy.NormalizeAll(tags); len(genres) > 0 {
 			artist.Genres = genres
 		}
 	}
@@ -264,9 +297,65 @@ func (c *LastFMClient) EnrichArtist(artist *models.Artist) error {
 		artist.ExternalURLs.LastFM = lastfmArtist.URL
 	}
 
+	// Popularity snapshot
+	artist.Listeners = parseLastFMCount(lastfmArtist.Stats.Listeners)
+	artist.Playcount = parseLastFMCount(lastfmArtist.Stats.Playcount)
+
+	// Replace only Last.fm edges, keeping those from other sources
+	similar := make([]models.SimilarArtist, 0, len(artist.Similar))
+	for _, edge := range artist.Similar {
+		if edge.Source != models.SimilaritySourceLastFM {
+			similar = append(similar, edge)
+		}
+	}
+	artist.Similar = append(similar, c.similarArtists(artist, lastfmArtist)...)
+
 	return nil
 }
 
+// similarArtists returns weighted similarity edges for an artist.
+// Falls back to the unweighted getinfo list, weighted by rank, when artist.getsimilar fails.
+func (c *LastFMClient) similarArtists(artist *models.Artist, lastfmArtist *LastFMArtist) []models.SimilarArtist {
+	similar, err := c.GetSimilarArtists(artist.MBID, artist.Name, similarArtistLimit)
+	weighted := err == nil
+	if err != nil {
+		similar = lastfmArtist.Similar.Artist
+	}
+
+	edges := make([]models.SimilarArtist, 0, len(similar))
+	for i, s := range similar {
+		if s.Name == "" {
+			continue
+		}
+
+		match := 1 - float64(i)/float64(len(similar))
+		if weighted {
+			if parsed, err := s.Match.Float64(); err == nil {
+				match = parsed
+			}
+		}
+
+		edges = append(edges, models.SimilarArtist{
+			ArtistMBID: artist.MBID,
+			Name:       s.Name,
+			MBID:       s.MBID,
+			Match:      match,
+			Source:     models.SimilaritySourceLastFM,
+		})
+	}
+
+	return edges
+}
+
+// parseLastFMCount parses the numeric strings Last.fm uses for stats
+func parseLastFMCount(value string) int64 {
+	count, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
 // buildURL builds the Last.fm API URL with parameters
 func (c *LastFMClient) buildURL(params map[string]string) string {
 	values := url.Values{}
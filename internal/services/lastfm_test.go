@@ -1,6 +1,8 @@
 package services
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -233,3 +235,79 @@ func indexString(s, substr string) int {
 	}
 	return -1
 }
+
+func TestLastFMEnrichArtistPopularityAndSimilar(t *testing.T) {
+	tests := []struct {
+		name          string
+		similarStatus int
+		expectedMatch []float64
+	}{
+		{"weighted getsimilar", http.StatusOK, []float64{0.9, 0.45}},
+		{"getinfo fallback", http.StatusInternalServerError, []float64{1, 0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Query().Get("method") {
+				case "artist.getinfo":
+					w.Write([]byte(`{"artist":{"name":"Test Artist","url":"https://www.last.fm/music/Test+Artist",
+						"stats":{"listeners":"123456","playcount":"7890123"},
+						"similar":{"artist":[{"name":"Neighbour One"},{"name":"Neighbour Two"}]}}}`))
+				case "artist.getsimilar":
+					if tt.similarStatus != http.StatusOK {
+						w.WriteHeader(tt.similarStatus)
+						return
+					}
+					w.Write([]byte(`{"similarartists":{"artist":[
+						{"name":"Neighbour One","mbid":"mbid-1","match":"0.9"},
+						{"name":"Neighbour Two","mbid":"","match":0.45}]}}`))
+				default:
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			}))
+			defer server.Close()
+
+			client := NewLastFMClient("test-key", "")
+			client.baseURL = server.URL
+			defer client.Close()
+
+			artist := &models.Artist{MBID: "artist-mbid", Name: "Test Artist"}
+			if err := client.EnrichArtist(artist); err != nil {
+				t.Fatalf("EnrichArtist failed: %v", err)
+			}
+
+			if artist.Listeners != 123456 || artist.Playcount != 7890123 {
+				t.Errorf("Unexpected popularity: listeners=%d playcount=%d", artist.Listeners, artist.Playcount)
+			}
+
+			if len(artist.Similar) != len(tt.expectedMatch) {
+				t.Fatalf("Expected %d similar artists, got %d", len(tt.expectedMatch), len(artist.Similar))
+			}
+			for i, edge := range artist.Similar {
+				if edge.Match != tt.expectedMatch[i] {
+					t.Errorf("Edge %d: expected match %v, got %v", i, tt.expectedMatch[i], edge.Match)
+				}
+				if edge.ArtistMBID != "artist-mbid" || edge.Source != models.SimilaritySourceLastFM {
+					t.Errorf("Edge %d has unexpected origin: %+v", i, edge)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLastFMCount(t *testing.T) {
+	tests := map[string]int64{
+		"12345": 12345,
+		" 42 ":  42,
+		"":      0,
+		"n/a":   0,
+	}
+
+	for input, expected := range tests {
+		if result := parseLastFMCount(input); result != expected {
+			t.Errorf("parseLastFMCount(%q) = %d, want %d", input, result, expected)
+		}
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"gocommender/internal/metrics"
 	"gocommender/internal/models"
+	"gocommender/internal/taxonomy"
 )
 
 // MusicBrainzClient handles MusicBrainz API interactions
@@ -26,9 +29,11 @@ type MusicBrainzArtist struct {
 	Country   string               `json:"country"`
 	BeginArea *MusicBrainzArea     `json:"begin-area"`
 	LifeSpan  *MusicBrainzLifeSpan `json:"life-span"`
-	Releases  []MusicBrainzRelease `json:"releases"`
 	Tags      []MusicBrainzTag     `json:"tags"`
 	Genres    []MusicBrainzGenre   `json:"genres"`
+
+	ReleaseGroups []MusicBrainzReleaseGroup `json:"release-groups"`
+	Relations     []MusicBrainzRelation     `json:"relations"`
 }
 
 type MusicBrainzArea struct {
@@ -40,10 +45,24 @@ type MusicBrainzLifeSpan struct {
 	End   string `json:"end"`
 }
 
-type MusicBrainzRelease struct {
-	ID    string `json:"id"`
-	Title string `json:"title"`
-	Date  string `json:"date"`
+type MusicBrainzReleaseGroup struct {
+	ID               string   `json:"id"`
+	Title            string   `json:"title"`
+	PrimaryType      string   `json:"primary-type"`
+	SecondaryTypes   []string `json:"secondary-types"`
+	FirstReleaseDate string   `json:"first-release-date"`
+}
+
+type MusicBrainzRelation struct {
+	Type       string `json:"type"`        // e.g. "wikidata", "wikipedia", "member of band", "collaboration"
+	TargetType string `json:"target-type"` // "url" or "artist"
+	URL        struct {
+		Resource string `json:"resource"`
+	} `json:"url"`
+	Artist *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"artist,omitempty"`
 }
 
 type MusicBrainzTag struct {
@@ -61,7 +80,8 @@ func NewMusicBrainzClient() *MusicBrainzClient {
 	return &MusicBrainzClient{
 		baseURL: "https://musicbrainz.org/ws/2",
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: metrics.InstrumentTransport("musicbrainz", nil),
 		},
 		userAgent:   "GoCommender/1.0 (https://github.com/lepinkainen/gocommender)",
 		rateLimiter: time.NewTicker(1100 * time.Millisecond), // ~50 requests/minute
@@ -70,8 +90,6 @@ func NewMusicBrainzClient() *MusicBrainzClient {
 
 // SearchArtist searches for artists by name and returns the best match with MBID
 func (c *MusicBrainzClient) SearchArtist(name string) (*MusicBrainzArtist, error) {
-	<-c.rateLimiter.C // Rate limiting
-
 	query := url.QueryEscape(fmt.Sprintf(`artist:"%s"`, name))
 	urlStr := fmt.Sprintf("%s/artist?query=%s&fmt=json&limit=1", c.baseURL, query)
 
@@ -82,6 +100,7 @@ func (c *MusicBrainzClient) SearchArtist(name string) (*MusicBrainzArtist, error
 
 	req.Header.Set("User-Agent", c.userAgent)
 
+	waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -109,9 +128,7 @@ func (c *MusicBrainzClient) SearchArtist(name string) (*MusicBrainzArtist, error
 
 // GetArtistByMBID fetches detailed artist information by MusicBrainz ID
 func (c *MusicBrainzClient) GetArtistByMBID(mbid string) (*MusicBrainzArtist, error) {
-	<-c.rateLimiter.C // Rate limiting
-
-	urlStr := fmt.Sprintf("%s/artist/%s?fmt=json&inc=releases+tags+genres", c.baseURL, mbid)
+	urlStr := fmt.Sprintf("%s/artist/%s?fmt=json&inc=release-groups+tags+genres+url-rels+artist-rels", c.baseURL, mbid)
 
 	req, err := http.NewRequest("GET", urlStr, nil)
 	if err != nil {
@@ -120,6 +137,7 @@ func (c *MusicBrainzClient) GetArtistByMBID(mbid string) (*MusicBrainzArtist, er
 
 	req.Header.Set("User-Agent", c.userAgent)
 
+	waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -142,25 +160,140 @@ func (c *MusicBrainzClient) GetArtistByMBID(mbid string) (*MusicBrainzArtist, er
 	return &artist, nil
 }
 
+// GetRelations fetches only the external links (Wikidata, Wikipedia, ...) and artist relations of an artist
+func (c *MusicBrainzClient) GetRelations(mbid string) ([]MusicBrainzRelation, error) {
+	urlStr := fmt.Sprintf("%s/artist/%s?fmt=json&inc=url-rels+artist-rels", c.baseURL, mbid)
+
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+
+	waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("artist with MBID %s not found", mbid)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var artist MusicBrainzArtist
+	if err := json.NewDecoder(resp.Body).Decode(&artist); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return artist.Relations, nil
+}
+
+// maxReleaseGroupPages bounds the number of browse requests per artist
+const maxReleaseGroupPages = 5
+
+// GetReleaseGroups browses all release groups (albums, EPs, singles, ...) of an artist
+func (c *MusicBrainzClient) GetReleaseGroups(mbid string) ([]MusicBrainzReleaseGroup, error) {
+	const pageSize = 100
+	groups := make([]MusicBrainzReleaseGroup, 0)
+
+	for page := 0; page < maxReleaseGroupPages; page++ {
+		urlStr := fmt.Sprintf("%s/release-group?artist=%s&fmt=json&limit=%d&offset=%d",
+			c.baseURL, url.QueryEscape(mbid), pageSize, page*pageSize)
+
+		req, err := http.NewRequest("GET", urlStr, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("User-Agent", c.userAgent)
+
+		waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+
+		var browseResult struct {
+			ReleaseGroups []MusicBrainzReleaseGroup `json:"release-groups"`
+			Count         int                       `json:"release-group-count"`
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(&browseResult)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		groups = append(groups, browseResult.ReleaseGroups...)
+
+		if len(browseResult.ReleaseGroups) < pageSize || len(groups) >= browseResult.Count {
+			break
+		}
+	}
+
+	return groups, nil
+}
+
+// ToReleaseGroups converts MusicBrainz release groups to internal models
+func (mb *MusicBrainzArtist) ToReleaseGroups() []models.ReleaseGroup {
+	groups := make([]models.ReleaseGroup, 0, len(mb.ReleaseGroups))
+	for _, rg := range mb.ReleaseGroups {
+		year, _ := strconv.Atoi(extractYear(rg.FirstReleaseDate))
+		secondary := models.ReleaseTypes(rg.SecondaryTypes)
+		if secondary == nil {
+			secondary = make(models.ReleaseTypes, 0)
+		}
+		groups = append(groups, models.ReleaseGroup{
+			MBID:             rg.ID,
+			ArtistMBID:       mb.ID,
+			Title:            rg.Title,
+			PrimaryType:      rg.PrimaryType,
+			SecondaryTypes:   secondary,
+			FirstReleaseDate: rg.FirstReleaseDate,
+			Year:             year,
+		})
+	}
+	return groups
+}
+
 // ToArtistModel converts MusicBrainz data to internal Artist model
 func (mb *MusicBrainzArtist) ToArtistModel() *models.Artist {
+	releases := mb.ToReleaseGroups()
+	discography := models.NewDiscography(mb.ID, releases)
+
 	artist := &models.Artist{
 		MBID:       mb.ID,
 		Name:       mb.Name,
-		AlbumCount: len(mb.Releases),
+		AlbumCount: discography.Albums,
 		Country:    mb.Country,
 		Verified:   models.VerificationMap{"musicbrainz": true},
 		ExternalURLs: models.ExternalURLs{
 			MusicBrainz: fmt.Sprintf("https://musicbrainz.org/artist/%s", mb.ID),
 		},
 		LastUpdated: time.Now(),
-		CacheExpiry: time.Now().Add(30 * 24 * time.Hour), // 30 days
+		Releases:    releases, // CacheExpiry is left to the TTL policy after enrichment
 	}
 
-	// Extract years active from life span
+	applyRelations(artist, mb.Relations)
+
+	// Extract years active from life span, falling back to the release span
 	if mb.LifeSpan != nil {
 		artist.YearsActive = formatYearsActive(mb.LifeSpan.Begin, mb.LifeSpan.End)
 	}
+	if artist.YearsActive == "" {
+		artist.YearsActive = discography.YearsActive()
+	}
 
 	// Extract genres from tags and genres
 	genres := make([]string, 0)
@@ -172,11 +305,61 @@ func (mb *MusicBrainzArtist) ToArtistModel() *models.Artist {
 	for _, genre := range mb.Genres {
 		genres = append(genres, genre.Name)
 	}
-	artist.Genres = removeDuplicates(genres)
+	artist.Genres = taxonomy.NormalizeAll(genres)
 
 	return artist
 }
 
+// musicBrainzRelationWeights maps artist-artist relation types to similarity edge weights.
+// Relation types not listed here (e.g. "is person", "teacher") do not imply musical similarity.
+var musicBrainzRelationWeights = map[string]float64{
+	"member of band":                   0.6,
+	"subgroup":                         0.6,
+	"collaboration":                    0.5,
+	"supporting musician":              0.3,
+	"vocal supporting musician":        0.3,
+	"instrumental supporting musician": 0.3,
+}
+
+// applyRelations copies known external links from MusicBrainz relations
+// and turns artist relations into similarity edges
+func applyRelations(artist *models.Artist, relations []MusicBrainzRelation) {
+	edges := make(map[string]int) // neighbour MBID -> index in artist.Similar
+
+	for _, rel := range relations {
+		if rel.TargetType == "artist" || rel.Artist != nil {
+			weight, known := musicBrainzRelationWeights[rel.Type]
+			if !known || rel.Artist == nil || rel.Artist.ID == "" || rel.Artist.ID == artist.MBID {
+				continue
+			}
+			if i, exists := edges[rel.Artist.ID]; exists {
+				artist.Similar[i].Match = max(artist.Similar[i].Match, weight)
+				continue
+			}
+			edges[rel.Artist.ID] = len(artist.Similar)
+			artist.Similar = append(artist.Similar, models.SimilarArtist{
+				ArtistMBID: artist.MBID,
+				Name:       rel.Artist.Name,
+				MBID:       rel.Artist.ID,
+				Match:      weight,
+				Source:     models.SimilaritySourceMusicBrainz,
+			})
+			continue
+		}
+
+		switch rel.Type {
+		case "wikidata":
+			if artist.ExternalURLs.Wikidata == "" {
+				artist.ExternalURLs.Wikidata = rel.URL.Resource
+			}
+		case "wikipedia":
+			if artist.ExternalURLs.Wikipedia == "" {
+				artist.ExternalURLs.Wikipedia = rel.URL.Resource
+			}
+		}
+	}
+}
+
 func formatYearsActive(begin, end string) string {
 	if begin == "" {
 		return ""
@@ -203,20 +386,6 @@ func extractYear(dateStr string) string {
 	return dateStr
 }
 
-func removeDuplicates(slice []string) []string {
-	seen := make(map[string]bool)
-	result := make([]string, 0)
-
-	for _, item := range slice {
-		if !seen[item] {
-			seen[item] = true
-			result = append(result, item)
-		}
-	}
-
-	return result
-}
-
 // Close gracefully shuts down the client
 func (c *MusicBrainzClient) Close() {
 	if c.rateLimiter != nil {
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+
+	"gocommender/internal/models"
+)
+
+func TestMusicBrainzToArtistModelDiscography(t *testing.T) {
+	mb := &MusicBrainzArtist{
+		ID:   "artist-mbid",
+		Name: "Test Artist",
+		ReleaseGroups: []MusicBrainzReleaseGroup{
+			{ID: "rg-1", Title: "Debut", PrimaryType: "Album", FirstReleaseDate: "1994-03-01"},
+			{ID: "rg-2", Title: "Follow Up", PrimaryType: "Album", FirstReleaseDate: "1997"},
+			{ID: "rg-3", Title: "Greatest Hits", PrimaryType: "Album", SecondaryTypes: []string{"Compilation"}, FirstReleaseDate: "2005-10-10"},
+			{ID: "rg-4", Title: "Single", PrimaryType: "Single", FirstReleaseDate: "1994-01-01"},
+		},
+	}
+
+	artist := mb.ToArtistModel()
+
+	if artist.AlbumCount != 2 {
+		t.Errorf("Expected AlbumCount 2 (studio albums only), got %d", artist.AlbumCount)
+	}
+
+	if len(artist.Releases) != 4 {
+		t.Fatalf("Expected 4 releases, got %d", len(artist.Releases))
+	}
+
+	if artist.Releases[0].Year != 1994 || artist.Releases[0].ArtistMBID != "artist-mbid" {
+		t.Errorf("Unexpected first release: %+v", artist.Releases[0])
+	}
+
+	if artist.Releases[0].SecondaryTypes == nil {
+		t.Error("Expected SecondaryTypes to be non-nil")
+	}
+
+	// No life span: years active falls back to the release span
+	if artist.YearsActive != "1994-2005" {
+		t.Errorf("Expected years active '1994-2005', got '%s'", artist.YearsActive)
+	}
+}
+
+func TestMusicBrainzToArtistModelLifeSpanPreferred(t *testing.T) {
+	mb := &MusicBrainzArtist{
+		ID:       "artist-mbid",
+		Name:     "Test Artist",
+		LifeSpan: &MusicBrainzLifeSpan{Begin: "1990-01-01"},
+		ReleaseGroups: []MusicBrainzReleaseGroup{
+			{ID: "rg-1", Title: "Debut", PrimaryType: models.ReleaseTypeAlbum, FirstReleaseDate: "1994"},
+		},
+	}
+
+	artist := mb.ToArtistModel()
+
+	if artist.YearsActive != "1990-present" {
+		t.Errorf("Expected years active '1990-present', got '%s'", artist.YearsActive)
+	}
+}
+
+func TestMusicBrainzArtistRelationsBecomeSimilarityEdges(t *testing.T) {
+	relation := func(relType, id, name string) MusicBrainzRelation {
+		rel := MusicBrainzRelation{Type: relType, TargetType: "artist"}
+		rel.Artist = &struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}{ID: id, Name: name}
+		return rel
+	}
+
+	mb := &MusicBrainzArtist{
+		ID:   "band-mbid",
+		Name: "Test Band",
+		Relations: []MusicBrainzRelation{
+			relation("member of band", "member-mbid", "Band Member"),
+			relation("collaboration", "member-mbid", "Band Member"), // Same artist, weaker relation
+			relation("collaboration", "collab-mbid", "Collaborator"),
+			relation("is person", "person-mbid", "Legal Name"), // Not a musical similarity
+		},
+	}
+
+	artist := mb.ToArtistModel()
+
+	if len(artist.Similar) != 2 {
+		t.Fatalf("Expected 2 similarity edges, got %d: %+v", len(artist.Similar), artist.Similar)
+	}
+	if artist.Similar[0].MBID != "member-mbid" || artist.Similar[0].Match != 0.6 {
+		t.Errorf("Expected member edge with the stronger weight, got %+v", artist.Similar[0])
+	}
+	if artist.Similar[1].Source != models.SimilaritySourceMusicBrainz || artist.Similar[1].ArtistMBID != "band-mbid" {
+		t.Errorf("Unexpected collaboration edge: %+v", artist.Similar[1])
+	}
+}
@@ -2,17 +2,26 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"net/http"
 	"strings"
 	"text/template"
 	"time"
 
+	"gocommender/internal/logging"
+	"gocommender/internal/metrics"
 	"gocommender/internal/models"
+	"gocommender/internal/tracing"
 )
 
+var llmLog = logging.For("llm")
+
+// llmTokens counts tokens reported by the LLM, whether or not a quota is configured
+var llmTokens = metrics.NewCounter("gocommender_llm_tokens_total",
+	"LLM tokens used by model and type (prompt or completion).", "model", "type")
+
 // OpenAIClient handles OpenAI API interactions
 type OpenAIClient struct {
 	apiKey         string
@@ -21,7 +30,7 @@ type OpenAIClient struct {
 	templatePath   string
 	httpClient     *http.Client
 	promptTemplate *template.Template
-	debug          bool
+	quota          *LLMQuota // Optional, records usage and refuses requests over quota
 }
 
 // OpenAIRequest represents the request structure for OpenAI API
@@ -96,6 +105,7 @@ type PromptData struct {
 	TotalTrackCount int           `json:"total_track_count"`
 	HasMoreTracks   bool          `json:"has_more_tracks"`
 	HasMoreArtists  bool          `json:"has_more_artists"`
+	Candidates      []string      `json:"candidates,omitempty"` // Graph candidates grounding hybrid recommendations
 }
 
 // PromptTrack represents a track for template rendering
@@ -107,8 +117,9 @@ type PromptTrack struct {
 	Stars  string `json:"stars"`
 }
 
-// NewOpenAIClient creates a new OpenAI API client
-func NewOpenAIClient(apiKey, model, templatePath string, debug bool) (*OpenAIClient, error) {
+// NewOpenAIClient creates a new OpenAI API client. Prompts and raw responses
+// are logged at debug level of the llm component.
+func NewOpenAIClient(apiKey, model, templatePath string) (*OpenAIClient, error) {
 	if model == "" {
 		model = "gpt-4o"
 	}
@@ -123,9 +134,9 @@ func NewOpenAIClient(apiKey, model, templatePath string, debug bool) (*OpenAICli
 		model:        model,
 		templatePath: templatePath,
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
+			Timeout:   60 * time.Second,
+			Transport: tracing.Transport("openai", metrics.InstrumentTransport("openai", nil)),
 		},
-		debug: debug,
 	}
 
 	// Load and cache the template
@@ -163,11 +174,43 @@ func (c *OpenAIClient) loadTemplate() error {
 	return nil
 }
 
+// UseQuota records the usage of every request and refuses requests once a quota is used up
+func (c *OpenAIClient) UseQuota(quota *LLMQuota) {
+	c.quota = quota
+}
+
+// IsConfigured reports whether an API key is available
+func (c *OpenAIClient) IsConfigured() bool {
+	return c != nil && c.apiKey != ""
+}
+
 // GetArtistRecommendations generates artist suggestions based on seed data
-func (c *OpenAIClient) GetArtistRecommendations(seedTracks []models.PlexTrack,
+func (c *OpenAIClient) GetArtistRecommendations(ctx context.Context,
+	seedTracks []models.PlexTrack,
 	knownArtists []string,
 	genre string,
 	maxResults int) (*ArtistSuggestions, error) {
+	return c.GetGroundedRecommendations(ctx, seedTracks, knownArtists, genre, maxResults, nil)
+}
+
+// GetGroundedRecommendations generates artist suggestions, preferring the given candidate artists.
+// Candidates typically come from the similarity graph; the LLM picks and complements them.
+func (c *OpenAIClient) GetGroundedRecommendations(ctx context.Context,
+	seedTracks []models.PlexTrack,
+	knownArtists []string,
+	genre string,
+	maxResults int,
+	candidates []string) (_ *ArtistSuggestions, err error) {
+	ctx, span := tracing.Start(ctx, "llm.GetRecommendations",
+		tracing.String("llm.model", c.model),
+		tracing.Int("llm.seed_tracks", len(seedTracks)),
+		tracing.Int("llm.candidates", len(candidates)),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	if c.apiKey == "" {
 		return nil, fmt.Errorf("OpenAI API key not configured")
 	}
@@ -176,18 +219,17 @@ func (c *OpenAIClient) GetArtistRecommendations(seedTracks []models.PlexTrack,
 		maxResults = 5
 	}
 
-	prompt := c.buildRecommendationPrompt(seedTracks, knownArtists, genre, maxResults)
+	prompt := c.buildRecommendationPrompt(seedTracks, knownArtists, genre, maxResults, candidates)
 
-	if c.debug {
-		slog.Debug("OpenAI request details",
-			"model", c.model,
-			"seed_tracks", len(seedTracks),
-			"known_artists", len(knownArtists),
-			"genre", genre,
-			"max_results", maxResults,
-			"prompt_content", prompt,
-		)
-	}
+	llmLog.Debug("OpenAI request details",
+		"model", c.model,
+		"seed_tracks", len(seedTracks),
+		"known_artists", len(knownArtists),
+		"genre", genre,
+		"max_results", maxResults,
+		"candidates", len(candidates),
+		"prompt_content", prompt,
+	)
 
 	request := OpenAIRequest{
 		Model: c.model,
@@ -208,40 +250,61 @@ func (c *OpenAIClient) GetArtistRecommendations(seedTracks []models.PlexTrack,
 		},
 	}
 
-	response, err := c.sendRequest(request)
+	var reservation *QuotaReservation
+	if c.quota != nil {
+		reservation, err = c.quota.Reserve(c.model, estimateUsage(request))
+		if err != nil {
+			return nil, err
+		}
+		defer reservation.Release()
+	}
+
+	response, err := c.sendRequest(ctx, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 
+	model := response.Model
+	if model == "" {
+		model = c.model
+	}
+	llmTokens.Add(float64(response.Usage.PromptTokens), model, "prompt")
+	llmTokens.Add(float64(response.Usage.CompletionTokens), model, "completion")
+	span.SetAttributes(
+		tracing.String("llm.response_model", model),
+		tracing.Int("llm.prompt_tokens", response.Usage.PromptTokens),
+		tracing.Int("llm.completion_tokens", response.Usage.CompletionTokens),
+	)
+
+	if reservation != nil {
+		if err := reservation.Settle(model, response.Usage); err != nil {
+			llmLog.WarnContext(ctx, "Failed to record LLM usage", "error", err)
+		}
+	}
+
 	if len(response.Choices) == 0 {
 		return nil, fmt.Errorf("no choices in OpenAI response")
 	}
 
 	content := response.Choices[0].Message.Content
 
-	if c.debug {
-		slog.Debug("OpenAI raw response",
-			"status", response.Choices[0].FinishReason,
-			"response_content", content,
-		)
-	}
+	llmLog.Debug("OpenAI raw response",
+		"status", response.Choices[0].FinishReason,
+		"response_content", content,
+	)
 
 	var suggestions ArtistSuggestions
 	if err := json.Unmarshal([]byte(content), &suggestions); err != nil {
-		if c.debug {
-			slog.Debug("Failed to parse JSON response", "error", err)
-		}
+		llmLog.Debug("Failed to parse JSON response", "error", err)
 		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
 	}
 
-	if c.debug {
-		slog.Debug("Parsed suggestions structure",
-			"suggestions_count", len(suggestions.Suggestions),
-			"suggestions", suggestions.Suggestions,
-			"reasoning", suggestions.Reasoning,
-			"confidence", suggestions.Confidence,
-		)
-	}
+	llmLog.Debug("Parsed suggestions structure",
+		"suggestions_count", len(suggestions.Suggestions),
+		"suggestions", suggestions.Suggestions,
+		"reasoning", suggestions.Reasoning,
+		"confidence", suggestions.Confidence,
+	)
 
 	// Validate suggestions
 	if err := c.validateSuggestions(&suggestions, maxResults); err != nil {
@@ -251,14 +314,26 @@ func (c *OpenAIClient) GetArtistRecommendations(seedTracks []models.PlexTrack,
 	return &suggestions, nil
 }
 
+// estimateUsage guesses the usage of a request before it is sent: its prompt at about
+// four characters per token, plus every completion token it allows
+func estimateUsage(request OpenAIRequest) OpenAIUsage {
+	chars := 0
+	for _, message := range request.Messages {
+		chars += len(message.Content)
+	}
+	usage := OpenAIUsage{PromptTokens: chars/4 + 1, CompletionTokens: request.MaxTokens}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return usage
+}
+
 // sendRequest sends the request to OpenAI API
-func (c *OpenAIClient) sendRequest(request OpenAIRequest) (*OpenAIResponse, error) {
+func (c *OpenAIClient) sendRequest(ctx context.Context, request OpenAIRequest) (*OpenAIResponse, error) {
 	jsonData, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -292,35 +367,31 @@ func (c *OpenAIClient) sendRequest(request OpenAIRequest) (*OpenAIResponse, erro
 func (c *OpenAIClient) buildRecommendationPrompt(seedTracks []models.PlexTrack,
 	knownArtists []string,
 	genre string,
-	maxResults int) string {
+	maxResults int,
+	candidates []string) string {
 
 	// Prepare template data
 	data := c.preparePromptData(seedTracks, knownArtists, genre, maxResults)
+	data.Candidates = candidates
 
-	if c.debug {
-		slog.Debug("Prepared prompt data",
-			"seed_tracks_count", len(data.SeedTracks),
-			"priority_artists_count", len(data.PriorityArtists),
-			"other_artists_count", len(data.OtherArtists),
-			"total_known_count", data.TotalKnownCount,
-			"has_more_tracks", data.HasMoreTracks,
-			"has_more_artists", data.HasMoreArtists,
-		)
-	}
+	llmLog.Debug("Prepared prompt data",
+		"seed_tracks_count", len(data.SeedTracks),
+		"priority_artists_count", len(data.PriorityArtists),
+		"other_artists_count", len(data.OtherArtists),
+		"total_known_count", data.TotalKnownCount,
+		"has_more_tracks", data.HasMoreTracks,
+		"has_more_artists", data.HasMoreArtists,
+	)
 
 	// Execute template
 	var buf bytes.Buffer
 	if err := c.promptTemplate.Execute(&buf, data); err != nil {
-		if c.debug {
-			slog.Debug("Template execution failed, using fallback prompt", "error", err)
-		}
+		llmLog.Debug("Template execution failed, using fallback prompt", "error", err)
 		// Fallback to basic prompt if template fails
 		return fmt.Sprintf("I need %d artist recommendations based on my music taste. Please suggest artists I don't already know.", maxResults)
 	}
 
-	if c.debug {
-		slog.Debug("Template execution successful")
-	}
+	llmLog.Debug("Template execution successful")
 
 	return buf.String()
 }
@@ -421,27 +492,21 @@ func containsArtist(slice []string, item string) bool {
 
 // validateSuggestions ensures LLM response meets requirements
 func (c *OpenAIClient) validateSuggestions(suggestions *ArtistSuggestions, maxResults int) error {
-	if c.debug {
-		slog.Debug("Validating suggestions",
-			"raw_suggestions_count", len(suggestions.Suggestions),
-			"max_results", maxResults,
-		)
-	}
+	llmLog.Debug("Validating suggestions",
+		"raw_suggestions_count", len(suggestions.Suggestions),
+		"max_results", maxResults,
+	)
 
 	if len(suggestions.Suggestions) == 0 {
-		if c.debug {
-			slog.Debug("Validation failed: no suggestions provided")
-		}
+		llmLog.Debug("Validation failed: no suggestions provided")
 		return fmt.Errorf("no suggestions provided")
 	}
 
 	if len(suggestions.Suggestions) > maxResults*2 {
-		if c.debug {
-			slog.Debug("Truncating suggestions",
-				"original_count", len(suggestions.Suggestions),
-				"truncated_to", maxResults,
-			)
-		}
+		llmLog.Debug("Truncating suggestions",
+			"original_count", len(suggestions.Suggestions),
+			"truncated_to", maxResults,
+		)
 		// Truncate if too many suggestions
 		suggestions.Suggestions = suggestions.Suggestions[:maxResults]
 	}
@@ -465,17 +530,13 @@ func (c *OpenAIClient) validateSuggestions(suggestions *ArtistSuggestions, maxRe
 
 	suggestions.Suggestions = cleaned
 
-	if c.debug {
-		slog.Debug("Suggestions after cleaning",
-			"cleaned_count", len(suggestions.Suggestions),
-			"cleaned_suggestions", suggestions.Suggestions,
-		)
-	}
+	llmLog.Debug("Suggestions after cleaning",
+		"cleaned_count", len(suggestions.Suggestions),
+		"cleaned_suggestions", suggestions.Suggestions,
+	)
 
 	if len(suggestions.Suggestions) == 0 {
-		if c.debug {
-			slog.Debug("Validation failed: no valid suggestions after cleaning")
-		}
+		llmLog.Debug("Validation failed: no valid suggestions after cleaning")
 		return fmt.Errorf("no valid suggestions after cleaning")
 	}
 
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"math"
 	"net/http"
@@ -9,7 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"gocommender/internal/metrics"
 	"gocommender/internal/models"
+	"gocommender/internal/tracing"
 )
 
 // PlexClient handles Plex Media Server API interactions
@@ -66,7 +70,7 @@ type PlexPlaylistXML struct {
 type PlexError struct {
 	StatusCode int
 	Message    string
-	URL        string
+	URL        string // Without the token
 }
 
 func (e *PlexError) Error() string {
@@ -74,17 +78,20 @@ func (e *PlexError) Error() string {
 		e.StatusCode, e.Message, e.URL)
 }
 
-// IsNotFound checks if error indicates resource not found
+// IsNotFound checks if error indicates resource not found, by Plex or a missing playlist
 func IsNotFound(err error) bool {
-	if plexErr, ok := err.(*PlexError); ok {
+	var plexErr *PlexError
+	if errors.As(err, &plexErr) {
 		return plexErr.StatusCode == http.StatusNotFound
 	}
-	return false
+	code := Code(err)
+	return code == CodeNotFound || code == CodePlaylistNotFound
 }
 
 // IsUnauthorized checks if error indicates authentication failure
 func IsUnauthorized(err error) bool {
-	if plexErr, ok := err.(*PlexError); ok {
+	var plexErr *PlexError
+	if errors.As(err, &plexErr) {
 		return plexErr.StatusCode == http.StatusUnauthorized
 	}
 	return false
@@ -96,16 +103,17 @@ func NewPlexClient(baseURL, token string) *PlexClient {
 		baseURL: strings.TrimSuffix(baseURL, "/"),
 		token:   token,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: tracing.Transport("plex", metrics.InstrumentTransport("plex", nil)),
 		},
 	}
 }
 
 // TestConnection verifies the Plex server is accessible
-func (c *PlexClient) TestConnection() error {
+func (c *PlexClient) TestConnection(ctx context.Context) error {
 	url := fmt.Sprintf("%s/?X-Plex-Token=%s", c.baseURL, c.token)
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return fmt.Errorf("failed to connect to Plex server: %w", err)
 	}
@@ -119,10 +127,10 @@ func (c *PlexClient) TestConnection() error {
 }
 
 // GetServerInfo retrieves basic server information for validation
-func (c *PlexClient) GetServerInfo() (map[string]string, error) {
+func (c *PlexClient) GetServerInfo(ctx context.Context) (map[string]string, error) {
 	url := fmt.Sprintf("%s/?X-Plex-Token=%s", c.baseURL, c.token)
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get server info: %w", err)
 	}
@@ -152,10 +160,10 @@ func (c *PlexClient) GetServerInfo() (map[string]string, error) {
 }
 
 // GetPlaylists retrieves all playlists from Plex server
-func (c *PlexClient) GetPlaylists() ([]models.PlexPlaylist, error) {
+func (c *PlexClient) GetPlaylists(ctx context.Context) ([]models.PlexPlaylist, error) {
 	url := fmt.Sprintf("%s/playlists?X-Plex-Token=%s", c.baseURL, c.token)
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get playlists: %w", err)
 	}
@@ -185,9 +193,9 @@ func (c *PlexClient) GetPlaylists() ([]models.PlexPlaylist, error) {
 }
 
 // GetPlaylistTracks retrieves tracks from a specific playlist
-func (c *PlexClient) GetPlaylistTracks(playlistName string) ([]models.PlexTrack, error) {
+func (c *PlexClient) GetPlaylistTracks(ctx context.Context, playlistName string) ([]models.PlexTrack, error) {
 	// First, find the playlist by name
-	playlistKey, err := c.findPlaylistKey(playlistName)
+	playlistKey, err := c.findPlaylistKey(ctx, playlistName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find playlist: %w", err)
 	}
@@ -196,7 +204,7 @@ func (c *PlexClient) GetPlaylistTracks(playlistName string) ([]models.PlexTrack,
 	url := fmt.Sprintf("%s/playlists/%s/items?X-Plex-Token=%s",
 		c.baseURL, playlistKey, c.token)
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get playlist tracks: %w", err)
 	}
@@ -234,9 +242,9 @@ func (c *PlexClient) GetPlaylistTracks(playlistName string) ([]models.PlexTrack,
 }
 
 // GetAllArtists retrieves all artists from the music library
-func (c *PlexClient) GetAllArtists() ([]string, error) {
+func (c *PlexClient) GetAllArtists(ctx context.Context) ([]string, error) {
 	// First, find the music library section
-	musicSectionKey, err := c.findMusicSectionKey()
+	musicSectionKey, err := c.findMusicSectionKey(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find music section: %w", err)
 	}
@@ -245,7 +253,7 @@ func (c *PlexClient) GetAllArtists() ([]string, error) {
 	url := fmt.Sprintf("%s/library/sections/%s/all?type=8&X-Plex-Token=%s",
 		c.baseURL, musicSectionKey, c.token)
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get artists: %w", err)
 	}
@@ -271,12 +279,12 @@ func (c *PlexClient) GetAllArtists() ([]string, error) {
 }
 
 // GetHighRatedTracks retrieves tracks with high user ratings from a playlist
-func (c *PlexClient) GetHighRatedTracks(playlistName string, minRating int) ([]models.PlexTrack, error) {
+func (c *PlexClient) GetHighRatedTracks(ctx context.Context, playlistName string, minRating int) ([]models.PlexTrack, error) {
 	if minRating < 1 || minRating > 10 {
 		minRating = 7 // Default to 7+ rating
 	}
 
-	tracks, err := c.GetPlaylistTracks(playlistName)
+	tracks, err := c.GetPlaylistTracks(ctx, playlistName)
 	if err != nil {
 		return nil, err
 	}
@@ -292,8 +300,8 @@ func (c *PlexClient) GetHighRatedTracks(playlistName string, minRating int) ([]m
 }
 
 // GetArtistsByGenre retrieves artists filtered by genre
-func (c *PlexClient) GetArtistsByGenre(genre string) ([]string, error) {
-	musicSectionKey, err := c.findMusicSectionKey()
+func (c *PlexClient) GetArtistsByGenre(ctx context.Context, genre string) ([]string, error) {
+	musicSectionKey, err := c.findMusicSectionKey(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -303,7 +311,7 @@ func (c *PlexClient) GetArtistsByGenre(genre string) ([]string, error) {
 	url := fmt.Sprintf("%s/library/sections/%s/all?type=8&genre=%s&X-Plex-Token=%s",
 		c.baseURL, musicSectionKey, genreQuery, c.token)
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get artists by genre: %w", err)
 	}
@@ -329,10 +337,10 @@ func (c *PlexClient) GetArtistsByGenre(genre string) ([]string, error) {
 }
 
 // findPlaylistKey searches for a playlist by name and returns its key
-func (c *PlexClient) findPlaylistKey(name string) (string, error) {
+func (c *PlexClient) findPlaylistKey(ctx context.Context, name string) (string, error) {
 	url := fmt.Sprintf("%s/playlists?X-Plex-Token=%s", c.baseURL, c.token)
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return "", fmt.Errorf("failed to search playlists: %w", err)
 	}
@@ -353,14 +361,15 @@ func (c *PlexClient) findPlaylistKey(name string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("playlist '%s' not found", name)
+	return "", NewError(CodePlaylistNotFound, fmt.Sprintf("playlist '%s' not found", name),
+		map[string]interface{}{"playlist": name})
 }
 
 // findMusicSectionKey finds the key for the music library section
-func (c *PlexClient) findMusicSectionKey() (string, error) {
+func (c *PlexClient) findMusicSectionKey(ctx context.Context) (string, error) {
 	url := fmt.Sprintf("%s/library/sections?X-Plex-Token=%s", c.baseURL, c.token)
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.get(ctx, url)
 	if err != nil {
 		return "", fmt.Errorf("failed to get library sections: %w", err)
 	}
@@ -392,8 +401,36 @@ func (c *PlexClient) findMusicSectionKey() (string, error) {
 	return "", fmt.Errorf("music library section not found")
 }
 
+// get sends a GET request. A transport error names the URL without the token.
+func (c *PlexClient) get(ctx context.Context, requestURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %s", redactPlexToken(requestURL))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactPlexToken(urlErr.URL)
+	}
+	return resp, err
+}
+
+// redactPlexToken removes the X-Plex-Token parameter from a request URL
+func redactPlexToken(requestURL string) string {
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	query := u.Query()
+	query.Del("X-Plex-Token")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
 // validatePlexResponse checks response status and creates appropriate errors
 func (c *PlexClient) validatePlexResponse(resp *http.Response, requestURL string) error {
+	requestURL = redactPlexToken(requestURL)
 	switch resp.StatusCode {
 	case http.StatusOK:
 		return nil
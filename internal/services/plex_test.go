@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlexErrorsHideToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	defer server.Close()
+
+	for _, baseURL := range []string{server.URL, unreachable.URL} {
+		err := NewPlexClient(baseURL, "plex-s3cret").TestConnection(context.Background())
+		if err == nil {
+			t.Fatalf("Expected %s to fail", baseURL)
+		}
+		if strings.Contains(err.Error(), "plex-s3cret") {
+			t.Errorf("Error leaks the Plex token: %v", err)
+		}
+	}
+}
+
+func TestPlexRequestsFollowContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// A cancelled request stops waiting for Plex instead of running into the client timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := NewPlexClient(server.URL, "token").GetPlaylists(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to end the request, got %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gocommender/internal/db"
+)
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// Cost estimates the price of a request's usage
+func (p ModelPrice) Cost(usage OpenAIUsage) float64 {
+	return (float64(usage.PromptTokens)*p.InputPerMillion +
+		float64(usage.CompletionTokens)*p.OutputPerMillion) / 1_000_000
+}
+
+// DefaultModelPrices are OpenAI list prices for the models this service is used with.
+// Dated model versions such as gpt-4o-2024-08-06 use the price of their base name.
+func DefaultModelPrices() map[string]ModelPrice {
+	return map[string]ModelPrice{
+		"gpt-4o":       {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+		"gpt-4o-mini":  {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+		"gpt-4.1":      {InputPerMillion: 2.00, OutputPerMillion: 8.00},
+		"gpt-4.1-mini": {InputPerMillion: 0.40, OutputPerMillion: 1.60},
+		"gpt-4.1-nano": {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	}
+}
+
+// QuotaConfig limits LLM usage per UTC day and month. Zero disables a limit.
+type QuotaConfig struct {
+	DailyTokens   int64
+	MonthlyTokens int64
+	DailyCost     float64 // USD
+	MonthlyCost   float64 // USD
+}
+
+// QuotaExceededError is returned instead of calling the LLM once a quota is used up
+type QuotaExceededError struct {
+	Period  string    `json:"period"` // daily or monthly
+	Limit   string    `json:"limit"`  // tokens or cost
+	Used    float64   `json:"used"`
+	Max     float64   `json:"max"`
+	ResetAt time.Time `json:"reset_at"`
+}
+
+func (e *QuotaExceededError) Error() string {
+	if e.Limit == "cost" {
+		return fmt.Sprintf("%s LLM cost quota exceeded ($%.2f of $%.2f), resets at %s",
+			e.Period, e.Used, e.Max, e.ResetAt.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s LLM token quota exceeded (%.0f of %.0f tokens), resets at %s",
+		e.Period, e.Used, e.Max, e.ResetAt.Format(time.RFC3339))
+}
+
+// QuotaPeriod is the usage of one quota period against its limits
+type QuotaPeriod struct {
+	db.LLMUsage
+	TotalTokens int64     `json:"total_tokens"`
+	TokenLimit  int64     `json:"token_limit,omitempty"`
+	CostLimit   float64   `json:"cost_limit_usd,omitempty"`
+	Start       time.Time `json:"start"`
+	ResetAt     time.Time `json:"reset_at"`
+	Exceeded    bool      `json:"exceeded"`
+}
+
+// QuotaStatus is the current LLM consumption
+type QuotaStatus struct {
+	Daily   QuotaPeriod `json:"daily"`
+	Monthly QuotaPeriod `json:"monthly"`
+}
+
+// LLMQuota records LLM usage and enforces daily and monthly quotas
+type LLMQuota struct {
+	usage  db.UsageRepository
+	config QuotaConfig
+	prices map[string]ModelPrice
+	now    func() time.Time
+
+	mu      sync.Mutex
+	pending db.LLMUsage // Reserved by requests in flight
+}
+
+// QuotaReservation holds the estimated usage of one LLM request until it is settled
+type QuotaReservation struct {
+	quota *LLMQuota
+	usage db.LLMUsage
+	done  bool
+}
+
+// NewLLMQuota creates a quota tracker; prices are looked up by model name
+func NewLLMQuota(usage db.UsageRepository, config QuotaConfig, prices map[string]ModelPrice) *LLMQuota {
+	return &LLMQuota{
+		usage:  usage,
+		config: config,
+		prices: prices,
+		now:    time.Now,
+	}
+}
+
+// Check returns a *QuotaExceededError if any quota is used up, counting reservations in flight
+func (q *LLMQuota) Check() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.check()
+}
+
+// Reserve checks the quotas and holds the estimated usage of a request against them until
+// the reservation is settled or released, so concurrent requests cannot all pass the check.
+// A period can only overshoot by the difference between one estimate and the actual usage.
+func (q *LLMQuota) Reserve(model string, estimate OpenAIUsage) (*QuotaReservation, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.check(); err != nil {
+		return nil, err
+	}
+
+	price, _ := q.price(model)
+	reservation := &QuotaReservation{quota: q, usage: db.LLMUsage{
+		Requests:         1,
+		PromptTokens:     int64(estimate.PromptTokens),
+		CompletionTokens: int64(estimate.CompletionTokens),
+		CostUSD:          price.Cost(estimate),
+	}}
+	q.pending = addUsage(q.pending, reservation.usage, 1)
+	return reservation, nil
+}
+
+// Settle records the actual usage of the request and releases the reservation
+func (r *QuotaReservation) Settle(model string, usage OpenAIUsage) error {
+	if r.done {
+		return nil
+	}
+	err := r.quota.Record(model, usage)
+	r.Release()
+	return err
+}
+
+// Release drops the reservation of a request that used nothing, or was settled
+func (r *QuotaReservation) Release() {
+	r.quota.mu.Lock()
+	defer r.quota.mu.Unlock()
+
+	if !r.done {
+		r.done = true
+		r.quota.pending = addUsage(r.quota.pending, r.usage, -1)
+	}
+}
+
+// check compares recorded and reserved usage with the limits; the caller holds mu
+func (q *LLMQuota) check() error {
+	status, err := q.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, period := range []struct {
+		name   string
+		period QuotaPeriod
+	}{{"daily", status.Daily}, {"monthly", status.Monthly}} {
+		p := period.period
+		tokens := p.TotalTokens + q.pending.TotalTokens()
+		cost := p.CostUSD + q.pending.CostUSD
+		if p.TokenLimit > 0 && tokens >= p.TokenLimit {
+			return &QuotaExceededError{Period: period.name, Limit: "tokens",
+				Used: float64(tokens), Max: float64(p.TokenLimit), ResetAt: p.ResetAt}
+		}
+		if p.CostLimit > 0 && cost >= p.CostLimit {
+			return &QuotaExceededError{Period: period.name, Limit: "cost",
+				Used: cost, Max: p.CostLimit, ResetAt: p.ResetAt}
+		}
+	}
+
+	return nil
+}
+
+// addUsage adds sign times b to a
+func addUsage(a, b db.LLMUsage, sign int64) db.LLMUsage {
+	return db.LLMUsage{
+		Requests:         a.Requests + sign*b.Requests,
+		PromptTokens:     a.PromptTokens + sign*b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + sign*b.CompletionTokens,
+		CostUSD:          a.CostUSD + float64(sign)*b.CostUSD,
+	}
+}
+
+// Record adds the usage of one LLM request
+func (q *LLMQuota) Record(model string, usage OpenAIUsage) error {
+	price, ok := q.price(model)
+	if !ok {
+		llmLog.Warn("No price configured for model, recording its cost as 0", "model", model)
+	}
+
+	return q.usage.AddLLMUsage(q.now(), model, db.LLMUsage{
+		Requests:         1,
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+		CostUSD:          price.Cost(usage),
+	})
+}
+
+// Status returns the usage of the current UTC day and month
+func (q *LLMQuota) Status() (*QuotaStatus, error) {
+	now := q.now().UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	daily, err := q.period(dayStart, dayStart.AddDate(0, 0, 1), q.config.DailyTokens, q.config.DailyCost)
+	if err != nil {
+		return nil, err
+	}
+	monthly, err := q.period(monthStart, monthStart.AddDate(0, 1, 0), q.config.MonthlyTokens, q.config.MonthlyCost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QuotaStatus{Daily: daily, Monthly: monthly}, nil
+}
+
+// period sums the usage between start and end and compares it with the limits
+func (q *LLMQuota) period(start, end time.Time, tokenLimit int64, costLimit float64) (QuotaPeriod, error) {
+	usage, err := q.usage.GetLLMUsage(start, end)
+	if err != nil {
+		return QuotaPeriod{}, err
+	}
+
+	period := QuotaPeriod{
+		LLMUsage:    usage,
+		TotalTokens: usage.TotalTokens(),
+		TokenLimit:  tokenLimit,
+		CostLimit:   costLimit,
+		Start:       start,
+		ResetAt:     end,
+	}
+	period.Exceeded = (tokenLimit > 0 && period.TotalTokens >= tokenLimit) ||
+		(costLimit > 0 && usage.CostUSD >= costLimit)
+	return period, nil
+}
+
+// price finds the price of a model, matching dated versions by their longest known prefix
+func (q *LLMQuota) price(model string) (ModelPrice, bool) {
+	if price, ok := q.prices[model]; ok {
+		return price, true
+	}
+
+	best := ""
+	for name := range q.prices {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return q.prices[best], true
+}
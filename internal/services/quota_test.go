@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gocommender/internal/config"
+	"gocommender/internal/db"
+	"gocommender/internal/models"
+)
+
+// setupQuota creates a quota tracker over a fresh database with a fixed clock
+func setupQuota(t *testing.T, quota QuotaConfig, now time.Time) *LLMQuota {
+	t.Helper()
+
+	database, err := config.InitDatabase(filepath.Join(t.TempDir(), "usage.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	tracker := NewLLMQuota(db.NewUsageDB(database), quota, DefaultModelPrices())
+	tracker.now = func() time.Time { return now }
+	return tracker
+}
+
+func TestModelPrice(t *testing.T) {
+	quota := NewLLMQuota(nil, QuotaConfig{}, DefaultModelPrices())
+
+	tests := []struct {
+		model    string
+		expected ModelPrice
+		found    bool
+	}{
+		{"gpt-4o", ModelPrice{2.50, 10.00}, true},
+		{"gpt-4o-2024-08-06", ModelPrice{2.50, 10.00}, true},
+		{"gpt-4o-mini-2024-07-18", ModelPrice{0.15, 0.60}, true},
+		{"o3", ModelPrice{}, false},
+	}
+
+	for _, tt := range tests {
+		price, found := quota.price(tt.model)
+		if price != tt.expected || found != tt.found {
+			t.Errorf("price(%q) = %+v, %v, want %+v, %v", tt.model, price, found, tt.expected, tt.found)
+		}
+	}
+
+	cost := ModelPrice{InputPerMillion: 2.50, OutputPerMillion: 10.00}.Cost(OpenAIUsage{PromptTokens: 2000, CompletionTokens: 500})
+	if cost != 0.01 {
+		t.Errorf("Expected $0.01, got %v", cost)
+	}
+}
+
+func TestLLMQuotaCheck(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	usage := OpenAIUsage{PromptTokens: 2000, CompletionTokens: 500, TotalTokens: 2500} // $0.01 with gpt-4o
+
+	tests := []struct {
+		name     string
+		config   QuotaConfig
+		period   string
+		limit    string
+		resetsAt time.Time
+	}{
+		{"unlimited", QuotaConfig{}, "", "", time.Time{}},
+		{"daily tokens", QuotaConfig{DailyTokens: 5000, MonthlyTokens: 10000}, "daily", "tokens", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{"monthly cost", QuotaConfig{DailyCost: 1, MonthlyCost: 0.02}, "monthly", "cost", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"under limits", QuotaConfig{DailyTokens: 6000, DailyCost: 0.05}, "", "", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota := setupQuota(t, tt.config, now)
+			if err := quota.Check(); err != nil {
+				t.Fatalf("Expected no quota error before any usage, got %v", err)
+			}
+
+			for i := 0; i < 2; i++ {
+				if err := quota.Record("gpt-4o-2024-08-06", usage); err != nil {
+					t.Fatalf("Record failed: %v", err)
+				}
+			}
+
+			err := quota.Check()
+			if tt.period == "" {
+				if err != nil {
+					t.Errorf("Expected no quota error, got %v", err)
+				}
+				return
+			}
+
+			var exceeded *QuotaExceededError
+			if !errors.As(err, &exceeded) {
+				t.Fatalf("Expected QuotaExceededError, got %v", err)
+			}
+			if exceeded.Period != tt.period || exceeded.Limit != tt.limit || !exceeded.ResetAt.Equal(tt.resetsAt) {
+				t.Errorf("Expected %s %s quota resetting at %v, got %+v", tt.period, tt.limit, tt.resetsAt, exceeded)
+			}
+		})
+	}
+}
+
+func TestLLMQuotaStatus(t *testing.T) {
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.UTC)
+	quota := setupQuota(t, QuotaConfig{DailyTokens: 2000, MonthlyCost: 5}, now)
+
+	// Usage earlier in the month counts for the month only
+	quota.now = func() time.Time { return now.AddDate(0, 0, -3) }
+	if err := quota.Record("gpt-4o", OpenAIUsage{PromptTokens: 1000, CompletionTokens: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	quota.now = func() time.Time { return now }
+	if err := quota.Record("gpt-4o", OpenAIUsage{PromptTokens: 1500, CompletionTokens: 500}); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := quota.Status()
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if status.Daily.Requests != 1 || status.Daily.TotalTokens != 2000 || !status.Daily.Exceeded {
+		t.Errorf("Unexpected daily status: %+v", status.Daily)
+	}
+	if status.Monthly.Requests != 2 || status.Monthly.TotalTokens != 4000 || status.Monthly.Exceeded {
+		t.Errorf("Unexpected monthly status: %+v", status.Monthly)
+	}
+	if !status.Monthly.Start.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected month to start on March 1st, got %v", status.Monthly.Start)
+	}
+}
+
+func TestLLMQuotaReserve(t *testing.T) {
+	quota := setupQuota(t, QuotaConfig{DailyTokens: 2000}, time.Now())
+	estimate := OpenAIUsage{PromptTokens: 500, CompletionTokens: 500, TotalTokens: 1000}
+
+	// Concurrent requests see each other's reservations, so only two fit
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var reserved []*QuotaReservation
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if reservation, err := quota.Reserve("gpt-4o", estimate); err == nil {
+				mu.Lock()
+				reserved = append(reserved, reservation)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(reserved) != 2 {
+		t.Fatalf("Expected 2 reservations, got %d", len(reserved))
+	}
+	if err := quota.Check(); err == nil {
+		t.Error("Expected reservations in flight to count against the quota")
+	}
+
+	// Settling records the actual usage, releasing gives the estimate back
+	if err := reserved[0].Settle("gpt-4o", OpenAIUsage{PromptTokens: 200, CompletionTokens: 100, TotalTokens: 300}); err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	reserved[1].Release()
+	reserved[0].Release()
+
+	if err := quota.Check(); err != nil {
+		t.Errorf("Expected quota to be available again, got %v", err)
+	}
+	status, _ := quota.Status()
+	if status.Daily.TotalTokens != 300 || status.Daily.Requests != 1 {
+		t.Errorf("Expected only the settled usage to be recorded, got %+v", status.Daily)
+	}
+	if quota.pending != (db.LLMUsage{}) {
+		t.Errorf("Expected no pending usage, got %+v", quota.pending)
+	}
+}
+
+func TestOpenAIClientQuota(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode(OpenAIResponse{
+			Model: "gpt-4o-2024-08-06",
+			Choices: []OpenAIChoice{{Message: OpenAIMessage{
+				Role:    "assistant",
+				Content: `{"suggestions": ["Ride", "Lush", "Chapterhouse"]}`,
+			}}},
+			Usage: OpenAIUsage{PromptTokens: 800, CompletionTokens: 200, TotalTokens: 1000},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewOpenAIClient("test-key", "gpt-4o", filepath.Join("..", "..", "prompts", "openai_recommendation.tmpl"))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.baseURL = server.URL
+	quota := setupQuota(t, QuotaConfig{DailyTokens: 1000}, time.Now())
+	client.UseQuota(quota)
+
+	seeds := []models.PlexTrack{{Title: "Alison", Artist: "Slowdive", Rating: 10}}
+	if _, err := client.GetArtistRecommendations(context.Background(), seeds, nil, "", 3); err != nil {
+		t.Fatalf("First request failed: %v", err)
+	}
+
+	status, _ := quota.Status()
+	if status.Daily.TotalTokens != 1000 || status.Daily.CostUSD != 0.004 {
+		t.Errorf("Expected usage of the first request to be recorded, got %+v", status.Daily)
+	}
+
+	// The quota is used up, so the second request never reaches OpenAI
+	_, err = client.GetArtistRecommendations(context.Background(), seeds, nil, "", 3)
+	var exceeded *QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		t.Fatalf("Expected QuotaExceededError, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 request to OpenAI, got %d", requests)
+	}
+}
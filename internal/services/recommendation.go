@@ -2,18 +2,28 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
 	"time"
 
+	"gocommender/internal/db"
+	"gocommender/internal/logging"
 	"gocommender/internal/models"
+	"gocommender/internal/notify"
+	"gocommender/internal/taxonomy"
+	"gocommender/internal/tracing"
 )
 
+var recommendationLog = logging.For("recommendation")
+
 // RecommendationService orchestrates the recommendation workflow
 type RecommendationService struct {
 	plexClient        *PlexClient
 	openaiClient      *OpenAIClient
 	enrichmentService *EnrichmentService
+	cacheManager      *db.CacheManager  // Optional, persists enriched suggestions
+	graph             *GraphRecommender // Nil when no cache is available
+	notifier          *notify.Dispatcher
 }
 
 // RecommendationResult contains the complete recommendation result
@@ -29,8 +39,13 @@ type RecommendationStats struct {
 	Duration         time.Duration `json:"duration"`
 	SeedTrackCount   int           `json:"seed_track_count"`
 	KnownArtistCount int           `json:"known_artist_count"`
+	Engine           string        `json:"engine"`
+	FallbackFrom     string        `json:"fallback_from,omitempty"`
+	FallbackReason   ErrorCode     `json:"fallback_reason,omitempty"`
 	LLMSuggestions   int           `json:"llm_suggestions"`
+	GraphCandidates  int           `json:"graph_candidates"`
 	FilteredCount    int           `json:"filtered_count"`
+	GenreFiltered    int           `json:"genre_filtered"` // Enriched artists dropped for not matching the genre
 	EnrichedCount    int           `json:"enriched_count"`
 	CacheHits        int           `json:"cache_hits"`
 	CacheMisses      int           `json:"cache_misses"`
@@ -46,17 +61,77 @@ type EnrichmentStats struct {
 	Errors       []string `json:"errors"`
 }
 
-// NewRecommendationService creates a new recommendation service
-func NewRecommendationService(plex *PlexClient, openai *OpenAIClient, enrichment *EnrichmentService) *RecommendationService {
-	return &RecommendationService{
+// NewRecommendationService creates a new recommendation service.
+// cache may be nil, in which case enriched artists are not persisted and the graph engine is unavailable.
+func NewRecommendationService(plex *PlexClient, openai *OpenAIClient, enrichment *EnrichmentService, cache *db.CacheManager) *RecommendationService {
+	service := &RecommendationService{
 		plexClient:        plex,
 		openaiClient:      openai,
 		enrichmentService: enrichment,
+		cacheManager:      cache,
+	}
+	if cache != nil {
+		service.graph = NewGraphRecommender(cache, enrichment)
+	}
+	return service
+}
+
+// UseNotifier sends the results of successful recommendations to notification targets
+func (s *RecommendationService) UseNotifier(notifier *notify.Dispatcher) {
+	s.notifier = notifier
+}
+
+// checkNotify rejects notification targets that are not configured before any work is done
+func (s *RecommendationService) checkNotify(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if s.notifier == nil {
+		return NewError(CodeInvalidRequest, "notifications are not configured", nil)
+	}
+	if err := s.notifier.Check(names); err != nil {
+		return NewError(CodeInvalidRequest, err.Error(), map[string]interface{}{"notify": names})
+	}
+	return nil
+}
+
+// selectEngine resolves the requested engine, defaulting to the LLM when it is configured
+func (s *RecommendationService) selectEngine(requested string) (string, error) {
+	if !models.IsValidEngine(requested) {
+		return "", NewError(CodeInvalidRequest, fmt.Sprintf("unknown recommendation engine %q", requested), nil)
 	}
+
+	engine := requested
+	if engine == "" {
+		engine = models.EngineLLM
+		if !s.openaiClient.IsConfigured() && s.graph != nil {
+			engine = models.EngineGraph
+		}
+	}
+
+	if engine == models.EngineLLM && !s.openaiClient.IsConfigured() {
+		return "", NewError(CodeEngineUnavailable, "LLM engine is not configured, use the graph engine",
+			map[string]interface{}{"engine": engine})
+	}
+	if engine != models.EngineLLM && s.graph == nil {
+		return "", NewError(CodeEngineUnavailable, fmt.Sprintf("%s engine requires the artist cache", engine),
+			map[string]interface{}{"engine": engine})
+	}
+
+	return engine, nil
 }
 
 // GenerateRecommendations performs the complete recommendation workflow
-func (s *RecommendationService) GenerateRecommendations(ctx context.Context, request models.RecommendRequest) (*RecommendationResult, error) {
+func (s *RecommendationService) GenerateRecommendations(ctx context.Context, request models.RecommendRequest) (_ *RecommendationResult, err error) {
+	ctx, span := tracing.Start(ctx, "recommendation.Generate",
+		tracing.String("playlist", request.PlaylistName),
+		tracing.Int("max_results", request.MaxResults),
+	)
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	stats := &RecommendationStats{
 		StartTime: time.Now(),
 		Errors:    make([]string, 0),
@@ -67,21 +142,37 @@ func (s *RecommendationService) GenerateRecommendations(ctx context.Context, req
 		request.MaxResults = 5
 	}
 
+	engine, err := s.selectEngine(request.Engine)
+	if err != nil {
+		return nil, err
+	}
+	stats.Engine = engine
+	span.SetAttributes(tracing.String("engine", engine))
+
+	if err := s.checkNotify(request.Notify); err != nil {
+		return nil, err
+	}
+
 	// Step 1: Get seed tracks from Plex playlist
-	log.Printf("Fetching seed tracks from playlist: %s", request.PlaylistName)
-	seedTracks, err := s.getHighRatedTracks(request.PlaylistName)
+	recommendationLog.InfoContext(ctx, "Fetching seed tracks", "playlist", request.PlaylistName)
+	seedTracks, err := s.getHighRatedTracks(ctx, request.PlaylistName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get seed tracks: %w", err)
 	}
 	stats.SeedTrackCount = len(seedTracks)
 
 	if len(seedTracks) == 0 {
-		return nil, fmt.Errorf("no high-rated tracks found in playlist '%s'", request.PlaylistName)
+		return nil, NewError(CodeNoSeedTracks, fmt.Sprintf("no high-rated tracks found in playlist '%s'", request.PlaylistName),
+			map[string]interface{}{"playlist": request.PlaylistName})
 	}
 
 	// Step 2: Get known artists from Plex library
-	log.Printf("Fetching known artists from Plex library")
-	knownArtists, err := s.plexClient.GetAllArtists()
+	recommendationLog.InfoContext(ctx, "Fetching known artists from Plex library")
+	var knownArtists []string
+	err = traceCall(ctx, "plex.GetAllArtists", func() (err error) {
+		knownArtists, err = s.plexClient.GetAllArtists(ctx)
+		return err
+	})
 	if err != nil {
 		stats.Errors = append(stats.Errors, fmt.Sprintf("Failed to get known artists: %v", err))
 		// Continue with empty list rather than fail
@@ -89,27 +180,35 @@ func (s *RecommendationService) GenerateRecommendations(ctx context.Context, req
 	}
 	stats.KnownArtistCount = len(knownArtists)
 
-	// Step 3: Generate LLM suggestions
-	log.Printf("Generating LLM suggestions for %d seed tracks", len(seedTracks))
 	genre := ""
 	if request.Genre != nil {
 		genre = *request.Genre
 	}
-	suggestions, err := s.openaiClient.GetArtistRecommendations(
-		seedTracks, knownArtists, genre, request.MaxResults*2) // Request more to allow for filtering
-	if err != nil {
-		return nil, fmt.Errorf("failed to get LLM suggestions: %w", err)
-	}
-	stats.LLMSuggestions = len(suggestions.Suggestions)
-	stats.APICallsMade++
-
-	// Step 4: Filter suggestions against known artists
-	log.Printf("Filtering %d suggestions against known artists", len(suggestions.Suggestions))
-	filtered := s.openaiClient.FilterKnownArtists(suggestions.Suggestions, knownArtists)
-	stats.FilteredCount = len(filtered)
 
-	if len(filtered) == 0 {
-		return nil, fmt.Errorf("all LLM suggestions were filtered out as known artists")
+	// Steps 3-4: Generate suggestions with the selected engine, filtered against known artists
+	var filtered []string
+	switch engine {
+	case models.EngineGraph:
+		filtered, err = s.graphSuggestions(ctx, seedTracks, knownArtists, genre, request.MaxResults, stats)
+	case models.EngineHybrid:
+		filtered, err = s.hybridSuggestions(ctx, seedTracks, knownArtists, genre, request.MaxResults, stats)
+	default:
+		filtered, err = s.llmSuggestions(ctx, seedTracks, knownArtists, genre, request.MaxResults, nil, stats)
+		// The default engine falls back to the graph when the LLM is over quota or down
+		if err != nil && request.Engine == "" && s.graph != nil && ctx.Err() == nil {
+			if reason, ok := llmFallbackReason(err); ok {
+				recommendationLog.WarnContext(ctx, "LLM unavailable, falling back to the graph engine", "reason", reason, "error", err)
+				stats.Errors = append(stats.Errors, fmt.Sprintf("LLM unavailable, using the graph engine: %v", err))
+				stats.FallbackFrom, stats.FallbackReason = engine, reason
+				engine = models.EngineGraph
+				stats.Engine = engine
+				span.SetAttributes(tracing.String("engine", engine), tracing.String("fallback_reason", string(reason)))
+				filtered, err = s.graphSuggestions(ctx, seedTracks, knownArtists, genre, request.MaxResults, stats)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// Limit to requested number
@@ -118,7 +217,7 @@ func (s *RecommendationService) GenerateRecommendations(ctx context.Context, req
 	}
 
 	// Step 5: Enrich artist data with metadata
-	log.Printf("Enriching %d filtered suggestions", len(filtered))
+	recommendationLog.InfoContext(ctx, "Enriching filtered suggestions", "count", len(filtered))
 	enrichedArtists, enrichStats := s.enrichArtistSuggestions(ctx, filtered)
 	stats.EnrichedCount = len(enrichedArtists)
 	stats.CacheHits += enrichStats.CacheHits
@@ -126,13 +225,31 @@ func (s *RecommendationService) GenerateRecommendations(ctx context.Context, req
 	stats.APICallsMade += enrichStats.APICallsMade
 	stats.Errors = append(stats.Errors, enrichStats.Errors...)
 
+	if genre != "" {
+		var dropped int
+		enrichedArtists, dropped = filterByGenre(enrichedArtists, genre)
+		stats.GenreFiltered = dropped
+	}
+
 	// Step 6: Build response
 	stats.EndTime = time.Now()
 	stats.Duration = stats.EndTime.Sub(stats.StartTime)
 
+	span.SetAttributes(
+		tracing.Int("seed_tracks", stats.SeedTrackCount),
+		tracing.Int("suggestions", len(enrichedArtists)),
+	)
+
+	// Outside an HTTP request, e.g. in the CLI, there is no request ID yet
+	requestID := logging.RequestID(ctx)
+	if requestID == "" {
+		requestID = logging.NewRequestID()
+	}
+
 	response := &models.RecommendResponse{
 		Status:      "success",
-		RequestID:   generateRequestID(),
+		RequestID:   requestID,
+		TraceID:     span.TraceID(),
 		Suggestions: enrichedArtists,
 		Metadata: models.RecommendMetadata{
 			SeedTrackCount:   stats.SeedTrackCount,
@@ -140,6 +257,9 @@ func (s *RecommendationService) GenerateRecommendations(ctx context.Context, req
 			ProcessingTime:   stats.Duration.String(),
 			CacheHits:        stats.CacheHits,
 			APICallsMade:     stats.APICallsMade,
+			Engine:           stats.Engine,
+			FallbackFrom:     stats.FallbackFrom,
+			FallbackReason:   string(stats.FallbackReason),
 			GeneratedAt:      time.Now(),
 		},
 	}
@@ -149,24 +269,127 @@ func (s *RecommendationService) GenerateRecommendations(ctx context.Context, req
 		Stats:    stats,
 	}
 
-	log.Printf("Recommendation complete: %d suggestions in %v",
-		len(enrichedArtists), stats.Duration)
+	recommendationLog.InfoContext(ctx, "Recommendation complete",
+		"engine", stats.Engine, "suggestions", len(enrichedArtists), "duration", stats.Duration)
+
+	if s.notifier != nil {
+		s.notifier.Notify(ctx, request.Notify, notify.RecommendationEvent(request, response))
+	}
 
 	return result, nil
 }
 
+// llmSuggestions asks the LLM for artists, optionally grounded by graph candidates
+func (s *RecommendationService) llmSuggestions(ctx context.Context, seedTracks []models.PlexTrack, knownArtists []string,
+	genre string, maxResults int, candidates []string, stats *RecommendationStats) ([]string, error) {
+	recommendationLog.InfoContext(ctx, "Generating LLM suggestions", "seed_tracks", len(seedTracks))
+	suggestions, err := s.openaiClient.GetGroundedRecommendations(
+		ctx, seedTracks, knownArtists, genre, maxResults*2, candidates) // Request more to allow for filtering
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM suggestions: %w", err)
+	}
+	stats.LLMSuggestions = len(suggestions.Suggestions)
+	stats.APICallsMade++
+
+	recommendationLog.InfoContext(ctx, "Filtering suggestions against known artists", "count", len(suggestions.Suggestions))
+	filtered := s.openaiClient.FilterKnownArtists(suggestions.Suggestions, knownArtists)
+	stats.FilteredCount = len(filtered)
+
+	if len(filtered) == 0 {
+		return nil, NewError(CodeNoRecommendations, "all LLM suggestions were filtered out as known artists",
+			map[string]interface{}{"engine": models.EngineLLM, "suggestions": len(suggestions.Suggestions)})
+	}
+
+	return filtered, nil
+}
+
+// llmFallbackReason reports whether an LLM failure is worth retrying with the graph,
+// and the code to record for it. Suggestions that were all known artists are not.
+func llmFallbackReason(err error) (ErrorCode, bool) {
+	var quotaErr *QuotaExceededError
+	switch code := Code(err); {
+	case errors.As(err, &quotaErr):
+		return CodeQuotaExceeded, true
+	case code != "":
+		return code, false
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeUpstreamTimeout, true
+	}
+	return CodeUpstreamUnavailable, true
+}
+
+// graphSuggestions walks the similarity graph from the seed artists; known artists are already excluded
+func (s *RecommendationService) graphSuggestions(ctx context.Context, seedTracks []models.PlexTrack, knownArtists []string,
+	genre string, maxResults int, stats *RecommendationStats) ([]string, error) {
+	recommendationLog.InfoContext(ctx, "Walking similarity graph", "seed_tracks", len(seedTracks))
+	candidates, err := s.graph.Recommend(ctx, extractSeedArtists(seedTracks), knownArtists, genre, maxResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get graph suggestions: %w", err)
+	}
+	stats.GraphCandidates = len(candidates)
+	stats.FilteredCount = len(candidates)
+
+	if len(candidates) == 0 {
+		return nil, NewError(CodeNoRecommendations, "similarity graph produced no unknown artists",
+			map[string]interface{}{"engine": models.EngineGraph})
+	}
+
+	return candidateNames(candidates), nil
+}
+
+// hybridSuggestions grounds the LLM prompt with graph candidates and falls back to them if the LLM fails
+func (s *RecommendationService) hybridSuggestions(ctx context.Context, seedTracks []models.PlexTrack, knownArtists []string,
+	genre string, maxResults int, stats *RecommendationStats) ([]string, error) {
+	candidates, err := s.graph.Recommend(ctx, extractSeedArtists(seedTracks), knownArtists, genre, maxResults*4)
+	if err != nil {
+		stats.Errors = append(stats.Errors, fmt.Sprintf("Graph candidates unavailable: %v", err))
+	}
+	stats.GraphCandidates = len(candidates)
+	names := candidateNames(candidates)
+
+	if s.openaiClient.IsConfigured() {
+		filtered, err := s.llmSuggestions(ctx, seedTracks, knownArtists, genre, maxResults, names, stats)
+		if err == nil {
+			return filtered, nil
+		}
+		stats.Errors = append(stats.Errors, fmt.Sprintf("LLM failed, using graph results: %v", err))
+	}
+
+	if len(names) == 0 {
+		return nil, NewError(CodeNoRecommendations, "no suggestions from LLM or similarity graph",
+			map[string]interface{}{"engine": models.EngineHybrid})
+	}
+	stats.FilteredCount = len(names)
+	return names, nil
+}
+
+// candidateNames returns the artist names of graph candidates in rank order
+func candidateNames(candidates []GraphCandidate) []string {
+	names := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		names = append(names, candidate.Name)
+	}
+	return names
+}
+
 // getHighRatedTracks retrieves high-rated tracks from the specified playlist
-func (s *RecommendationService) getHighRatedTracks(playlistName string) ([]models.PlexTrack, error) {
+func (s *RecommendationService) getHighRatedTracks(ctx context.Context, playlistName string) (_ []models.PlexTrack, err error) {
+	ctx, span := tracing.Start(ctx, "plex.GetHighRatedTracks", tracing.String("playlist", playlistName))
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
 	// Get high-rated tracks (7+ rating)
-	tracks, err := s.plexClient.GetHighRatedTracks(playlistName, 7)
+	tracks, err := s.plexClient.GetHighRatedTracks(ctx, playlistName, 7)
 	if err != nil {
 		return nil, err
 	}
 
 	// If no high-rated tracks, try with lower threshold
 	if len(tracks) == 0 {
-		log.Printf("No tracks with 7+ rating, trying 5+ rating")
-		tracks, err = s.plexClient.GetHighRatedTracks(playlistName, 5)
+		recommendationLog.InfoContext(ctx, "No tracks with 7+ rating, trying 5+ rating")
+		tracks, err = s.plexClient.GetHighRatedTracks(ctx, playlistName, 5)
 		if err != nil {
 			return nil, err
 		}
@@ -174,8 +397,8 @@ func (s *RecommendationService) getHighRatedTracks(playlistName string) ([]model
 
 	// If still no tracks, get all tracks from playlist
 	if len(tracks) == 0 {
-		log.Printf("No rated tracks found, using all tracks from playlist")
-		tracks, err = s.plexClient.GetPlaylistTracks(playlistName)
+		recommendationLog.InfoContext(ctx, "No rated tracks found, using all tracks from playlist")
+		tracks, err = s.plexClient.GetPlaylistTracks(ctx, playlistName)
 		if err != nil {
 			return nil, err
 		}
@@ -194,10 +417,10 @@ func (s *RecommendationService) enrichArtistSuggestions(ctx context.Context, art
 
 	// Process each artist sequentially for now (can be optimized later)
 	for _, name := range artistNames {
-		log.Printf("Enriching artist: %s", name)
+		recommendationLog.DebugContext(ctx, "Enriching artist", "artist", name)
 
 		// Use enrichment service to get full artist data
-		artist, err := s.enrichmentService.EnrichArtistByName(name, nil)
+		artist, err := s.enrichmentService.EnrichArtistByName(ctx, name, nil)
 		if err != nil {
 			stats.Errors = append(stats.Errors, fmt.Sprintf("Failed to enrich %s: %v", name, err))
 			stats.CacheMisses++
@@ -212,12 +435,34 @@ func (s *RecommendationService) enrichArtistSuggestions(ctx context.Context, art
 		}
 
 		stats.APICallsMade++ // Simplified - enrichment service makes multiple calls
+
+		// Persist the artist and its discography for later lookups
+		if s.cacheManager != nil {
+			if err := s.cacheManager.CacheArtist(artist, s.cacheManager.TTLPolicy()); err != nil {
+				stats.Errors = append(stats.Errors, fmt.Sprintf("Failed to cache %s: %v", name, err))
+			}
+		}
+
 		enriched = append(enriched, *artist)
 	}
 
 	return enriched, stats
 }
 
+// filterByGenre drops artists whose genres are known and do not fall under the requested genre.
+// Subgenres match, so "metal" keeps "doom metal". Artists without genres are kept.
+func filterByGenre(artists []models.Artist, genre string) ([]models.Artist, int) {
+	kept := make([]models.Artist, 0, len(artists))
+	for _, artist := range artists {
+		if len(artist.Genres) == 0 || taxonomy.MatchesAny(artist.Genres, genre) {
+			kept = append(kept, artist)
+			continue
+		}
+		recommendationLog.Debug("Dropping artist outside the genre", "artist", artist.Name, "genres", artist.Genres, "genre", genre)
+	}
+	return kept, len(artists) - len(kept)
+}
+
 // hasAnyVerification checks if an artist has been verified by any service
 func hasAnyVerification(verified models.VerificationMap) bool {
 	for _, isVerified := range verified {
@@ -227,8 +472,3 @@ func hasAnyVerification(verified models.VerificationMap) bool {
 	}
 	return false
 }
-
-// generateRequestID creates a unique request identifier
-func generateRequestID() string {
-	return fmt.Sprintf("rec_%d", time.Now().UnixNano())
-}
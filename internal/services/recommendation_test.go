@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gocommender/internal/db"
+	"gocommender/internal/models"
+	"gocommender/internal/notify"
+)
+
+func TestSelectEngine(t *testing.T) {
+	configured := &OpenAIClient{apiKey: "key"}
+	unconfigured := &OpenAIClient{}
+	cache := setupGraphCache(t)
+
+	tests := []struct {
+		name      string
+		openai    *OpenAIClient
+		cache     *db.CacheManager
+		requested string
+		expected  string
+		wantErr   bool
+	}{
+		{"default with LLM", configured, cache, "", models.EngineLLM, false},
+		{"default without LLM", unconfigured, cache, "", models.EngineGraph, false},
+		{"explicit graph", configured, cache, models.EngineGraph, models.EngineGraph, false},
+		{"hybrid", configured, cache, models.EngineHybrid, models.EngineHybrid, false},
+		{"llm unconfigured", unconfigured, cache, models.EngineLLM, "", true},
+		{"graph without cache", configured, nil, models.EngineGraph, "", true},
+		{"unknown engine", configured, cache, "magic", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewRecommendationService(nil, tt.openai, nil, tt.cache)
+			engine, err := service.selectEngine(tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectEngine(%q) error = %v, wantErr %v", tt.requested, err, tt.wantErr)
+			}
+			if engine != tt.expected {
+				t.Errorf("selectEngine(%q) = %q, want %q", tt.requested, engine, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLLMFallbackReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected ErrorCode
+		fallback bool
+	}{
+		{"quota", fmt.Errorf("failed to get LLM suggestions: %w", &QuotaExceededError{Period: "daily"}), CodeQuotaExceeded, true},
+		{"timeout", fmt.Errorf("request failed: %w", context.DeadlineExceeded), CodeUpstreamTimeout, true},
+		{"upstream", errors.New("OpenAI API error: 500"), CodeUpstreamUnavailable, true},
+		{"all known", NewError(CodeNoRecommendations, "no new artists", nil), CodeNoRecommendations, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, fallback := llmFallbackReason(tt.err)
+			if code != tt.expected || fallback != tt.fallback {
+				t.Errorf("llmFallbackReason() = (%q, %v), want (%q, %v)", code, fallback, tt.expected, tt.fallback)
+			}
+		})
+	}
+}
+
+func TestCheckNotify(t *testing.T) {
+	service := NewRecommendationService(nil, &OpenAIClient{}, nil, nil)
+	if err := service.checkNotify(nil); err != nil {
+		t.Errorf("Expected no error without targets, got %v", err)
+	}
+	if err := service.checkNotify([]string{"discord"}); Code(err) != CodeInvalidRequest {
+		t.Errorf("Expected invalid_request without a notifier, got %v", err)
+	}
+
+	notifier, err := notify.New(notify.Config{
+		Targets: []notify.TargetConfig{{Name: "discord", Type: notify.TypeDiscord, URL: "https://discord.com/api/webhooks/1/token"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create notifier: %v", err)
+	}
+	service.UseNotifier(notifier)
+
+	if err := service.checkNotify([]string{"discord"}); err != nil {
+		t.Errorf("Expected a configured target to pass, got %v", err)
+	}
+	if err := service.checkNotify([]string{"discord", "pager"}); Code(err) != CodeInvalidRequest {
+		t.Errorf("Expected invalid_request for an unknown target, got %v", err)
+	}
+}
+
+func TestFilterByGenre(t *testing.T) {
+	artists := []models.Artist{
+		{Name: "Doom Band", Genres: models.Genres{"doom metal"}},
+		{Name: "Jazz Trio", Genres: models.Genres{"jazz"}},
+		{Name: "Unknown"},
+		{Name: "Crossover", Genres: models.Genres{"jazz", "metalcore"}},
+	}
+
+	kept, dropped := filterByGenre(artists, "metal")
+	if dropped != 1 || len(kept) != 3 {
+		t.Fatalf("Expected 1 artist dropped, got %d dropped and %d kept", dropped, len(kept))
+	}
+	for _, artist := range kept {
+		if artist.Name == "Jazz Trio" {
+			t.Error("Expected Jazz Trio to be filtered out")
+		}
+	}
+}
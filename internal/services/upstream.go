@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"gocommender/internal/metrics"
+	"gocommender/internal/models"
+)
+
+// ErrNotCached is returned in offline mode when a request has no stored response
+var ErrNotCached = errors.New("response not cached")
+
+// credentialParams are stripped from cache keys so tokens never end up in the database
+var credentialParams = []string{"token", "api_key", "key", "secret"}
+
+// ResponseStore persists raw upstream responses
+type ResponseStore interface {
+	GetResponse(source, key string) (*models.UpstreamResponse, error)
+	SaveResponse(response *models.UpstreamResponse) error
+}
+
+// ResponseCacheStats counts how requests through a CachingTransport were answered
+type ResponseCacheStats struct {
+	Hits        int64 `json:"hits"`        // Served from a fresh stored response
+	Revalidated int64 `json:"revalidated"` // Upstream answered 304 Not Modified
+	Fetched     int64 `json:"fetched"`     // Fetched and stored
+	Misses      int64 `json:"misses"`      // Offline requests without a stored response
+}
+
+// responseCacheRequests mirrors ResponseCacheStats across all sources
+var responseCacheRequests = metrics.NewCounter("gocommender_response_cache_requests_total",
+	"Upstream requests through the response cache by source and result (hit, miss, revalidated, fetched).",
+	"source", "result")
+
+// CachingTransport stores successful GET responses of one upstream API.
+// Fresh responses are served without a network call; stale ones are revalidated with
+// If-None-Match / If-Modified-Since. In offline mode every request is answered from the store.
+type CachingTransport struct {
+	source  string
+	ttl     time.Duration
+	store   ResponseStore
+	next    http.RoundTripper
+	offline bool
+
+	hits, revalidated, fetched, misses atomic.Int64
+}
+
+// NewCachingTransport wraps next, which may be nil to use http.DefaultTransport
+func NewCachingTransport(source string, ttl time.Duration, store ResponseStore, next http.RoundTripper, offline bool) *CachingTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &CachingTransport{
+		source:  source,
+		ttl:     ttl,
+		store:   store,
+		next:    next,
+		offline: offline,
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		if t.offline {
+			return nil, fmt.Errorf("%s %s: %w", req.Method, t.source, ErrNotCached)
+		}
+		return t.next.RoundTrip(req)
+	}
+
+	key := responseCacheKey(req.URL)
+	cached, err := t.store.GetResponse(t.source, key)
+	if err != nil {
+		enrichmentLog.WarnContext(req.Context(), "Failed to read response cache", "source", t.source, "error", err)
+		cached = nil
+	}
+
+	if t.offline {
+		if cached == nil {
+			t.misses.Add(1)
+			responseCacheRequests.Inc(t.source, "miss")
+			return nil, fmt.Errorf("%s %s: %w", t.source, key, ErrNotCached)
+		}
+		t.hits.Add(1)
+		responseCacheRequests.Inc(t.source, "hit")
+		return cachedResponse(req, cached), nil
+	}
+
+	if cached != nil && cached.IsFresh() {
+		t.hits.Add(1)
+		responseCacheRequests.Inc(t.source, "hit")
+		return cachedResponse(req, cached), nil
+	}
+
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		t.revalidated.Add(1)
+		responseCacheRequests.Inc(t.source, "revalidated")
+		cached.FetchedAt = time.Now()
+		cached.ExpiresAt = cached.FetchedAt.Add(t.ttl)
+		t.save(cached)
+		return cachedResponse(req, cached), nil
+	}
+
+	// Only successful responses are worth replaying
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	now := time.Now()
+	t.fetched.Add(1)
+	responseCacheRequests.Inc(t.source, "fetched")
+	t.save(&models.UpstreamResponse{
+		Source:       t.source,
+		Key:          key,
+		StatusCode:   resp.StatusCode,
+		ContentType:  resp.Header.Get("Content-Type"),
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		FetchedAt:    now,
+		ExpiresAt:    now.Add(t.ttl),
+	})
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// CanServe reports whether a request would be answered without a network call
+func (t *CachingTransport) CanServe(req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return t.offline
+	}
+	if t.offline {
+		return true // Either served or rejected, never sent
+	}
+
+	cached, err := t.store.GetResponse(t.source, responseCacheKey(req.URL))
+	return err == nil && cached != nil && cached.IsFresh()
+}
+
+// Stats returns the request counters
+func (t *CachingTransport) Stats() ResponseCacheStats {
+	return ResponseCacheStats{
+		Hits:        t.hits.Load(),
+		Revalidated: t.revalidated.Load(),
+		Fetched:     t.fetched.Load(),
+		Misses:      t.misses.Load(),
+	}
+}
+
+// save stores a response, logging failures since the fetched data is still usable
+func (t *CachingTransport) save(response *models.UpstreamResponse) {
+	if err := t.store.SaveResponse(response); err != nil {
+		enrichmentLog.Warn("Failed to store response", "source", t.source, "error", err)
+	}
+}
+
+// cachedResponse builds an HTTP response from a stored body
+func cachedResponse(req *http.Request, cached *models.UpstreamResponse) *http.Response {
+	header := make(http.Header)
+	if cached.ContentType != "" {
+		header.Set("Content-Type", cached.ContentType)
+	}
+	header.Set("X-Gocommender-Cache", "hit")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", cached.StatusCode, http.StatusText(cached.StatusCode)),
+		StatusCode:    cached.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+}
+
+// responseCacheKey is the request host, path and sorted query without
+// credentials. The host tells apart sources that serve the same path from
+// several hosts, like the Wikipedia languages.
+func responseCacheKey(u *url.URL) string {
+	query := u.Query()
+	for _, param := range credentialParams {
+		query.Del(param)
+	}
+
+	key := u.Host + u.Path
+	if encoded := query.Encode(); encoded != "" {
+		return key + "?" + encoded
+	}
+	return key
+}
+
+// waitForRateLimit blocks on the limiter unless the response cache can answer the request
+func waitForRateLimit(limiter *time.Ticker, client *http.Client, req *http.Request) {
+	if transport, ok := client.Transport.(*CachingTransport); ok && transport.CanServe(req) {
+		return
+	}
+	<-limiter.C
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gocommender/internal/config"
+	"gocommender/internal/db"
+)
+
+func newTestResponseDB(t *testing.T) *db.ResponseDB {
+	t.Helper()
+
+	database, err := config.InitDatabase(filepath.Join(t.TempDir(), "responses.db"))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	return db.NewResponseDB(database)
+}
+
+// newETagServer serves a JSON body with an ETag and answers matching If-None-Match with 304
+func newETagServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"name":"Slowdive"}`)
+	}))
+}
+
+func getBody(t *testing.T, client *http.Client, urlStr string) (int, string) {
+	t.Helper()
+
+	resp, err := client.Get(urlStr)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", urlStr, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestCachingTransportStoresAndRevalidates(t *testing.T) {
+	var requests int
+	server := newETagServer(t, &requests)
+	defer server.Close()
+
+	store := newTestResponseDB(t)
+	transport := NewCachingTransport("musicbrainz", time.Hour, store, nil, false)
+	client := &http.Client{Transport: transport}
+
+	// First request is fetched and stored, the second is a hit even with a different token
+	if _, body := getBody(t, client, server.URL+"/artist/1?fmt=json&token=a"); body != `{"name":"Slowdive"}` {
+		t.Fatalf("Unexpected body %q", body)
+	}
+	if _, body := getBody(t, client, server.URL+"/artist/1?token=b&fmt=json"); body != `{"name":"Slowdive"}` {
+		t.Fatalf("Unexpected cached body %q", body)
+	}
+	if requests != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", requests)
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	stored, err := store.GetResponse("musicbrainz", host+"/artist/1?fmt=json")
+	if err != nil || stored == nil {
+		t.Fatalf("Expected response to be stored without the token, got %v, %v", stored, err)
+	}
+	if stored.ETag != `"v1"` {
+		t.Errorf("Expected ETag to be stored, got %q", stored.ETag)
+	}
+
+	// Stale responses are revalidated with the stored ETag
+	stored.ExpiresAt = time.Now().Add(-time.Minute)
+	if err := store.SaveResponse(stored); err != nil {
+		t.Fatalf("Failed to expire response: %v", err)
+	}
+	if status, body := getBody(t, client, server.URL+"/artist/1?fmt=json"); status != http.StatusOK || body != `{"name":"Slowdive"}` {
+		t.Errorf("Expected 304 to be answered from the cache, got %d %q", status, body)
+	}
+	if requests != 2 {
+		t.Errorf("Expected a revalidation request, got %d requests", requests)
+	}
+
+	refreshed, _ := store.GetResponse("musicbrainz", host+"/artist/1?fmt=json")
+	if !refreshed.IsFresh() {
+		t.Error("Expected revalidated response to be fresh again")
+	}
+
+	// Errors are passed through and not stored
+	if status, _ := getBody(t, client, server.URL+"/missing"); status != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", status)
+	}
+	if missing, _ := store.GetResponse("musicbrainz", host+"/missing"); missing != nil {
+		t.Error("Expected error responses not to be stored")
+	}
+
+	stats := transport.Stats()
+	if stats.Fetched != 1 || stats.Hits != 1 || stats.Revalidated != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestCachingTransportOffline(t *testing.T) {
+	var requests int
+	server := newETagServer(t, &requests)
+	defer server.Close()
+
+	store := newTestResponseDB(t)
+	online := &http.Client{Transport: NewCachingTransport("discogs", time.Nanosecond, store, nil, false)}
+	getBody(t, online, server.URL+"/artists/1")
+
+	offlineTransport := NewCachingTransport("discogs", 0, store, nil, true)
+	offline := &http.Client{Transport: offlineTransport}
+
+	// Stale responses are replayed as-is
+	if _, body := getBody(t, offline, server.URL+"/artists/1"); body != `{"name":"Slowdive"}` {
+		t.Errorf("Expected stored body offline, got %q", body)
+	}
+
+	_, err := offline.Get(server.URL + "/artists/2")
+	if !errors.Is(err, ErrNotCached) {
+		t.Errorf("Expected ErrNotCached, got %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected no upstream requests offline, got %d total", requests)
+	}
+	if stats := offlineTransport.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Unexpected offline stats %+v", stats)
+	}
+}
+
+func TestEnrichmentServiceOfflineSkipsRateLimit(t *testing.T) {
+	var requests int
+	server := newETagServer(t, &requests)
+	defer server.Close()
+
+	store := newTestResponseDB(t)
+	service := NewEnrichmentService("", "", "")
+	defer service.Close()
+	service.musicbrainz.baseURL = server.URL
+
+	service.UseResponseCache(store, map[string]time.Duration{"musicbrainz": time.Hour}, false)
+	if _, err := service.musicbrainz.GetRelations("mbid-1"); err != nil {
+		t.Fatalf("Online request failed: %v", err)
+	}
+
+	service.UseResponseCache(store, nil, true)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := service.musicbrainz.GetRelations("mbid-1"); err != nil {
+			t.Fatalf("Offline request failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected cached requests to skip rate limiting, took %v", elapsed)
+	}
+
+	if _, err := service.musicbrainz.GetRelations("mbid-2"); !errors.Is(err, ErrNotCached) {
+		t.Errorf("Expected uncached request to fail offline, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected a single upstream request, got %d", requests)
+	}
+	if stats := service.GetResponseCacheStats()["musicbrainz"]; stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("Unexpected musicbrainz stats %+v", stats)
+	}
+}
+
+func TestResponseCacheKey(t *testing.T) {
+	tests := []struct {
+		rawURL   string
+		expected string
+	}{
+		{"https://api.discogs.com/artists/1?token=secret", "api.discogs.com/artists/1"},
+		{"https://ws.audioscrobbler.com/2.0/?method=artist.getinfo&api_key=secret&artist=Slowdive&format=json", "ws.audioscrobbler.com/2.0/?artist=Slowdive&format=json&method=artist.getinfo"},
+		{"https://musicbrainz.org/ws/2/artist/abc?inc=url-rels&fmt=json", "musicbrainz.org/ws/2/artist/abc?fmt=json&inc=url-rels"},
+		{"https://de.wikipedia.org/w/api.php?titles=Slowdive", "de.wikipedia.org/w/api.php?titles=Slowdive"},
+		{"https://en.wikipedia.org/w/api.php?titles=Slowdive", "en.wikipedia.org/w/api.php?titles=Slowdive"},
+	}
+
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.rawURL)
+		if key := responseCacheKey(u); key != tt.expected {
+			t.Errorf("responseCacheKey(%s) = %q, want %q", tt.rawURL, key, tt.expected)
+		}
+	}
+}
+
+func TestCachingTransportSeparatesHosts(t *testing.T) {
+	// Two hosts serving the same path, like the Wikipedia languages
+	newServer := func(language string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, `{"lang":%q}`, language)
+		}))
+	}
+	de := newServer("de")
+	defer de.Close()
+	en := newServer("en")
+	defer en.Close()
+
+	client := &http.Client{Transport: NewCachingTransport("wikipedia", time.Hour, newTestResponseDB(t), nil, false)}
+	for _, tt := range []struct {
+		server   *httptest.Server
+		expected string
+	}{
+		{de, `{"lang":"de"}`},
+		{en, `{"lang":"en"}`},
+		{de, `{"lang":"de"}`},
+	} {
+		if _, body := getBody(t, client, tt.server.URL+"/w/api.php?titles=Slowdive"); body != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, body)
+		}
+	}
+}
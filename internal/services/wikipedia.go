@@ -0,0 +1,257 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gocommender/internal/metrics"
+	"gocommender/internal/models"
+)
+
+// DefaultLanguage is used when no language preference is configured and as the last fallback
+const DefaultLanguage = "en"
+
+// WikipediaClient fetches artist biographies via Wikidata sitelinks and Wikipedia extracts
+type WikipediaClient struct {
+	wikidataURL  string // Wikidata action API endpoint
+	wikipediaURL string // Wikipedia action API endpoint, %s is replaced with the language code
+	httpClient   *http.Client
+	userAgent    string
+	rateLimiter  *time.Ticker
+}
+
+// wikidataResponse wraps the wbgetentities response
+type wikidataResponse struct {
+	Entities map[string]struct {
+		Sitelinks map[string]struct {
+			Site  string `json:"site"`
+			Title string `json:"title"`
+		} `json:"sitelinks"`
+	} `json:"entities"`
+}
+
+// wikipediaExtractResponse wraps the prop=extracts query response
+type wikipediaExtractResponse struct {
+	Query struct {
+		Pages map[string]struct {
+			Title   string  `json:"title"`
+			Extract string  `json:"extract"`
+			Missing *string `json:"missing,omitempty"`
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+var (
+	wikidataIDPattern    = regexp.MustCompile(`^Q[0-9]+$`)
+	wikipediaPathPattern = regexp.MustCompile(`^https?://([a-z\-]+)\.wikipedia\.org/wiki/(.+)$`)
+	multipleNewlines     = regexp.MustCompile(`\n{2,}`)
+)
+
+// NewWikipediaClient creates a new Wikidata/Wikipedia client
+func NewWikipediaClient() *WikipediaClient {
+	return &WikipediaClient{
+		wikidataURL:  "https://www.wikidata.org/w/api.php",
+		wikipediaURL: "https://%s.wikipedia.org/w/api.php",
+		httpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: metrics.InstrumentTransport("wikipedia", nil),
+		},
+		userAgent:   "GoCommender/1.0 (https://github.com/lepinkainen/gocommender)",
+		rateLimiter: time.NewTicker(200 * time.Millisecond), // Be polite to Wikimedia
+	}
+}
+
+// GetSitelinks returns the Wikipedia article titles for a Wikidata entity keyed by language code
+func (c *WikipediaClient) GetSitelinks(wikidataID string) (map[string]string, error) {
+	if !wikidataIDPattern.MatchString(wikidataID) {
+		return nil, fmt.Errorf("invalid Wikidata ID %q", wikidataID)
+	}
+
+	params := url.Values{}
+	params.Set("action", "wbgetentities")
+	params.Set("ids", wikidataID)
+	params.Set("props", "sitelinks")
+	params.Set("format", "json")
+
+	var response wikidataResponse
+	if err := c.get(c.wikidataURL+"?"+params.Encode(), &response); err != nil {
+		return nil, err
+	}
+
+	entity, exists := response.Entities[wikidataID]
+	if !exists {
+		return nil, fmt.Errorf("wikidata entity %s not found", wikidataID)
+	}
+
+	titles := make(map[string]string)
+	for site, link := range entity.Sitelinks {
+		// Only plain language wikis, e.g. "dewiki" but not "dewikiquote" or "commonswiki"
+		lang, ok := strings.CutSuffix(site, "wiki")
+		if !ok || lang == "" || lang == "commons" || lang == "species" || lang == "meta" {
+			continue
+		}
+		titles[strings.ReplaceAll(lang, "_", "-")] = link.Title
+	}
+
+	return titles, nil
+}
+
+// GetIntroExtract returns the plain text introduction of a Wikipedia article
+func (c *WikipediaClient) GetIntroExtract(lang, title string) (string, error) {
+	params := url.Values{}
+	params.Set("action", "query")
+	params.Set("prop", "extracts")
+	params.Set("exintro", "1")
+	params.Set("explaintext", "1")
+	params.Set("redirects", "1")
+	params.Set("titles", title)
+	params.Set("format", "json")
+
+	var response wikipediaExtractResponse
+	if err := c.get(fmt.Sprintf(c.wikipediaURL, lang)+"?"+params.Encode(), &response); err != nil {
+		return "", err
+	}
+
+	for _, page := range response.Query.Pages {
+		if page.Missing != nil {
+			continue
+		}
+		if extract := cleanWikipediaExtract(page.Extract); extract != "" {
+			return extract, nil
+		}
+	}
+
+	return "", fmt.Errorf("no extract for %s:%s", lang, title)
+}
+
+// EnrichArtist sets the artist description from Wikipedia in the first preferred language available.
+// English is always tried last. The extract replaces shorter Last.fm and Discogs bios, also on refresh.
+func (c *WikipediaClient) EnrichArtist(artist *models.Artist, languages []string) error {
+	if artist.Verified == nil {
+		artist.Verified = make(models.VerificationMap)
+	}
+
+	titles, err := c.resolveTitles(artist)
+	if err != nil || len(titles) == 0 {
+		// Graceful degradation - no Wikipedia article known for this artist
+		artist.Verified["wikipedia"] = false
+		return nil
+	}
+
+	for _, lang := range preferredLanguages(languages) {
+		title, exists := titles[lang]
+		if !exists {
+			continue
+		}
+
+		extract, err := c.GetIntroExtract(lang, title)
+		if err != nil {
+			continue
+		}
+
+		artist.Verified["wikipedia"] = true
+
+		artist.Description = extract
+		artist.ExternalURLs.Wikipedia = wikipediaArticleURL(lang, title)
+		return nil
+	}
+
+	artist.Verified["wikipedia"] = false
+	return nil
+}
+
+// resolveTitles finds article titles via the Wikidata relation, falling back to a direct Wikipedia link
+func (c *WikipediaClient) resolveTitles(artist *models.Artist) (map[string]string, error) {
+	if id := wikidataIDFromURL(artist.ExternalURLs.Wikidata); id != "" {
+		return c.GetSitelinks(id)
+	}
+
+	if matches := wikipediaPathPattern.FindStringSubmatch(artist.ExternalURLs.Wikipedia); matches != nil {
+		title, err := url.PathUnescape(matches[2])
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{matches[1]: strings.ReplaceAll(title, "_", " ")}, nil
+	}
+
+	return nil, fmt.Errorf("no Wikidata or Wikipedia link for %s", artist.Name)
+}
+
+// get performs a rate limited GET request and decodes the JSON response
+func (c *WikipediaClient) get(urlStr string, target interface{}) error {
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+
+	waitForRateLimit(c.rateLimiter, c.httpClient, req) // Rate limiting, skipped for cached responses
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// preferredLanguages normalizes the language preference and appends the default language
+func preferredLanguages(languages []string) []string {
+	result := make([]string, 0, len(languages)+1)
+	seen := make(map[string]bool)
+
+	candidates := append(append([]string{}, languages...), DefaultLanguage)
+	for _, lang := range candidates {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if lang == "" || seen[lang] {
+			continue
+		}
+		seen[lang] = true
+		result = append(result, lang)
+	}
+
+	return result
+}
+
+// wikidataIDFromURL extracts the entity ID from a Wikidata URL such as https://www.wikidata.org/wiki/Q1299
+func wikidataIDFromURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+	id := rawURL[strings.LastIndex(rawURL, "/")+1:]
+	if !wikidataIDPattern.MatchString(id) {
+		return ""
+	}
+	return id
+}
+
+func wikipediaArticleURL(lang, title string) string {
+	return fmt.Sprintf("https://%s.wikipedia.org/wiki/%s", lang, url.PathEscape(strings.ReplaceAll(title, " ", "_")))
+}
+
+// cleanWikipediaExtract trims the plain text extract and collapses blank lines between paragraphs
+func cleanWikipediaExtract(extract string) string {
+	extract = strings.TrimSpace(extract)
+	return multipleNewlines.ReplaceAllString(extract, "\n\n")
+}
+
+// Close stops the rate limiter
+func (c *WikipediaClient) Close() {
+	if c.rateLimiter != nil {
+		c.rateLimiter.Stop()
+	}
+}
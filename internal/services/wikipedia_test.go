@@ -0,0 +1,203 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gocommender/internal/models"
+)
+
+// newWikimediaStandIn serves canned wbgetentities and extract responses
+func newWikimediaStandIn(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var requested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		requested = append(requested, r.URL.Path+" "+query.Get("titles"))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wikidata":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"entities": map[string]interface{}{
+					"Q1299": map[string]interface{}{
+						"sitelinks": map[string]interface{}{
+							"enwiki":      map[string]string{"site": "enwiki", "title": "The Beatles"},
+							"dewiki":      map[string]string{"site": "dewiki", "title": "The Beatles"},
+							"commonswiki": map[string]string{"site": "commonswiki", "title": "Category:The Beatles"},
+							"dewikiquote": map[string]string{"site": "dewikiquote", "title": "The Beatles"},
+						},
+					},
+				},
+			})
+		case "/de/api.php":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"query": map[string]interface{}{
+					"pages": map[string]interface{}{
+						"1": map[string]string{"title": "The Beatles", "extract": "Die Beatles waren eine Band.\n\n\n\nSie kamen aus Liverpool.\n"},
+					},
+				},
+			})
+		case "/en/api.php":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"query": map[string]interface{}{
+					"pages": map[string]interface{}{
+						"1": map[string]string{"title": "The Beatles", "extract": "The Beatles were a band."},
+					},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server, &requested
+}
+
+func newTestWikipediaClient(baseURL string) *WikipediaClient {
+	client := NewWikipediaClient()
+	client.wikidataURL = baseURL + "/wikidata"
+	client.wikipediaURL = baseURL + "/%s/api.php"
+	return client
+}
+
+func TestWikipediaEnrichArtistLanguagePreference(t *testing.T) {
+	server, _ := newWikimediaStandIn(t)
+	defer server.Close()
+
+	client := newTestWikipediaClient(server.URL)
+	defer client.Close()
+
+	tests := []struct {
+		name        string
+		languages   []string
+		description string
+		articleURL  string
+	}{
+		{"german preferred", []string{"de"}, "Die Beatles waren eine Band.\n\nSie kamen aus Liverpool.", "https://de.wikipedia.org/wiki/The_Beatles"},
+		{"finnish falls back to english", []string{"fi"}, "The Beatles were a band.", "https://en.wikipedia.org/wiki/The_Beatles"},
+		{"no preference", nil, "The Beatles were a band.", "https://en.wikipedia.org/wiki/The_Beatles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artist := &models.Artist{
+				Name:         "The Beatles",
+				ExternalURLs: models.ExternalURLs{Wikidata: "https://www.wikidata.org/wiki/Q1299"},
+			}
+
+			if err := client.EnrichArtist(artist, tt.languages); err != nil {
+				t.Fatalf("EnrichArtist failed: %v", err)
+			}
+
+			if !artist.Verified["wikipedia"] {
+				t.Error("Expected wikipedia verification to be true")
+			}
+			if artist.Description != tt.description {
+				t.Errorf("Expected description %q, got %q", tt.description, artist.Description)
+			}
+			if artist.ExternalURLs.Wikipedia != tt.articleURL {
+				t.Errorf("Expected article URL %s, got %s", tt.articleURL, artist.ExternalURLs.Wikipedia)
+			}
+		})
+	}
+}
+
+func TestWikipediaEnrichArtistReplacesOtherDescription(t *testing.T) {
+	server, _ := newWikimediaStandIn(t)
+	defer server.Close()
+
+	client := newTestWikipediaClient(server.URL)
+	defer client.Close()
+
+	artist := &models.Artist{
+		Name:         "The Beatles",
+		Description:  "The Beatles were an English rock band... Read more on Last.fm",
+		ExternalURLs: models.ExternalURLs{Wikidata: "https://www.wikidata.org/wiki/Q1299"},
+	}
+
+	client.EnrichArtist(artist, []string{"de"})
+
+	if artist.Description != "Die Beatles waren eine Band.\n\nSie kamen aus Liverpool." {
+		t.Errorf("Expected the German extract to replace the Last.fm bio, got %q", artist.Description)
+	}
+
+	// Without an article the description is kept
+	artist.ExternalURLs = models.ExternalURLs{}
+	client.EnrichArtist(artist, []string{"de"})
+	if artist.Description != "Die Beatles waren eine Band.\n\nSie kamen aus Liverpool." {
+		t.Errorf("Expected the description to be kept without an article, got %q", artist.Description)
+	}
+}
+
+func TestWikipediaEnrichArtistDirectLink(t *testing.T) {
+	server, requested := newWikimediaStandIn(t)
+	defer server.Close()
+
+	client := newTestWikipediaClient(server.URL)
+	defer client.Close()
+
+	artist := &models.Artist{
+		Name:         "The Beatles",
+		ExternalURLs: models.ExternalURLs{Wikipedia: "https://en.wikipedia.org/wiki/The_Beatles"},
+	}
+
+	client.EnrichArtist(artist, []string{"de"})
+
+	if artist.Description != "The Beatles were a band." {
+		t.Errorf("Unexpected description %q", artist.Description)
+	}
+	if len(*requested) != 1 || !strings.HasSuffix((*requested)[0], " The Beatles") {
+		t.Errorf("Expected a single extract request for the linked title, got %v", *requested)
+	}
+}
+
+func TestWikipediaEnrichArtistNoLinks(t *testing.T) {
+	client := NewWikipediaClient()
+	defer client.Close()
+
+	artist := &models.Artist{Name: "Unknown Artist"}
+	if err := client.EnrichArtist(artist, nil); err != nil {
+		t.Fatalf("EnrichArtist should degrade gracefully, got: %v", err)
+	}
+	if artist.Verified["wikipedia"] {
+		t.Error("Expected wikipedia verification to be false")
+	}
+}
+
+func TestPreferredLanguages(t *testing.T) {
+	languages := []string{" FI ", "de", "fi", ""}
+	result := preferredLanguages(languages)
+
+	expected := []string{"fi", "de", "en"}
+	if strings.Join(result, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if languages[0] != " FI " {
+		t.Error("preferredLanguages should not modify its input")
+	}
+}
+
+func TestApplyURLRelations(t *testing.T) {
+	mb := &MusicBrainzArtist{ID: "artist-mbid", Name: "Test Artist"}
+	mb.Relations = make([]MusicBrainzRelation, 2)
+	mb.Relations[0].Type = "wikidata"
+	mb.Relations[0].TargetType = "url"
+	mb.Relations[0].URL.Resource = "https://www.wikidata.org/wiki/Q1299"
+	mb.Relations[1].Type = "discogs"
+	mb.Relations[1].TargetType = "url"
+	mb.Relations[1].URL.Resource = "https://www.discogs.com/artist/82730"
+
+	artist := mb.ToArtistModel()
+
+	if artist.ExternalURLs.Wikidata != "https://www.wikidata.org/wiki/Q1299" {
+		t.Errorf("Expected Wikidata URL to be set, got %q", artist.ExternalURLs.Wikidata)
+	}
+	if wikidataIDFromURL(artist.ExternalURLs.Wikidata) != "Q1299" {
+		t.Errorf("Expected Wikidata ID Q1299, got %q", wikidataIDFromURL(artist.ExternalURLs.Wikidata))
+	}
+}
@@ -0,0 +1,251 @@
+package taxonomy
+
+// The seed data follows the MusicBrainz genre list (https://musicbrainz.org/genres) and its
+// "subgenre of" relations. Multi-word genres whose parent is their last words, such as
+// "doom metal" -> "metal", do not need an entry in seedParents; that link is inferred.
+
+// seedGenres are canonical genre names, spelled as MusicBrainz spells them
+var seedGenres = []string{
+	// Rock
+	"rock", "alternative rock", "indie rock", "art rock", "garage rock", "glam rock", "gothic rock",
+	"hard rock", "soft rock", "yacht rock", "psychedelic rock", "progressive rock", "space rock",
+	"math rock", "noise rock", "post-rock", "krautrock", "stoner rock", "southern rock", "surf rock",
+	"blues rock", "folk rock", "country rock", "jazz rock", "pop rock", "punk rock", "heartland rock",
+	"symphonic rock", "christian rock", "rock and roll", "rockabilly", "grunge", "britpop",
+	"madchester", "shoegaze", "slowcore", "emo", "midwest emo", "deathrock",
+	// Punk and hardcore
+	"punk", "pop punk", "skate punk", "ska punk", "crust punk", "anarcho-punk", "folk punk",
+	"hardcore punk", "post-hardcore", "post-punk", "melodic hardcore", "d-beat", "riot grrrl",
+	// Metal
+	"metal", "heavy metal", "black metal", "atmospheric black metal", "death metal",
+	"melodic death metal", "technical death metal", "doom metal", "funeral doom metal",
+	"thrash metal", "speed metal", "power metal", "folk metal", "viking metal", "sludge metal",
+	"stoner metal", "progressive metal", "symphonic metal", "gothic metal", "industrial metal",
+	"alternative metal", "nu metal", "groove metal", "drone metal", "post-metal", "metalcore",
+	"deathcore", "grindcore", "djent", "extreme metal",
+	// Pop
+	"pop", "indie pop", "dream pop", "noise pop", "art pop", "power pop", "chamber pop",
+	"baroque pop", "synth-pop", "electropop", "dance-pop", "k-pop", "j-pop", "hyperpop",
+	"bedroom pop", "sophisti-pop", "singer-songwriter",
+	// Electronic
+	"electronic", "ambient", "dark ambient", "idm", "techno", "minimal techno", "detroit techno",
+	"house", "deep house", "tech house", "acid house", "progressive house", "witch house",
+	"trance", "psytrance", "drum and bass", "jungle", "dubstep", "uk garage", "breakbeat",
+	"big beat", "downtempo", "trip hop", "chillout", "synthwave", "vaporwave", "electro",
+	"electroclash", "industrial", "ebm", "glitch", "lo-fi", "new wave", "darkwave", "coldwave",
+	"dance", "disco", "nu-disco", "italo-disco",
+	// Hip hop
+	"hip hop", "boom bap", "trap", "grime", "gangsta rap", "conscious hip hop", "jazz rap",
+	"alternative hip hop", "abstract hip hop", "cloud rap", "drill",
+	// Rhythm & blues, soul, funk
+	"rhythm & blues", "contemporary r&b", "soul", "neo soul", "funk", "p-funk", "gospel",
+	// Jazz and blues
+	"jazz", "bebop", "hard bop", "cool jazz", "free jazz", "modal jazz", "jazz fusion",
+	"acid jazz", "nu jazz", "smooth jazz", "swing", "big band", "vocal jazz", "bossa nova",
+	"blues", "delta blues", "chicago blues", "electric blues",
+	// Folk and country
+	"folk", "indie folk", "freak folk", "neofolk", "contemporary folk", "americana",
+	"country", "alt-country", "outlaw country", "bluegrass",
+	// Reggae and Caribbean
+	"reggae", "roots reggae", "dub", "dancehall", "ska", "rocksteady",
+	// Latin
+	"latin", "salsa", "reggaeton", "cumbia", "samba", "tango",
+	// Classical
+	"classical", "baroque", "opera", "contemporary classical", "minimalism", "film score",
+	// Experimental
+	"experimental", "noise", "drone", "avant-garde", "musique concrète", "psychedelic",
+	// World
+	"afrobeat", "afrobeats", "world", "flamenco",
+}
+
+// seedParents lists parents that cannot be inferred from the genre name
+var seedParents = map[string][]string{
+	"indie rock":       {"alternative rock"},
+	"grunge":           {"alternative rock"},
+	"britpop":          {"alternative rock"},
+	"madchester":       {"alternative rock"},
+	"shoegaze":         {"alternative rock", "dream pop"},
+	"slowcore":         {"indie rock"},
+	"emo":              {"hardcore punk"},
+	"yacht rock":       {"soft rock"},
+	"blues rock":       {"blues", "rock"},
+	"folk rock":        {"folk", "rock"},
+	"country rock":     {"country", "rock"},
+	"jazz rock":        {"jazz", "rock"},
+	"pop rock":         {"pop", "rock"},
+	"punk rock":        {"punk"},
+	"rockabilly":       {"rock and roll", "country"},
+	"deathrock":        {"gothic rock", "punk"},
+	"post-rock":        {"rock"},
+	"krautrock":        {"rock"},
+	"anarcho-punk":     {"punk"},
+	"folk punk":        {"folk", "punk"},
+	"ska punk":         {"ska", "punk"},
+	"post-hardcore":    {"hardcore punk"},
+	"post-punk":        {"punk"},
+	"d-beat":           {"hardcore punk"},
+	"riot grrrl":       {"punk"},
+	"post-metal":       {"metal"},
+	"metalcore":        {"metal", "hardcore punk"},
+	"deathcore":        {"death metal", "metalcore"},
+	"grindcore":        {"extreme metal", "hardcore punk"},
+	"djent":            {"progressive metal"},
+	"synth-pop":        {"pop", "electronic"},
+	"electropop":       {"synth-pop"},
+	"dance-pop":        {"pop", "dance"},
+	"k-pop":            {"pop"},
+	"j-pop":            {"pop"},
+	"hyperpop":         {"pop", "electronic"},
+	"sophisti-pop":     {"pop"},
+	"ambient":          {"electronic"},
+	"idm":              {"electronic"},
+	"techno":           {"electronic"},
+	"house":            {"electronic"},
+	"witch house":      {"electronic"},
+	"trance":           {"electronic"},
+	"psytrance":        {"trance"},
+	"drum and bass":    {"electronic"},
+	"jungle":           {"electronic"},
+	"dubstep":          {"electronic"},
+	"uk garage":        {"electronic"},
+	"breakbeat":        {"electronic"},
+	"big beat":         {"breakbeat"},
+	"downtempo":        {"electronic"},
+	"trip hop":         {"downtempo", "hip hop"},
+	"chillout":         {"electronic"},
+	"synthwave":        {"electronic"},
+	"vaporwave":        {"electronic"},
+	"electro":          {"electronic"},
+	"electroclash":     {"electro"},
+	"industrial":       {"electronic"},
+	"ebm":              {"industrial"},
+	"glitch":           {"electronic"},
+	"new wave":         {"rock", "pop"},
+	"darkwave":         {"new wave"},
+	"coldwave":         {"new wave"},
+	"disco":            {"dance"},
+	"nu-disco":         {"disco"},
+	"italo-disco":      {"disco"},
+	"boom bap":         {"hip hop"},
+	"trap":             {"hip hop"},
+	"grime":            {"hip hop", "electronic"},
+	"gangsta rap":      {"hip hop"},
+	"jazz rap":         {"hip hop", "jazz"},
+	"cloud rap":        {"hip hop"},
+	"drill":            {"hip hop"},
+	"contemporary r&b": {"rhythm & blues"},
+	"soul":             {"rhythm & blues"},
+	"funk":             {"soul"},
+	"p-funk":           {"funk"},
+	"bebop":            {"jazz"},
+	"hard bop":         {"bebop"},
+	"swing":            {"jazz"},
+	"big band":         {"swing"},
+	"jazz fusion":      {"jazz", "rock"},
+	"bossa nova":       {"samba", "jazz"},
+	"americana":        {"country", "folk"},
+	"alt-country":      {"country", "alternative rock"},
+	"bluegrass":        {"country"},
+	"neofolk":          {"folk"},
+	"dub":              {"reggae"},
+	"dancehall":        {"reggae"},
+	"rocksteady":       {"ska"},
+	"salsa":            {"latin"},
+	"reggaeton":        {"latin", "dancehall"},
+	"cumbia":           {"latin"},
+	"samba":            {"latin"},
+	"tango":            {"latin"},
+	"baroque":          {"classical"},
+	"opera":            {"classical"},
+	"minimalism":       {"contemporary classical"},
+	"film score":       {"classical"},
+	"noise":            {"experimental"},
+	"drone":            {"experimental"},
+	"musique concrète": {"experimental"},
+	"afrobeat":         {"funk", "jazz"},
+	"flamenco":         {"world"},
+	"extreme metal":    {"metal"},
+}
+
+// seedSynonyms maps common tag spellings to canonical genre names
+var seedSynonyms = map[string]string{
+	"hip-hop":                     "hip hop",
+	"hiphop":                      "hip hop",
+	"rap":                         "hip hop",
+	"r&b":                         "contemporary r&b",
+	"rnb":                         "contemporary r&b",
+	"r'n'b":                       "contemporary r&b",
+	"r and b":                     "contemporary r&b",
+	"rhythm and blues":            "rhythm & blues",
+	"rock n roll":                 "rock and roll",
+	"rock'n'roll":                 "rock and roll",
+	"rock & roll":                 "rock and roll",
+	"punk-rock":                   "punk rock",
+	"post rock":                   "post-rock",
+	"post punk":                   "post-punk",
+	"post hardcore":               "post-hardcore",
+	"post metal":                  "post-metal",
+	"synthpop":                    "synth-pop",
+	"synth pop":                   "synth-pop",
+	"electro-pop":                 "electropop",
+	"dance pop":                   "dance-pop",
+	"kpop":                        "k-pop",
+	"jpop":                        "j-pop",
+	"electronica":                 "electronic",
+	"electronic music":            "electronic",
+	"edm":                         "electronic",
+	"dnb":                         "drum and bass",
+	"d&b":                         "drum and bass",
+	"drum & bass":                 "drum and bass",
+	"drum n bass":                 "drum and bass",
+	"drum'n'bass":                 "drum and bass",
+	"triphop":                     "trip hop",
+	"trip-hop":                    "trip hop",
+	"chill out":                   "chillout",
+	"chill-out":                   "chillout",
+	"lofi":                        "lo-fi",
+	"lo fi":                       "lo-fi",
+	"shoegazer":                   "shoegaze",
+	"shoegazing":                  "shoegaze",
+	"nu-metal":                    "nu metal",
+	"numetal":                     "nu metal",
+	"prog rock":                   "progressive rock",
+	"prog":                        "progressive rock",
+	"prog metal":                  "progressive metal",
+	"alternative":                 "alternative rock",
+	"alt rock":                    "alternative rock",
+	"alt-rock":                    "alternative rock",
+	"indie":                       "indie rock",
+	"alt country":                 "alt-country",
+	"alt. country":                "alt-country",
+	"singer songwriter":           "singer-songwriter",
+	"classic rock":                "rock",
+	"avantgarde":                  "avant-garde",
+	"avant garde":                 "avant-garde",
+	"modern classical":            "contemporary classical",
+	"soundtrack":                  "film score",
+	"neo-soul":                    "neo soul",
+	"krautrock/kosmische":         "krautrock",
+	"dark wave":                   "darkwave",
+	"cold wave":                   "coldwave",
+	"new-wave":                    "new wave",
+	"psychedelia":                 "psychedelic",
+	"world music":                 "world",
+	"bossanova":                   "bossa nova",
+	"ebm (electronic body music)": "ebm",
+	"electronic body music":       "ebm",
+}
+
+// seedBlocklist contains popular tags that describe the listener, not the music
+var seedBlocklist = []string{
+	"seen live", "favorites", "favourites", "favorite", "favourite", "my favorite",
+	"my favourite", "favorite artists", "favourite artists", "favorite bands", "albums i own",
+	"awesome", "amazing", "beautiful", "love", "loved", "cool", "good", "great", "best",
+	"check out", "to check out", "spotify", "under 2000 listeners", "all", "music", "other",
+	"female vocalists", "male vocalists", "female vocalist", "male vocalist", "female vocals",
+	"male vocals", "female fronted", "british", "american", "uk", "usa", "english", "swedish",
+	"german", "finnish", "canadian", "australian", "japanese", "french", "norwegian",
+	"icelandic", "irish", "scottish", "suomi", "guitar", "piano", "covers", "cover", "live",
+	"oldies", "classic", "legend", "legends", "genius", "sexy", "mellow", "melancholic",
+	"melancholy", "sad", "happy", "chill", "relax", "relaxing", "epic", "catchy",
+}
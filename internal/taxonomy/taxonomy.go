@@ -0,0 +1,235 @@
+// Package taxonomy normalizes free-form genre tags and matches them against a genre hierarchy.
+package taxonomy
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Taxonomy holds canonical genres, tag synonyms, blocked tags and parent links
+type Taxonomy struct {
+	known     map[string]bool
+	parents   map[string][]string
+	synonyms  map[string]string
+	blocklist map[string]bool
+}
+
+// blockedPatterns catch listener tags that vary too much for the blocklist
+var blockedPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(19|20)?\d0'?s$`), // Decades: 80s, 1980s, 80's
+	regexp.MustCompile(`^\d{4}$`),          // Years
+	regexp.MustCompile(`^(my )?fav`),       // favorites, fav artists, my favs
+	regexp.MustCompile(`^seen `),           // seen live 2019
+	regexp.MustCompile(`listeners?$`),
+}
+
+var defaultTaxonomy = NewTaxonomy(seedGenres, seedParents, seedSynonyms, seedBlocklist)
+
+// Default returns the taxonomy built from the bundled MusicBrainz seed data
+func Default() *Taxonomy {
+	return defaultTaxonomy
+}
+
+// NewTaxonomy builds a taxonomy. Parents and synonyms may reference genres missing from known.
+func NewTaxonomy(known []string, parents map[string][]string, synonyms map[string]string, blocklist []string) *Taxonomy {
+	t := &Taxonomy{
+		known:     make(map[string]bool, len(known)),
+		parents:   make(map[string][]string, len(parents)),
+		synonyms:  make(map[string]string, len(synonyms)),
+		blocklist: make(map[string]bool, len(blocklist)),
+	}
+
+	for _, genre := range known {
+		t.known[clean(genre)] = true
+	}
+	for genre, genreParents := range parents {
+		for _, parent := range genreParents {
+			t.parents[clean(genre)] = append(t.parents[clean(genre)], clean(parent))
+		}
+	}
+	for tag, genre := range synonyms {
+		t.synonyms[clean(tag)] = clean(genre)
+	}
+	for _, tag := range blocklist {
+		t.blocklist[clean(tag)] = true
+	}
+
+	return t
+}
+
+// Normalize returns the canonical name of a tag, or false when the tag is not a genre.
+// Unknown tags that are not blocked are kept in their cleaned-up form.
+func (t *Taxonomy) Normalize(tag string) (string, bool) {
+	tag = clean(tag)
+	if tag == "" || t.isBlocked(tag) {
+		return "", false
+	}
+
+	if genre, ok := t.synonyms[tag]; ok {
+		return genre, true
+	}
+
+	// Spelling variants of known genres: "post rock", "hip-hop", "drum & bass"
+	for _, variant := range []string{
+		tag,
+		strings.ReplaceAll(tag, "-", " "),
+		strings.ReplaceAll(tag, " ", "-"),
+		strings.ReplaceAll(tag, " & ", " and "),
+		strings.ReplaceAll(tag, " and ", " & "),
+	} {
+		if t.known[variant] {
+			return variant, true
+		}
+		if genre, ok := t.synonyms[variant]; ok {
+			return genre, true
+		}
+	}
+
+	return tag, true
+}
+
+// NormalizeAll normalizes tags, dropping non-genres and duplicates while keeping order
+func (t *Taxonomy) NormalizeAll(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+
+	for _, tag := range tags {
+		genre, ok := t.Normalize(tag)
+		if !ok || seen[genre] {
+			continue
+		}
+		seen[genre] = true
+		result = append(result, genre)
+	}
+
+	return result
+}
+
+// Parents returns the direct parents of a normalized genre.
+// Without an explicit entry, a known genre named by the trailing words is used: "doom metal" -> "metal".
+func (t *Taxonomy) Parents(genre string) []string {
+	if parents, ok := t.parents[genre]; ok {
+		return parents
+	}
+
+	words := strings.Fields(genre)
+	for i := 1; i < len(words); i++ {
+		suffix := strings.Join(words[i:], " ")
+		if t.known[suffix] {
+			return []string{suffix}
+		}
+	}
+
+	return nil
+}
+
+// Ancestors returns every genre above a normalized genre in the hierarchy
+func (t *Taxonomy) Ancestors(genre string) []string {
+	var ancestors []string
+	visited := map[string]bool{genre: true}
+	queue := t.Parents(genre)
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		ancestors = append(ancestors, current)
+		queue = append(queue, t.Parents(current)...)
+	}
+
+	return ancestors
+}
+
+// Descendants returns the known genres below a genre, sorted by name
+func (t *Taxonomy) Descendants(genre string) []string {
+	genre, ok := t.Normalize(genre)
+	if !ok {
+		return nil
+	}
+
+	var descendants []string
+	for candidate := range t.known {
+		if candidate != genre && t.isA(candidate, genre) {
+			descendants = append(descendants, candidate)
+		}
+	}
+
+	sort.Strings(descendants)
+	return descendants
+}
+
+// Matches reports whether a tag falls under the requested genre, either directly or as a subgenre.
+// Asking for "metal" matches "doom metal" and "metalcore", but asking for "doom metal" does not match "metal".
+func (t *Taxonomy) Matches(tag, requested string) bool {
+	genre, ok := t.Normalize(tag)
+	if !ok {
+		return false
+	}
+	requested, ok = t.Normalize(requested)
+	if !ok {
+		return false
+	}
+
+	return t.isA(genre, requested)
+}
+
+// MatchesAny reports whether any of the tags falls under the requested genre
+func (t *Taxonomy) MatchesAny(tags []string, requested string) bool {
+	for _, tag := range tags {
+		if t.Matches(tag, requested) {
+			return true
+		}
+	}
+	return false
+}
+
+// isA checks whether a normalized genre equals or descends from another normalized genre
+func (t *Taxonomy) isA(genre, ancestor string) bool {
+	if genre == ancestor {
+		return true
+	}
+	for _, candidate := range t.Ancestors(genre) {
+		if candidate == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlocked checks the blocklist and the blocked tag patterns
+func (t *Taxonomy) isBlocked(tag string) bool {
+	if t.blocklist[tag] {
+		return true
+	}
+	for _, pattern := range blockedPatterns {
+		if pattern.MatchString(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// clean lowercases a tag and collapses whitespace and underscores
+func clean(tag string) string {
+	tag = strings.ReplaceAll(strings.ToLower(tag), "_", " ")
+	return strings.Join(strings.Fields(tag), " ")
+}
+
+// Normalize normalizes a tag with the default taxonomy
+func Normalize(tag string) (string, bool) {
+	return defaultTaxonomy.Normalize(tag)
+}
+
+// NormalizeAll normalizes tags with the default taxonomy
+func NormalizeAll(tags []string) []string {
+	return defaultTaxonomy.NormalizeAll(tags)
+}
+
+// MatchesAny reports whether any tag falls under the requested genre in the default taxonomy
+func MatchesAny(tags []string, requested string) bool {
+	return defaultTaxonomy.MatchesAny(tags, requested)
+}
@@ -0,0 +1,152 @@
+package taxonomy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+		ok       bool
+	}{
+		{"Rock", "rock", true},
+		{"  Doom   Metal ", "doom metal", true},
+		{"Hip-Hop", "hip hop", true},
+		{"post rock", "post-rock", true},
+		{"Drum & Bass", "drum and bass", true},
+		{"rnb", "contemporary r&b", true},
+		{"Synthpop", "synth-pop", true},
+		{"swedish_doom", "swedish doom", true}, // Unknown tags are kept
+		{"seen live", "", false},
+		{"Favorites", "", false},
+		{"fav artists", "", false},
+		{"80s", "", false},
+		{"1990s", "", false},
+		{"80's", "", false},
+		{"2007", "", false},
+		{"female vocalists", "", false},
+		{"under 2000 listeners", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			genre, ok := Normalize(tt.tag)
+			if genre != tt.expected || ok != tt.ok {
+				t.Errorf("Normalize(%q) = (%q, %v), want (%q, %v)", tt.tag, genre, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	tags := []string{"Shoegaze", "seen live", "dream pop", "shoegazer", "Dream Pop", "90s"}
+
+	result := NormalizeAll(tags)
+	expected := []string{"shoegaze", "dream pop"}
+	if strings.Join(result, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestParentsAndAncestors(t *testing.T) {
+	taxonomy := Default()
+
+	if parents := taxonomy.Parents("atmospheric black metal"); len(parents) != 1 || parents[0] != "black metal" {
+		t.Errorf("Expected inferred parent black metal, got %v", parents)
+	}
+	if parents := taxonomy.Parents("witch house"); len(parents) != 1 || parents[0] != "electronic" {
+		t.Errorf("Expected explicit parent to override inference, got %v", parents)
+	}
+	if parents := taxonomy.Parents("metal"); len(parents) != 0 {
+		t.Errorf("Expected metal to be a root genre, got %v", parents)
+	}
+
+	ancestors := strings.Join(taxonomy.Ancestors("shoegaze"), ",")
+	for _, expected := range []string{"alternative rock", "dream pop", "rock", "pop"} {
+		if !strings.Contains(ancestors, expected) {
+			t.Errorf("Expected %s among shoegaze ancestors, got %s", expected, ancestors)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		tag       string
+		requested string
+		expected  bool
+	}{
+		{"doom metal", "metal", true},
+		{"Funeral Doom Metal", "Metal", true},
+		{"metalcore", "metal", true},
+		{"deathcore", "metal", true},
+		{"swedish doom metal", "doom metal", true}, // Unknown tag, inferred parent
+		{"metal", "doom metal", false},
+		{"indie rock", "rock", true},
+		{"shoegaze", "rock", true},
+		{"hip-hop", "hip hop", true},
+		{"trip hop", "hip hop", true},
+		{"jazz", "metal", false},
+		{"seen live", "rock", false},
+		{"rock", "seen live", false},
+	}
+
+	taxonomy := Default()
+	for _, tt := range tests {
+		t.Run(tt.tag+" in "+tt.requested, func(t *testing.T) {
+			if result := taxonomy.Matches(tt.tag, tt.requested); result != tt.expected {
+				t.Errorf("Matches(%q, %q) = %v, want %v", tt.tag, tt.requested, result, tt.expected)
+			}
+		})
+	}
+
+	if !MatchesAny([]string{"jazz", "sludge metal"}, "metal") {
+		t.Error("Expected MatchesAny to find sludge metal under metal")
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	descendants := Default().Descendants("Metal")
+
+	found := make(map[string]bool, len(descendants))
+	for _, genre := range descendants {
+		found[genre] = true
+	}
+
+	for _, expected := range []string{"doom metal", "funeral doom metal", "metalcore", "djent", "grindcore"} {
+		if !found[expected] {
+			t.Errorf("Expected %s among metal descendants", expected)
+		}
+	}
+	if found["metal"] || found["rock"] {
+		t.Errorf("Unexpected genres among metal descendants: %v", descendants)
+	}
+}
+
+func TestSeedDataConsistency(t *testing.T) {
+	taxonomy := Default()
+
+	for genre, parents := range seedParents {
+		if !taxonomy.known[genre] {
+			t.Errorf("Parent entry for unknown genre %q", genre)
+		}
+		for _, parent := range parents {
+			if !taxonomy.known[parent] {
+				t.Errorf("Genre %q has unknown parent %q", genre, parent)
+			}
+		}
+		for _, ancestor := range taxonomy.Ancestors(genre) {
+			if ancestor == genre {
+				t.Errorf("Genre %q is its own ancestor", genre)
+			}
+		}
+	}
+
+	for tag, genre := range seedSynonyms {
+		if !taxonomy.known[genre] {
+			t.Errorf("Synonym %q points to unknown genre %q", tag, genre)
+		}
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"database/sql"
 	"testing"
 
+	"gocommender/internal/dialect"
+	"gocommender/internal/migrations"
+
 	_ "modernc.org/sqlite"
 )
 
@@ -20,25 +23,7 @@ func CreateTestDB(t *testing.T) (*sql.DB, func()) {
 	db.SetMaxIdleConns(1)
 
 	// Create schema
-	schema := `
-		CREATE TABLE artists (
-			mbid TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			verified_json TEXT DEFAULT '{}',
-			album_count INTEGER DEFAULT 0,
-			years_active TEXT DEFAULT '',
-			description TEXT DEFAULT '',
-			genres_json TEXT DEFAULT '[]',
-			country TEXT DEFAULT '',
-			image_url TEXT DEFAULT '',
-			external_urls_json TEXT DEFAULT '{}',
-			last_updated DATETIME DEFAULT CURRENT_TIMESTAMP,
-			cache_expiry DATETIME NOT NULL
-		);
-	`
-
-	_, err = db.Exec(schema)
-	if err != nil {
+	if _, err := migrations.Up(db, dialect.SQLite); err != nil {
 		t.Fatalf("Failed to create schema: %v", err)
 	}
 
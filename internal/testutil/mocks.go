@@ -149,14 +149,14 @@ func NewMockPlexClient() *MockPlexClient {
 	}
 }
 
-func (m *MockPlexClient) GetPlaylists() ([]models.PlexPlaylist, error) {
+func (m *MockPlexClient) GetPlaylists(ctx context.Context) ([]models.PlexPlaylist, error) {
 	if m.shouldErr {
 		return nil, errors.New("mock plex error")
 	}
 	return m.playlists, nil
 }
 
-func (m *MockPlexClient) GetPlaylistTracks(name string) ([]models.PlexTrack, error) {
+func (m *MockPlexClient) GetPlaylistTracks(ctx context.Context, name string) ([]models.PlexTrack, error) {
 	if m.shouldErr {
 		return nil, errors.New("mock plex error")
 	}
@@ -167,8 +167,8 @@ func (m *MockPlexClient) GetPlaylistTracks(name string) ([]models.PlexTrack, err
 	return []models.PlexTrack{}, nil
 }
 
-func (m *MockPlexClient) GetHighRatedTracks(name string, minRating int) ([]models.PlexTrack, error) {
-	tracks, err := m.GetPlaylistTracks(name)
+func (m *MockPlexClient) GetHighRatedTracks(ctx context.Context, name string, minRating int) ([]models.PlexTrack, error) {
+	tracks, err := m.GetPlaylistTracks(ctx, name)
 	if err != nil {
 		return nil, err
 	}
@@ -182,21 +182,21 @@ func (m *MockPlexClient) GetHighRatedTracks(name string, minRating int) ([]model
 	return filtered, nil
 }
 
-func (m *MockPlexClient) GetAllArtists() ([]string, error) {
+func (m *MockPlexClient) GetAllArtists(ctx context.Context) ([]string, error) {
 	if m.shouldErr {
 		return nil, errors.New("mock plex error")
 	}
 	return m.artists, nil
 }
 
-func (m *MockPlexClient) TestConnection() error {
+func (m *MockPlexClient) TestConnection(ctx context.Context) error {
 	if m.shouldErr {
 		return errors.New("mock connection error")
 	}
 	return nil
 }
 
-func (m *MockPlexClient) GetServerInfo() (map[string]string, error) {
+func (m *MockPlexClient) GetServerInfo(ctx context.Context) (map[string]string, error) {
 	if m.shouldErr {
 		return nil, errors.New("mock server info error")
 	}
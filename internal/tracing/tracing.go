@@ -0,0 +1,259 @@
+// Package tracing records spans of work in the OpenTelemetry data model and hands
+// finished spans to an exporter, see SetExporter.
+//
+// Spans are started from a context and carry their trace through it:
+//
+//	ctx, span := tracing.Start(ctx, "plex.GetAllArtists")
+//	defer span.End()
+//
+// Trace context crosses process boundaries as a W3C traceparent header, see Extract and Inject.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID identifies all spans of one trace
+type TraceID [16]byte
+
+// String returns the ID in lowercase hex
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid reports whether the ID is not all zeroes
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// SpanID identifies a span within its trace
+type SpanID [8]byte
+
+// String returns the ID in lowercase hex
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// IsValid reports whether the ID is not all zeroes
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// Attribute is a key-value pair describing a span
+type Attribute struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"` // string, bool, int64 or float64
+}
+
+// String creates a string attribute
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+// Int creates an integer attribute
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: int64(value)} }
+
+// Bool creates a boolean attribute
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Float creates a floating point attribute
+func Float(key string, value float64) Attribute { return Attribute{Key: key, Value: value} }
+
+// SpanData is a finished span as handed to exporters
+type SpanData struct {
+	TraceID      TraceID     `json:"-"`
+	SpanID       SpanID      `json:"-"`
+	ParentSpanID SpanID      `json:"-"`
+	Name         string      `json:"name"`
+	Kind         string      `json:"kind"` // internal, server or client
+	Start        time.Time   `json:"start"`
+	End          time.Time   `json:"end"`
+	Attributes   []Attribute `json:"attributes,omitempty"`
+	Error        string      `json:"error,omitempty"`
+}
+
+// Span kinds, following OpenTelemetry
+const (
+	KindInternal = "internal"
+	KindServer   = "server"
+	KindClient   = "client"
+)
+
+// Span is an operation in progress. All methods are safe on a nil span.
+type Span struct {
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// spanKey stores the current span in a context
+type spanKey struct{}
+
+// remoteKey stores a parent propagated from another process
+type remoteKey struct{}
+
+// remoteParent is the trace context of a span in another process
+type remoteParent struct {
+	traceID TraceID
+	spanID  SpanID
+}
+
+// Start starts a span as a child of the span in ctx, or of a remote parent from
+// Extract, or as the root of a new trace. The span must be ended with End.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return StartKind(ctx, name, KindInternal, attrs...)
+}
+
+// StartKind starts a span of the given kind, see Start
+func StartKind(ctx context.Context, name, kind string, attrs ...Attribute) (context.Context, *Span) {
+	span := &Span{data: SpanData{
+		SpanID:     newSpanID(),
+		Name:       name,
+		Kind:       kind,
+		Start:      time.Now(),
+		Attributes: attrs,
+	}}
+
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.data.TraceID = parent.data.TraceID
+		span.data.ParentSpanID = parent.data.SpanID
+	} else if remote, ok := ctx.Value(remoteKey{}).(remoteParent); ok {
+		span.data.TraceID = remote.traceID
+		span.data.ParentSpanID = remote.spanID
+	} else {
+		span.data.TraceID = newTraceID()
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFromContext returns the current span, or nil if there is none
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// TraceIDFromContext returns the hex trace ID of the current span, or "" if there is none
+func TraceIDFromContext(ctx context.Context) string {
+	return SpanFromContext(ctx).TraceID()
+}
+
+// TraceID returns the hex ID of the span's trace
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.data.TraceID.String()
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes = append(s.data.Attributes, attrs...)
+}
+
+// RecordError marks the span as failed; nil errors are ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End finishes the span and exports it. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Attributes = append([]Attribute(nil), s.data.Attributes...)
+	s.mu.Unlock()
+
+	if exporter := currentExporter(); exporter != nil {
+		exporter.Export(data)
+	}
+}
+
+// Extract returns a context carrying the remote parent from a traceparent header, if valid
+func Extract(ctx context.Context, header http.Header) context.Context {
+	// version-traceid-parentid-flags
+	parts := strings.Split(strings.TrimSpace(header.Get("traceparent")), "-")
+	if len(parts) != 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return ctx
+	}
+
+	var remote remoteParent
+	if !decodeHex(remote.traceID[:], parts[1]) || !decodeHex(remote.spanID[:], parts[2]) ||
+		!remote.traceID.IsValid() || !remote.spanID.IsValid() {
+		return ctx
+	}
+
+	return context.WithValue(ctx, remoteKey{}, remote)
+}
+
+// Inject sets the traceparent header for the current span, if any
+func Inject(ctx context.Context, header http.Header) {
+	span := SpanFromContext(ctx)
+	if span == nil {
+		return
+	}
+	header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", span.data.TraceID, span.data.SpanID))
+}
+
+// decodeHex decodes s into dst, which it must fill exactly
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	rand.Read(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	rand.Read(id[:])
+	return id
+}
+
+// Exporter receives finished spans. Export must not block for long; exporters
+// that send spans over the network should queue them.
+type Exporter interface {
+	Export(span SpanData)
+	Shutdown(ctx context.Context) error
+}
+
+var (
+	exporterMu sync.RWMutex
+	exporter   Exporter
+)
+
+// SetExporter sets where finished spans are sent; nil discards them.
+// Spans are recorded either way so trace IDs can be reported.
+func SetExporter(e Exporter) {
+	exporterMu.Lock()
+	defer exporterMu.Unlock()
+	exporter = e
+}
+
+func currentExporter() Exporter {
+	exporterMu.RLock()
+	defer exporterMu.RUnlock()
+	return exporter
+}
@@ -0,0 +1,231 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingExporter keeps exported spans for inspection
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+func (e *recordingExporter) Export(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func (e *recordingExporter) Shutdown(ctx context.Context) error { return nil }
+
+// record installs a recording exporter for the duration of a test
+func record(t *testing.T) *recordingExporter {
+	t.Helper()
+	recorder := &recordingExporter{}
+	SetExporter(recorder)
+	t.Cleanup(func() { SetExporter(nil) })
+	return recorder
+}
+
+func TestStartNestsSpans(t *testing.T) {
+	recorder := record(t)
+
+	ctx, root := Start(context.Background(), "root", String("playlist", "Favorites"))
+	_, child := Start(ctx, "child")
+	child.RecordError(errors.New("upstream failed"))
+	child.End()
+	root.End()
+	root.End() // Ending twice exports once
+
+	if len(recorder.spans) != 2 {
+		t.Fatalf("Expected 2 exported spans, got %d", len(recorder.spans))
+	}
+
+	childData, rootData := recorder.spans[0], recorder.spans[1]
+	if childData.TraceID != rootData.TraceID {
+		t.Error("Expected the child to share the root's trace")
+	}
+	if childData.ParentSpanID != rootData.SpanID {
+		t.Error("Expected the child's parent to be the root")
+	}
+	if rootData.ParentSpanID.IsValid() {
+		t.Error("Expected the root to have no parent")
+	}
+	if childData.Error != "upstream failed" {
+		t.Errorf("Expected the child's error to be recorded, got %q", childData.Error)
+	}
+	if TraceIDFromContext(ctx) != rootData.TraceID.String() || len(root.TraceID()) != 32 {
+		t.Errorf("Unexpected trace ID %q", TraceIDFromContext(ctx))
+	}
+	if TraceIDFromContext(context.Background()) != "" {
+		t.Error("Expected no trace ID without a span")
+	}
+}
+
+func TestExtractInject(t *testing.T) {
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx, span := Start(Extract(context.Background(), header), "server")
+	if span.TraceID() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the remote trace to continue, got %s", span.TraceID())
+	}
+	if span.data.ParentSpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected the remote span as parent, got %s", span.data.ParentSpanID)
+	}
+
+	out := http.Header{}
+	Inject(ctx, out)
+	expected := "00-4bf92f3577b34da6a3ce929d0e0e4736-" + span.data.SpanID.String() + "-01"
+	if out.Get("traceparent") != expected {
+		t.Errorf("Expected traceparent %s, got %s", expected, out.Get("traceparent"))
+	}
+
+	for _, invalid := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		header.Set("traceparent", invalid)
+		_, span := Start(Extract(context.Background(), header), "server")
+		if span.data.ParentSpanID.IsValid() {
+			t.Errorf("Expected %q to be ignored", invalid)
+		}
+	}
+}
+
+func TestJSONExporter(t *testing.T) {
+	var out bytes.Buffer
+	SetExporter(NewJSONExporter(&out))
+	t.Cleanup(func() { SetExporter(nil) })
+
+	ctx, root := Start(context.Background(), "root")
+	_, child := Start(ctx, "child", Int("count", 3), Bool("cached", true))
+	child.End()
+	root.End()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %s", len(lines), out.String())
+	}
+
+	var span map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &span); err != nil {
+		t.Fatalf("Invalid JSON line: %v", err)
+	}
+	if span["name"] != "child" || span["trace_id"] != root.TraceID() || span["parent_span_id"] != root.data.SpanID.String() {
+		t.Errorf("Unexpected span line: %s", lines[0])
+	}
+	attributes, _ := span["attributes"].(map[string]interface{})
+	if attributes["count"] != float64(3) || attributes["cached"] != true {
+		t.Errorf("Unexpected attributes: %v", span["attributes"])
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan otlpRequest, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected request %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var request otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("Invalid OTLP request: %v", err)
+		}
+		requests <- request
+	}))
+	defer collector.Close()
+
+	exporter := NewOTLPExporter(collector.URL+"/", "gocommender-test")
+	SetExporter(exporter)
+	t.Cleanup(func() { SetExporter(nil) })
+
+	ctx, root := StartKind(context.Background(), "POST /api/recommend", KindServer)
+	_, child := Start(ctx, "plex.GetAllArtists", Int("artists", 42))
+	child.RecordError(errors.New("timeout"))
+	child.End()
+	root.End()
+
+	// Shutdown sends what is queued
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	exporter.Export(SpanData{Name: "after shutdown"}) // Dropped, must not panic
+
+	request := <-requests
+	resource := request.ResourceSpans[0]
+	if name := resource.Resource.Attributes[0]; name.Key != "service.name" || *name.Value.StringValue != "gocommender-test" {
+		t.Errorf("Unexpected resource attribute %+v", name)
+	}
+
+	spans := resource.ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].ParentSpanID != spans[1].SpanID || spans[0].TraceID != root.TraceID() {
+		t.Error("Expected the child to reference its parent")
+	}
+	if spans[0].Status.Code != 2 || spans[0].Status.Message != "timeout" {
+		t.Errorf("Expected an error status, got %+v", spans[0].Status)
+	}
+	if spans[1].Kind != 2 || spans[1].Status.Code != 0 {
+		t.Errorf("Expected an unset server span, got kind %d status %+v", spans[1].Kind, spans[1].Status)
+	}
+	if attr := spans[0].Attributes[0]; attr.Key != "artists" || *attr.Value.IntValue != "42" {
+		t.Errorf("Unexpected attribute %+v", attr)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	recorder := record(t)
+
+	var received string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: Transport("test", nil)}
+
+	// Requests outside a trace pass through untouched
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if received != "" || len(recorder.spans) != 0 {
+		t.Error("Expected no span or traceparent outside a trace")
+	}
+
+	ctx, root := Start(context.Background(), "root")
+	req, _ := http.NewRequestWithContext(ctx, "GET", upstream.URL, nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	root.End()
+
+	if len(recorder.spans) != 2 {
+		t.Fatalf("Expected a client span and the root, got %d spans", len(recorder.spans))
+	}
+	clientSpan := recorder.spans[0]
+	if clientSpan.Kind != KindClient || clientSpan.Name != "test GET" || clientSpan.Error == "" {
+		t.Errorf("Unexpected client span %+v", clientSpan)
+	}
+	if !strings.Contains(received, clientSpan.SpanID.String()) || !strings.Contains(received, root.TraceID()) {
+		t.Errorf("Expected traceparent of the client span, got %q", received)
+	}
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// tracingTransport records a client span for each request made within a trace
+type tracingTransport struct {
+	upstream string
+	next     http.RoundTripper
+}
+
+// Transport wraps a transport, http.DefaultTransport if nil, so requests whose context
+// carries a span get a child span and a traceparent header. Other requests pass through.
+func Transport(upstream string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &tracingTransport{upstream: upstream, next: next}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if SpanFromContext(req.Context()) == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, span := StartKind(req.Context(), t.upstream+" "+req.Method, KindClient,
+		String("http.method", req.Method),
+		String("server.address", req.URL.Host),
+		String("url.path", req.URL.Path),
+	)
+	defer span.End()
+
+	req = req.Clone(ctx)
+	Inject(ctx, req.Header)
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	span.SetAttributes(Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= 500 {
+		span.RecordError(fmt.Errorf("status %d", resp.StatusCode))
+	}
+	return resp, nil
+}
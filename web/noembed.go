@@ -0,0 +1,7 @@
+//go:build !embedui
+
+package web
+
+import "io/fs"
+
+var dist fs.FS
@@ -0,0 +1,43 @@
+// Writes .br and .gz copies of the built files next to them, so the Go server
+// can send them precompressed. Uses only Node's zlib.
+import { readdir, readFile, writeFile } from 'node:fs/promises'
+import { join, extname } from 'node:path'
+import { brotliCompressSync, gzipSync, constants } from 'node:zlib'
+
+const dist = new URL('../dist/', import.meta.url).pathname
+const compressible = new Set(['.html', '.js', '.css', '.svg', '.json', '.map', '.txt', '.xml', '.webmanifest'])
+const minSize = 1024
+
+async function* files(dir) {
+  for (const entry of await readdir(dir, { withFileTypes: true })) {
+    const path = join(dir, entry.name)
+    if (entry.isDirectory()) {
+      yield* files(path)
+    } else {
+      yield path
+    }
+  }
+}
+
+let count = 0
+for await (const path of files(dist)) {
+  if (!compressible.has(extname(path))) continue
+
+  const data = await readFile(path)
+  if (data.length < minSize) continue
+
+  const brotli = brotliCompressSync(data, {
+    params: {
+      [constants.BROTLI_PARAM_QUALITY]: constants.BROTLI_MAX_QUALITY,
+      [constants.BROTLI_PARAM_SIZE_HINT]: data.length
+    }
+  })
+  const gzip = gzipSync(data, { level: 9 })
+
+  // A variant that does not save anything is not worth serving
+  if (brotli.length < data.length) await writeFile(path + '.br', brotli)
+  if (gzip.length < data.length) await writeFile(path + '.gz', gzip)
+  count++
+}
+
+console.log(`Precompressed ${count} files in dist/`)
@@ -1,18 +1,36 @@
 // HTTP Client for GoCommender API
 import type {
+  ArtistListResponse,
+  ArtistSearchParams,
   ArtistResponse,
+  CacheClearResponse,
+  CacheInvalidation,
+  CacheStats,
+  Discography,
   HealthResponse,
   PlaylistsResponse,
+  PlexTestResponse,
   RecommendRequest,
   RecommendResponse,
+  SimilarArtistsResponse,
+  UsageResponse,
   ApiError as ApiErrorType
 } from '../types/api.js';
+import { endpoints } from '../types/schema.gen.js';
+import type { Endpoints, RequestOf, ResponseOf } from '../types/schema.gen.js';
 
 export class ApiClient {
   private baseUrl: string;
+  private apiKey?: string;
 
-  constructor(baseUrl: string = '/api') {
+  constructor(baseUrl: string = '/api', apiKey?: string) {
     this.baseUrl = baseUrl;
+    this.apiKey = apiKey;
+  }
+
+  // Set the API key sent with every request, needed when the server has AUTH_ENABLED
+  setApiKey(apiKey?: string): void {
+    this.apiKey = apiKey;
   }
 
   // Generic fetch wrapper with error handling
@@ -23,23 +41,26 @@ export class ApiClient {
     const url = `${this.baseUrl}${endpoint}`;
     
     const config: RequestInit = {
+      ...options,
       headers: {
         'Content-Type': 'application/json',
+        ...(this.apiKey ? { Authorization: `Bearer ${this.apiKey}` } : {}),
         ...options.headers,
       },
-      ...options,
     };
 
     try {
       const response = await fetch(url, config);
       
       if (!response.ok) {
-        const errorData: ApiErrorType = await response.json().catch(() => ({
-          error: `HTTP ${response.status}: ${response.statusText}`,
-          status: 'error',
-          timestamp: new Date().toISOString(),
-        }));
-        throw new ApiError(errorData.error, response.status);
+        const errorData: ApiErrorType | undefined = await response.json().catch(() => undefined);
+        throw new ApiError(
+          errorData?.message ?? `HTTP ${response.status}: ${response.statusText}`,
+          response.status,
+          errorData?.code,
+          errorData?.details,
+          errorData?.request_id || response.headers.get('X-Request-ID') || undefined
+        );
       }
 
       return await response.json();
@@ -56,22 +77,47 @@ export class ApiClient {
     }
   }
 
+  // Calls an operation of the OpenAPI document; paths, parameters and
+  // responses are checked against the generated Endpoints table
+  async call<K extends keyof Endpoints>(operation: K, request: RequestOf<K>): Promise<ResponseOf<K>> {
+    const { method, path } = endpoints[operation];
+    const { params, query, body } = request as {
+      params?: Record<string, string>;
+      query?: Record<string, string | number | boolean | undefined>;
+      body?: unknown;
+    };
+
+    const endpoint = path
+      .replace(/^\/api/, '')
+      .replace(/\{(\w+)\}/g, (_, name: string) => encodeURIComponent(params?.[name] ?? ''));
+
+    const search = new URLSearchParams();
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value !== undefined && value !== '') {
+        search.set(key, String(value));
+      }
+    }
+    const queryString = search.toString();
+
+    return this.fetchApi<ResponseOf<K>>(queryString ? `${endpoint}?${queryString}` : endpoint, {
+      method,
+      ...(body !== undefined ? { body: JSON.stringify(body) } : {}),
+    });
+  }
+
   // Health check endpoint
   async getHealth(): Promise<HealthResponse> {
-    return this.fetchApi<HealthResponse>('/health');
+    return this.call('getHealth', {});
   }
 
   // Get Plex playlists
   async getPlaylists(): Promise<PlaylistsResponse> {
-    return this.fetchApi<PlaylistsResponse>('/plex/playlists');
+    return this.call('getPlaylists', {});
   }
 
   // Generate recommendations
   async getRecommendations(request: RecommendRequest): Promise<RecommendResponse> {
-    return this.fetchApi<RecommendResponse>('/recommend', {
-      method: 'POST',
-      body: JSON.stringify(request),
-    });
+    return this.call('recommend', { body: request });
   }
 
   // Get artist details by MBID
@@ -80,23 +126,67 @@ export class ApiClient {
       throw new ApiError('Invalid artist MBID format', 400);
     }
     
-    return this.fetchApi<ArtistResponse>(`/artists/${mbid}`);
+    return this.call('getArtist', { params: { mbid } });
+  }
+
+  // Get artist discography by MBID
+  async getArtistReleases(mbid: string): Promise<Discography> {
+    if (!mbid || !this.isValidMBID(mbid)) {
+      throw new ApiError('Invalid artist MBID format', 400);
+    }
+
+    return this.call('getArtistReleases', { params: { mbid } });
+  }
+
+  // Get similar artists from the similarity graph
+  async getSimilarArtists(mbid: string, minMatch: number = 0): Promise<SimilarArtistsResponse> {
+    if (!mbid || !this.isValidMBID(mbid)) {
+      throw new ApiError('Invalid artist MBID format', 400);
+    }
+
+    return this.call('getSimilarArtists', { params: { mbid }, query: { min_match: minMatch } });
+  }
+
+  // List cached artists by Last.fm listener count
+  async listArtists(minListeners: number = 0, maxListeners: number = 0, limit: number = 50, offset: number = 0): Promise<ArtistListResponse> {
+    return this.call('listArtists', {
+      query: { min_listeners: minListeners, max_listeners: maxListeners, limit, offset },
+    });
+  }
+
+  // Search cached artists; pass next_page back as page to continue
+  async searchArtists(search: ArtistSearchParams = {}): Promise<ArtistListResponse> {
+    return this.call('listArtists', { query: search });
   }
 
   // Test Plex connection
-  async testPlex(): Promise<{ status: string; server?: any }> {
-    return this.fetchApi<{ status: string; server?: any }>('/plex/test');
+  async testPlex(): Promise<PlexTestResponse> {
+    return this.call('testPlex', {});
+  }
+
+  // LLM token and cost usage against the daily and monthly quotas
+  async getUsage(): Promise<UsageResponse> {
+    return this.call('getUsage', {});
   }
 
   // Get cache statistics
-  async getCacheStats(): Promise<any> {
-    return this.fetchApi<any>('/cache/stats');
+  async getCacheStats(): Promise<CacheStats> {
+    return this.call('getCacheStats', {});
   }
 
   // Clear cache
-  async clearCache(type: 'expired' | 'all' = 'expired'): Promise<any> {
-    return this.fetchApi<any>(`/cache/clear?type=${type}`, {
-      method: 'POST',
+  async clearCache(type: 'expired' | 'all' = 'expired'): Promise<CacheClearResponse> {
+    return this.call('clearCache', { query: { type } });
+  }
+
+  // Expire matching artists; with refresh they are also queued for an immediate background refresh
+  async invalidateCache(filter: CacheInvalidation, refresh: boolean = false): Promise<CacheClearResponse> {
+    return this.call('clearCache', {
+      query: {
+        ...filter,
+        mbid: filter.mbid?.join(','),
+        type: refresh ? 'refresh' : 'invalidate',
+      },
     });
   }
 
@@ -108,11 +198,14 @@ export class ApiClient {
   }
 }
 
-// Custom error class for API errors
+// Custom error class for API errors; code is set for errors returned by the server
 export class ApiError extends Error {
   constructor(
     message: string,
-    public statusCode: number
+    public statusCode: number,
+    public code?: ApiErrorType['code'],
+    public details?: ApiErrorType['details'],
+    public requestId?: string
   ) {
     super(message);
     this.name = 'ApiError';
@@ -1,114 +1,65 @@
 // API Types for GoCommender Frontend
-// These types match the Go models in internal/models/
+// Models and responses are generated from the server's OpenAPI document into
+// schema.gen.ts (go generate ./internal/api); this file adds UI-only types.
 
-export interface Artist {
-  mbid: string;
-  name: string;
-  album_count: number;
-  years_active: string;
-  description: string;
-  genres: string[];
-  country: string;
-  image_url: string;
-  verified: Record<string, boolean>;
-  external_urls: ExternalURLs;
-  last_updated: string;
-}
-
-export interface ExternalURLs {
-  discogs?: string;
-  musicbrainz?: string;
-  lastfm?: string;
-  spotify?: string;
-}
-
-export interface PlexPlaylist {
-  name: string;
-  type: string;
-  smart: boolean;
-  track_count: number;
-  duration: number; // milliseconds
-  tracks: PlexTrack[];
-}
-
-export interface PlexTrack {
-  title: string;
-  artist: string;
-  album: string;
-  year: number;
-  rating: number; // 1-10 scale
-  play_count: number;
-  last_played: string;
-}
-
-export interface RecommendRequest {
-  playlist_name: string;
-  genre?: string;
-  max_results: number;
-}
-
-export interface RecommendResponse {
-  status: string;
-  request_id: string;
-  suggestions: Artist[];
-  metadata: RecommendMetadata;
-  error?: string;
-}
+import type {
+  Artist,
+  ArtistListResponse,
+  ErrorResponse,
+  HealthResponse,
+  PlexPlaylist,
+  QuotaPeriod,
+  RecommendRequest,
+  RequestOf,
+} from './schema.gen.js';
 
-export interface RecommendMetadata {
-  seed_track_count: number;
-  known_artist_count: number;
-  processing_time: string;
-  cache_hits: number;
-  api_calls_made: number;
-  generated_at: string;
-}
+export type {
+  Artist,
+  ArtistLink,
+  ArtistListResponse,
+  ArtistResponse,
+  BackupResult,
+  BuildInfo,
+  CacheClearResponse,
+  CacheStats,
+  Discography,
+  ExternalURLs,
+  HealthResponse,
+  ImportResponse,
+  InfoResponse,
+  LabelAffiliation,
+  PlaylistsResponse,
+  PlexPlaylist,
+  PlexTestResponse,
+  PlexTrack,
+  RecommendMetadata,
+  RecommendRequest,
+  RecommendResponse,
+  ReleaseGroup,
+  SimilarArtist,
+  SimilarArtistsResponse,
+  UsageResponse,
+} from './schema.gen.js';
 
-// Health endpoint response
-export interface HealthResponse {
-  status: string;
-  service: string;
-  timestamp: string;
-  version: string;
-  database: {
-    status: string;
-    total_entries?: number;
-    valid_entries?: number;
-    expired_entries?: number;
-    error?: string;
-  };
-  plex: {
-    status: string;
-    error?: string;
-  };
-}
+export type ArtistSort = ArtistListResponse['sort'];
 
-// Build info response
-export interface BuildInfo {
-  version: string;
-  commit: string;
-  build_date: string;
-  go_version: string;
-  platform: string;
-}
+export type RecommendationEngine = NonNullable<RecommendRequest['engine']>;
 
-// Playlists API response
-export interface PlaylistsResponse {
-  playlists: PlexPlaylist[];
-  count: number;
-}
+export type ArtistSearchParams = NonNullable<RequestOf<'listArtists'>['query']>;
 
-// Artist API response
-export interface ArtistResponse {
-  artist: Artist;
-  needs_fetch: boolean;
-}
+// LLM consumption of one quota period; limits are omitted when not set
+export type LLMUsagePeriod = QuotaPeriod;
 
 // Generic API error response
-export interface ApiError {
-  error: string;
-  status: string;
-  timestamp: string;
+export type ApiError = ErrorResponse;
+
+// Selects cached artists to invalidate; set fields are combined
+export interface CacheInvalidation {
+  mbid?: string[];
+  source?: string; // e.g. 'discogs' to re-fetch only Discogs data
+  genre?: string; // Includes subgenres
+  updated_after?: string; // RFC 3339 timestamp or YYYY-MM-DD
+  updated_before?: string;
 }
 
 // Loading states for UI
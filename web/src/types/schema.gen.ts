@@ -0,0 +1,402 @@
+// Code generated from the OpenAPI document by cmd/openapi; DO NOT EDIT.
+// Regenerate with: go generate ./internal/api
+
+export interface Artist {
+  album_count: number;
+  country: string;
+  description: string;
+  external_urls: ExternalURLs;
+  genres: string[];
+  groups?: ArtistLink[];
+  image_url: string;
+  labels?: LabelAffiliation[];
+  last_updated: string;
+  listeners: number;
+  mbid: string;
+  members?: ArtistLink[];
+  name: string;
+  playcount: number;
+  verified: Record<string, boolean>;
+  years_active: string;
+}
+
+export interface ArtistLink {
+  active: boolean;
+  discogs_id?: number;
+  name: string;
+}
+
+export interface ArtistListResponse {
+  artists: Artist[];
+  count: number;
+  limit: number;
+  next_page: string; // Cursor for the following page, empty on the last page
+  offset: number;
+  sort: 'relevance' | 'name' | 'listeners';
+}
+
+export interface ArtistResponse {
+  artist: Artist;
+  needs_fetch: boolean; // The artist is not cached or has expired, and is null until it is fetched
+}
+
+export interface BackupResult {
+  created_at: string;
+  duration_ms: number;
+  path: string;
+  removed?: string[];
+  size: number;
+}
+
+export interface BuildInfo {
+  build_date: string;
+  commit: string;
+  go_version: string;
+  platform: string;
+  version: string;
+}
+
+export interface CacheClearResponse {
+  cleared?: ClearResult;
+  invalidated?: InvalidationResult;
+  message: string;
+  status: 'success';
+  type: 'expired' | 'all' | 'invalidate' | 'refresh';
+}
+
+export interface CacheStats {
+  expired: number;
+  total: number;
+  valid: number;
+}
+
+export interface ClearResult {
+  aliases: number;
+  artists: number;
+  releases: number;
+  similar: number;
+}
+
+export interface Discography {
+  albums: number;
+  artist_mbid: string;
+  eps: number;
+  first_release_year?: number;
+  last_release_year?: number;
+  other: number;
+  release_groups: ReleaseGroup[];
+  singles: number;
+}
+
+export interface ErrorResponse {
+  code: 'invalid_request' | 'unauthorized' | 'forbidden' | 'not_found' | 'method_not_allowed' | 'rate_limited' | 'internal_error' | 'not_implemented' | 'unavailable' | 'playlist_not_found' | 'no_seed_tracks' | 'no_recommendations' | 'engine_unavailable' | 'llm_quota_exceeded' | 'plex_unauthorized' | 'plex_error' | 'upstream_unavailable' | 'upstream_timeout'; // Stable identifier of the failure for clients to match on
+  details?: Record<string, unknown>; // Context such as the playlist, quota or retry_after, depending on the code
+  message: string; // Human-readable description
+  request_id: string; // ID of the request, also in the X-Request-ID header
+}
+
+export interface ExternalURLs {
+  discogs?: string;
+  lastfm?: string;
+  musicbrainz?: string;
+  spotify?: string;
+  wikidata?: string;
+  wikipedia?: string;
+}
+
+export interface HealthResponse {
+  database: {
+    error?: 'invalid_request' | 'unauthorized' | 'forbidden' | 'not_found' | 'method_not_allowed' | 'rate_limited' | 'internal_error' | 'not_implemented' | 'unavailable' | 'playlist_not_found' | 'no_seed_tracks' | 'no_recommendations' | 'engine_unavailable' | 'llm_quota_exceeded' | 'plex_unauthorized' | 'plex_error' | 'upstream_unavailable' | 'upstream_timeout'; // Code of the failure, the details are logged
+    expired_entries?: number;
+    status: 'connected' | 'error';
+    total_entries?: number;
+    valid_entries?: number;
+  };
+  plex: {
+    error?: 'invalid_request' | 'unauthorized' | 'forbidden' | 'not_found' | 'method_not_allowed' | 'rate_limited' | 'internal_error' | 'not_implemented' | 'unavailable' | 'playlist_not_found' | 'no_seed_tracks' | 'no_recommendations' | 'engine_unavailable' | 'llm_quota_exceeded' | 'plex_unauthorized' | 'plex_error' | 'upstream_unavailable' | 'upstream_timeout'; // Code of the failure, the details are logged
+    status: 'connected' | 'error';
+  };
+  service: string;
+  status: string;
+  timestamp: string;
+  version: string;
+}
+
+export interface ImportResponse {
+  ignored: number;
+  imported: number;
+  on_conflict: 'skip' | 'overwrite' | 'newest-wins';
+  skipped: number;
+  status: 'success';
+}
+
+export interface InfoResponse {
+  build: BuildInfo;
+  data_sources: string[];
+  description: string;
+  features: string[];
+  service: string;
+}
+
+export interface InvalidationResult {
+  artists: number;
+  queued?: number;
+  responses?: number;
+}
+
+export interface LabelAffiliation {
+  name: string;
+  releases: number;
+}
+
+export interface LivenessResponse {
+  status: 'alive';
+}
+
+export interface PlaylistsResponse {
+  count: number;
+  playlists: PlexPlaylist[];
+}
+
+export interface PlexPlaylist {
+  duration: number;
+  name: string;
+  smart: boolean;
+  track_count: number;
+  tracks: PlexTrack[];
+  type: string;
+}
+
+export interface PlexTestResponse {
+  server: Record<string, string>;
+  status: 'connected';
+}
+
+export interface PlexTrack {
+  album: string;
+  artist: string;
+  last_played: string;
+  play_count: number;
+  rating: number;
+  title: string;
+  year: number;
+}
+
+export interface QuotaPeriod {
+  completion_tokens: number;
+  cost_limit_usd?: number;
+  cost_usd: number;
+  exceeded: boolean;
+  prompt_tokens: number;
+  requests: number;
+  reset_at: string;
+  start: string;
+  token_limit?: number;
+  total_tokens: number;
+}
+
+export interface QuotaStatus {
+  daily: QuotaPeriod;
+  monthly: QuotaPeriod;
+}
+
+export interface ReadinessResponse {
+  checks: Record<string, 'ok' | 'error'>; // Result of each dependency check
+  status: 'ready' | 'unavailable' | 'draining';
+}
+
+export interface RecommendMetadata {
+  api_calls_made: number;
+  cache_hits: number;
+  engine: 'llm' | 'graph' | 'hybrid';
+  fallback_from?: string;
+  fallback_reason?: string;
+  generated_at: string;
+  known_artist_count: number;
+  processing_time: string;
+  seed_track_count: number;
+}
+
+export interface RecommendRequest {
+  engine?: 'llm' | 'graph' | 'hybrid';
+  genre?: string;
+  max_results?: number;
+  notify?: string[];
+  playlist_name: string;
+}
+
+export interface RecommendResponse {
+  error?: string;
+  metadata: RecommendMetadata;
+  request_id: string;
+  status: string;
+  suggestions: Artist[];
+  trace_id?: string;
+}
+
+export interface ReleaseGroup {
+  artist_mbid: string;
+  first_release_date: string;
+  mbid: string;
+  primary_type: string;
+  secondary_types: string[];
+  title: string;
+  year: number;
+}
+
+export interface RootResponse {
+  description: string;
+  endpoints: Record<string, string>; // Summary of each route, by method and path
+  service: string;
+  version: string;
+}
+
+export interface SimilarArtist {
+  artist_mbid: string;
+  cached: boolean;
+  listeners?: number;
+  match: number;
+  mbid?: string;
+  name: string;
+  source: string;
+  updated_at: string;
+}
+
+export interface SimilarArtistsResponse {
+  count: number;
+  mbid: string;
+  similar: SimilarArtist[];
+}
+
+export interface UsageResponse {
+  llm: QuotaStatus;
+  timestamp: string;
+}
+
+// Endpoints holds the path parameters, query, body and response of each operation
+export interface Endpoints {
+  // Write a database backup to the backup directory
+  backupDatabase: {
+    response: BackupResult;
+  };
+  // Clear cache entries
+  clearCache: {
+    query?: {
+      genre?: string; // Genre, including its subgenres
+      mbid?: string; // Comma separated MusicBrainz IDs
+      source?: string; // Artists verified by a source such as discogs, also expiring its stored responses; not combinable with the other filters
+      type?: 'expired' | 'all' | 'invalidate' | 'refresh'; // What to clear, default expired
+      updated_after?: string; // RFC 3339 timestamp or YYYY-MM-DD
+      updated_before?: string; // RFC 3339 timestamp or YYYY-MM-DD
+    };
+    response: CacheClearResponse;
+  };
+  // Get artist information by MusicBrainz ID
+  getArtist: {
+    params: {
+      mbid: string; // MusicBrainz artist ID
+    };
+    response: ArtistResponse;
+  };
+  // Get artist discography (albums, EPs, singles)
+  getArtistReleases: {
+    params: {
+      mbid: string; // MusicBrainz artist ID
+    };
+    response: Discography;
+  };
+  // Cache performance statistics
+  getCacheStats: {
+    response: CacheStats;
+  };
+  // Service health check
+  getHealth: {
+    response: HealthResponse;
+  };
+  // Detailed API and build information
+  getInfo: {
+    response: InfoResponse;
+  };
+  // Liveness probe
+  getLiveness: {
+    response: LivenessResponse;
+  };
+  // This OpenAPI document
+  getOpenAPI: {
+    response: Record<string, unknown>;
+  };
+  // List Plex playlists
+  getPlaylists: {
+    response: PlaylistsResponse;
+  };
+  // Readiness probe
+  getReadiness: {
+    response: ReadinessResponse;
+  };
+  // Get similar artists from the similarity graph
+  getSimilarArtists: {
+    params: {
+      mbid: string; // MusicBrainz artist ID
+    };
+    query?: {
+      limit?: number; // Maximum neighbours, 1 to 100, default 25
+      min_match?: number; // Minimum similarity, 0 to 1
+    };
+    response: SimilarArtistsResponse;
+  };
+  // LLM token and cost usage against the quotas
+  getUsage: {
+    response: UsageResponse;
+  };
+  // Search cached artists
+  listArtists: {
+    query?: {
+      country?: string; // ISO 3166 two-letter country code
+      genre?: string; // Genre, including its subgenres
+      limit?: number; // Page size, 1 to 200, default 50
+      max_listeners?: number; // Maximum Last.fm listeners, 0 for no limit
+      min_listeners?: number; // Minimum Last.fm listeners
+      offset?: number; // Results to skip
+      page?: string; // next_page of the previous response
+      q?: string; // Full-text search
+      sort?: 'relevance' | 'name' | 'listeners'; // Order of the results
+      verified?: string; // true, or a source name such as discogs
+    };
+    response: ArtistListResponse;
+  };
+  // Generate artist recommendations
+  recommend: {
+    body: RecommendRequest;
+    response: RecommendResponse;
+  };
+  // Test Plex connection
+  testPlex: {
+    response: PlexTestResponse;
+  };
+}
+
+// endpoints maps each operation to its method and path template
+export const endpoints = {
+  backupDatabase: { method: 'POST', path: '/api/admin/backup' },
+  clearCache: { method: 'POST', path: '/api/cache/clear' },
+  getArtist: { method: 'GET', path: '/api/artists/{mbid}' },
+  getArtistReleases: { method: 'GET', path: '/api/artists/{mbid}/releases' },
+  getCacheStats: { method: 'GET', path: '/api/cache/stats' },
+  getHealth: { method: 'GET', path: '/api/health' },
+  getInfo: { method: 'GET', path: '/api/info' },
+  getLiveness: { method: 'GET', path: '/api/health/live' },
+  getOpenAPI: { method: 'GET', path: '/api/openapi.json' },
+  getPlaylists: { method: 'GET', path: '/api/plex/playlists' },
+  getReadiness: { method: 'GET', path: '/api/health/ready' },
+  getSimilarArtists: { method: 'GET', path: '/api/artists/{mbid}/similar' },
+  getUsage: { method: 'GET', path: '/api/usage' },
+  listArtists: { method: 'GET', path: '/api/artists' },
+  recommend: { method: 'POST', path: '/api/recommend' },
+  testPlex: { method: 'GET', path: '/api/plex/test' },
+} as const satisfies { [K in keyof Endpoints]: { method: string; path: string } };
+
+// RequestOf is what a client sends for an operation
+export type RequestOf<K extends keyof Endpoints> = Omit<Endpoints[K], 'response'>;
+
+// ResponseOf is what an operation answers with
+export type ResponseOf<K extends keyof Endpoints> = Endpoints[K]['response'];
@@ -0,0 +1,11 @@
+// Package web holds the built web UI. It is only embedded when the server is
+// compiled with -tags embedui after npm run build has written web/dist; other
+// builds serve the API alone.
+package web
+
+import "io/fs"
+
+// Dist returns the built UI, or nil if this binary was compiled without it
+func Dist() fs.FS {
+	return dist
+}